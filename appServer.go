@@ -14,6 +14,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -33,6 +34,7 @@ type application struct {
 	params            AppParams
 	createContentFunc func(Session) SessionContent
 	sessions          map[int]Session
+	sessionsMutex     sync.RWMutex
 }
 
 func (app *application) getStartPage() string {
@@ -45,8 +47,28 @@ func (app *application) getStartPage() string {
 	return buffer.String()
 }
 
-func (app *application) Finish() {
+// sessionList returns the copy of the list of the application sessions
+func (app *application) sessionList() []Session {
+	app.sessionsMutex.RLock()
+	defer app.sessionsMutex.RUnlock()
+
+	result := make([]Session, 0, len(app.sessions))
 	for _, session := range app.sessions {
+		if session != nil {
+			result = append(result, session)
+		}
+	}
+	return result
+}
+
+func (app *application) session(id int) Session {
+	app.sessionsMutex.RLock()
+	defer app.sessionsMutex.RUnlock()
+	return app.sessions[id]
+}
+
+func (app *application) Finish() {
+	for _, session := range app.sessionList() {
 		session.close()
 	}
 
@@ -58,6 +80,7 @@ func (app *application) Finish() {
 	}
 }
 
+// nextSessionID returns the unused session id. The caller must hold sessionsMutex
 func (app *application) nextSessionID() int {
 	n := rand.Intn(0x7FFFFFFE) + 1
 	_, ok := app.sessions[n]
@@ -69,7 +92,9 @@ func (app *application) nextSessionID() int {
 }
 
 func (app *application) removeSession(id int) {
+	app.sessionsMutex.Lock()
 	delete(app.sessions, id)
+	app.sessionsMutex.Unlock()
 }
 
 func (app *application) ServeHTTP(w http.ResponseWriter, req *http.Request) {
@@ -87,7 +112,7 @@ func (app *application) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 		case "/ws":
 			if bridge := CreateSocketBridge(w, req); bridge != nil {
-				go app.socketReader(bridge)
+				go app.socketReader(bridge, req.Header.Get("Accept-Language"))
 			}
 
 		default:
@@ -101,10 +126,17 @@ func (app *application) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 				w.WriteHeader(http.StatusNotFound)
 			}
 		}
+
+	case "POST":
+		if id, ok := strings.CutPrefix(req.URL.Path[1:], uploadPathPrefix); ok {
+			serveFileUpload(id, w, req)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
 	}
 }
 
-func (app *application) socketReader(bridge webBridge) {
+func (app *application) socketReader(bridge webBridge, acceptLanguage string) {
 	var session Session
 	events := make(chan DataObject, 1024)
 
@@ -121,6 +153,10 @@ func (app *application) socketReader(bridge webBridge) {
 
 		if obj := ParseDataText(message); obj != nil {
 			command := obj.Tag()
+			if acceptLanguage != "" && (command == "startSession" || command == "reconnect") {
+				obj.SetPropertyValue("accept-language", acceptLanguage)
+			}
+
 			switch command {
 			case "startSession":
 				answer := ""
@@ -135,7 +171,7 @@ func (app *application) socketReader(bridge webBridge) {
 			case "reconnect":
 				if sessionText, ok := obj.PropertyValue("session"); ok {
 					if sessionID, err := strconv.Atoi(sessionText); err == nil {
-						if session = app.sessions[sessionID]; session != nil {
+						if session = app.session(sessionID); session != nil {
 							session.setBridge(events, bridge)
 							answer := allocStringBuilder()
 							defer freeStringBuilder(answer)
@@ -206,13 +242,21 @@ func (app *application) startSession(params DataObject, events chan DataObject,
 		return nil, ""
 	}
 
+	app.sessionsMutex.Lock()
 	session := newSession(app, app.nextSessionID(), "", params)
+	// the id is reserved until the content is created
+	app.sessions[session.ID()] = nil
+	app.sessionsMutex.Unlock()
+
 	session.setBridge(events, bridge)
 	if !session.setContent(app.createContentFunc(session)) {
+		app.removeSession(session.ID())
 		return nil, ""
 	}
 
+	app.sessionsMutex.Lock()
 	app.sessions[session.ID()] = session
+	app.sessionsMutex.Unlock()
 
 	answer := allocStringBuilder()
 	defer freeStringBuilder(answer)
@@ -231,6 +275,28 @@ func (app *application) startSession(params DataObject, events chan DataObject,
 }
 
 var apps = []*application{}
+var appsMutex sync.Mutex
+
+// allSessions returns the sessions of all started applications
+func allSessions() []Session {
+	appsMutex.Lock()
+	defer appsMutex.Unlock()
+
+	result := []Session{}
+	for _, app := range apps {
+		result = append(result, app.sessionList()...)
+	}
+	return result
+}
+
+// notifyThemeChanged passes the change of the theme to all sessions which use it
+func notifyThemeChanged(theme Theme) {
+	for _, session := range allSessions() {
+		if session.usesTheme(theme) {
+			session.themeChanged()
+		}
+	}
+}
 
 // StartApp - create the new application and start it
 func StartApp(addr string, createContentFunc func(Session) SessionContent, params AppParams) {
@@ -238,7 +304,9 @@ func StartApp(addr string, createContentFunc func(Session) SessionContent, param
 	app.params = params
 	app.sessions = map[int]Session{}
 	app.createContentFunc = createContentFunc
+	appsMutex.Lock()
 	apps = append(apps, app)
+	appsMutex.Unlock()
 
 	redirectAddr := ""
 	if index := strings.IndexRune(addr, ':'); index >= 0 {
@@ -21,6 +21,8 @@ type wasmApp struct {
 	close             chan DataObject
 }
 
+var wasmApplication *wasmApp
+
 func (app *wasmApp) Finish() {
 	app.session.close()
 }
@@ -61,6 +63,13 @@ func (app *wasmApp) handleMessage(this js.Value, args []js.Value) any {
 	return nil
 }
 
+// notifyThemeChanged updates the CSS of the session if it uses the theme
+func notifyThemeChanged(theme Theme) {
+	if app := wasmApplication; app != nil && app.session != nil && app.session.usesTheme(theme) {
+		app.session.updateThemeCSS()
+	}
+}
+
 func (app *wasmApp) removeSession(id int) {
 }
 
@@ -152,6 +161,11 @@ func (app *wasmApp) init(params AppParams) {
 	div.Set("style", "visibility: hidden;")
 	body.Call("appendChild", div)
 
+	div = document.Call("createElement", "div")
+	div.Set("className", "ruiToastLayer ruiToastBottomRight")
+	div.Set("id", "ruiToastLayer")
+	body.Call("appendChild", div)
+
 	div = document.Call("createElement", "a")
 	div.Set("id", "ruiDownloader")
 	div.Set("download", "")
@@ -174,6 +188,7 @@ func StartApp(addr string, createContentFunc func(Session) SessionContent, param
 	app.createContentFunc = createContentFunc
 	app.close = make(chan DataObject)
 	app.bridge = createWasmBridge(app.close)
+	wasmApplication = app
 
 	app.init(params)
 	<-app.close
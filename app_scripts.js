@@ -14,6 +14,20 @@ window.onblur = function(event) {
 	sendMessage( "session-pause{session=" + sessionID +"}" );
 }
 
+function colorSchemeChanged() {
+	var message = "colorSchemeChanged{session=" + sessionID;
+	if (window.matchMedia("(prefers-color-scheme: dark)").matches) {
+		message += ",dark=1";
+	}
+	if (window.matchMedia("(prefers-contrast: more)").matches) {
+		message += ",contrast=1";
+	}
+	sendMessage(message + "}");
+}
+
+window.matchMedia("(prefers-color-scheme: dark)").addEventListener("change", colorSchemeChanged);
+window.matchMedia("(prefers-contrast: more)").addEventListener("change", colorSchemeChanged);
+
 function sessionInfo() {
 
 	const touch_screen = (('ontouchstart' in document.documentElement) || (navigator.maxTouchPoints > 0) || (navigator.msMaxTouchPoints > 0)) ? "1" : "0";
@@ -47,6 +61,10 @@ function sessionInfo() {
 		message += ",dark=1";
 	} 
 
+	if (window.matchMedia("(prefers-contrast: more)").matches) {
+		message += ",contrast=1";
+	}
+
 	const pixelRatio = window.devicePixelRatio;
 	if (pixelRatio) {
 		message += ",pixel-ratio=" + pixelRatio;
@@ -160,6 +178,44 @@ function updateCSSRule(selector, ruleText) {
 	scanElementsSize();
 }
 
+function replaceCSSRule(selector, ruleText) {
+	var styleSheet = document.querySelector('style').sheet;
+	if (!styleSheet) {
+		return;
+	}
+	var rules = styleSheet.cssRules;
+	var compact = function(text) {
+		return text.replace(/\s/g, "");
+	}
+	selector = compact(selector);
+
+	var index = -1;
+	var lastStyleRule = -1;
+	for (var i = rules.length - 1; i >= 0; i--) {
+		var rule = rules[i];
+		if (!rule.selectorText) {
+			continue;
+		}
+		if (lastStyleRule < 0) {
+			lastStyleRule = i;
+		}
+		if (compact(rule.selectorText) == selector) {
+			index = i;
+			break;
+		}
+	}
+
+	if (index >= 0) {
+		styleSheet.deleteRule(index);
+	} else {
+		index = lastStyleRule + 1;
+	}
+	if (ruleText) {
+		styleSheet.insertRule(ruleText, index);
+	}
+	scanElementsSize();
+}
+
 function updateCSSStyle(elementId, style) {
 	var element = document.getElementById(elementId);
 	if (element) {
@@ -433,6 +489,14 @@ function clickEvent(element, event) {
 	event.stopPropagation();
 }
 
+function chartWheelEvent(element, event) {
+	event.preventDefault();
+	const rect = element.getBoundingClientRect();
+	var message = "chart-wheel{session=" + sessionID + ",id=" + element.id +
+		",x=" + (event.clientX - rect.left) + ",y=" + (event.clientY - rect.top) + ",delta=" + event.deltaY + "}";
+	sendMessage(message);
+}
+
 function doubleClickEvent(element, event) {
 	mouseEvent(element, event, "double-click-event")
 	event.preventDefault();
@@ -942,6 +1006,470 @@ function listViewBlurEvent(element, event) {
 	}
 }
 
+function treeItemMessage(tree, item, command, event) {
+	var message = command + "{session=" + sessionID + ",id=" + tree.id + ",path=\"" + item.getAttribute("data-path") + "\"";
+	if (event) {
+		if (event.ctrlKey || event.metaKey) {
+			message += ",ctrl=1";
+		}
+		if (event.shiftKey) {
+			message += ",shift=1";
+		}
+	}
+	sendMessage(message + "}");
+}
+
+function selectTreeItem(tree, item, command, event) {
+	var currentId = tree.getAttribute("data-current");
+	const focusStyle = getListFocusedItemStyle(tree);
+	const blurStyle = getListSelectedItemStyle(tree);
+
+	if (currentId && currentId != item.id) {
+		var current = document.getElementById(currentId);
+		if (current && current.classList) {
+			current.classList.remove(focusStyle, blurStyle);
+		}
+	}
+
+	if (item.classList) {
+		item.classList.remove(focusStyle, blurStyle);
+		if (tree === document.activeElement) {
+			item.classList.add(focusStyle);
+		} else {
+			item.classList.add(blurStyle);
+		}
+	}
+
+	tree.setAttribute("data-current", item.id);
+	if (item.scrollIntoViewIfNeeded) {
+		item.scrollIntoViewIfNeeded()
+	} else {
+		item.scrollIntoView({block: "nearest", inline: "nearest"});
+	}
+
+	if (command) {
+		treeItemMessage(tree, item, command, event);
+	}
+}
+
+function selectTreeItemByID(treeId, itemId) {
+	const tree = document.getElementById(treeId);
+	const item = document.getElementById(itemId);
+	if (tree && item) {
+		selectTreeItem(tree, item);
+	}
+}
+
+function treeItemClickEvent(element, event) {
+	event.stopPropagation();
+
+	if (element.getAttribute("data-disabled") == "1") {
+		return
+	}
+
+	const tree = document.getElementById(element.getAttribute("data-tree"));
+	if (tree) {
+		selectTreeItem(tree, element, "treeItemClick", event);
+	}
+}
+
+function treeToggleClickEvent(element, event) {
+	event.stopPropagation();
+	const item = element.parentNode;
+	const tree = document.getElementById(item.getAttribute("data-tree"));
+	if (tree && item.getAttribute("data-expanded") != "-1") {
+		treeItemMessage(tree, item, "treeItemToggle");
+	}
+}
+
+function treeCheckboxClickEvent(element, event) {
+	event.stopPropagation();
+	const item = element.parentNode;
+	if (item.getAttribute("data-disabled") == "1") {
+		return
+	}
+	const tree = document.getElementById(item.getAttribute("data-tree"));
+	if (tree) {
+		selectTreeItem(tree, item, "treeItemSelected");
+		treeItemMessage(tree, item, "treeItemCheck");
+	}
+}
+
+function treeViewItems(tree) {
+	var result = [];
+	const items = tree.querySelectorAll("[data-tree='" + tree.id + "']");
+	for (var i = 0; i < items.length; i++) {
+		if (items[i].getAttribute("data-disabled") != "1") {
+			result.push(items[i]);
+		}
+	}
+	return result;
+}
+
+function treeViewKeyDownEvent(element, event) {
+	const key = getKey(event);
+	if (!key) {
+		return;
+	}
+
+	const items = treeViewItems(element);
+	if (items.length == 0) {
+		return;
+	}
+
+	var current;
+	const currentId = element.getAttribute("data-current");
+	if (currentId) {
+		current = document.getElementById(currentId);
+	}
+
+	var index = current ? items.indexOf(current) : -1;
+	var item;
+
+	switch (key) {
+		case "ArrowDown":
+			item = items[Math.min(index + 1, items.length - 1)];
+			break;
+
+		case "ArrowUp":
+			item = items[Math.max(index - 1, 0)];
+			break;
+
+		case "Home":
+			item = items[0];
+			break;
+
+		case "End":
+			item = items[items.length - 1];
+			break;
+
+		case "ArrowRight":
+			if (!current) {
+				item = items[0];
+			} else if (current.getAttribute("data-expanded") == "0") {
+				treeItemMessage(element, current, "treeItemToggle");
+			} else if (current.getAttribute("data-expanded") == "1" && index + 1 < items.length) {
+				item = items[index + 1];
+			}
+			break;
+
+		case "ArrowLeft":
+			if (!current) {
+				item = items[0];
+			} else if (current.getAttribute("data-expanded") == "1") {
+				treeItemMessage(element, current, "treeItemToggle");
+			} else {
+				const parentId = current.getAttribute("data-parent");
+				if (parentId) {
+					item = document.getElementById(parentId);
+				}
+			}
+			break;
+
+		case " ":
+			if (current) {
+				if (current.querySelector(".ruiTreeCheckbox")) {
+					treeItemMessage(element, current, "treeItemCheck");
+				} else {
+					treeItemMessage(element, current, "treeItemClick", event);
+				}
+			} else {
+				item = items[0];
+			}
+			break;
+
+		case "Enter":
+			if (current) {
+				treeItemMessage(element, current, "treeItemClick", event);
+			} else {
+				item = items[0];
+			}
+			break;
+
+		default:
+			return;
+	}
+
+	if (item && item !== current) {
+		selectTreeItem(element, item, "treeItemSelected", event);
+	}
+
+	event.stopPropagation();
+	event.preventDefault();
+}
+
+function treeViewFocusEvent(element, event) {
+	listViewFocusEvent(element, event);
+}
+
+function treeViewBlurEvent(element, event) {
+	listViewBlurEvent(element, event);
+}
+
+function codeEditorID(textarea) {
+	return textarea.id.substring(0, textarea.id.length - 5);
+}
+
+function codeEditorEscape(text) {
+	text = text.replaceAll(/\\/g, "\\\\")
+	return text.replaceAll(/\"/g, "\\\"")
+}
+
+function codePointLength(text) {
+	var count = 0;
+	for (const ch of text) {
+		count++;
+	}
+	return count;
+}
+
+function codePointToIndex(text, offset) {
+	var index = 0;
+	for (const ch of text) {
+		if (offset <= 0) {
+			break;
+		}
+		index += ch.length;
+		offset--;
+	}
+	return index;
+}
+
+function codeEditorUpdateView(textarea) {
+	const id = codeEditorID(textarea);
+	const text = textarea.value;
+
+	var gutter = document.getElementById(id + "-gutter");
+	if (gutter) {
+		const lineCount = text.split("\n").length;
+		if (gutter.lineCount != lineCount) {
+			var numbers = "";
+			for (var i = 1; i <= lineCount; i++) {
+				numbers += i + "\n";
+			}
+			gutter.textContent = numbers;
+			gutter.lineCount = lineCount;
+		}
+	}
+
+	codeEditorScrollEvent(textarea);
+}
+
+function codeEditorInputEvent(textarea) {
+	const oldText = textarea.codeText != undefined ? textarea.codeText : textarea.defaultValue;
+	const text = textarea.value;
+
+	var start = 0;
+	const minLength = Math.min(oldText.length, text.length);
+	while (start < minLength && oldText.charCodeAt(start) == text.charCodeAt(start)) {
+		start++;
+	}
+	if (start > 0 && start < minLength && (oldText.charCodeAt(start - 1) & 0xFC00) == 0xD800) {
+		start--;	// do not split a surrogate pair
+	}
+
+	var oldEnd = oldText.length;
+	var newEnd = text.length;
+	while (oldEnd > start && newEnd > start && oldText.charCodeAt(oldEnd - 1) == text.charCodeAt(newEnd - 1)) {
+		oldEnd--;
+		newEnd--;
+	}
+	if (oldEnd < oldText.length && (oldText.charCodeAt(oldEnd) & 0xFC00) == 0xDC00) {
+		oldEnd++;
+		newEnd++;
+	}
+
+	textarea.codeText = text;
+	textarea.codeVersion = (textarea.codeVersion || 0) + 1;
+
+	var code = document.getElementById(codeEditorID(textarea) + "-code");
+	if (code) {
+		code.textContent = text + "\n";
+	}
+	codeEditorUpdateView(textarea);
+
+	const prefix = codePointLength(oldText.substring(0, start));
+	const message = "codeEdit{session=" + sessionID + ",id=" + codeEditorID(textarea) +
+		",version=" + textarea.codeVersion +
+		",start=" + prefix +
+		",end=" + (prefix + codePointLength(oldText.substring(start, oldEnd))) +
+		",text=\"" + codeEditorEscape(text.substring(start, newEnd)) + "\"}";
+	sendMessage(message);
+	codeEditorSelectionEvent(textarea);
+}
+
+function codeEditorKeyDownEvent(textarea, event) {
+	if (event.key == "Tab" && !event.shiftKey && !event.ctrlKey && !event.altKey && !event.metaKey) {
+		event.preventDefault();
+		if (textarea.readOnly || textarea.disabled) {
+			return;
+		}
+		const tab = textarea.getAttribute("data-tab") || "\t";
+		if (!document.execCommand || !document.execCommand("insertText", false, tab)) {
+			textarea.setRangeText(tab, textarea.selectionStart, textarea.selectionEnd, "end");
+			codeEditorInputEvent(textarea);
+		}
+	}
+}
+
+function codeEditorSelectionEvent(textarea) {
+	const text = textarea.value;
+	const start = codePointLength(text.substring(0, textarea.selectionStart));
+	const end = start + codePointLength(text.substring(textarea.selectionStart, textarea.selectionEnd));
+
+	if (textarea.codeSelectionStart !== start || textarea.codeSelectionEnd !== end) {
+		textarea.codeSelectionStart = start;
+		textarea.codeSelectionEnd = end;
+		sendMessage("codeSelection{session=" + sessionID + ",id=" + codeEditorID(textarea) +
+			",start=" + start + ",end=" + end + "}");
+	}
+	codeEditorCurrentLine(textarea);
+}
+
+function codeEditorLineHeight(textarea) {
+	const style = window.getComputedStyle(textarea);
+	const height = parseFloat(style.lineHeight);
+	return isNaN(height) ? parseFloat(style.fontSize) * 1.2 : height;
+}
+
+function codeEditorCurrentLine(textarea) {
+	var line = document.getElementById(codeEditorID(textarea) + "-line");
+	if (line) {
+		const style = window.getComputedStyle(textarea);
+		const lineNumber = textarea.value.substring(0, textarea.selectionStart).split("\n").length - 1;
+		const lineHeight = codeEditorLineHeight(textarea);
+		line.style.top = (parseFloat(style.paddingTop) + lineNumber * lineHeight - textarea.scrollTop) + "px";
+		line.style.height = lineHeight + "px";
+	}
+}
+
+function codeEditorScrollEvent(textarea) {
+	const id = codeEditorID(textarea);
+	var code = document.getElementById(id + "-code");
+	if (code) {
+		code.scrollTop = textarea.scrollTop;
+		code.scrollLeft = textarea.scrollLeft;
+	}
+	var gutter = document.getElementById(id + "-gutter");
+	if (gutter) {
+		gutter.scrollTop = textarea.scrollTop;
+	}
+	codeEditorCurrentLine(textarea);
+}
+
+function setCodeEditorText(textareaId, text, html) {
+	var textarea = document.getElementById(textareaId);
+	if (textarea) {
+		const start = textarea.selectionStart;
+		const end = textarea.selectionEnd;
+		textarea.value = text;
+		textarea.codeText = text;
+		textarea.setSelectionRange(Math.min(start, text.length), Math.min(end, text.length));
+
+		var code = document.getElementById(codeEditorID(textarea) + "-code");
+		if (code) {
+			code.innerHTML = html;
+		}
+		codeEditorUpdateView(textarea);
+	}
+}
+
+function setCodeHighlight(textareaId, version, html) {
+	var textarea = document.getElementById(textareaId);
+	if (textarea && textarea.codeVersion == version) {
+		var code = document.getElementById(codeEditorID(textarea) + "-code");
+		if (code) {
+			code.innerHTML = html;
+			codeEditorScrollEvent(textarea);
+		}
+	}
+}
+
+function setCodeSelection(textareaId, start, end) {
+	var textarea = document.getElementById(textareaId);
+	if (textarea) {
+		const text = textarea.value;
+		const startIndex = codePointToIndex(text, start);
+		const endIndex = startIndex + codePointToIndex(text.substring(startIndex), end - start);
+		textarea.codeSelectionStart = start;
+		textarea.codeSelectionEnd = end;
+		textarea.focus();
+		textarea.setSelectionRange(startIndex, endIndex);
+
+		const lineNumber = text.substring(0, startIndex).split("\n").length - 1;
+		const lineHeight = codeEditorLineHeight(textarea);
+		const top = lineNumber * lineHeight;
+		if (top < textarea.scrollTop || top + lineHeight > textarea.scrollTop + textarea.clientHeight) {
+			textarea.scrollTop = Math.max(0, top - textarea.clientHeight / 2);
+		}
+		codeEditorScrollEvent(textarea);
+	}
+}
+
+function calendarDayClickEvent(calendarId, element, event) {
+	event.stopPropagation();
+	sendMessage("calendarDayClick{session=" + sessionID + ",id=" + calendarId + ",date=" + element.getAttribute("data-date") + "}");
+}
+
+function calendarPageEvent(calendarId, step, event) {
+	event.stopPropagation();
+	sendMessage("calendarPage{session=" + sessionID + ",id=" + calendarId + ",step=" + step + "}");
+}
+
+function dateRangePickerClickEvent(element, event) {
+	event.stopPropagation();
+	sendMessage("dateRangePickerClick{session=" + sessionID + ",id=" + element.id + "}");
+}
+
+function dateRangePickerKeyDownEvent(element, event) {
+	if (enterOrSpaceKeyClickEvent(event)) {
+		dateRangePickerClickEvent(element, event);
+	}
+}
+
+var toastTimers = {};
+
+function startToastTimer(toastId) {
+	const element = document.getElementById(toastId);
+	if (!element || toastTimers[toastId]) {
+		return;
+	}
+	const timeout = parseInt(getIntAttribute(element, "data-timeout"));
+	if (timeout > 0) {
+		toastTimers[toastId] = window.setTimeout(function() {
+			delete toastTimers[toastId];
+			if (document.getElementById(toastId)) {
+				sendMessage("toastClose{session=" + sessionID + ",id=" + toastId + "}");
+			}
+		}, timeout);
+	}
+}
+
+function stopToastTimer(toastId) {
+	if (toastTimers[toastId]) {
+		window.clearTimeout(toastTimers[toastId]);
+		delete toastTimers[toastId];
+	}
+}
+
+function toastMouseEnter(element) {
+	stopToastTimer(element.id);
+}
+
+function toastMouseLeave(element) {
+	startToastTimer(element.id);
+}
+
+function toastActionClick(toastId, action) {
+	stopToastTimer(toastId);
+	sendMessage("toastAction{session=" + sessionID + ",id=" + toastId + ",action=" + action + "}");
+}
+
+function toastCloseClick(toastId) {
+	stopToastTimer(toastId);
+	sendMessage("toastClose{session=" + sessionID + ",id=" + toastId + "}");
+}
+
 function selectRadioButton(radioButtonId) {
 	var element = document.getElementById(radioButtonId);
 	if (element) {
@@ -1064,6 +1592,124 @@ function loadSelectedFile(elementId, index) {
 	}
 }
 
+var fileUploads = {};
+var fileUploadMessages = [];
+
+function uploadSelectedFile(elementId, index, uploadId, chunkSize) {
+	const element = document.getElementById(elementId);
+	const files = element ? element.files : null;
+	startFileUpload(elementId, files && index >= 0 && index < files.length ? files[index] : null, uploadId, chunkSize);
+}
+
+function startFileUpload(elementId, file, uploadId, chunkSize) {
+	if (!file) {
+		sendFileUploadMessage("fileUploadError{session=" + sessionID + ",id=" + elementId + 
+			",upload=" + uploadId + ",error=`File not found`}");
+		return;
+	}
+
+	const upload = { elementId: elementId, id: uploadId, file: file, chunkSize: chunkSize, 
+		offset: 0, retry: 0, request: null, timer: null };
+	fileUploads[uploadId] = upload;
+	sendFileUploadChunk(upload);
+}
+
+function sendFileUploadChunk(upload) {
+	const request = new XMLHttpRequest();
+	upload.request = request;
+	request.open("POST", "/upload/" + upload.id + "?offset=" + upload.offset);
+	request.onload = function() {
+		upload.request = null;
+		const offset = parseInt(request.getResponseHeader("Upload-Offset"));
+		switch (request.status) {
+		case 200:
+		case 409:
+			if (!isNaN(offset)) {
+				upload.offset = offset;
+			}
+			upload.retry = 0;
+			if (upload.offset >= upload.file.size) {
+				delete fileUploads[upload.id];
+				sendFileUploadMessage("fileUploaded{session=" + sessionID + ",id=" + upload.elementId + 
+					",upload=" + upload.id + "}");
+			} else {
+				sendMessage("fileUploadProgress{session=" + sessionID + ",id=" + upload.elementId + 
+					",upload=" + upload.id + ",loaded=" + upload.offset + "}");
+				sendFileUploadChunk(upload);
+			}
+			break;
+
+		case 408:
+		case 502:
+		case 503:
+		case 504:
+			retryFileUpload(upload);
+			break;
+
+		default:
+			delete fileUploads[upload.id];
+			sendFileUploadMessage("fileUploadError{session=" + sessionID + ",id=" + upload.elementId + 
+				",upload=" + upload.id + ",error=`" + (request.responseText || request.statusText).trim() + "`}");
+		}
+	};
+	request.onerror = function() {
+		upload.request = null;
+		retryFileUpload(upload);
+	};
+	request.send(upload.file.slice(upload.offset, Math.min(upload.offset + upload.chunkSize, upload.file.size)));
+}
+
+function retryFileUpload(upload) {
+	if (fileUploads[upload.id]) {
+		const delay = Math.min(30000, 1000 * Math.pow(2, upload.retry));
+		upload.retry++;
+		upload.timer = setTimeout(function() {
+			upload.timer = null;
+			sendFileUploadChunk(upload);
+		}, delay);
+	}
+}
+
+function cancelFileUpload(uploadId) {
+	const upload = fileUploads[uploadId];
+	if (upload) {
+		delete fileUploads[uploadId];
+		if (upload.timer) {
+			clearTimeout(upload.timer);
+		}
+		if (upload.request) {
+			upload.request.abort();
+		}
+	}
+}
+
+function sendFileUploadMessage(message) {
+	// the final messages are kept until the connection is restored
+	if (window.socket === null || (window.socket && window.socket.readyState !== WebSocket.OPEN)) {
+		fileUploadMessages.push(message);
+	} else {
+		sendMessage(message);
+	}
+}
+
+function resumeFileUploads() {
+	const messages = fileUploadMessages;
+	fileUploadMessages = [];
+	for (const message of messages) {
+		sendMessage(message);
+	}
+
+	for (const id in fileUploads) {
+		const upload = fileUploads[id];
+		if (upload.timer) {
+			clearTimeout(upload.timer);
+			upload.timer = null;
+			upload.retry = 0;
+			sendFileUploadChunk(upload);
+		}
+	}
+}
+
 function startResize(element, mx, my, event) {
 	var view = element.parentNode;
 	if (!view) {
@@ -1400,9 +2046,250 @@ function mediaSetVolume(elementId, volume) {
 	}
 }
 
-function startDownload(url, filename) {
+var mediaRecorders = {};
+var mediaRecorderObserver = null;
+
+function mediaRecorderData(elementId) {
+	let data = mediaRecorders[elementId];
+	if (!data) {
+		data = { stream: null, recorder: null, chunks: [], media: null, url: null, opening: null, 
+			element: document.getElementById(elementId) };
+		mediaRecorders[elementId] = data;
+		observeMediaRecorders();
+	}
+	return data;
+}
+
+function observeMediaRecorders() {
+	if (!mediaRecorderObserver && window.MutationObserver) {
+		mediaRecorderObserver = new MutationObserver(checkMediaRecorders);
+		mediaRecorderObserver.observe(document.body, { childList: true, subtree: true });
+	}
+}
+
+// checkMediaRecorders releases the camera and the microphone of the recorders removed from the page
+// and attaches the preview to the re-created elements
+function checkMediaRecorders() {
+	for (const elementId in mediaRecorders) {
+		const element = document.getElementById(elementId);
+		if (!element) {
+			releaseMediaRecorder(elementId);
+		} else if (element !== mediaRecorders[elementId].element) {
+			showMediaRecorderPreview(elementId);
+		}
+	}
+
+	if (mediaRecorderObserver && Object.keys(mediaRecorders).length == 0) {
+		mediaRecorderObserver.disconnect();
+		mediaRecorderObserver = null;
+	}
+}
+
+function releaseMediaRecorder(elementId) {
+	const data = mediaRecorders[elementId];
+	if (data) {
+		closeMediaRecorder(elementId);
+		delete mediaRecorders[elementId];
+		data.chunks = [];
+		if (data.url) {
+			URL.revokeObjectURL(data.url);
+			data.url = null;
+		}
+	}
+}
+
+function mediaRecorderState(elementId, state) {
+	sendMessage("recorder-state-changed-event{session=" + sessionID + ",id=" + elementId + ",state=" + state + "}");
+}
+
+function mediaRecorderError(elementId, error) {
+	let code = 0;
+	switch (error.name) {
+	case "NotAllowedError":
+	case "SecurityError":
+		code = 1;
+		break;
+
+	case "NotFoundError":
+	case "OverconstrainedError":
+		code = 2;
+		break;
+
+	case "NotSupportedError":
+		code = 3;
+		break;
+
+	case "NotReadableError":
+	case "AbortError":
+		code = 4;
+		break;
+	}
+	const message = (error.message || error.name || "").replace(/`/g, "'");
+	sendMessage("recorder-error-event{session=" + sessionID + ",id=" + elementId + ",code=" + code + ",message=`" + message + "`}");
+}
+
+function showMediaRecorderPreview(elementId) {
+	const element = document.getElementById(elementId);
+	const data = mediaRecorders[elementId];
+	if (element && data) {
+		data.element = element;
+		if (data.stream && element.tagName == "VIDEO") {
+			element.srcObject = data.stream;
+			element.muted = true;
+			element.play().catch(function() {});
+		} else {
+			element.srcObject = null;
+			if (data.url) {
+				element.src = data.url;
+			} else {
+				element.removeAttribute("src");
+			}
+		}
+	}
+}
+
+function openMediaRecorder(elementId, video, audio, facingMode) {
+	const data = mediaRecorderData(elementId);
+	if (data.stream) {
+		return Promise.resolve(data.stream);
+	}
+	if (data.opening) {
+		return data.opening;
+	}
+
+	if (!navigator.mediaDevices || !navigator.mediaDevices.getUserMedia || !window.MediaRecorder) {
+		mediaRecorderError(elementId, { name: "NotSupportedError", message: "Media capture is not supported" });
+		return Promise.reject();
+	}
+
+	const constraints = { audio: audio, video: video ? { facingMode: facingMode } : false };
+	data.opening = navigator.mediaDevices.getUserMedia(constraints).then(function(stream) {
+		data.opening = null;
+		data.stream = stream;
+		showMediaRecorderPreview(elementId);
+		mediaRecorderState(elementId, 1);
+		return stream;
+	}, function(error) {
+		data.opening = null;
+		mediaRecorderError(elementId, error);
+		throw error;
+	});
+	return data.opening;
+}
+
+function startMediaRecorder(elementId, video, audio, facingMode, mimeType) {
+	openMediaRecorder(elementId, video, audio, facingMode).then(function(stream) {
+		const data = mediaRecorderData(elementId);
+		if (data.recorder && data.recorder.state != "inactive") {
+			return;
+		}
+
+		let recorder;
+		try {
+			recorder = mimeType && MediaRecorder.isTypeSupported(mimeType) ? 
+				new MediaRecorder(stream, { mimeType: mimeType }) : new MediaRecorder(stream);
+		} catch (error) {
+			mediaRecorderError(elementId, error);
+			return;
+		}
+
+		data.recorder = recorder;
+		data.chunks = [];
+		recorder.ondataavailable = function(event) {
+			if (mediaRecorders[elementId] !== data) {
+				return;
+			}
+			if (event.data && event.data.size > 0) {
+				data.chunks.push(event.data);
+			}
+		};
+		recorder.onpause = function() {
+			mediaRecorderState(elementId, 3);
+		};
+		recorder.onresume = function() {
+			mediaRecorderState(elementId, 2);
+		};
+		recorder.onerror = function(event) {
+			mediaRecorderError(elementId, event.error || { name: "", message: "Recording error" });
+		};
+		recorder.onstop = function() {
+			if (mediaRecorders[elementId] !== data) {
+				// the recorder is removed from the page
+				data.chunks = [];
+				data.recorder = null;
+				return;
+			}
+			const type = recorder.mimeType || (data.chunks.length > 0 ? data.chunks[0].type : "");
+			data.media = new Blob(data.chunks, { type: type });
+			data.chunks = [];
+			data.recorder = null;
+			if (data.url) {
+				URL.revokeObjectURL(data.url);
+			}
+			data.url = URL.createObjectURL(data.media);
+			if (!data.stream) {
+				showMediaRecorderPreview(elementId);
+			}
+			sendMessage("media-recorded-event{session=" + sessionID + ",id=" + elementId + 
+				",size=" + data.media.size + ",mime-type=`" + data.media.type + "`,last-modified=" + Date.now() + "}");
+			mediaRecorderState(elementId, data.stream ? 1 : 0);
+		};
+		recorder.start(1000);
+		mediaRecorderState(elementId, 2);
+	}, function() {});
+}
+
+function pauseMediaRecorder(elementId) {
+	const data = mediaRecorders[elementId];
+	if (data && data.recorder && data.recorder.state == "recording") {
+		data.recorder.pause();
+	}
+}
+
+function resumeMediaRecorder(elementId) {
+	const data = mediaRecorders[elementId];
+	if (data && data.recorder && data.recorder.state == "paused") {
+		data.recorder.resume();
+	}
+}
+
+function stopMediaRecorder(elementId) {
+	const data = mediaRecorders[elementId];
+	if (data && data.recorder && data.recorder.state != "inactive") {
+		data.recorder.stop();
+	}
+}
+
+function closeMediaRecorder(elementId) {
+	const data = mediaRecorders[elementId];
+	if (data && data.stream) {
+		const stream = data.stream;
+		data.stream = null;
+		if (data.recorder && data.recorder.state != "inactive") {
+			// the state is sent by the "stop" handler
+			data.recorder.stop();
+		} else {
+			mediaRecorderState(elementId, 0);
+		}
+		for (const track of stream.getTracks()) {
+			track.stop();
+		}
+		showMediaRecorderPreview(elementId);
+	}
+}
+
+function uploadRecordedMedia(elementId, uploadId, chunkSize) {
+	const data = mediaRecorders[elementId];
+	startFileUpload(elementId, data ? data.media : null, uploadId, chunkSize);
+}
+
+function startDownload(url, filename, cookieName, key, maxAge) {
 	var element = document.getElementById("ruiDownloader");
 	if (element) {
+		if (cookieName) {
+			// the download key of the session is checked by the server
+			document.cookie = cookieName + "=" + key + "; max-age=" + maxAge + "; path=/; samesite=strict";
+		}
 		element.href = url;
 		element.setAttribute("download", filename);
 		element.click();
@@ -1918,6 +2805,98 @@ function getPropertyValue(answerID, elementId, name) {
 	sendMessage('answer{answerID=' + answerID + ', value=""}')
 }
 
+function canvasImageDataBytes(data) {
+	if (typeof data === 'string') {
+		const text = atob(data);
+		const bytes = new Uint8ClampedArray(text.length);
+		for (let i = 0; i < text.length; i++) {
+			bytes[i] = text.charCodeAt(i);
+		}
+		return bytes;
+	}
+	return new Uint8ClampedArray(data.buffer, data.byteOffset, data.byteLength);
+}
+
+function putCanvasImageData(ctx, data, width, height, x, y) {
+	if (ctx) {
+		const dpr = window.devicePixelRatio || 1;
+		const imageData = new ImageData(canvasImageDataBytes(data), width, height);
+		ctx.putImageData(imageData, Math.round(x * dpr), Math.round(y * dpr));
+	}
+}
+
+function drawCanvasImageData(ctx, data, width, height, x, y, w, h) {
+	if (ctx) {
+		const canvas = document.createElement('canvas');
+		canvas.width = width;
+		canvas.height = height;
+		canvas.getContext('2d').putImageData(new ImageData(canvasImageDataBytes(data), width, height), 0, 0);
+		ctx.drawImage(canvas, x, y, w, h);
+	}
+}
+
+function readCanvasImageData(elementId, x, y, width, height) {
+	const canvas = document.getElementById(elementId);
+	if (canvas) {
+		const ctx = canvas.getContext('2d');
+		if (ctx) {
+			const dpr = window.devicePixelRatio || 1;
+			const w = Math.round(width * dpr);
+			const h = Math.round(height * dpr);
+			if (w > 0 && h > 0) {
+				return ctx.getImageData(Math.round(x * dpr), Math.round(y * dpr), w, h);
+			}
+		}
+	}
+	return null;
+}
+
+function canvasErrorText(error) {
+	return (error.message || error.name || String(error)).replace(/`/g, "'");
+}
+
+function getCanvasImageData(answerID, elementId, x, y, width, height) {
+	let imageData;
+	try {
+		// getImageData throws SecurityError if the canvas is tainted by a cross-origin image
+		imageData = readCanvasImageData(elementId, x, y, width, height);
+	} catch (error) {
+		sendMessage('answer{answerID=' + answerID + ', width=0, height=0, error=`' + canvasErrorText(error) + '`}');
+		return
+	}
+	if (!imageData) {
+		sendMessage('answer{answerID=' + answerID + ', width=0, height=0}');
+		return
+	}
+
+	const bytes = imageData.data;
+	var text = '';
+	for (let i = 0; i < bytes.length; i += 0x8000) {
+		text += String.fromCharCode.apply(null, bytes.subarray(i, i + 0x8000));
+	}
+
+	sendMessage('answer{answerID=' + answerID + ', width=' + imageData.width + ', height=' + imageData.height + 
+		', data="' + btoa(text) + '"}');
+}
+
+function getCanvasDataURL(answerID, elementId, type, quality) {
+	const canvas = document.getElementById(elementId);
+	if (canvas) {
+		let url;
+		try {
+			// toDataURL throws SecurityError if the canvas is tainted by a cross-origin image
+			url = quality >= 0 ? canvas.toDataURL(type, quality) : canvas.toDataURL(type);
+		} catch (error) {
+			sendMessage('answer{answerID=' + answerID + ', url="", error=`' + canvasErrorText(error) + '`}');
+			return
+		}
+		sendMessage('answer{answerID=' + answerID + ', url="' + url + '"}');
+		return
+	}
+
+	sendMessage('answer{answerID=' + answerID + ', url=""}');
+}
+
 function appendStyles(styles) {
 	document.querySelector('style').textContent += styles
 }
@@ -1937,6 +2916,78 @@ function getCanvasContext(elementId) {
 	return null;
 }
 
+var canvasAnimations = {};
+
+function startCanvasAnimation(elementId) {
+	var animation = canvasAnimations[elementId];
+	if (!animation) {
+		animation = { frame: 0, time: 0, last: null, request: 0, waiting: false, running: false };
+		canvasAnimations[elementId] = animation;
+	}
+	animation.running = true;
+	requestCanvasFrame(elementId, animation);
+}
+
+function stopCanvasAnimation(elementId) {
+	const animation = canvasAnimations[elementId];
+	if (animation) {
+		if (animation.request) {
+			window.cancelAnimationFrame(animation.request);
+		}
+		delete canvasAnimations[elementId];
+	}
+}
+
+function requestCanvasFrame(elementId, animation) {
+	if (!animation.running || animation.waiting || animation.request || document.hidden) {
+		return;
+	}
+
+	animation.request = window.requestAnimationFrame(function(timestamp) {
+		animation.request = 0;
+		if (!document.getElementById(elementId)) {
+			delete canvasAnimations[elementId];
+			return;
+		}
+
+		var delta = 0;
+		if (animation.last != null) {
+			delta = timestamp - animation.last;
+		}
+		animation.last = timestamp;
+		animation.time += delta;
+		animation.waiting = true;
+
+		sendMessage("canvas-frame{session=" + sessionID + ",id=" + elementId + ",frame=" + animation.frame +
+			",time=" + animation.time + ",delta=" + delta + ",timestamp=" + timestamp + "}");
+		animation.frame++;
+	});
+}
+
+function canvasFrameDone(elementId) {
+	const animation = canvasAnimations[elementId];
+	if (animation) {
+		animation.waiting = false;
+		requestCanvasFrame(elementId, animation);
+	}
+}
+
+document.addEventListener("visibilitychange", function() {
+	for (const elementId in canvasAnimations) {
+		const animation = canvasAnimations[elementId];
+		if (document.hidden) {
+			if (animation.request) {
+				window.cancelAnimationFrame(animation.request);
+				animation.request = 0;
+			}
+			// the hidden time is not counted
+			animation.last = null;
+		} else {
+			requestCanvasFrame(elementId, animation);
+		}
+	}
+});
+
 function localStorageSet(key, value) {
 	try {
 		localStorage.setItem(key, value)
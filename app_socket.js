@@ -31,6 +31,7 @@ function socketOpen() {
 
 function socketReopen() {
 	sendMessage( "reconnect{session=" + sessionID + "}" );
+	resumeFileUploads();
 }
 
 function socketReconnect() {
@@ -76,6 +76,7 @@ func getStartPage(buffer *strings.Builder, params AppParams, addScripts string)
 	<body id="body" onkeydown="keyDownEvent(this, event)">
 		<div class="ruiRoot" id="ruiRootView"></div>
 		<div class="ruiPopupLayer" id="ruiPopupLayer" style="visibility: hidden; isolation: isolate;"></div>
+		<div class="ruiToastLayer ruiToastBottomRight" id="ruiToastLayer"></div>
 		<div class="ruiTooltipLayer" id="ruiTooltipLayer" style="visibility: hidden; opacity: 0;">
 		<div id="ruiTooltipText" class="ruiTooltipText"></div>
 		<div id="ruiTooltipTopArrow" class="ruiTooltipTopArrow"></div>
@@ -0,0 +1,36 @@
+package rui
+
+// AudioRecorder - the view recording the sound from the microphone.
+// If the "controls" property is true then the last recording can be played back
+type AudioRecorder interface {
+	MediaRecorder
+}
+
+type audioRecorderData struct {
+	mediaRecorderData
+}
+
+// NewAudioRecorder create new AudioRecorder object and return it
+func NewAudioRecorder(session Session, params Params) AudioRecorder {
+	view := new(audioRecorderData)
+	view.init(session)
+	setInitParams(view, params)
+	return view
+}
+
+func newAudioRecorder(session Session) View {
+	return NewAudioRecorder(session, nil)
+}
+
+func (recorder *audioRecorderData) init(session Session) {
+	recorder.mediaRecorderData.init(session)
+	recorder.tag = "AudioRecorder"
+}
+
+func (recorder *audioRecorderData) String() string {
+	return getViewString(recorder)
+}
+
+func (recorder *audioRecorderData) htmlTag() string {
+	return "audio"
+}
@@ -0,0 +1,681 @@
+package rui
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CalendarSelectionChangedEvent is the constant for "calendar-selection-changed" property tag.
+	// The "calendar-selection-changed" event occurs when the set of selected days is changed.
+	// The main listener format: func(CalendarView, []time.Time), where the second argument is the sorted list
+	// of selected days. In the RangeSelection mode the list contains the first and the last day of the range
+	// (or only the first day if the range is not completed yet)
+	CalendarSelectionChangedEvent = "calendar-selection-changed"
+	// CalendarMode is the constant for "calendar-mode" property tag.
+	// The "calendar-mode" int property defines the page of a CalendarView: MonthCalendar (0) or WeekCalendar (1)
+	CalendarMode = "calendar-mode"
+	// CalendarSelectionMode is the constant for "calendar-selection-mode" property tag.
+	// The "calendar-selection-mode" int property defines how days are selected:
+	// SingleSelection (0), MultipleSelection (1), or RangeSelection (2)
+	CalendarSelectionMode = "calendar-selection-mode"
+	// CalendarDate is the constant for "calendar-date" property tag.
+	// The "calendar-date" property sets the day whose month (week) is displayed. The default value is today
+	CalendarDate = "calendar-date"
+	// CalendarMin is the constant for "calendar-min" property tag.
+	// The "calendar-min" property sets the minimal day which can be selected
+	CalendarMin = "calendar-min"
+	// CalendarMax is the constant for "calendar-max" property tag.
+	// The "calendar-max" property sets the maximal day which can be selected
+	CalendarMax = "calendar-max"
+	// DateDisabled is the constant for "date-disabled" property tag.
+	// The "date-disabled" property sets the function of the format func(time.Time) bool which returns true
+	// if the day can not be selected
+	DateDisabled = "date-disabled"
+	// FirstDayOfWeek is the constant for "first-day-of-week" property tag.
+	// The "first-day-of-week" int property sets the first day of a week: 0 - Sunday, 1 - Monday, ..., 6 - Saturday.
+	// By default, the first day of a week of the session locale is used
+	FirstDayOfWeek = "first-day-of-week"
+)
+
+const (
+	// MonthCalendar is value of "calendar-mode" property: the month page is displayed
+	MonthCalendar = 0
+	// WeekCalendar is value of "calendar-mode" property: the week page is displayed
+	WeekCalendar = 1
+
+	// RangeSelection is value of "calendar-selection-mode" property: the range of days is selected
+	// by clicking on the first and the last day of the range
+	RangeSelection = 2
+)
+
+// CalendarView - the month/week calendar with selectable days
+type CalendarView interface {
+	View
+	// SelectedDates returns the sorted list of selected days
+	SelectedDates() []time.Time
+	// SetSelectedDates sets the list of selected days. Disabled days are ignored
+	SetSelectedDates(dates ...time.Time)
+	// IsDateEnabled returns true if the day can be selected
+	IsDateEnabled(date time.Time) bool
+}
+
+type calendarViewData struct {
+	viewData
+	selected          []time.Time
+	disabledFunc      func(time.Time) bool
+	selectedListeners []func(CalendarView, []time.Time)
+}
+
+// NewCalendarView create new CalendarView object and return it
+func NewCalendarView(session Session, params Params) CalendarView {
+	view := new(calendarViewData)
+	view.init(session)
+	setInitParams(view, params)
+	return view
+}
+
+func newCalendarView(session Session) View {
+	return NewCalendarView(session, nil)
+}
+
+// Init initialize fields of CalendarView by default values
+func (calendar *calendarViewData) init(session Session) {
+	calendar.viewData.init(session)
+	calendar.tag = "CalendarView"
+	calendar.systemClass = "ruiCalendarView"
+	calendar.selected = []time.Time{}
+	calendar.selectedListeners = []func(CalendarView, []time.Time){}
+}
+
+func (calendar *calendarViewData) supportedProperties() []string {
+	return append([]string{
+		CalendarDate, CalendarMin, CalendarMax, CalendarMode, CalendarSelectionMode, DateDisabled,
+		Checked, FirstDayOfWeek, CalendarSelectionChangedEvent,
+	}, calendar.viewData.supportedProperties()...)
+}
+
+func (calendar *calendarViewData) String() string {
+	return getViewString(calendar)
+}
+
+func (calendar *calendarViewData) normalizeTag(tag string) string {
+	tag = strings.ToLower(tag)
+	switch tag {
+	case Min, Max:
+		return "calendar-" + tag
+
+	case "selection-mode":
+		return CalendarSelectionMode
+	}
+	return tag
+}
+
+func (calendar *calendarViewData) Remove(tag string) {
+	calendar.remove(calendar.normalizeTag(tag))
+}
+
+func (calendar *calendarViewData) remove(tag string) {
+	switch tag {
+	case CalendarSelectionChangedEvent:
+		if len(calendar.selectedListeners) == 0 {
+			return
+		}
+		calendar.selectedListeners = []func(CalendarView, []time.Time){}
+
+	case DateDisabled:
+		if calendar.disabledFunc == nil {
+			return
+		}
+		calendar.disabledFunc = nil
+		calendar.updateSelection()
+
+	case Checked:
+		calendar.SetSelectedDates()
+		return
+
+	case CalendarMode, CalendarSelectionMode, CalendarDate, CalendarMin, CalendarMax, FirstDayOfWeek:
+		if _, ok := calendar.properties.Load(tag); !ok {
+			return
+		}
+		calendar.properties.Delete(tag)
+		if tag != CalendarMode && tag != CalendarDate && tag != FirstDayOfWeek {
+			calendar.updateSelection()
+		} else if calendar.created {
+			updateInnerHTML(calendar.htmlID(), calendar.session)
+		}
+
+	default:
+		calendar.viewData.remove(tag)
+		return
+	}
+
+	calendar.propertyChangedEvent(tag)
+}
+
+func (calendar *calendarViewData) Set(tag string, value any) bool {
+	return calendar.set(calendar.normalizeTag(tag), value)
+}
+
+func (calendar *calendarViewData) set(tag string, value any) bool {
+	if value == nil {
+		calendar.remove(tag)
+		return true
+	}
+
+	switch tag {
+	case CalendarSelectionChangedEvent:
+		listeners, ok := valueToEventListeners[CalendarView, []time.Time](value)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		} else if listeners == nil {
+			listeners = []func(CalendarView, []time.Time){}
+		}
+		calendar.selectedListeners = listeners
+
+	case DateDisabled:
+		fn, ok := value.(func(time.Time) bool)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+		calendar.disabledFunc = fn
+		calendar.updateSelection()
+
+	case Checked:
+		switch value := value.(type) {
+		case time.Time:
+			calendar.SetSelectedDates(value)
+
+		case []time.Time:
+			calendar.SetSelectedDates(value...)
+
+		case string:
+			// the list of dates separated by commas: "2024-05-03, 2024-05-07"
+			text, ok := calendar.session.resolveConstants(value)
+			if !ok {
+				return false
+			}
+			dates := []time.Time{}
+			for _, item := range strings.Split(text, ",") {
+				if item = strings.Trim(item, " \t"); item != "" {
+					date, ok := valueToDate(item, calendar.session)
+					if !ok {
+						invalidPropertyValue(tag, value)
+						return false
+					}
+					dates = append(dates, date)
+				}
+			}
+			calendar.SetSelectedDates(dates...)
+
+		default:
+			notCompatibleType(tag, value)
+			return false
+		}
+		return true
+
+	case CalendarMode, CalendarSelectionMode:
+		if !calendar.setEnumProperty(tag, value, enumProperties[tag].values) {
+			return false
+		}
+		if tag == CalendarSelectionMode {
+			calendar.updateSelection()
+		} else if calendar.created {
+			updateInnerHTML(calendar.htmlID(), calendar.session)
+		}
+
+	case FirstDayOfWeek:
+		if !calendar.setIntProperty(tag, value) {
+			return false
+		}
+		if calendar.created {
+			updateInnerHTML(calendar.htmlID(), calendar.session)
+		}
+
+	case CalendarDate, CalendarMin, CalendarMax:
+		date, ok := valueToDate(value, calendar.session)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+		calendar.properties.Store(tag, date)
+		if tag != CalendarDate {
+			calendar.updateSelection()
+		} else if calendar.created {
+			updateInnerHTML(calendar.htmlID(), calendar.session)
+		}
+
+	default:
+		return calendar.viewData.set(tag, value)
+	}
+
+	calendar.propertyChangedEvent(tag)
+	return true
+}
+
+func (calendar *calendarViewData) Get(tag string) any {
+	return calendar.get(calendar.normalizeTag(tag))
+}
+
+func (calendar *calendarViewData) get(tag string) any {
+	switch tag {
+	case CalendarSelectionChangedEvent:
+		return calendar.selectedListeners
+
+	case DateDisabled:
+		return calendar.disabledFunc
+
+	case Checked:
+		return calendar.SelectedDates()
+	}
+	return calendar.viewData.get(tag)
+}
+
+// dateOnly returns the midnight UTC of the date day
+func dateOnly(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// valueToDate converts time.Time or a string in the "2006-01-02" format to the date
+func valueToDate(value any, session Session) (time.Time, bool) {
+	switch value := value.(type) {
+	case time.Time:
+		return dateOnly(value), true
+
+	case string:
+		if text, ok := session.resolveConstants(value); ok {
+			if date, err := time.Parse(dateFormat, strings.Trim(text, " \t")); err == nil {
+				return date, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (calendar *calendarViewData) dateProperty(tag string) (time.Time, bool) {
+	if value := calendar.getRaw(tag); value != nil {
+		if date, ok := value.(time.Time); ok {
+			return date, true
+		}
+	}
+	if value := valueFromStyle(calendar, tag); value != nil {
+		return valueToDate(value, calendar.session)
+	}
+	return time.Time{}, false
+}
+
+func (calendar *calendarViewData) IsDateEnabled(date time.Time) bool {
+	return isDateEnabled(date, calendar.dateProperty, calendar.disabledFunc)
+}
+
+// isDateEnabled returns true if the date is inside the "calendar-min"..."calendar-max" range
+// and is not disabled by the "date-disabled" function
+func isDateEnabled(date time.Time, dateProperty func(tag string) (time.Time, bool), disabled func(time.Time) bool) bool {
+	date = dateOnly(date)
+	if min, ok := dateProperty(CalendarMin); ok && date.Before(min) {
+		return false
+	}
+	if max, ok := dateProperty(CalendarMax); ok && date.After(max) {
+		return false
+	}
+	if disabled != nil && disabled(date) {
+		return false
+	}
+	return true
+}
+
+// isDateRangeEnabled returns true if the range can be selected: all its days are enabled
+func isDateRangeEnabled(start, end time.Time, dateProperty func(tag string) (time.Time, bool), disabled func(time.Time) bool) bool {
+	if !isDateEnabled(start, dateProperty, disabled) || !isDateEnabled(end, dateProperty, disabled) {
+		return false
+	}
+	if disabled != nil {
+		for day := dateOnly(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+			if disabled(day) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (calendar *calendarViewData) SelectedDates() []time.Time {
+	result := make([]time.Time, len(calendar.selected))
+	copy(result, calendar.selected)
+	return result
+}
+
+func (calendar *calendarViewData) SetSelectedDates(dates ...time.Time) {
+	selected := []time.Time{}
+	for _, date := range dates {
+		if date = dateOnly(date); calendar.IsDateEnabled(date) {
+			selected = append(selected, date)
+		}
+	}
+	calendar.setSelected(selected)
+}
+
+// setSelected normalizes the list of days according to the selection mode and fires the event if it is changed.
+// Returns false if the selection is not changed
+func (calendar *calendarViewData) setSelected(dates []time.Time) bool {
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	selected := []time.Time{}
+	for _, date := range dates {
+		if len(selected) == 0 || !selected[len(selected)-1].Equal(date) {
+			selected = append(selected, date)
+		}
+	}
+
+	switch GetCalendarSelectionMode(calendar) {
+	case SingleSelection:
+		if len(selected) > 1 {
+			selected = selected[:1]
+		}
+
+	case RangeSelection:
+		if len(selected) > 2 {
+			selected = []time.Time{selected[0], selected[len(selected)-1]}
+		}
+	}
+
+	changed := len(selected) != len(calendar.selected)
+	if !changed {
+		for i, date := range selected {
+			if !date.Equal(calendar.selected[i]) {
+				changed = true
+				break
+			}
+		}
+	}
+
+	if !changed {
+		return false
+	}
+
+	calendar.selected = selected
+	if calendar.created {
+		updateInnerHTML(calendar.htmlID(), calendar.session)
+	}
+	for _, listener := range calendar.selectedListeners {
+		listener(calendar, calendar.SelectedDates())
+	}
+	calendar.propertyChangedEvent(Checked)
+	return true
+}
+
+// updateSelection removes the disabled days from the selection after the constraints are changed
+func (calendar *calendarViewData) updateSelection() {
+	dates := []time.Time{}
+	for _, date := range calendar.selected {
+		if calendar.IsDateEnabled(date) {
+			dates = append(dates, date)
+		}
+	}
+	if !calendar.setSelected(dates) && calendar.created {
+		// the days are redrawn because the constraints are changed
+		updateInnerHTML(calendar.htmlID(), calendar.session)
+	}
+}
+
+func (calendar *calendarViewData) clickDate(date time.Time) {
+	if !calendar.IsDateEnabled(date) {
+		return
+	}
+
+	switch GetCalendarSelectionMode(calendar) {
+	case MultipleSelection:
+		dates := []time.Time{}
+		found := false
+		for _, selected := range calendar.selected {
+			if selected.Equal(date) {
+				found = true
+			} else {
+				dates = append(dates, selected)
+			}
+		}
+		if !found {
+			dates = append(dates, date)
+		}
+		calendar.setSelected(dates)
+
+	case RangeSelection:
+		if len(calendar.selected) == 1 && !date.Before(calendar.selected[0]) {
+			// the range must not contain disabled days
+			if isDateRangeEnabled(calendar.selected[0], date, calendar.dateProperty, calendar.disabledFunc) {
+				calendar.setSelected([]time.Time{calendar.selected[0], date})
+			} else {
+				calendar.setSelected([]time.Time{date})
+			}
+		} else {
+			calendar.setSelected([]time.Time{date})
+		}
+
+	default:
+		calendar.setSelected([]time.Time{date})
+	}
+}
+
+func (calendar *calendarViewData) currentDate() time.Time {
+	if date, ok := calendar.dateProperty(CalendarDate); ok {
+		return date
+	}
+	if len(calendar.selected) > 0 {
+		return calendar.selected[0]
+	}
+	return dateOnly(time.Now())
+}
+
+// page returns the first and the last (exclusive) day of the displayed page
+func (calendar *calendarViewData) page() (time.Time, time.Time) {
+	date := calendar.currentDate()
+	firstDay := time.Weekday(GetFirstDayOfWeek(calendar))
+
+	weekStart := func(day time.Time) time.Time {
+		return day.AddDate(0, 0, -((int(day.Weekday()) - int(firstDay) + 7) % 7))
+	}
+
+	if GetCalendarMode(calendar) == WeekCalendar {
+		start := weekStart(date)
+		return start, start.AddDate(0, 0, 7)
+	}
+
+	start := weekStart(time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC))
+	return start, start.AddDate(0, 0, 42)
+}
+
+func (calendar *calendarViewData) turnPage(step int) {
+	date := calendar.currentDate()
+	if GetCalendarMode(calendar) == WeekCalendar {
+		date = date.AddDate(0, 0, 7*step)
+	} else {
+		date = time.Date(date.Year(), date.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+	}
+	calendar.Set(CalendarDate, date)
+}
+
+func (calendar *calendarViewData) dayClass(date, today time.Time, month time.Month) string {
+	if !calendar.IsDateEnabled(date) {
+		return "ruiCalendarDisabled"
+	}
+
+	count := len(calendar.selected)
+	for _, selected := range calendar.selected {
+		if selected.Equal(date) {
+			return "ruiCalendarSelected"
+		}
+	}
+
+	if count == 2 && GetCalendarSelectionMode(calendar) == RangeSelection &&
+		date.After(calendar.selected[0]) && date.Before(calendar.selected[1]) {
+		return "ruiCalendarRange"
+	}
+
+	if date.Equal(today) {
+		return "ruiCalendarToday"
+	}
+
+	if GetCalendarMode(calendar) == MonthCalendar && date.Month() != month {
+		return "ruiCalendarOtherDay"
+	}
+
+	return "ruiCalendarDay"
+}
+
+func (calendar *calendarViewData) htmlSubviews(self View, buffer *strings.Builder) {
+	session := calendar.session
+	date := calendar.currentDate()
+	start, end := calendar.page()
+	min, hasMin := calendar.dateProperty(CalendarMin)
+	max, hasMax := calendar.dateProperty(CalendarMax)
+
+	monthName := func(month time.Month) string {
+		text, _ := session.GetString(month.String())
+		return text
+	}
+
+	navButton := func(step int, text string, enabled bool) {
+		if enabled {
+			buffer.WriteString(`<div class="ruiCalendarNav" onclick="calendarPageEvent('`)
+			buffer.WriteString(calendar.htmlID())
+			buffer.WriteString(`', `)
+			buffer.WriteString(strconv.Itoa(step))
+			buffer.WriteString(`, event)">`)
+		} else {
+			buffer.WriteString(`<div class="ruiCalendarNav" style="visibility: hidden;">`)
+		}
+		buffer.WriteString(text)
+		buffer.WriteString(`</div>`)
+	}
+
+	buffer.WriteString(`<div class="ruiCalendarHeader">`)
+	navButton(-1, "‹", !hasMin || min.Before(start))
+
+	buffer.WriteString(`<div class="ruiCalendarTitle">`)
+	if GetCalendarMode(calendar) == WeekCalendar {
+		last := end.AddDate(0, 0, -1)
+		buffer.WriteString(strconv.Itoa(start.Day()))
+		buffer.WriteRune(' ')
+		buffer.WriteString(monthName(start.Month()))
+		buffer.WriteString(" – ")
+		buffer.WriteString(strconv.Itoa(last.Day()))
+		buffer.WriteRune(' ')
+		buffer.WriteString(monthName(last.Month()))
+		buffer.WriteRune(' ')
+		buffer.WriteString(strconv.Itoa(last.Year()))
+	} else {
+		buffer.WriteString(monthName(date.Month()))
+		buffer.WriteRune(' ')
+		buffer.WriteString(strconv.Itoa(date.Year()))
+	}
+	buffer.WriteString(`</div>`)
+
+	navButton(1, "›", !hasMax || !max.Before(end))
+	buffer.WriteString(`</div><div class="ruiCalendarGrid">`)
+
+	for day := start; day.Before(start.AddDate(0, 0, 7)); day = day.AddDate(0, 0, 1) {
+		name, _ := session.GetString(day.Weekday().String())
+		if runes := []rune(name); len(runes) > 2 {
+			name = string(runes[:2])
+		}
+		buffer.WriteString(`<div class="ruiCalendarWeekday">`)
+		buffer.WriteString(name)
+		buffer.WriteString(`</div>`)
+	}
+
+	today := dateOnly(time.Now())
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		class := calendar.dayClass(day, today, date.Month())
+		buffer.WriteString(`<div class="`)
+		buffer.WriteString(class)
+		buffer.WriteString(`" data-date="`)
+		buffer.WriteString(day.Format(dateFormat))
+		buffer.WriteRune('"')
+		if class != "ruiCalendarDisabled" && !IsDisabled(calendar) {
+			buffer.WriteString(` onclick="calendarDayClickEvent('`)
+			buffer.WriteString(calendar.htmlID())
+			buffer.WriteString(`', this, event)"`)
+		}
+		buffer.WriteRune('>')
+		buffer.WriteString(strconv.Itoa(day.Day()))
+		buffer.WriteString(`</div>`)
+	}
+
+	buffer.WriteString(`</div>`)
+}
+
+func (calendar *calendarViewData) handleCommand(self View, command string, data DataObject) bool {
+	switch command {
+	case "calendarDayClick":
+		if text, ok := data.PropertyValue("date"); ok && !IsDisabled(calendar) {
+			if date, err := time.Parse(dateFormat, text); err == nil {
+				calendar.clickDate(date)
+			}
+		}
+
+	case "calendarPage":
+		if step, ok := dataIntProperty(data, "step"); ok && step != 0 {
+			calendar.turnPage(step)
+		}
+
+	default:
+		return calendar.viewData.handleCommand(self, command, data)
+	}
+
+	return true
+}
+
+// GetCalendarSelectionChangedListeners returns the "calendar-selection-changed" listeners of the CalendarView.
+// If there are no listeners then the empty list is returned
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetCalendarSelectionChangedListeners(view View, subviewID ...string) []func(CalendarView, []time.Time) {
+	return getEventListeners[CalendarView, []time.Time](view, subviewID, CalendarSelectionChangedEvent)
+}
+
+// GetCalendarMode returns the page mode of the CalendarView: MonthCalendar (0) or WeekCalendar (1).
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetCalendarMode(view View, subviewID ...string) int {
+	return enumStyledProperty(view, subviewID, CalendarMode, MonthCalendar, false)
+}
+
+// GetCalendarSelectionMode returns the selection mode of the CalendarView:
+// SingleSelection (0), MultipleSelection (1), or RangeSelection (2).
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetCalendarSelectionMode(view View, subviewID ...string) int {
+	return enumStyledProperty(view, subviewID, CalendarSelectionMode, SingleSelection, false)
+}
+
+// GetFirstDayOfWeek returns the first day of a week: 0 - Sunday, 1 - Monday, ..., 6 - Saturday.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetFirstDayOfWeek(view View, subviewID ...string) int {
+	day := intStyledProperty(view, subviewID, FirstDayOfWeek, -1)
+	if day < 0 || day > 6 {
+		if len(subviewID) > 0 && subviewID[0] != "" {
+			view = ViewByID(view, subviewID[0])
+		}
+		if view != nil {
+			return int(view.Session().Locale().FirstDayOfWeek)
+		}
+		return 1
+	}
+	return day
+}
+
+// GetCalendarSelectedDates returns the sorted list of selected days of the CalendarView.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetCalendarSelectedDates(view View, subviewID ...string) []time.Time {
+	if len(subviewID) > 0 && subviewID[0] != "" {
+		view = ViewByID(view, subviewID[0])
+	}
+	if calendar, ok := view.(CalendarView); ok {
+		return calendar.SelectedDates()
+	}
+	return []time.Time{}
+}
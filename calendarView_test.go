@@ -0,0 +1,191 @@
+package rui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarViewSelection(t *testing.T) {
+	createTestLog(t, false)
+	session := newSession(nil, 0, "", nil)
+	// the week starts on Monday in the "ru" locale
+	session.SetLanguage("ru")
+
+	day := func(d int) time.Time {
+		return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	calendar := NewCalendarView(session, Params{
+		CalendarDate: "2024-03-10",
+		Min:          "2024-03-05",
+		CalendarMax:  day(25),
+		DateDisabled: func(date time.Time) bool {
+			return date.Weekday() == time.Sunday
+		},
+	})
+
+	if start, end := calendar.(*calendarViewData).page(); !start.Equal(time.Date(2024, time.February, 26, 0, 0, 0, 0, time.UTC)) || !end.Equal(start.AddDate(0, 0, 42)) {
+		t.Errorf("month page: %v - %v", start, end)
+	}
+
+	for _, date := range []time.Time{day(4), day(26), day(10)} {
+		if calendar.IsDateEnabled(date) {
+			t.Errorf("%s is enabled", date.Format(dateFormat))
+		}
+	}
+
+	count := 0
+	calendar.Set(CalendarSelectionChangedEvent, func(dates []time.Time) {
+		count++
+	})
+
+	calendarClick := func(d int) {
+		calendar.(*calendarViewData).clickDate(day(d))
+	}
+
+	calendarClick(6)
+	calendarClick(7)
+	if dates := calendar.SelectedDates(); len(dates) != 1 || !dates[0].Equal(day(7)) || count != 2 {
+		t.Errorf("single selection: %v, %d events", dates, count)
+	}
+
+	calendar.Set(CalendarSelectionMode, "multiple")
+	calendarClick(12)
+	calendarClick(6)
+	calendarClick(12)
+	calendarClick(10)
+	if dates := calendar.SelectedDates(); len(dates) != 2 || !dates[0].Equal(day(6)) || !dates[1].Equal(day(7)) {
+		t.Errorf("multiple selection: %v", dates)
+	}
+
+	calendar.Set(CalendarSelectionMode, RangeSelection)
+	calendarClick(11)
+	calendarClick(13)
+	if dates := calendar.SelectedDates(); len(dates) != 2 || !dates[0].Equal(day(11)) || !dates[1].Equal(day(13)) {
+		t.Errorf("range selection: %v", dates)
+	}
+
+	// the range can not contain the disabled day (Sunday, March 17)
+	calendarClick(15)
+	calendarClick(19)
+	if dates := calendar.SelectedDates(); len(dates) != 1 || !dates[0].Equal(day(19)) {
+		t.Errorf("range selection with a disabled day: %v", dates)
+	}
+
+	calendar.Set(Min, "2024-03-20")
+	if dates := calendar.SelectedDates(); len(dates) != 0 {
+		t.Errorf("selection after min changing: %v", dates)
+	}
+
+	calendar.Set(CalendarMode, WeekCalendar)
+	if start, end := calendar.(*calendarViewData).page(); !start.Equal(day(4)) || !end.Equal(day(11)) {
+		t.Errorf("week page: %v - %v", start, end)
+	}
+}
+
+type calendarTestBridge struct {
+	webBridge
+	updates int
+}
+
+func (bridge *calendarTestBridge) callFunc(funcName string, args ...any) bool {
+	return true
+}
+
+func (bridge *calendarTestBridge) updateInnerHTML(htmlID, html string) {
+	bridge.updates++
+}
+
+func TestCalendarViewRedraw(t *testing.T) {
+	createTestLog(t, false)
+	session := newSession(nil, 0, "", nil)
+	bridge := new(calendarTestBridge)
+	session.setBridge(nil, bridge)
+
+	calendar := NewCalendarView(session, Params{CalendarDate: "2024-03-10"})
+	session.(*sessionData).rootView = calendar
+	calendar.(*calendarViewData).created = true
+
+	date := time.Date(2024, time.March, 12, 0, 0, 0, 0, time.UTC)
+	calendar.(*calendarViewData).clickDate(date)
+	if bridge.updates != 1 {
+		t.Errorf("%d updates after the selection, expected 1", bridge.updates)
+	}
+	calendar.SetSelectedDates(date)
+	if bridge.updates != 1 {
+		t.Errorf("the calendar is redrawn when the selection is not changed")
+	}
+
+	// the disabled days must be redrawn though the selection is not changed
+	calendar.Set(CalendarMax, "2024-03-20")
+	if bridge.updates != 2 {
+		t.Errorf("the calendar is not redrawn after the max date is changed")
+	}
+}
+
+func TestDateRangePickerLimits(t *testing.T) {
+	createTestLog(t, true)
+	session := newSession(nil, 0, "", nil)
+
+	day := func(d int) time.Time {
+		return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC)
+	}
+	picker := NewDateRangePicker(session, Params{
+		CalendarMin: day(5),
+		CalendarMax: "2024-03-25",
+		DateDisabled: func(date time.Time) bool {
+			return date.Weekday() == time.Sunday
+		},
+		DateRangeStart: day(11),
+		DateRangeEnd:   day(15),
+	})
+
+	check := func(start, end int) {
+		if rangeStart, rangeEnd, ok := picker.DateRange(); !ok || !rangeStart.Equal(day(start)) || !rangeEnd.Equal(day(end)) {
+			t.Errorf("range: %v - %v, expected: %d - %d", rangeStart, rangeEnd, start, end)
+		}
+	}
+	check(11, 15)
+
+	if picker.Set(DateRangeStart, "2024-03-01") {
+		t.Error("the start before the min date is set")
+	}
+	if picker.Set(DateRangeEnd, day(26)) {
+		t.Error("the end after the max date is set")
+	}
+	// Sundays, March 17 and 24, are disabled
+	if picker.Set(DateRangeEnd, day(19)) {
+		t.Error("the range with the disabled day is set")
+	}
+	check(11, 15)
+
+	picker.SetDateRange(day(23), day(18))
+	check(18, 23)
+	picker.SetDateRange(day(18), day(24))
+	picker.SetDateRange(day(17), day(17))
+	check(18, 23)
+}
+
+func TestDateTimePickerTimeZone(t *testing.T) {
+	createTestLog(t, false)
+	session := newSession(nil, 0, "", nil)
+
+	location := time.FixedZone("UTC+3", 3*60*60)
+	picker := NewDateTimePicker(session, Params{
+		Value:    "2024-03-10T12:30",
+		TimeZone: location,
+	})
+
+	value := GetDateTimePickerValue(picker)
+	if expected := time.Date(2024, time.March, 10, 9, 30, 0, 0, time.UTC); !value.Equal(expected) {
+		t.Errorf("value: %v, expected: %v", value, expected)
+	}
+	if value.Location() != location {
+		t.Errorf("value location: %v", value.Location())
+	}
+
+	picker.Set(Value, time.Date(2024, time.March, 10, 0, 0, 15, 0, time.UTC))
+	if text := picker.(*dateTimePickerData).formatValue(GetDateTimePickerValue(picker)); text != "2024-03-10T03:00:15" {
+		t.Errorf("formatted value: %s", text)
+	}
+}
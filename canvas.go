@@ -1,6 +1,8 @@
 package rui
 
 import (
+	"image"
+	"image/draw"
 	"math"
 	"strconv"
 	"strings"
@@ -289,6 +291,20 @@ type Canvas interface {
 	// DrawImageFragment draws the fragment (described by srcX, srcY, srcWidth, srcHeight) of image
 	// in the rectangle (dstX, dstY, dstWidth, dstHeight), scaling in height and width if necessary
 	DrawImageFragment(srcX, srcY, srcWidth, srcHeight, dstX, dstY, dstWidth, dstHeight float64, image Image)
+	// DrawGoImage draws the Go image in the rectangle (x, y, width, height), scaling in height and width if necessary.
+	// The current transformation, clipping and compositing are applied as for DrawImageInRect
+	DrawGoImage(x, y, width, height float64, img image.Image)
+
+	// GetImageData returns the pixels of the rectangle (x, y, width, height) of the canvas.
+	// The rectangle is set in the canvas coordinates without the transformation and is scaled
+	// by the device pixel ratio, so the size of the result is the size of the rectangle in
+	// the device pixels. All commands drawn before the call are applied to the result.
+	// Returns nil if the pixels are not available
+	GetImageData(x, y, width, height float64) *image.NRGBA
+	// PutImageData replaces the pixels of the canvas starting at the (x, y) position by the pixels of the image.
+	// One pixel of the image corresponds to one device pixel of the canvas. The transformation, clipping,
+	// global alpha and composite operation are not applied
+	PutImageData(x, y float64, img image.Image)
 
 	finishDraw()
 }
@@ -821,3 +837,40 @@ func (canvas *canvasData) DrawImageFragment(srcX, srcY, srcWidth, srcHeight, dst
 
 	canvas.session.callCanvasImageFunc(image.URL(), "", "drawImage", srcX, srcY, srcWidth, srcHeight, dstX, dstY, dstWidth, dstHeight)
 }
+
+func (canvas *canvasData) DrawGoImage(x, y, width, height float64, img image.Image) {
+	if img == nil || img.Bounds().Empty() {
+		return
+	}
+
+	canvas.session.callCanvasImageDataFunc("drawCanvasImageData", canvasNRGBA(img), x, y, width, height)
+}
+
+func (canvas *canvasData) GetImageData(x, y, width, height float64) *image.NRGBA {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	return canvas.session.canvasImageData(canvas.view.htmlID(), x, y, width, height)
+}
+
+func (canvas *canvasData) PutImageData(x, y float64, img image.Image) {
+	if img == nil || img.Bounds().Empty() {
+		return
+	}
+
+	canvas.session.callCanvasImageDataFunc("putCanvasImageData", canvasNRGBA(img), x, y)
+}
+
+// canvasNRGBA returns the non-premultiplied pixels of the image packed without gaps and starting at (0, 0)
+func canvasNRGBA(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if nrgba, ok := img.(*image.NRGBA); ok && bounds.Min == (image.Point{}) &&
+		nrgba.Stride == 4*width && len(nrgba.Pix) == 4*width*height {
+		return nrgba
+	}
+
+	result := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(result, result.Bounds(), img, bounds.Min, draw.Src)
+	return result
+}
@@ -0,0 +1,1012 @@
+package rui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"math"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+type canvasImageSource struct {
+	data    []byte
+	mime    string
+	decoded *image.RGBA
+}
+
+// canvasImages caches the images used by the recorded drawing. The key is the image URL
+type canvasImages map[string]*canvasImageSource
+
+func (images canvasImages) source(img Image) *canvasImageSource {
+	src := img.URL()
+	if source, ok := images[src]; ok {
+		return source
+	}
+
+	source := new(canvasImageSource)
+	images[src] = source
+
+	if strings.HasPrefix(src, "data:") {
+		if header, content, ok := strings.Cut(src[5:], ","); ok {
+			source.mime, _, _ = strings.Cut(header, ";")
+			if strings.HasSuffix(header, ";base64") {
+				source.data, _ = base64.StdEncoding.DecodeString(content)
+			} else if text, err := url.PathUnescape(content); err == nil {
+				source.data = []byte(text)
+			}
+		}
+	} else if !strings.Contains(src, "://") {
+		name, _, _ := strings.Cut(src, "?")
+		source.data = readImageResource(name)
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".png":
+			source.mime = "image/png"
+
+		case ".jpg", ".jpeg":
+			source.mime = "image/jpeg"
+
+		case ".gif":
+			source.mime = "image/gif"
+
+		case ".svg":
+			source.mime = "image/svg+xml"
+
+		case ".webp":
+			source.mime = "image/webp"
+		}
+	}
+
+	if source.data != nil {
+		if decoded, _, err := image.Decode(bytes.NewReader(source.data)); err == nil {
+			bounds := decoded.Bounds()
+			source.decoded = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+			draw.Draw(source.decoded, source.decoded.Bounds(), decoded, bounds.Min, draw.Src)
+		}
+	}
+	return source
+}
+
+// size returns the size of the image in pixels
+func (source *canvasImageSource) size(img Image) (float64, float64) {
+	if source.decoded != nil {
+		bounds := source.decoded.Bounds()
+		return float64(bounds.Dx()), float64(bounds.Dy())
+	}
+	return img.Width(), img.Height()
+}
+
+// canvasColor is the premultiplied color with components in the range 0...1
+type canvasColor struct {
+	r, g, b, a float64
+}
+
+func premultipliedCanvasColor(color Color) canvasColor {
+	a, r, g, b := color.ARGB()
+	alpha := float64(a) / 255
+	return canvasColor{
+		r: float64(r) / 255 * alpha,
+		g: float64(g) / 255 * alpha,
+		b: float64(b) / 255 * alpha,
+		a: alpha,
+	}
+}
+
+// canvasRasterizer calculates the anti-aliased coverage of polygons by the accumulation of signed areas.
+// The nonzero winding rule is approximated by clamping the absolute value of the accumulated area
+type canvasRasterizer struct {
+	width, height int
+	area          []float64
+	coverage      []float64
+	minY, maxY    int
+}
+
+func newCanvasRasterizer(width, height int) *canvasRasterizer {
+	return &canvasRasterizer{
+		width:    width,
+		height:   height,
+		area:     make([]float64, height*(width+2)),
+		coverage: make([]float64, width*height),
+		minY:     height,
+		maxY:     -1,
+	}
+}
+
+func (rasterizer *canvasRasterizer) line(p0, p1 canvasPoint) {
+	if p0.y == p1.y || math.IsNaN(p0.x+p0.y+p1.x+p1.y) {
+		return
+	}
+
+	dir := 1.0
+	if p0.y > p1.y {
+		dir = -1
+		p0, p1 = p1, p0
+	}
+
+	height := float64(rasterizer.height)
+	if p1.y <= 0 || p0.y >= height {
+		return
+	}
+
+	dxdy := (p1.x - p0.x) / (p1.y - p0.y)
+	x := p0.x
+	startY := p0.y
+	if startY < 0 {
+		x -= startY * dxdy
+		startY = 0
+	}
+	endY := math.Min(p1.y, height)
+
+	width := float64(rasterizer.width)
+	clamp := func(value float64) float64 {
+		return math.Max(0, math.Min(width, value))
+	}
+
+	stride := rasterizer.width + 2
+	y := int(startY)
+	rasterizer.minY = min(rasterizer.minY, y)
+	for ; float64(y) < endY; y++ {
+		dy := math.Min(float64(y+1), endY) - math.Max(float64(y), startY)
+		nextX := x + dxdy*dy
+		d := dy * dir
+
+		x0, x1 := clamp(x), clamp(nextX)
+		if x0 > x1 {
+			x0, x1 = x1, x0
+		}
+
+		row := rasterizer.area[y*stride : (y+1)*stride]
+		x0Floor := math.Floor(x0)
+		x0i := int(x0Floor)
+		x1Ceil := math.Ceil(x1)
+		x1i := int(x1Ceil)
+		if x1i <= x0i+1 {
+			xm := 0.5*(x0+x1) - x0Floor
+			row[x0i] += d - d*xm
+			row[x0i+1] += d * xm
+		} else {
+			s := 1 / (x1 - x0)
+			x0f := x0 - x0Floor
+			a0 := 0.5 * s * (1 - x0f) * (1 - x0f)
+			x1f := x1 - x1Ceil + 1
+			am := 0.5 * s * x1f * x1f
+			row[x0i] += d * a0
+			if x1i == x0i+2 {
+				row[x0i+1] += d * (1 - a0 - am)
+			} else {
+				a1 := s * (1.5 - x0f)
+				row[x0i+1] += d * (a1 - a0)
+				for xi := x0i + 2; xi < x1i-1; xi++ {
+					row[xi] += d * s
+				}
+				a2 := a1 + float64(x1i-x0i-3)*s
+				row[x1i-1] += d * (1 - a2 - am)
+			}
+			row[x1i] += d * am
+		}
+		x = nextX
+	}
+	rasterizer.maxY = max(rasterizer.maxY, y-1)
+}
+
+func (rasterizer *canvasRasterizer) polygon(points []canvasPoint) {
+	if count := len(points); count > 2 {
+		for i, point := range points {
+			rasterizer.line(point, points[(i+1)%count])
+		}
+	}
+}
+
+// accumulate calculates the coverage of the added polygons and clears the area buffer.
+// It returns the range of the rows (the end is exclusive) containing the coverage
+func (rasterizer *canvasRasterizer) accumulate() (int, int) {
+	minY, maxY := rasterizer.minY, rasterizer.maxY+1
+	stride := rasterizer.width + 2
+	for y := minY; y < maxY; y++ {
+		row := rasterizer.area[y*stride : (y+1)*stride]
+		coverage := rasterizer.coverage[y*rasterizer.width : (y+1)*rasterizer.width]
+		sum := 0.0
+		for x := range coverage {
+			sum += row[x]
+			coverage[x] = math.Min(math.Abs(sum), 1)
+		}
+		clear(row)
+	}
+	rasterizer.minY, rasterizer.maxY = rasterizer.height, -1
+	return minY, maxY
+}
+
+// canvasPolyline is the flattened sub-path
+type canvasPolyline struct {
+	points []canvasPoint
+	closed bool
+}
+
+// flattenCanvasShape converts the shape to polylines transformed by the matrix.
+// The scale is the size of the shape unit on the image and defines the number of curve segments
+func flattenCanvasShape(shape []canvasSegment, matrix canvasMatrix, scale float64) []canvasPolyline {
+	result := []canvasPolyline{}
+	var current canvasPolyline
+	var last canvasPoint
+
+	commit := func() {
+		if len(current.points) > 1 {
+			result = append(result, current)
+		}
+		current = canvasPolyline{}
+	}
+	add := func(point canvasPoint) {
+		point = matrix.apply(point)
+		if count := len(current.points); count == 0 || current.points[count-1] != point {
+			current.points = append(current.points, point)
+		}
+	}
+
+	for _, segment := range shape {
+		switch segment.kind {
+		case canvasMoveTo:
+			commit()
+			last = segment.points[0]
+			add(last)
+
+		case canvasLineTo:
+			last = segment.points[0]
+			add(last)
+
+		case canvasCurveTo:
+			p0, p1, p2, p3 := last, segment.points[0], segment.points[1], segment.points[2]
+			length := math.Hypot(p1.x-p0.x, p1.y-p0.y) + math.Hypot(p2.x-p1.x, p2.y-p1.y) + math.Hypot(p3.x-p2.x, p3.y-p2.y)
+			count := max(1, min(256, int(math.Ceil(math.Sqrt(length*scale*2)))))
+			for i := 1; i <= count; i++ {
+				t := float64(i) / float64(count)
+				u := 1 - t
+				add(canvasPoint{
+					x: u*u*u*p0.x + 3*u*u*t*p1.x + 3*u*t*t*p2.x + t*t*t*p3.x,
+					y: u*u*u*p0.y + 3*u*u*t*p1.y + 3*u*t*t*p2.y + t*t*t*p3.y,
+				})
+			}
+			last = p3
+
+		case canvasClosePath:
+			if count := len(current.points); count > 1 && current.points[0] == current.points[count-1] {
+				current.points = current.points[:count-1]
+			}
+			current.closed = true
+			commit()
+		}
+	}
+	commit()
+	return result
+}
+
+func canvasPolygonArea(points []canvasPoint) float64 {
+	area := 0.0
+	count := len(points)
+	for i, point := range points {
+		next := points[(i+1)%count]
+		area += point.x*next.y - next.x*point.y
+	}
+	return area
+}
+
+// canvasStroker converts polylines to the polygons of the stroke. All polygons have the same orientation,
+// so their union is filled by the nonzero winding rule
+type canvasStroker struct {
+	state    *canvasRecorderState
+	scale    float64
+	polygons [][]canvasPoint
+}
+
+func (stroker *canvasStroker) add(points ...canvasPoint) {
+	if canvasPolygonArea(points) < 0 {
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+	stroker.polygons = append(stroker.polygons, points)
+}
+
+func (stroker *canvasStroker) circle(center canvasPoint, radius float64) {
+	count := max(8, min(128, int(math.Ceil(radius*stroker.scale*2))))
+	points := make([]canvasPoint, count)
+	for i := range points {
+		sin, cos := math.Sincos(2 * math.Pi * float64(i) / float64(count))
+		points[i] = canvasPoint{center.x + radius*cos, center.y + radius*sin}
+	}
+	stroker.add(points...)
+}
+
+func canvasDirection(p0, p1 canvasPoint) (canvasPoint, float64) {
+	dx, dy := p1.x-p0.x, p1.y-p0.y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return canvasPoint{}, 0
+	}
+	return canvasPoint{dx / length, dy / length}, length
+}
+
+func (stroker *canvasStroker) join(point, dir0, dir1 canvasPoint) {
+	halfWidth := stroker.state.lineWidth / 2
+	cross := dir0.x*dir1.y - dir0.y*dir1.x
+	dot := dir0.x*dir1.x + dir0.y*dir1.y
+	if math.Abs(cross) < 1e-9 && dot > 0 {
+		return
+	}
+
+	if stroker.state.lineJoin == RoundJoin {
+		stroker.circle(point, halfWidth)
+		return
+	}
+
+	side := -1.0
+	if cross < 0 {
+		side = 1
+	}
+	n0 := canvasPoint{-dir0.y * side, dir0.x * side}
+	n1 := canvasPoint{-dir1.y * side, dir1.x * side}
+	outer0 := canvasPoint{point.x + n0.x*halfWidth, point.y + n0.y*halfWidth}
+	outer1 := canvasPoint{point.x + n1.x*halfWidth, point.y + n1.y*halfWidth}
+
+	if stroker.state.lineJoin == MiterJoin && 1+dot > 1e-9 {
+		miter := canvasPoint{(n0.x + n1.x) / (1 + dot), (n0.y + n1.y) / (1 + dot)}
+		// the default canvas miter limit is 10
+		if math.Hypot(miter.x, miter.y) <= 10 {
+			stroker.add(point, outer0, canvasPoint{point.x + miter.x*halfWidth, point.y + miter.y*halfWidth}, outer1)
+			return
+		}
+	}
+	stroker.add(point, outer0, outer1)
+}
+
+func (stroker *canvasStroker) lineCap(point, dir canvasPoint) {
+	halfWidth := stroker.state.lineWidth / 2
+	switch stroker.state.lineCap {
+	case RoundCap:
+		stroker.circle(point, halfWidth)
+
+	case SquareCap:
+		normal := canvasPoint{-dir.y * halfWidth, dir.x * halfWidth}
+		end := canvasPoint{point.x + dir.x*halfWidth, point.y + dir.y*halfWidth}
+		stroker.add(
+			canvasPoint{point.x + normal.x, point.y + normal.y},
+			canvasPoint{end.x + normal.x, end.y + normal.y},
+			canvasPoint{end.x - normal.x, end.y - normal.y},
+			canvasPoint{point.x - normal.x, point.y - normal.y})
+	}
+}
+
+func (stroker *canvasStroker) polyline(line canvasPolyline) {
+	points := line.points
+	if line.closed {
+		points = append(points[:len(points):len(points)], points[0])
+	}
+
+	count := len(points)
+	if count < 2 {
+		return
+	}
+
+	halfWidth := stroker.state.lineWidth / 2
+	var firstDir, lastDir canvasPoint
+	for i := 0; i < count-1; i++ {
+		dir, length := canvasDirection(points[i], points[i+1])
+		if length == 0 {
+			continue
+		}
+
+		normal := canvasPoint{-dir.y * halfWidth, dir.x * halfWidth}
+		p0, p1 := points[i], points[i+1]
+		stroker.add(
+			canvasPoint{p0.x + normal.x, p0.y + normal.y},
+			canvasPoint{p1.x + normal.x, p1.y + normal.y},
+			canvasPoint{p1.x - normal.x, p1.y - normal.y},
+			canvasPoint{p0.x - normal.x, p0.y - normal.y})
+
+		if i == 0 {
+			firstDir = dir
+		} else {
+			stroker.join(p0, lastDir, dir)
+		}
+		lastDir = dir
+	}
+
+	if line.closed {
+		stroker.join(points[0], lastDir, firstDir)
+	} else {
+		stroker.lineCap(points[0], canvasPoint{-firstDir.x, -firstDir.y})
+		stroker.lineCap(points[count-1], lastDir)
+	}
+}
+
+// dashes splits the polyline by the dash pattern of the state
+func (stroker *canvasStroker) dashes(line canvasPolyline) []canvasPolyline {
+	dash := stroker.state.dash
+	points := line.points
+	if line.closed {
+		points = append(points[:len(points):len(points)], points[0])
+	}
+
+	total := 0.0
+	for _, value := range dash {
+		total += value
+	}
+
+	index := 0
+	remain := dash[0]
+	offset := math.Mod(stroker.state.dashOffset, total)
+	for offset > 0 {
+		if offset < remain {
+			remain -= offset
+			break
+		}
+		offset -= remain
+		index = (index + 1) % len(dash)
+		remain = dash[index]
+	}
+
+	result := []canvasPolyline{}
+	var current canvasPolyline
+	if index%2 == 0 {
+		current.points = []canvasPoint{points[0]}
+	}
+
+	for i := 0; i < len(points)-1; i++ {
+		p0, p1 := points[i], points[i+1]
+		dir, length := canvasDirection(p0, p1)
+		position := 0.0
+		for length-position > remain {
+			position += remain
+			point := canvasPoint{p0.x + dir.x*position, p0.y + dir.y*position}
+			if index%2 == 0 {
+				current.points = append(current.points, point)
+				result = append(result, current)
+				current = canvasPolyline{}
+			} else {
+				current.points = []canvasPoint{point}
+			}
+			index = (index + 1) % len(dash)
+			remain = dash[index]
+		}
+		remain -= length - position
+		if index%2 == 0 {
+			current.points = append(current.points, p1)
+		}
+	}
+
+	if index%2 == 0 && len(current.points) > 1 {
+		result = append(result, current)
+	}
+	return result
+}
+
+func (stroker *canvasStroker) stroke(lines []canvasPolyline) [][]canvasPoint {
+	for _, line := range lines {
+		if len(stroker.state.dash) > 0 {
+			for _, dash := range stroker.dashes(line) {
+				stroker.polyline(dash)
+			}
+		} else {
+			stroker.polyline(line)
+		}
+	}
+	return stroker.polygons
+}
+
+type canvasRasterWriter struct {
+	recorder   *canvasRecorder
+	image      *image.RGBA
+	scale      float64
+	base       canvasMatrix
+	rasterizer *canvasRasterizer
+	images     canvasImages
+	clip       []canvasClip
+	clipMask   []float64
+}
+
+func (recorder *canvasRecorder) Image(scale float64) *image.RGBA {
+	if scale <= 0 || math.IsNaN(scale) || math.IsInf(scale, 0) {
+		scale = 1
+	}
+
+	width := int(math.Ceil(recorder.width * scale))
+	height := int(math.Ceil(recorder.height * scale))
+	writer := &canvasRasterWriter{
+		recorder:   recorder,
+		image:      image.NewRGBA(image.Rect(0, 0, width, height)),
+		scale:      scale,
+		base:       canvasMatrix{scale, 0, 0, scale, 0, 0},
+		rasterizer: newCanvasRasterizer(width, height),
+		images:     canvasImages{},
+	}
+
+	for i := range recorder.records {
+		writer.drawRecord(&recorder.records[i])
+	}
+	return writer.image
+}
+
+// fillPolygons rasterizes the polygons and returns the range of the covered rows
+func (writer *canvasRasterWriter) fillPolygons(polygons [][]canvasPoint) (int, int) {
+	for _, polygon := range polygons {
+		writer.rasterizer.polygon(polygon)
+	}
+	return writer.rasterizer.accumulate()
+}
+
+func (writer *canvasRasterWriter) fillLines(lines []canvasPolyline) (int, int) {
+	for _, line := range lines {
+		writer.rasterizer.polygon(line.points)
+	}
+	return writer.rasterizer.accumulate()
+}
+
+// updateClip calculates the clip mask of the state. A nil mask means no clipping
+func (writer *canvasRasterWriter) updateClip(clip []canvasClip) {
+	if len(clip) == len(writer.clip) && (len(clip) == 0 || &clip[0] == &writer.clip[0]) {
+		return
+	}
+
+	writer.clip = clip
+	if len(clip) == 0 {
+		writer.clipMask = nil
+		return
+	}
+
+	mask := make([]float64, len(writer.rasterizer.coverage))
+	for i := range mask {
+		mask[i] = 1
+	}
+
+	for _, item := range clip {
+		matrix := writer.base.multiply(item.matrix)
+		minY, maxY := writer.fillLines(flattenCanvasShape(item.shape, matrix, matrix.scale()))
+		width := writer.rasterizer.width
+		for y := 0; y < writer.rasterizer.height; y++ {
+			row := mask[y*width : (y+1)*width]
+			if y < minY || y >= maxY {
+				clear(row)
+				continue
+			}
+			for x, coverage := range writer.rasterizer.coverage[y*width : (y+1)*width] {
+				row[x] *= coverage
+			}
+		}
+	}
+	writer.clipMask = mask
+}
+
+// sampler returns the function calculating the color of the paint in the image point.
+// The nil function means that nothing is painted
+func (writer *canvasRasterWriter) sampler(paint canvasPaint, matrix canvasMatrix) func(x, y float64) canvasColor {
+	if paint.kind == canvasSolidPaint {
+		color := premultipliedCanvasColor(paint.color)
+		return func(float64, float64) canvasColor {
+			return color
+		}
+	}
+
+	inverse, ok := matrix.invert()
+	if !ok {
+		return nil
+	}
+
+	switch paint.kind {
+	case canvasLinearPaint:
+		p0, p1 := paint.points[0], paint.points[1]
+		dx, dy := p1.x-p0.x, p1.y-p0.y
+		length := dx*dx + dy*dy
+		if length == 0 {
+			return nil
+		}
+		return func(x, y float64) canvasColor {
+			point := inverse.apply(canvasPoint{x, y})
+			return canvasGradientColor(paint.stops, ((point.x-p0.x)*dx+(point.y-p0.y)*dy)/length)
+		}
+
+	case canvasRadialPaint:
+		c0, c1 := paint.points[0], paint.points[1]
+		r0, r1 := paint.radius[0], paint.radius[1]
+		cdx, cdy, dr := c1.x-c0.x, c1.y-c0.y, r1-r0
+		a := cdx*cdx + cdy*cdy - dr*dr
+		return func(x, y float64) canvasColor {
+			point := inverse.apply(canvasPoint{x, y})
+			pdx, pdy := point.x-c0.x, point.y-c0.y
+			b := pdx*cdx + pdy*cdy + r0*dr
+			c := pdx*pdx + pdy*pdy - r0*r0
+
+			var t float64
+			if math.Abs(a) < 1e-9 {
+				if b == 0 {
+					return canvasColor{}
+				}
+				t = c / (2 * b)
+			} else {
+				disc := b*b - a*c
+				if disc < 0 {
+					return canvasColor{}
+				}
+				sqrt := math.Sqrt(disc)
+				t = (b + sqrt) / a
+				if r0+t*dr < 0 {
+					t = (b - sqrt) / a
+				}
+			}
+			if r0+t*dr < 0 {
+				return canvasColor{}
+			}
+			return canvasGradientColor(paint.stops, t)
+		}
+
+	case canvasPatternPaint:
+		source := writer.images.source(paint.image)
+		if source.decoded == nil {
+			return nil
+		}
+		repeatX := paint.repeat == RepeatXY || paint.repeat == RepeatX
+		repeatY := paint.repeat == RepeatXY || paint.repeat == RepeatY
+		return func(x, y float64) canvasColor {
+			point := inverse.apply(canvasPoint{x, y})
+			return canvasImagePixel(source.decoded, int(math.Floor(point.x)), int(math.Floor(point.y)), repeatX, repeatY)
+		}
+	}
+	return nil
+}
+
+func canvasGradientColor(stops []GradientPoint, t float64) canvasColor {
+	count := len(stops)
+	if t <= stops[0].Offset {
+		return premultipliedCanvasColor(stops[0].Color)
+	}
+	if t >= stops[count-1].Offset {
+		return premultipliedCanvasColor(stops[count-1].Color)
+	}
+
+	for i := 1; i < count; i++ {
+		if t <= stops[i].Offset {
+			c0 := premultipliedCanvasColor(stops[i-1].Color)
+			c1 := premultipliedCanvasColor(stops[i].Color)
+			length := stops[i].Offset - stops[i-1].Offset
+			if length <= 0 {
+				return c1
+			}
+			k := (t - stops[i-1].Offset) / length
+			return canvasColor{
+				r: c0.r + (c1.r-c0.r)*k,
+				g: c0.g + (c1.g-c0.g)*k,
+				b: c0.b + (c1.b-c0.b)*k,
+				a: c0.a + (c1.a-c0.a)*k,
+			}
+		}
+	}
+	return premultipliedCanvasColor(stops[count-1].Color)
+}
+
+func canvasImagePixel(img *image.RGBA, x, y int, repeatX, repeatY bool) canvasColor {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if repeatX {
+		x = ((x % width) + width) % width
+	} else if x < 0 || x >= width {
+		return canvasColor{}
+	}
+	if repeatY {
+		y = ((y % height) + height) % height
+	} else if y < 0 || y >= height {
+		return canvasColor{}
+	}
+
+	offset := img.PixOffset(x, y)
+	pixel := img.Pix[offset : offset+4]
+	return canvasColor{
+		r: float64(pixel[0]) / 255,
+		g: float64(pixel[1]) / 255,
+		b: float64(pixel[2]) / 255,
+		a: float64(pixel[3]) / 255,
+	}
+}
+
+// composite paints the coverage of the rasterizer in the rows range by the sampler (source-over)
+func (writer *canvasRasterWriter) composite(minY, maxY int, sampler func(x, y float64) canvasColor) {
+	width := writer.rasterizer.width
+	pix := writer.image.Pix
+	stride := writer.image.Stride
+	for y := minY; y < maxY; y++ {
+		for x, coverage := range writer.rasterizer.coverage[y*width : (y+1)*width] {
+			if writer.clipMask != nil {
+				coverage *= writer.clipMask[y*width+x]
+			}
+			if coverage <= 0 {
+				continue
+			}
+
+			color := sampler(float64(x)+0.5, float64(y)+0.5)
+			alpha := color.a * coverage
+			if alpha <= 0 {
+				continue
+			}
+
+			pixel := pix[y*stride+x*4 : y*stride+x*4+4]
+			for i, value := range []float64{color.r, color.g, color.b, color.a} {
+				pixel[i] = uint8(math.Round(math.Min(255, value*coverage*255+float64(pixel[i])*(1-alpha))))
+			}
+		}
+	}
+}
+
+func (writer *canvasRasterWriter) clearCoverage(minY, maxY int) {
+	width := writer.rasterizer.width
+	pix := writer.image.Pix
+	stride := writer.image.Stride
+	for y := minY; y < maxY; y++ {
+		for x, coverage := range writer.rasterizer.coverage[y*width : (y+1)*width] {
+			if writer.clipMask != nil {
+				coverage *= writer.clipMask[y*width+x]
+			}
+			if coverage > 0 {
+				pixel := pix[y*stride+x*4 : y*stride+x*4+4]
+				for i := range pixel {
+					pixel[i] = uint8(math.Round(float64(pixel[i]) * (1 - coverage)))
+				}
+			}
+		}
+	}
+}
+
+func (writer *canvasRasterWriter) strokePolygons(shape []canvasSegment, state *canvasRecorderState, matrix canvasMatrix) [][]canvasPoint {
+	scale := matrix.scale()
+	stroker := &canvasStroker{state: state, scale: scale}
+	polygons := stroker.stroke(flattenCanvasShape(shape, canvasIdentity, scale))
+	for _, polygon := range polygons {
+		for i, point := range polygon {
+			polygon[i] = matrix.apply(point)
+		}
+	}
+	return polygons
+}
+
+func (writer *canvasRasterWriter) drawRecord(record *canvasRecord) {
+	state := &record.state
+	matrix := writer.base.multiply(state.matrix)
+	writer.updateClip(state.clip)
+
+	switch record.kind {
+	case canvasFillRecord:
+		if sampler := writer.sampler(state.fill, matrix); sampler != nil {
+			minY, maxY := writer.fillLines(flattenCanvasShape(record.shape, matrix, matrix.scale()))
+			writer.composite(minY, maxY, sampler)
+		}
+
+	case canvasStrokeRecord:
+		if sampler := writer.sampler(state.stroke, matrix); sampler != nil {
+			minY, maxY := writer.fillPolygons(writer.strokePolygons(record.shape, state, matrix))
+			writer.composite(minY, maxY, sampler)
+		}
+
+	case canvasClearRecord:
+		minY, maxY := writer.fillLines(flattenCanvasShape(record.shape, matrix, matrix.scale()))
+		writer.clearCoverage(minY, maxY)
+
+	case canvasFillTextRecord, canvasStrokeTextRecord:
+		paint := state.fill
+		if record.kind == canvasStrokeTextRecord {
+			paint = state.stroke
+		}
+		if sampler := writer.sampler(paint, matrix); sampler != nil {
+			polygons := canvasTextPolygons(record.text, record.point, state)
+			for _, polygon := range polygons {
+				for i, point := range polygon {
+					polygon[i] = matrix.apply(point)
+				}
+			}
+			minY, maxY := writer.fillPolygons(polygons)
+			writer.composite(minY, maxY, sampler)
+		}
+
+	case canvasImageRecord:
+		writer.drawImage(record, matrix)
+
+	case canvasPutImageRecord:
+		writer.putImage(record)
+	}
+}
+
+// putImage replaces the pixels of the destination rectangle by the image pixels
+// without the transformation, clipping and blending
+func (writer *canvasRasterWriter) putImage(record *canvasRecord) {
+	source := writer.images.source(record.image)
+	if source.decoded == nil {
+		return
+	}
+
+	img := source.decoded
+	bounds := writer.image.Bounds()
+	x0 := int(math.Round(record.dst[0] * writer.scale))
+	y0 := int(math.Round(record.dst[1] * writer.scale))
+	x1 := min(bounds.Dx(), int(math.Round((record.dst[0]+record.dst[2])*writer.scale)))
+	y1 := min(bounds.Dy(), int(math.Round((record.dst[1]+record.dst[3])*writer.scale)))
+	maxX, maxY := img.Bounds().Dx()-1, img.Bounds().Dy()-1
+
+	for y := max(0, y0); y < y1; y++ {
+		sy := min(maxY, int(float64(y-y0)/writer.scale))
+		for x := max(0, x0); x < x1; x++ {
+			sx := min(maxX, int(float64(x-x0)/writer.scale))
+			src := img.PixOffset(sx, sy)
+			copy(writer.image.Pix[writer.image.PixOffset(x, y):][:4], img.Pix[src:src+4])
+		}
+	}
+}
+
+func (writer *canvasRasterWriter) drawImage(record *canvasRecord, matrix canvasMatrix) {
+	source := writer.images.source(record.image)
+	if source.decoded == nil {
+		return
+	}
+
+	bounds := source.decoded.Bounds()
+	src := record.src
+	if src[2] < 0 {
+		src = [4]float64{0, 0, float64(bounds.Dx()), float64(bounds.Dy())}
+	}
+	dst := record.dst
+	if dst[2] < 0 {
+		dst[2], dst[3] = src[2], src[3]
+	}
+	if src[2] <= 0 || src[3] <= 0 || dst[2] == 0 || dst[3] == 0 {
+		return
+	}
+
+	// the matrix converts the image coordinates to the canvas coordinates
+	imageMatrix := matrix.multiply(canvasMatrix{
+		dst[2] / src[2], 0, 0, dst[3] / src[3],
+		dst[0] - src[0]*dst[2]/src[2], dst[1] - src[1]*dst[3]/src[3],
+	})
+	inverse, ok := imageMatrix.invert()
+	if !ok {
+		return
+	}
+
+	img := source.decoded
+	minX, minY := math.Max(0, src[0]), math.Max(0, src[1])
+	maxX := math.Min(float64(bounds.Dx()), src[0]+src[2])
+	maxY := math.Min(float64(bounds.Dy()), src[1]+src[3])
+	sampler := func(x, y float64) canvasColor {
+		point := inverse.apply(canvasPoint{x, y})
+		// bilinear interpolation inside the source rectangle
+		u := math.Max(minX, math.Min(maxX, point.x)) - 0.5
+		v := math.Max(minY, math.Min(maxY, point.y)) - 0.5
+		x0, y0 := math.Floor(u), math.Floor(v)
+		fx, fy := u-x0, v-y0
+		clampX := func(value float64) int {
+			return int(math.Max(minX, math.Min(maxX-1, value)))
+		}
+		clampY := func(value float64) int {
+			return int(math.Max(minY, math.Min(maxY-1, value)))
+		}
+		c00 := canvasImagePixel(img, clampX(x0), clampY(y0), false, false)
+		c10 := canvasImagePixel(img, clampX(x0+1), clampY(y0), false, false)
+		c01 := canvasImagePixel(img, clampX(x0), clampY(y0+1), false, false)
+		c11 := canvasImagePixel(img, clampX(x0+1), clampY(y0+1), false, false)
+		mix := func(v00, v10, v01, v11 float64) float64 {
+			return (v00*(1-fx)+v10*fx)*(1-fy) + (v01*(1-fx)+v11*fx)*fy
+		}
+		return canvasColor{
+			r: mix(c00.r, c10.r, c01.r, c11.r),
+			g: mix(c00.g, c10.g, c01.g, c11.g),
+			b: mix(c00.b, c10.b, c01.b, c11.b),
+			a: mix(c00.a, c10.a, c01.a, c11.a),
+		}
+	}
+
+	shape := rectCanvasShape(dst[0], dst[1], dst[2], dst[3])
+	top, bottom := writer.fillLines(flattenCanvasShape(shape.segments, matrix, 1))
+	writer.composite(top, bottom, sampler)
+}
+
+// canvasFontGlyphs is the built-in 5x7 bitmap font for the characters 0x20...0x7E.
+// Each glyph is 5 columns, the bit 0 of the column is the top row
+var canvasFontGlyphs = [95][5]byte{
+	{0x00, 0x00, 0x00, 0x00, 0x00}, {0x00, 0x00, 0x5F, 0x00, 0x00}, {0x00, 0x07, 0x00, 0x07, 0x00}, {0x14, 0x7F, 0x14, 0x7F, 0x14},
+	{0x24, 0x2A, 0x7F, 0x2A, 0x12}, {0x23, 0x13, 0x08, 0x64, 0x62}, {0x36, 0x49, 0x55, 0x22, 0x50}, {0x00, 0x05, 0x03, 0x00, 0x00},
+	{0x00, 0x1C, 0x22, 0x41, 0x00}, {0x00, 0x41, 0x22, 0x1C, 0x00}, {0x08, 0x2A, 0x1C, 0x2A, 0x08}, {0x08, 0x08, 0x3E, 0x08, 0x08},
+	{0x00, 0x50, 0x30, 0x00, 0x00}, {0x08, 0x08, 0x08, 0x08, 0x08}, {0x00, 0x60, 0x60, 0x00, 0x00}, {0x20, 0x10, 0x08, 0x04, 0x02},
+	{0x3E, 0x51, 0x49, 0x45, 0x3E}, {0x00, 0x42, 0x7F, 0x40, 0x00}, {0x42, 0x61, 0x51, 0x49, 0x46}, {0x21, 0x41, 0x45, 0x4B, 0x31},
+	{0x18, 0x14, 0x12, 0x7F, 0x10}, {0x27, 0x45, 0x45, 0x45, 0x39}, {0x3C, 0x4A, 0x49, 0x49, 0x30}, {0x01, 0x71, 0x09, 0x05, 0x03},
+	{0x36, 0x49, 0x49, 0x49, 0x36}, {0x06, 0x49, 0x49, 0x29, 0x1E}, {0x00, 0x36, 0x36, 0x00, 0x00}, {0x00, 0x56, 0x36, 0x00, 0x00},
+	{0x08, 0x14, 0x22, 0x41, 0x00}, {0x14, 0x14, 0x14, 0x14, 0x14}, {0x00, 0x41, 0x22, 0x14, 0x08}, {0x02, 0x01, 0x51, 0x09, 0x06},
+	{0x32, 0x49, 0x79, 0x41, 0x3E}, {0x7E, 0x11, 0x11, 0x11, 0x7E}, {0x7F, 0x49, 0x49, 0x49, 0x36}, {0x3E, 0x41, 0x41, 0x41, 0x22},
+	{0x7F, 0x41, 0x41, 0x22, 0x1C}, {0x7F, 0x49, 0x49, 0x49, 0x41}, {0x7F, 0x09, 0x09, 0x09, 0x01}, {0x3E, 0x41, 0x49, 0x49, 0x7A},
+	{0x7F, 0x08, 0x08, 0x08, 0x7F}, {0x00, 0x41, 0x7F, 0x41, 0x00}, {0x20, 0x40, 0x41, 0x3F, 0x01}, {0x7F, 0x08, 0x14, 0x22, 0x41},
+	{0x7F, 0x40, 0x40, 0x40, 0x40}, {0x7F, 0x02, 0x0C, 0x02, 0x7F}, {0x7F, 0x04, 0x08, 0x10, 0x7F}, {0x3E, 0x41, 0x41, 0x41, 0x3E},
+	{0x7F, 0x09, 0x09, 0x09, 0x06}, {0x3E, 0x41, 0x51, 0x21, 0x5E}, {0x7F, 0x09, 0x19, 0x29, 0x46}, {0x46, 0x49, 0x49, 0x49, 0x31},
+	{0x01, 0x01, 0x7F, 0x01, 0x01}, {0x3F, 0x40, 0x40, 0x40, 0x3F}, {0x1F, 0x20, 0x40, 0x20, 0x1F}, {0x3F, 0x40, 0x38, 0x40, 0x3F},
+	{0x63, 0x14, 0x08, 0x14, 0x63}, {0x07, 0x08, 0x70, 0x08, 0x07}, {0x61, 0x51, 0x49, 0x45, 0x43}, {0x00, 0x7F, 0x41, 0x41, 0x00},
+	{0x02, 0x04, 0x08, 0x10, 0x20}, {0x00, 0x41, 0x41, 0x7F, 0x00}, {0x04, 0x02, 0x01, 0x02, 0x04}, {0x40, 0x40, 0x40, 0x40, 0x40},
+	{0x00, 0x01, 0x02, 0x04, 0x00}, {0x20, 0x54, 0x54, 0x54, 0x78}, {0x7F, 0x48, 0x44, 0x44, 0x38}, {0x38, 0x44, 0x44, 0x44, 0x20},
+	{0x38, 0x44, 0x44, 0x48, 0x7F}, {0x38, 0x54, 0x54, 0x54, 0x18}, {0x08, 0x7E, 0x09, 0x01, 0x02}, {0x0C, 0x52, 0x52, 0x52, 0x3E},
+	{0x7F, 0x08, 0x04, 0x04, 0x78}, {0x00, 0x44, 0x7D, 0x40, 0x00}, {0x20, 0x40, 0x44, 0x3D, 0x00}, {0x7F, 0x10, 0x28, 0x44, 0x00},
+	{0x00, 0x41, 0x7F, 0x40, 0x00}, {0x7C, 0x04, 0x18, 0x04, 0x78}, {0x7C, 0x08, 0x04, 0x04, 0x78}, {0x38, 0x44, 0x44, 0x44, 0x38},
+	{0x7C, 0x14, 0x14, 0x14, 0x08}, {0x08, 0x14, 0x14, 0x18, 0x7C}, {0x7C, 0x08, 0x04, 0x04, 0x08}, {0x48, 0x54, 0x54, 0x54, 0x20},
+	{0x04, 0x3F, 0x44, 0x40, 0x20}, {0x3C, 0x40, 0x40, 0x20, 0x7C}, {0x1C, 0x20, 0x40, 0x20, 0x1C}, {0x3C, 0x40, 0x30, 0x40, 0x3C},
+	{0x44, 0x28, 0x10, 0x28, 0x44}, {0x0C, 0x50, 0x50, 0x50, 0x3C}, {0x44, 0x64, 0x54, 0x4C, 0x44}, {0x00, 0x08, 0x36, 0x41, 0x00},
+	{0x00, 0x00, 0x7F, 0x00, 0x00}, {0x00, 0x41, 0x36, 0x08, 0x00}, {0x08, 0x04, 0x08, 0x10, 0x08},
+}
+
+// canvasFontUnknownGlyph is drawn for characters missing in the built-in font
+var canvasFontUnknownGlyph = [5]byte{0x7F, 0x41, 0x41, 0x41, 0x7F}
+
+// canvasTextPolygons returns the polygons of the text drawn by the built-in bitmap font
+func canvasTextPolygons(text string, point canvasPoint, state *canvasRecorderState) [][]canvasPoint {
+	size := state.font.size
+	unit := size / 10
+	x := point.x
+	switch state.align {
+	case CenterAlign:
+		x -= canvasTextWidth(text, size) / 2
+
+	case RightAlign, EndAlign:
+		x -= canvasTextWidth(text, size)
+	}
+
+	baseline := point.y
+	switch state.baseline {
+	case TopBaseline:
+		baseline += size * canvasFontAscent
+
+	case HangingBaseline:
+		baseline += unit * 7
+
+	case MiddleBaseline:
+		baseline += size * (canvasFontAscent - canvasFontDescent) / 2
+
+	case BottomBaseline, IdeographicBaseline:
+		baseline -= size * canvasFontDescent
+	}
+
+	bold := 0.0
+	if state.font.params.Weight >= 6 {
+		bold = unit / 2
+	}
+	slant := 0.0
+	if state.font.params.Italic {
+		slant = 0.2
+	}
+
+	polygons := [][]canvasPoint{}
+	for _, ch := range text {
+		glyph := canvasFontUnknownGlyph
+		if ch >= 0x20 && ch <= 0x7E {
+			glyph = canvasFontGlyphs[ch-0x20]
+		} else if ch == '\t' {
+			glyph = canvasFontGlyphs[0]
+		}
+
+		for row := 0; row < 7; row++ {
+			top := baseline - float64(7-row)*unit
+			bottom := top + unit
+			for column := 0; column < 5; {
+				if glyph[column]&(1<<row) == 0 {
+					column++
+					continue
+				}
+				start := column
+				for column < 5 && glyph[column]&(1<<row) != 0 {
+					column++
+				}
+				left := x + float64(start)*unit
+				right := x + float64(column)*unit + bold
+				topShift := (baseline - top) * slant
+				bottomShift := (baseline - bottom) * slant
+				polygons = append(polygons, []canvasPoint{
+					{left + topShift, top}, {right + topShift, top},
+					{right + bottomShift, bottom}, {left + bottomShift, bottom},
+				})
+			}
+		}
+		x += size * canvasFontAdvance
+	}
+	return polygons
+}
@@ -0,0 +1,918 @@
+package rui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+// CanvasRecorder is the off-screen Canvas. It records the drawing commands instead of sending them
+// to the client and renders them to SVG and PNG in pure Go. It allows to use the same drawing function
+// as the CanvasView (the "draw-function" property) for reports, downloads, PDF embedding and tests.
+//
+// Limitations: shadows are ignored; the PNG text is drawn by the built-in 5x7 bitmap font
+// (non-ASCII characters are drawn as boxes) and StrokeText fills glyphs by the stroke style;
+// TextMetrics is approximated by this font; the SVG ignores ClearRect of a part of the canvas
+// and repeats an image pattern in both directions. Images are read by their URL from the
+// application resources (or "data:" URL) regardless of the loading status.
+// One pixel of GetImageData and PutImageData corresponds to one canvas unit.
+type CanvasRecorder interface {
+	Canvas
+	// Reset erases all recorded commands and restores the initial drawing state
+	Reset()
+	// SVG returns the recorded drawing as the SVG document
+	SVG() string
+	// Image rasterizes the recorded drawing. The scale is the number of image pixels
+	// per canvas unit (for example, 2 for the high-resolution image). Values <= 0 are treated as 1
+	Image(scale float64) *image.RGBA
+	// PNG rasterizes the recorded drawing (see Image) and encodes it to PNG
+	PNG(scale float64) ([]byte, error)
+}
+
+type canvasPoint struct {
+	x, y float64
+}
+
+// canvasMatrix is the affine transformation [a, b, c, d, e, f]:
+// x' = a*x + c*y + e, y' = b*x + d*y + f
+type canvasMatrix [6]float64
+
+var canvasIdentity = canvasMatrix{1, 0, 0, 1, 0, 0}
+
+const (
+	canvasMoveTo = iota
+	canvasLineTo
+	canvasCurveTo
+	canvasClosePath
+)
+
+// canvasSegment is the path segment. canvasCurveTo uses all points (two control points and the end point),
+// canvasMoveTo and canvasLineTo use the first point, canvasClosePath doesn't use points
+type canvasSegment struct {
+	kind   int
+	points [3]canvasPoint
+}
+
+type canvasShape struct {
+	segments   []canvasSegment
+	current    canvasPoint
+	start      canvasPoint
+	hasCurrent bool
+	closed     bool
+}
+
+const (
+	canvasSolidPaint = iota
+	canvasLinearPaint
+	canvasRadialPaint
+	canvasPatternPaint
+)
+
+type canvasPaint struct {
+	kind   int
+	color  Color
+	points [2]canvasPoint
+	radius [2]float64
+	stops  []GradientPoint
+	image  Image
+	repeat int
+}
+
+type canvasClip struct {
+	shape  []canvasSegment
+	matrix canvasMatrix
+}
+
+type canvasFont struct {
+	name   string
+	size   float64
+	params FontParams
+}
+
+type canvasRecorderState struct {
+	matrix     canvasMatrix
+	clip       []canvasClip
+	fill       canvasPaint
+	stroke     canvasPaint
+	lineWidth  float64
+	lineJoin   int
+	lineCap    int
+	dash       []float64
+	dashOffset float64
+	font       canvasFont
+	baseline   int
+	align      int
+}
+
+const (
+	canvasFillRecord = iota
+	canvasStrokeRecord
+	canvasClearRecord
+	canvasFillTextRecord
+	canvasStrokeTextRecord
+	canvasImageRecord
+	canvasPutImageRecord
+)
+
+type canvasRecord struct {
+	kind  int
+	state canvasRecorderState
+	shape []canvasSegment
+	text  string
+	point canvasPoint
+	image Image
+	// src and dst are the image fragment and the destination rectangle (x, y, width, height).
+	// The negative src width means the whole image
+	src, dst [4]float64
+}
+
+type canvasRecorder struct {
+	width, height float64
+	state         canvasRecorderState
+	stack         []canvasRecorderState
+	records       []canvasRecord
+}
+
+// NewCanvasRecorder creates the off-screen Canvas with the given size in pixels.
+// The drawing function of the CanvasView can be applied to it:
+//
+//	recorder := rui.NewCanvasRecorder(600, 400)
+//	drawer(recorder)
+//	session.DownloadFileData("chart.svg", []byte(recorder.SVG()))
+func NewCanvasRecorder(width, height float64) CanvasRecorder {
+	recorder := new(canvasRecorder)
+	recorder.width = math.Max(width, 0)
+	recorder.height = math.Max(height, 0)
+	recorder.Reset()
+	return recorder
+}
+
+func defaultCanvasRecorderState() canvasRecorderState {
+	return canvasRecorderState{
+		matrix:    canvasIdentity,
+		fill:      canvasPaint{kind: canvasSolidPaint, color: Black},
+		stroke:    canvasPaint{kind: canvasSolidPaint, color: Black},
+		lineWidth: 1,
+		lineJoin:  MiterJoin,
+		lineCap:   ButtCap,
+		font:      canvasFont{name: "sans-serif", size: 10},
+		baseline:  AlphabeticBaseline,
+		align:     StartAlign,
+	}
+}
+
+func (recorder *canvasRecorder) Reset() {
+	recorder.state = defaultCanvasRecorderState()
+	recorder.stack = []canvasRecorderState{}
+	recorder.records = []canvasRecord{}
+}
+
+func (recorder *canvasRecorder) finishDraw() {
+}
+
+func (recorder *canvasRecorder) View() CanvasView {
+	return nil
+}
+
+func (recorder *canvasRecorder) Width() float64 {
+	return recorder.width
+}
+
+func (recorder *canvasRecorder) Height() float64 {
+	return recorder.height
+}
+
+func (recorder *canvasRecorder) Save() {
+	recorder.stack = append(recorder.stack, recorder.state)
+}
+
+func (recorder *canvasRecorder) Restore() {
+	if count := len(recorder.stack); count > 0 {
+		recorder.state = recorder.stack[count-1]
+		recorder.stack = recorder.stack[:count-1]
+	}
+}
+
+func (recorder *canvasRecorder) addClip(shape *canvasShape) {
+	clip := recorder.state.clip
+	// the full slice expression forces a copy, so the recorded states keep their clips
+	recorder.state.clip = append(clip[:len(clip):len(clip)], canvasClip{
+		shape:  shape.segments,
+		matrix: recorder.state.matrix,
+	})
+}
+
+func (recorder *canvasRecorder) ClipRect(x, y, width, height float64) {
+	shape := new(canvasShape)
+	shape.rect(x, y, width, height)
+	recorder.addClip(shape)
+}
+
+func (recorder *canvasRecorder) ClipPath(path Path) {
+	recorder.addClip(canvasShapeFromPath(path))
+}
+
+func (recorder *canvasRecorder) transform(matrix canvasMatrix) {
+	recorder.state.matrix = recorder.state.matrix.multiply(matrix)
+}
+
+func (recorder *canvasRecorder) SetScale(x, y float64) {
+	recorder.transform(canvasMatrix{x, 0, 0, y, 0, 0})
+}
+
+func (recorder *canvasRecorder) SetTranslation(x, y float64) {
+	recorder.transform(canvasMatrix{1, 0, 0, 1, x, y})
+}
+
+func (recorder *canvasRecorder) SetRotation(angle float64) {
+	sin, cos := math.Sincos(angle)
+	recorder.transform(canvasMatrix{cos, sin, -sin, cos, 0, 0})
+}
+
+func (recorder *canvasRecorder) SetTransformation(xScale, yScale, xSkew, ySkew, dx, dy float64) {
+	recorder.transform(canvasMatrix{xScale, ySkew, xSkew, yScale, dx, dy})
+}
+
+func (recorder *canvasRecorder) ResetTransformation() {
+	recorder.state.matrix = canvasIdentity
+}
+
+func (recorder *canvasRecorder) SetSolidColorFillStyle(color Color) {
+	recorder.state.fill = canvasPaint{kind: canvasSolidPaint, color: color}
+}
+
+func (recorder *canvasRecorder) SetSolidColorStrokeStyle(color Color) {
+	recorder.state.stroke = canvasPaint{kind: canvasSolidPaint, color: color}
+}
+
+func canvasGradientStops(color0, color1 Color, stopPoints []GradientPoint) []GradientPoint {
+	stops := []GradientPoint{{Offset: 0, Color: color0}}
+	for _, point := range stopPoints {
+		if point.Offset >= 0 && point.Offset <= 1 {
+			stops = append(stops, point)
+		}
+	}
+	stops = append(stops, GradientPoint{Offset: 1, Color: color1})
+	sort.SliceStable(stops, func(i, j int) bool {
+		return stops[i].Offset < stops[j].Offset
+	})
+	return stops
+}
+
+func linearCanvasPaint(x0, y0 float64, color0 Color, x1, y1 float64, color1 Color, stopPoints []GradientPoint) canvasPaint {
+	return canvasPaint{
+		kind:   canvasLinearPaint,
+		points: [2]canvasPoint{{x0, y0}, {x1, y1}},
+		stops:  canvasGradientStops(color0, color1, stopPoints),
+	}
+}
+
+func (recorder *canvasRecorder) SetLinearGradientFillStyle(x0, y0 float64, color0 Color, x1, y1 float64, color1 Color, stopPoints []GradientPoint) {
+	recorder.state.fill = linearCanvasPaint(x0, y0, color0, x1, y1, color1, stopPoints)
+}
+
+func (recorder *canvasRecorder) SetLinearGradientStrokeStyle(x0, y0 float64, color0 Color, x1, y1 float64, color1 Color, stopPoints []GradientPoint) {
+	recorder.state.stroke = linearCanvasPaint(x0, y0, color0, x1, y1, color1, stopPoints)
+}
+
+func radialCanvasPaint(x0, y0, r0 float64, color0 Color, x1, y1, r1 float64, color1 Color, stopPoints []GradientPoint) canvasPaint {
+	return canvasPaint{
+		kind:   canvasRadialPaint,
+		points: [2]canvasPoint{{x0, y0}, {x1, y1}},
+		radius: [2]float64{r0, r1},
+		stops:  canvasGradientStops(color0, color1, stopPoints),
+	}
+}
+
+func (recorder *canvasRecorder) SetRadialGradientFillStyle(x0, y0, r0 float64, color0 Color, x1, y1, r1 float64, color1 Color, stopPoints []GradientPoint) {
+	recorder.state.fill = radialCanvasPaint(x0, y0, r0, color0, x1, y1, r1, color1, stopPoints)
+}
+
+func (recorder *canvasRecorder) SetRadialGradientStrokeStyle(x0, y0, r0 float64, color0 Color, x1, y1, r1 float64, color1 Color, stopPoints []GradientPoint) {
+	recorder.state.stroke = radialCanvasPaint(x0, y0, r0, color0, x1, y1, r1, color1, stopPoints)
+}
+
+func (recorder *canvasRecorder) SetImageFillStyle(image Image, repeat int) {
+	if image == nil {
+		return
+	}
+
+	switch repeat {
+	case NoRepeat, RepeatXY, RepeatX, RepeatY:
+		recorder.state.fill = canvasPaint{kind: canvasPatternPaint, image: image, repeat: repeat}
+	}
+}
+
+func (recorder *canvasRecorder) SetLineWidth(width float64) {
+	if width > 0 && !math.IsInf(width, 0) {
+		recorder.state.lineWidth = width
+	}
+}
+
+func (recorder *canvasRecorder) SetLineJoin(join int) {
+	switch join {
+	case MiterJoin, RoundJoin, BevelJoin:
+		recorder.state.lineJoin = join
+	}
+}
+
+func (recorder *canvasRecorder) SetLineCap(cap int) {
+	switch cap {
+	case ButtCap, RoundCap, SquareCap:
+		recorder.state.lineCap = cap
+	}
+}
+
+func (recorder *canvasRecorder) SetLineDash(dash []float64, offset float64) {
+	sum := 0.0
+	for _, value := range dash {
+		if value < 0 || math.IsInf(value, 0) || math.IsNaN(value) {
+			return
+		}
+		sum += value
+	}
+
+	if sum == 0 {
+		recorder.state.dash = nil
+	} else {
+		recorder.state.dash = append([]float64{}, dash...)
+		if len(dash)%2 == 1 {
+			recorder.state.dash = append(recorder.state.dash, dash...)
+		}
+	}
+	if offset >= 0 {
+		recorder.state.dashOffset = offset
+	}
+}
+
+// canvasFontSize converts the font size to pixels. The relative sizes are calculated for the 16px root font
+func canvasFontSize(size SizeUnit) float64 {
+	switch size.Type {
+	case SizeInPixel:
+		return size.Value
+
+	case SizeInEM:
+		return size.Value * 16
+
+	case SizeInEX:
+		return size.Value * 8
+
+	case SizeInPercent:
+		return size.Value * 16 / 100
+
+	case SizeInPt:
+		return size.Value * 96 / 72
+
+	case SizeInPc:
+		return size.Value * 16
+
+	case SizeInInch:
+		return size.Value * 96
+
+	case SizeInMM:
+		return size.Value * 96 / 25.4
+
+	case SizeInCM:
+		return size.Value * 96 / 2.54
+	}
+	return 16
+}
+
+func (recorder *canvasRecorder) SetFont(name string, size SizeUnit) {
+	recorder.SetFontWithParams(name, size, FontParams{})
+}
+
+func (recorder *canvasRecorder) SetFontWithParams(name string, size SizeUnit, params FontParams) {
+	recorder.state.font = canvasFont{name: name, size: canvasFontSize(size), params: params}
+}
+
+func (recorder *canvasRecorder) TextMetrics(text string, fontName string, fontSize SizeUnit, fontParams FontParams) TextMetrics {
+	size := canvasFontSize(fontSize)
+	width := canvasTextWidth(text, size)
+	return TextMetrics{
+		Width:   width,
+		Ascent:  size * canvasFontAscent,
+		Descent: size * canvasFontDescent,
+		Left:    0,
+		Right:   width,
+	}
+}
+
+func (recorder *canvasRecorder) SetTextBaseline(baseline int) {
+	switch baseline {
+	case AlphabeticBaseline, TopBaseline, MiddleBaseline, BottomBaseline, HangingBaseline, IdeographicBaseline:
+		recorder.state.baseline = baseline
+	}
+}
+
+func (recorder *canvasRecorder) SetTextAlign(align int) {
+	switch align {
+	case LeftAlign, RightAlign, CenterAlign, StartAlign, EndAlign:
+		recorder.state.align = align
+	}
+}
+
+func (recorder *canvasRecorder) SetShadow(offsetX, offsetY, blur float64, color Color) {
+}
+
+func (recorder *canvasRecorder) ResetShadow() {
+}
+
+func (recorder *canvasRecorder) addRecord(kind int, shape *canvasShape) {
+	record := canvasRecord{kind: kind, state: recorder.state}
+	if shape != nil {
+		record.shape = shape.segments
+	}
+	recorder.records = append(recorder.records, record)
+}
+
+func rectCanvasShape(x, y, width, height float64) *canvasShape {
+	shape := new(canvasShape)
+	shape.rect(x, y, width, height)
+	return shape
+}
+
+func (recorder *canvasRecorder) ClearRect(x, y, width, height float64) {
+	recorder.addRecord(canvasClearRecord, rectCanvasShape(x, y, width, height))
+}
+
+func (recorder *canvasRecorder) FillRect(x, y, width, height float64) {
+	recorder.addRecord(canvasFillRecord, rectCanvasShape(x, y, width, height))
+}
+
+func (recorder *canvasRecorder) StrokeRect(x, y, width, height float64) {
+	recorder.addRecord(canvasStrokeRecord, rectCanvasShape(x, y, width, height))
+}
+
+func (recorder *canvasRecorder) FillAndStrokeRect(x, y, width, height float64) {
+	shape := rectCanvasShape(x, y, width, height)
+	recorder.addRecord(canvasFillRecord, shape)
+	recorder.addRecord(canvasStrokeRecord, shape)
+}
+
+func roundedRectCanvasShape(x, y, width, height, r float64) *canvasShape {
+	shape := new(canvasShape)
+	shape.moveTo(x, y+r)
+	shape.arc(x+r, y+r, r, math.Pi, math.Pi*3/2, false)
+	shape.lineTo(x+width-r, y)
+	shape.arc(x+width-r, y+r, r, math.Pi*3/2, math.Pi*2, false)
+	shape.lineTo(x+width, y+height-r)
+	shape.arc(x+width-r, y+height-r, r, 0, math.Pi/2, false)
+	shape.lineTo(x+r, y+height)
+	shape.arc(x+r, y+height-r, r, math.Pi/2, math.Pi, false)
+	shape.close()
+	return shape
+}
+
+func (recorder *canvasRecorder) FillRoundedRect(x, y, width, height, r float64) {
+	recorder.addRecord(canvasFillRecord, roundedRectCanvasShape(x, y, width, height, r))
+}
+
+func (recorder *canvasRecorder) StrokeRoundedRect(x, y, width, height, r float64) {
+	recorder.addRecord(canvasStrokeRecord, roundedRectCanvasShape(x, y, width, height, r))
+}
+
+func (recorder *canvasRecorder) FillAndStrokeRoundedRect(x, y, width, height, r float64) {
+	shape := roundedRectCanvasShape(x, y, width, height, r)
+	recorder.addRecord(canvasFillRecord, shape)
+	recorder.addRecord(canvasStrokeRecord, shape)
+}
+
+func ellipseCanvasShape(x, y, radiusX, radiusY, rotation float64) *canvasShape {
+	shape := new(canvasShape)
+	shape.ellipse(x, y, radiusX, radiusY, rotation, 0, math.Pi*2, false)
+	shape.close()
+	return shape
+}
+
+func (recorder *canvasRecorder) FillEllipse(x, y, radiusX, radiusY, rotation float64) {
+	if radiusX >= 0 && radiusY >= 0 {
+		recorder.addRecord(canvasFillRecord, ellipseCanvasShape(x, y, radiusX, radiusY, rotation))
+	}
+}
+
+func (recorder *canvasRecorder) StrokeEllipse(x, y, radiusX, radiusY, rotation float64) {
+	if radiusX >= 0 && radiusY >= 0 {
+		recorder.addRecord(canvasStrokeRecord, ellipseCanvasShape(x, y, radiusX, radiusY, rotation))
+	}
+}
+
+func (recorder *canvasRecorder) FillAndStrokeEllipse(x, y, radiusX, radiusY, rotation float64) {
+	if radiusX >= 0 && radiusY >= 0 {
+		shape := ellipseCanvasShape(x, y, radiusX, radiusY, rotation)
+		recorder.addRecord(canvasFillRecord, shape)
+		recorder.addRecord(canvasStrokeRecord, shape)
+	}
+}
+
+func (recorder *canvasRecorder) FillPath(path Path) {
+	recorder.addRecord(canvasFillRecord, canvasShapeFromPath(path))
+}
+
+func (recorder *canvasRecorder) StrokePath(path Path) {
+	recorder.addRecord(canvasStrokeRecord, canvasShapeFromPath(path))
+}
+
+func (recorder *canvasRecorder) FillAndStrokePath(path Path) {
+	shape := canvasShapeFromPath(path)
+	recorder.addRecord(canvasFillRecord, shape)
+	recorder.addRecord(canvasStrokeRecord, shape)
+}
+
+func (recorder *canvasRecorder) DrawLine(x0, y0, x1, y1 float64) {
+	shape := new(canvasShape)
+	shape.moveTo(x0, y0)
+	shape.lineTo(x1, y1)
+	recorder.addRecord(canvasStrokeRecord, shape)
+}
+
+func (recorder *canvasRecorder) addTextRecord(kind int, x, y float64, text string) {
+	if text != "" {
+		recorder.records = append(recorder.records, canvasRecord{
+			kind:  kind,
+			state: recorder.state,
+			text:  text,
+			point: canvasPoint{x, y},
+		})
+	}
+}
+
+func (recorder *canvasRecorder) FillText(x, y float64, text string) {
+	recorder.addTextRecord(canvasFillTextRecord, x, y, text)
+}
+
+func (recorder *canvasRecorder) StrokeText(x, y float64, text string) {
+	recorder.addTextRecord(canvasStrokeTextRecord, x, y, text)
+}
+
+func (recorder *canvasRecorder) addImageRecord(image Image, src, dst [4]float64) {
+	if image != nil {
+		recorder.records = append(recorder.records, canvasRecord{
+			kind:  canvasImageRecord,
+			state: recorder.state,
+			image: image,
+			src:   src,
+			dst:   dst,
+		})
+	}
+}
+
+func (recorder *canvasRecorder) DrawImage(x, y float64, image Image) {
+	recorder.addImageRecord(image, [4]float64{0, 0, -1, -1}, [4]float64{x, y, -1, -1})
+}
+
+func (recorder *canvasRecorder) DrawImageInRect(x, y, width, height float64, image Image) {
+	recorder.addImageRecord(image, [4]float64{0, 0, -1, -1}, [4]float64{x, y, width, height})
+}
+
+func (recorder *canvasRecorder) DrawImageFragment(srcX, srcY, srcWidth, srcHeight, dstX, dstY, dstWidth, dstHeight float64, image Image) {
+	recorder.addImageRecord(image, [4]float64{srcX, srcY, srcWidth, srcHeight}, [4]float64{dstX, dstY, dstWidth, dstHeight})
+}
+
+func (recorder *canvasRecorder) DrawGoImage(x, y, width, height float64, img image.Image) {
+	if img != nil && !img.Bounds().Empty() {
+		recorder.addImageRecord(canvasGoImage(img), [4]float64{0, 0, -1, -1}, [4]float64{x, y, width, height})
+	}
+}
+
+func (recorder *canvasRecorder) GetImageData(x, y, width, height float64) *image.NRGBA {
+	w, h := int(math.Round(width)), int(math.Round(height))
+	if w <= 0 || h <= 0 {
+		return nil
+	}
+
+	result := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(result, result.Bounds(), recorder.Image(1), image.Pt(int(math.Round(x)), int(math.Round(y))), draw.Src)
+	return result
+}
+
+func (recorder *canvasRecorder) PutImageData(x, y float64, img image.Image) {
+	if img == nil || img.Bounds().Empty() {
+		return
+	}
+
+	goImage := canvasGoImage(img)
+	if goImage == nil {
+		return
+	}
+
+	bounds := img.Bounds()
+	recorder.records = append(recorder.records, canvasRecord{
+		kind:  canvasPutImageRecord,
+		state: defaultCanvasRecorderState(),
+		image: goImage,
+		src:   [4]float64{0, 0, -1, -1},
+		dst:   [4]float64{math.Round(x), math.Round(y), float64(bounds.Dx()), float64(bounds.Dy())},
+	})
+}
+
+// canvasGoImage wraps the Go image to Image with the "data:" URL
+func canvasGoImage(img image.Image) Image {
+	buffer := new(bytes.Buffer)
+	if err := png.Encode(buffer, canvasNRGBA(img)); err != nil {
+		ErrorLog(err.Error())
+		return nil
+	}
+
+	bounds := img.Bounds()
+	return &imageData{
+		url:           "data:image/png;base64," + base64.StdEncoding.EncodeToString(buffer.Bytes()),
+		loadingStatus: ImageReady,
+		width:         float64(bounds.Dx()),
+		height:        float64(bounds.Dy()),
+	}
+}
+
+func (recorder *canvasRecorder) PNG(scale float64) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	if err := png.Encode(buffer, recorder.Image(scale)); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (matrix canvasMatrix) multiply(next canvasMatrix) canvasMatrix {
+	return canvasMatrix{
+		matrix[0]*next[0] + matrix[2]*next[1],
+		matrix[1]*next[0] + matrix[3]*next[1],
+		matrix[0]*next[2] + matrix[2]*next[3],
+		matrix[1]*next[2] + matrix[3]*next[3],
+		matrix[0]*next[4] + matrix[2]*next[5] + matrix[4],
+		matrix[1]*next[4] + matrix[3]*next[5] + matrix[5],
+	}
+}
+
+func (matrix canvasMatrix) apply(point canvasPoint) canvasPoint {
+	return canvasPoint{
+		x: matrix[0]*point.x + matrix[2]*point.y + matrix[4],
+		y: matrix[1]*point.x + matrix[3]*point.y + matrix[5],
+	}
+}
+
+func (matrix canvasMatrix) invert() (canvasMatrix, bool) {
+	det := matrix[0]*matrix[3] - matrix[1]*matrix[2]
+	if det == 0 || math.IsNaN(det) {
+		return canvasIdentity, false
+	}
+	return canvasMatrix{
+		matrix[3] / det,
+		-matrix[1] / det,
+		-matrix[2] / det,
+		matrix[0] / det,
+		(matrix[2]*matrix[5] - matrix[3]*matrix[4]) / det,
+		(matrix[1]*matrix[4] - matrix[0]*matrix[5]) / det,
+	}, true
+}
+
+// scale returns the average scaling factor of the matrix
+func (matrix canvasMatrix) scale() float64 {
+	return math.Sqrt(math.Abs(matrix[0]*matrix[3] - matrix[1]*matrix[2]))
+}
+
+func (shape *canvasShape) ensureStart() {
+	if shape.closed {
+		shape.segments = append(shape.segments, canvasSegment{kind: canvasMoveTo, points: [3]canvasPoint{shape.start}})
+		shape.closed = false
+	}
+}
+
+func (shape *canvasShape) moveTo(x, y float64) {
+	shape.current = canvasPoint{x, y}
+	shape.start = shape.current
+	shape.hasCurrent = true
+	shape.closed = false
+	shape.segments = append(shape.segments, canvasSegment{kind: canvasMoveTo, points: [3]canvasPoint{shape.current}})
+}
+
+func (shape *canvasShape) lineTo(x, y float64) {
+	if !shape.hasCurrent {
+		shape.moveTo(x, y)
+		return
+	}
+	shape.ensureStart()
+	shape.current = canvasPoint{x, y}
+	shape.segments = append(shape.segments, canvasSegment{kind: canvasLineTo, points: [3]canvasPoint{shape.current}})
+}
+
+func (shape *canvasShape) curveTo(cp0x, cp0y, cp1x, cp1y, x, y float64) {
+	if !shape.hasCurrent {
+		shape.moveTo(cp0x, cp0y)
+	}
+	shape.ensureStart()
+	shape.current = canvasPoint{x, y}
+	shape.segments = append(shape.segments, canvasSegment{
+		kind:   canvasCurveTo,
+		points: [3]canvasPoint{{cp0x, cp0y}, {cp1x, cp1y}, shape.current},
+	})
+}
+
+func (shape *canvasShape) quadTo(cpx, cpy, x, y float64) {
+	if !shape.hasCurrent {
+		shape.moveTo(cpx, cpy)
+	}
+	p0 := shape.current
+	shape.curveTo(p0.x+(cpx-p0.x)*2/3, p0.y+(cpy-p0.y)*2/3, x+(cpx-x)*2/3, y+(cpy-y)*2/3, x, y)
+}
+
+func (shape *canvasShape) close() {
+	if shape.hasCurrent && !shape.closed {
+		shape.segments = append(shape.segments, canvasSegment{kind: canvasClosePath})
+		shape.current = shape.start
+		shape.closed = true
+	}
+}
+
+func (shape *canvasShape) rect(x, y, width, height float64) {
+	shape.moveTo(x, y)
+	shape.lineTo(x+width, y)
+	shape.lineTo(x+width, y+height)
+	shape.lineTo(x, y+height)
+	shape.close()
+}
+
+func (shape *canvasShape) arc(x, y, radius, startAngle, endAngle float64, anticlockwise bool) {
+	shape.ellipse(x, y, radius, radius, 0, startAngle, endAngle, anticlockwise)
+}
+
+// ellipse adds the elliptical arc approximated by cubic Bézier curves (one curve per quarter)
+func (shape *canvasShape) ellipse(x, y, radiusX, radiusY, rotation, startAngle, endAngle float64, anticlockwise bool) {
+	positiveMod := func(value float64) float64 {
+		value = math.Mod(value, 2*math.Pi)
+		if value < 0 {
+			value += 2 * math.Pi
+		}
+		return value
+	}
+
+	sweep := endAngle - startAngle
+	if anticlockwise {
+		if -sweep >= 2*math.Pi {
+			sweep = -2 * math.Pi
+		} else {
+			sweep = -positiveMod(-sweep)
+		}
+	} else if sweep >= 2*math.Pi {
+		sweep = 2 * math.Pi
+	} else {
+		sweep = positiveMod(sweep)
+	}
+
+	sinRotation, cosRotation := math.Sincos(rotation)
+	point := func(u, v float64) (float64, float64) {
+		u *= radiusX
+		v *= radiusY
+		return x + u*cosRotation - v*sinRotation, y + u*sinRotation + v*cosRotation
+	}
+
+	sin, cos := math.Sincos(startAngle)
+	startX, startY := point(cos, sin)
+	if shape.hasCurrent {
+		if shape.closed || shape.current != (canvasPoint{startX, startY}) {
+			shape.lineTo(startX, startY)
+		}
+	} else {
+		shape.moveTo(startX, startY)
+	}
+
+	count := int(math.Ceil(math.Abs(sweep)/(math.Pi/2) - 1e-9))
+	if count == 0 {
+		return
+	}
+
+	step := sweep / float64(count)
+	k := 4.0 / 3.0 * math.Tan(step/4)
+	angle := startAngle
+	for i := 0; i < count; i++ {
+		sin0, cos0 := math.Sincos(angle)
+		angle += step
+		sin1, cos1 := math.Sincos(angle)
+
+		cp0x, cp0y := point(cos0-k*sin0, sin0+k*cos0)
+		cp1x, cp1y := point(cos1+k*sin1, sin1-k*cos1)
+		endX, endY := point(cos1, sin1)
+		shape.curveTo(cp0x, cp0y, cp1x, cp1y, endX, endY)
+	}
+}
+
+func (shape *canvasShape) arcTo(x1, y1, x2, y2, radius float64) {
+	if !shape.hasCurrent {
+		shape.moveTo(x1, y1)
+	}
+
+	p0 := shape.current
+	v1 := canvasPoint{p0.x - x1, p0.y - y1}
+	v2 := canvasPoint{x2 - x1, y2 - y1}
+	len1 := math.Hypot(v1.x, v1.y)
+	len2 := math.Hypot(v2.x, v2.y)
+	if radius <= 0 || len1 == 0 || len2 == 0 {
+		shape.lineTo(x1, y1)
+		return
+	}
+
+	v1 = canvasPoint{v1.x / len1, v1.y / len1}
+	v2 = canvasPoint{v2.x / len2, v2.y / len2}
+	cross := v1.x*v2.y - v1.y*v2.x
+	if math.Abs(cross) < 1e-9 {
+		shape.lineTo(x1, y1)
+		return
+	}
+
+	angle := math.Acos(math.Max(-1, math.Min(1, v1.x*v2.x+v1.y*v2.y)))
+	dist := radius / math.Tan(angle/2)
+	t1 := canvasPoint{x1 + v1.x*dist, y1 + v1.y*dist}
+	t2 := canvasPoint{x1 + v2.x*dist, y1 + v2.y*dist}
+
+	bisector := canvasPoint{v1.x + v2.x, v1.y + v2.y}
+	bisectorLen := math.Hypot(bisector.x, bisector.y)
+	centerDist := radius / math.Sin(angle/2)
+	center := canvasPoint{x1 + bisector.x/bisectorLen*centerDist, y1 + bisector.y/bisectorLen*centerDist}
+
+	startAngle := math.Atan2(t1.y-center.y, t1.x-center.x)
+	endAngle := math.Atan2(t2.y-center.y, t2.x-center.x)
+	diff := math.Remainder(endAngle-startAngle, 2*math.Pi)
+
+	shape.lineTo(t1.x, t1.y)
+	shape.arc(center.x, center.y, radius, startAngle, startAngle+diff, diff < 0)
+}
+
+func canvasShapeFromPath(path Path) *canvasShape {
+	shape := new(canvasShape)
+	data, ok := path.(*pathData)
+	if !ok {
+		return shape
+	}
+
+	for _, element := range data.elements {
+		args := make([]float64, 0, len(element.args))
+		anticlockwise := false
+		for _, arg := range element.args {
+			switch arg := arg.(type) {
+			case float64:
+				args = append(args, arg)
+
+			case bool:
+				anticlockwise = arg
+			}
+		}
+
+		switch element.funcName {
+		case "beginPath":
+			*shape = canvasShape{}
+
+		case "moveTo":
+			if len(args) >= 2 {
+				shape.moveTo(args[0], args[1])
+			}
+
+		case "lineTo":
+			if len(args) >= 2 {
+				shape.lineTo(args[0], args[1])
+			}
+
+		case "arcTo":
+			if len(args) >= 5 {
+				shape.arcTo(args[0], args[1], args[2], args[3], args[4])
+			}
+
+		case "arc":
+			if len(args) >= 5 {
+				shape.arc(args[0], args[1], args[2], args[3], args[4], anticlockwise)
+			}
+
+		case "bezierCurveTo":
+			if len(args) >= 6 {
+				shape.curveTo(args[0], args[1], args[2], args[3], args[4], args[5])
+			}
+
+		case "quadraticCurveTo":
+			if len(args) >= 4 {
+				shape.quadTo(args[0], args[1], args[2], args[3])
+			}
+
+		case "ellipse":
+			if len(args) >= 7 {
+				shape.ellipse(args[0], args[1], args[2], args[3], args[4], args[5], args[6], anticlockwise)
+			}
+
+		case "close", "closePath":
+			shape.close()
+		}
+	}
+	return shape
+}
+
+const (
+	canvasFontAscent  = 0.8
+	canvasFontDescent = 0.2
+	// canvasFontAdvance is the width of the character cell of the built-in font relative to the font size
+	canvasFontAdvance = 0.6
+)
+
+func canvasTextWidth(text string, fontSize float64) float64 {
+	return float64(utf8.RuneCountInString(text)) * fontSize * canvasFontAdvance
+}
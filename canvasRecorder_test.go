@@ -0,0 +1,185 @@
+package rui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCanvasRecorder(t *testing.T) {
+	createTestLog(t, false)
+
+	pattern := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	for x := 0; x < 2; x++ {
+		for y := 0; y < 2; y++ {
+			pattern.Set(x, y, color.RGBA{R: 0, G: 0, B: 255, A: 255})
+		}
+	}
+	buffer := new(bytes.Buffer)
+	png.Encode(buffer, pattern)
+	dataImage := &imageData{url: "data:image/png;base64," + base64.StdEncoding.EncodeToString(buffer.Bytes())}
+
+	recorder := NewCanvasRecorder(100, 60)
+	recorder.SetSolidColorFillStyle(0xFFFF0000)
+	recorder.FillRect(10, 10, 30, 20)
+
+	recorder.Save()
+	recorder.ClipRect(0, 0, 50, 60)
+	recorder.SetSolidColorFillStyle(0x8000FF00)
+	recorder.FillRect(0, 0, 100, 5)
+	recorder.Restore()
+
+	path := NewPath()
+	path.Arc(70, 20, 10, 0, math.Pi*2, true)
+	recorder.SetSolidColorFillStyle(0xFF0000FF)
+	recorder.FillPath(path)
+
+	recorder.SetLineWidth(4)
+	recorder.SetLineCap(RoundCap)
+	recorder.SetSolidColorStrokeStyle(0xFF00FF00)
+	recorder.DrawLine(10, 45, 90, 45)
+
+	recorder.DrawImageInRect(90, 0, 10, 10, dataImage)
+
+	recorder.SetFont("Arial", Px(10))
+	recorder.SetSolidColorFillStyle(0xFF000000)
+	recorder.FillText(10, 58, "Hi")
+
+	img := recorder.Image(1)
+	if bounds := img.Bounds(); bounds.Dx() != 100 || bounds.Dy() != 60 {
+		t.Fatalf("image size: %v", bounds)
+	}
+
+	pixels := []struct {
+		x, y  int
+		color color.RGBA
+	}{
+		{20, 20, color.RGBA{R: 255, A: 255}},
+		{5, 20, color.RGBA{}},
+		{20, 2, color.RGBA{R: 0, G: 128, B: 0, A: 128}},
+		{60, 2, color.RGBA{}},
+		{70, 20, color.RGBA{B: 255, A: 255}},
+		{70, 35, color.RGBA{}},
+		{50, 45, color.RGBA{G: 255, A: 255}},
+		{50, 40, color.RGBA{}},
+		{95, 5, color.RGBA{B: 255, A: 255}},
+		{10, 55, color.RGBA{A: 255}},
+	}
+	for _, pixel := range pixels {
+		if c := img.RGBAAt(pixel.x, pixel.y); c != pixel.color {
+			t.Errorf("pixel (%d, %d): %v, expected %v", pixel.x, pixel.y, c, pixel.color)
+		}
+	}
+
+	if c := img.RGBAAt(91, 45); c.G == 0 || c.G == 255 {
+		t.Errorf("the round cap is not anti-aliased: %v", c)
+	}
+
+	data, err := recorder.PNG(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Error(err)
+	} else if bounds := decoded.Bounds(); bounds.Dx() != 200 || bounds.Dy() != 120 {
+		t.Errorf("scaled image size: %v", bounds)
+	}
+
+	svg := recorder.SVG()
+	for _, text := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="100" height="60" viewBox="0 0 100 60">`,
+		`<path d="M10,10 L40,10 L40,30 L10,30 Z" fill="#ff0000"/>`,
+		`<clipPath id="clip1"><path d="M0,0 L50,0 L50,60 L0,60 Z"/></clipPath>`,
+		`<g clip-path="url(#clip1)"><path d="M0,0 L100,0 L100,5 L0,5 Z" fill="#00ff00" fill-opacity="0.502"/>`,
+		`fill="none" stroke="#00ff00" stroke-width="4" stroke-linecap="round"/>`,
+		`<image x="90" y="0" width="10" height="10" preserveAspectRatio="none" xlink:href="data:image/png;base64,`,
+		`<text x="10" y="58" font-family="Arial" font-size="10" fill="#000000" xml:space="preserve">Hi</text>`,
+	} {
+		if !strings.Contains(svg, text) {
+			t.Errorf(`"%s" is not found in SVG:\n%s`, text, svg)
+		}
+	}
+
+	recorder.ClearRect(0, 0, 100, 60)
+	if svg := recorder.SVG(); strings.Contains(svg, "fill=") {
+		t.Errorf("the cleared drawing is written:\n%s", svg)
+	}
+	if c := recorder.Image(1).RGBAAt(20, 20); c.A != 0 {
+		t.Errorf("the canvas is not cleared: %v", c)
+	}
+
+	recorder.Reset()
+	recorder.SetTranslation(50, 30)
+	recorder.SetRotation(math.Pi / 4)
+	recorder.SetLineDash([]float64{4, 2}, 0)
+	recorder.StrokeRect(-10, -10, 20, 20)
+	if svg := recorder.SVG(); !strings.Contains(svg, `transform="matrix(0.707 0.707 -0.707 0.707 50 30)"`) ||
+		!strings.Contains(svg, `stroke-dasharray="4 2"`) {
+		t.Errorf("the transformation or the dash is not written:\n%s", svg)
+	}
+	if c := recorder.Image(1).RGBAAt(50, 30); c.A != 0 {
+		t.Errorf("the stroked rectangle is filled: %v", c)
+	}
+}
+
+func TestCanvasImageData(t *testing.T) {
+	createTestLog(t, false)
+
+	heatmap := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		heatmap.SetNRGBA(x, 0, color.NRGBA{R: 255, A: 255})
+		heatmap.SetNRGBA(x, 1, color.NRGBA{B: 255, A: 128})
+	}
+	if canvasNRGBA(heatmap) != heatmap {
+		t.Error("the packed NRGBA image is copied")
+	}
+	if sub := canvasNRGBA(heatmap.SubImage(image.Rect(1, 1, 3, 2))); sub.Bounds() != image.Rect(0, 0, 2, 1) ||
+		sub.NRGBAAt(0, 0) != (color.NRGBA{B: 255, A: 128}) {
+		t.Errorf("invalid sub image: %v %v", sub.Bounds(), sub.NRGBAAt(0, 0))
+	}
+
+	recorder := NewCanvasRecorder(20, 10)
+	recorder.SetSolidColorFillStyle(0xFF00FF00)
+	recorder.FillRect(0, 0, 20, 10)
+	recorder.Save()
+	recorder.SetTranslation(100, 100)
+	recorder.ClipRect(0, 0, 1, 1)
+	recorder.PutImageData(2, 3, heatmap)
+	recorder.Restore()
+	recorder.DrawGoImage(10, 0, 8, 4, heatmap)
+
+	data := recorder.GetImageData(0, 0, 20, 10)
+	if data == nil || data.Bounds() != image.Rect(0, 0, 20, 10) {
+		t.Fatalf("invalid image data: %v", data)
+	}
+	for _, test := range []struct {
+		x, y     int
+		expected color.NRGBA
+	}{
+		{1, 1, color.NRGBA{G: 255, A: 255}},
+		{2, 3, color.NRGBA{R: 255, A: 255}},
+		{5, 4, color.NRGBA{B: 255, A: 128}},
+		{6, 4, color.NRGBA{G: 255, A: 255}},
+		{11, 0, color.NRGBA{R: 255, A: 255}},
+	} {
+		if c := data.NRGBAAt(test.x, test.y); c != test.expected {
+			t.Errorf("the pixel (%d, %d) is %v, expected %v", test.x, test.y, c, test.expected)
+		}
+	}
+	if c := data.NRGBAAt(14, 3); c.R != 0 || c.A != 255 || c.B < 120 || c.G < 120 {
+		t.Errorf("the drawn image is not blended: %v", c)
+	}
+
+	if part := recorder.GetImageData(18, 8, 4, 4); part.NRGBAAt(1, 1).A == 0 || part.NRGBAAt(3, 3).A != 0 {
+		t.Errorf("invalid pixels outside the canvas: %v %v", part.NRGBAAt(1, 1), part.NRGBAAt(3, 3))
+	}
+
+	if svg := recorder.SVG(); !strings.Contains(svg, `<image x="2" y="3" width="4" height="2" preserveAspectRatio="none" xlink:href="data:image/png;base64,`) {
+		t.Errorf("the put image is not written:\n%s", svg)
+	}
+}
@@ -0,0 +1,458 @@
+package rui
+
+import (
+	"encoding/base64"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type canvasSVGWriter struct {
+	recorder *canvasRecorder
+	images   canvasImages
+	defs     strings.Builder
+	body     strings.Builder
+	clipIDs  map[*canvasClip]string
+	idCount  int
+}
+
+func (recorder *canvasRecorder) SVG() string {
+	writer := &canvasSVGWriter{
+		recorder: recorder,
+		images:   canvasImages{},
+		clipIDs:  map[*canvasClip]string{},
+	}
+
+	for i := range recorder.records {
+		writer.writeRecord(&recorder.records[i])
+	}
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	width := svgNumber(recorder.width)
+	height := svgNumber(recorder.height)
+	buffer.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="`)
+	buffer.WriteString(width)
+	buffer.WriteString(`" height="`)
+	buffer.WriteString(height)
+	buffer.WriteString(`" viewBox="0 0 `)
+	buffer.WriteString(width)
+	buffer.WriteRune(' ')
+	buffer.WriteString(height)
+	buffer.WriteString("\">\n")
+	if writer.defs.Len() > 0 {
+		buffer.WriteString("<defs>\n")
+		buffer.WriteString(writer.defs.String())
+		buffer.WriteString("</defs>\n")
+	}
+	buffer.WriteString(writer.body.String())
+	buffer.WriteString("</svg>\n")
+	return buffer.String()
+}
+
+func svgNumber(value float64) string {
+	value = math.Round(value*1000) / 1000
+	if value == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func (writer *canvasSVGWriter) newID(prefix string) string {
+	writer.idCount++
+	return prefix + strconv.Itoa(writer.idCount)
+}
+
+func writeSVGAttribute(buffer *strings.Builder, name, value string) {
+	buffer.WriteRune(' ')
+	buffer.WriteString(name)
+	buffer.WriteString(`="`)
+	buffer.WriteString(value)
+	buffer.WriteRune('"')
+}
+
+func svgPathData(shape []canvasSegment) string {
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	writePoint := func(point canvasPoint) {
+		buffer.WriteString(svgNumber(point.x))
+		buffer.WriteRune(',')
+		buffer.WriteString(svgNumber(point.y))
+	}
+
+	for _, segment := range shape {
+		if buffer.Len() > 0 {
+			buffer.WriteRune(' ')
+		}
+		switch segment.kind {
+		case canvasMoveTo:
+			buffer.WriteRune('M')
+			writePoint(segment.points[0])
+
+		case canvasLineTo:
+			buffer.WriteRune('L')
+			writePoint(segment.points[0])
+
+		case canvasCurveTo:
+			buffer.WriteRune('C')
+			writePoint(segment.points[0])
+			buffer.WriteRune(' ')
+			writePoint(segment.points[1])
+			buffer.WriteRune(' ')
+			writePoint(segment.points[2])
+
+		case canvasClosePath:
+			buffer.WriteRune('Z')
+		}
+	}
+	return buffer.String()
+}
+
+func writeSVGTransform(buffer *strings.Builder, matrix canvasMatrix) {
+	if matrix != canvasIdentity {
+		values := make([]string, 6)
+		for i, value := range matrix {
+			values[i] = svgNumber(value)
+		}
+		writeSVGAttribute(buffer, "transform", "matrix("+strings.Join(values, " ")+")")
+	}
+}
+
+// clipID returns the id of the clipPath element which is the intersection of all clips
+func (writer *canvasSVGWriter) clipID(clip []canvasClip) string {
+	parentID := ""
+	for i := range clip {
+		if id, ok := writer.clipIDs[&clip[i]]; ok {
+			parentID = id
+			continue
+		}
+
+		id := writer.newID("clip")
+		writer.defs.WriteString(`<clipPath id="`)
+		writer.defs.WriteString(id)
+		writer.defs.WriteRune('"')
+		if parentID != "" {
+			writeSVGAttribute(&writer.defs, "clip-path", "url(#"+parentID+")")
+		}
+		writer.defs.WriteString(`><path d="`)
+		writer.defs.WriteString(svgPathData(clip[i].shape))
+		writer.defs.WriteRune('"')
+		writeSVGTransform(&writer.defs, clip[i].matrix)
+		writer.defs.WriteString("/></clipPath>\n")
+
+		writer.clipIDs[&clip[i]] = id
+		parentID = id
+	}
+	return parentID
+}
+
+func svgColor(color Color) (string, string) {
+	a, r, g, b := color.ARGB()
+	text := "#" + strconv.FormatUint(uint64(r)<<16|uint64(g)<<8|uint64(b)|1<<24, 16)[1:]
+	if a == 255 {
+		return text, ""
+	}
+	return text, svgNumber(float64(a) / 255)
+}
+
+func (writer *canvasSVGWriter) writeGradientStops(stops []GradientPoint) {
+	for _, stop := range stops {
+		color, opacity := svgColor(stop.Color)
+		writer.defs.WriteString(`<stop offset="`)
+		writer.defs.WriteString(svgNumber(stop.Offset))
+		writer.defs.WriteString(`" stop-color="`)
+		writer.defs.WriteString(color)
+		writer.defs.WriteRune('"')
+		if opacity != "" {
+			writeSVGAttribute(&writer.defs, "stop-opacity", opacity)
+		}
+		writer.defs.WriteString("/>\n")
+	}
+}
+
+// writePaint writes the "fill" or "stroke" attributes of the paint. Gradients and patterns are added to defs
+func (writer *canvasSVGWriter) writePaint(attribute string, paint canvasPaint) {
+	switch paint.kind {
+	case canvasSolidPaint:
+		color, opacity := svgColor(paint.color)
+		writeSVGAttribute(&writer.body, attribute, color)
+		if opacity != "" {
+			writeSVGAttribute(&writer.body, attribute+"-opacity", opacity)
+		}
+		return
+
+	case canvasLinearPaint:
+		id := writer.newID("gradient")
+		writer.defs.WriteString(`<linearGradient id="`)
+		writer.defs.WriteString(id)
+		writer.defs.WriteString(`" gradientUnits="userSpaceOnUse"`)
+		writeSVGAttribute(&writer.defs, "x1", svgNumber(paint.points[0].x))
+		writeSVGAttribute(&writer.defs, "y1", svgNumber(paint.points[0].y))
+		writeSVGAttribute(&writer.defs, "x2", svgNumber(paint.points[1].x))
+		writeSVGAttribute(&writer.defs, "y2", svgNumber(paint.points[1].y))
+		writer.defs.WriteString(">\n")
+		writer.writeGradientStops(paint.stops)
+		writer.defs.WriteString("</linearGradient>\n")
+		writeSVGAttribute(&writer.body, attribute, "url(#"+id+")")
+
+	case canvasRadialPaint:
+		id := writer.newID("gradient")
+		writer.defs.WriteString(`<radialGradient id="`)
+		writer.defs.WriteString(id)
+		writer.defs.WriteString(`" gradientUnits="userSpaceOnUse"`)
+		writeSVGAttribute(&writer.defs, "fx", svgNumber(paint.points[0].x))
+		writeSVGAttribute(&writer.defs, "fy", svgNumber(paint.points[0].y))
+		writeSVGAttribute(&writer.defs, "fr", svgNumber(paint.radius[0]))
+		writeSVGAttribute(&writer.defs, "cx", svgNumber(paint.points[1].x))
+		writeSVGAttribute(&writer.defs, "cy", svgNumber(paint.points[1].y))
+		writeSVGAttribute(&writer.defs, "r", svgNumber(paint.radius[1]))
+		writer.defs.WriteString(">\n")
+		writer.writeGradientStops(paint.stops)
+		writer.defs.WriteString("</radialGradient>\n")
+		writeSVGAttribute(&writer.body, attribute, "url(#"+id+")")
+
+	case canvasPatternPaint:
+		source := writer.images.source(paint.image)
+		width, height := source.size(paint.image)
+		if width <= 0 || height <= 0 {
+			writeSVGAttribute(&writer.body, attribute, "none")
+			return
+		}
+
+		id := writer.newID("pattern")
+		writer.defs.WriteString(`<pattern id="`)
+		writer.defs.WriteString(id)
+		writer.defs.WriteString(`" patternUnits="userSpaceOnUse"`)
+		writeSVGAttribute(&writer.defs, "width", svgNumber(width))
+		writeSVGAttribute(&writer.defs, "height", svgNumber(height))
+		writer.defs.WriteString(`><image`)
+		writeSVGAttribute(&writer.defs, "width", svgNumber(width))
+		writeSVGAttribute(&writer.defs, "height", svgNumber(height))
+		writeSVGAttribute(&writer.defs, "xlink:href", htmlEscape(source.href(paint.image)))
+		writer.defs.WriteString("/></pattern>\n")
+		writeSVGAttribute(&writer.body, attribute, "url(#"+id+")")
+	}
+}
+
+func (writer *canvasSVGWriter) writeStrokeAttributes(state *canvasRecorderState) {
+	writer.body.WriteString(` fill="none"`)
+	writer.writePaint("stroke", state.stroke)
+	if state.lineWidth != 1 {
+		writeSVGAttribute(&writer.body, "stroke-width", svgNumber(state.lineWidth))
+	}
+	switch state.lineJoin {
+	case RoundJoin:
+		writeSVGAttribute(&writer.body, "stroke-linejoin", "round")
+
+	case BevelJoin:
+		writeSVGAttribute(&writer.body, "stroke-linejoin", "bevel")
+	}
+	switch state.lineCap {
+	case RoundCap:
+		writeSVGAttribute(&writer.body, "stroke-linecap", "round")
+
+	case SquareCap:
+		writeSVGAttribute(&writer.body, "stroke-linecap", "square")
+	}
+	if len(state.dash) > 0 {
+		values := make([]string, len(state.dash))
+		for i, value := range state.dash {
+			values[i] = svgNumber(value)
+		}
+		writeSVGAttribute(&writer.body, "stroke-dasharray", strings.Join(values, " "))
+		if state.dashOffset != 0 {
+			writeSVGAttribute(&writer.body, "stroke-dashoffset", svgNumber(state.dashOffset))
+		}
+	}
+}
+
+func (writer *canvasSVGWriter) writeFontAttributes(font canvasFont) {
+	names := strings.Split(font.name, ",")
+	for i, name := range names {
+		name = strings.Trim(name, " \n\"'")
+		if strings.Contains(name, " ") {
+			name = "'" + name + "'"
+		}
+		names[i] = name
+	}
+	writeSVGAttribute(&writer.body, "font-family", htmlEscape(strings.Join(names, ",")))
+	writeSVGAttribute(&writer.body, "font-size", svgNumber(font.size))
+	if font.params.Italic {
+		writeSVGAttribute(&writer.body, "font-style", "italic")
+	}
+	if font.params.SmallCaps {
+		writeSVGAttribute(&writer.body, "font-variant", "small-caps")
+	}
+	if font.params.Weight > 0 && font.params.Weight <= 9 && font.params.Weight != 4 {
+		writeSVGAttribute(&writer.body, "font-weight", strconv.Itoa(font.params.Weight*100))
+	}
+}
+
+func (writer *canvasSVGWriter) writeText(record *canvasRecord) {
+	state := &record.state
+	writer.body.WriteString("<text")
+	writeSVGAttribute(&writer.body, "x", svgNumber(record.point.x))
+	writeSVGAttribute(&writer.body, "y", svgNumber(record.point.y))
+	writeSVGTransform(&writer.body, state.matrix)
+	writer.writeFontAttributes(state.font)
+
+	switch state.align {
+	case CenterAlign:
+		writeSVGAttribute(&writer.body, "text-anchor", "middle")
+
+	case RightAlign, EndAlign:
+		writeSVGAttribute(&writer.body, "text-anchor", "end")
+	}
+
+	switch state.baseline {
+	case TopBaseline:
+		writeSVGAttribute(&writer.body, "dominant-baseline", "text-before-edge")
+
+	case MiddleBaseline:
+		writeSVGAttribute(&writer.body, "dominant-baseline", "middle")
+
+	case BottomBaseline:
+		writeSVGAttribute(&writer.body, "dominant-baseline", "text-after-edge")
+
+	case HangingBaseline:
+		writeSVGAttribute(&writer.body, "dominant-baseline", "hanging")
+
+	case IdeographicBaseline:
+		writeSVGAttribute(&writer.body, "dominant-baseline", "ideographic")
+	}
+
+	if record.kind == canvasStrokeTextRecord {
+		writer.writeStrokeAttributes(state)
+	} else {
+		writer.writePaint("fill", state.fill)
+	}
+	writer.body.WriteString(` xml:space="preserve">`)
+	writer.body.WriteString(htmlEscape(record.text))
+	writer.body.WriteString("</text>\n")
+}
+
+func (writer *canvasSVGWriter) writeImage(record *canvasRecord) {
+	source := writer.images.source(record.image)
+	width, height := source.size(record.image)
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	href := htmlEscape(source.href(record.image))
+	dst := record.dst
+	if dst[2] < 0 {
+		dst[2], dst[3] = width, height
+	}
+
+	if record.src[2] < 0 {
+		writer.body.WriteString("<image")
+		writeSVGAttribute(&writer.body, "x", svgNumber(dst[0]))
+		writeSVGAttribute(&writer.body, "y", svgNumber(dst[1]))
+		writeSVGAttribute(&writer.body, "width", svgNumber(dst[2]))
+		writeSVGAttribute(&writer.body, "height", svgNumber(dst[3]))
+		writeSVGAttribute(&writer.body, "preserveAspectRatio", "none")
+		writeSVGTransform(&writer.body, record.state.matrix)
+		writeSVGAttribute(&writer.body, "xlink:href", href)
+		writer.body.WriteString("/>\n")
+		return
+	}
+
+	writer.body.WriteString("<g")
+	writeSVGTransform(&writer.body, record.state.matrix)
+	writer.body.WriteString("><svg")
+	writeSVGAttribute(&writer.body, "x", svgNumber(dst[0]))
+	writeSVGAttribute(&writer.body, "y", svgNumber(dst[1]))
+	writeSVGAttribute(&writer.body, "width", svgNumber(dst[2]))
+	writeSVGAttribute(&writer.body, "height", svgNumber(dst[3]))
+	writeSVGAttribute(&writer.body, "viewBox", svgNumber(record.src[0])+" "+svgNumber(record.src[1])+" "+
+		svgNumber(record.src[2])+" "+svgNumber(record.src[3]))
+	writeSVGAttribute(&writer.body, "preserveAspectRatio", "none")
+	writer.body.WriteString("><image")
+	writeSVGAttribute(&writer.body, "width", svgNumber(width))
+	writeSVGAttribute(&writer.body, "height", svgNumber(height))
+	writeSVGAttribute(&writer.body, "xlink:href", href)
+	writer.body.WriteString("/></svg></g>\n")
+}
+
+// isFullClear returns true if the record clears the whole canvas
+func (writer *canvasSVGWriter) isFullClear(record *canvasRecord) bool {
+	if len(record.state.clip) > 0 {
+		return false
+	}
+
+	corners := []canvasPoint{{0, 0}, {writer.recorder.width, writer.recorder.height}}
+	if inverse, ok := record.state.matrix.invert(); ok && record.state.matrix[1] == 0 && record.state.matrix[2] == 0 {
+		minX, minY := math.Inf(1), math.Inf(1)
+		maxX, maxY := math.Inf(-1), math.Inf(-1)
+		for _, segment := range record.shape {
+			if segment.kind != canvasClosePath {
+				point := segment.points[0]
+				minX, minY = math.Min(minX, point.x), math.Min(minY, point.y)
+				maxX, maxY = math.Max(maxX, point.x), math.Max(maxY, point.y)
+			}
+		}
+		for _, corner := range corners {
+			point := inverse.apply(corner)
+			if point.x < minX || point.x > maxX || point.y < minY || point.y > maxY {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (writer *canvasSVGWriter) writeRecord(record *canvasRecord) {
+	if record.kind == canvasClearRecord {
+		if writer.isFullClear(record) {
+			writer.body.Reset()
+		}
+		return
+	}
+
+	clipID := ""
+	if len(record.state.clip) > 0 {
+		clipID = writer.clipID(record.state.clip)
+		writer.body.WriteString(`<g clip-path="url(#`)
+		writer.body.WriteString(clipID)
+		writer.body.WriteString(`)">`)
+	}
+
+	switch record.kind {
+	case canvasFillRecord:
+		writer.body.WriteString(`<path d="`)
+		writer.body.WriteString(svgPathData(record.shape))
+		writer.body.WriteRune('"')
+		writeSVGTransform(&writer.body, record.state.matrix)
+		writer.writePaint("fill", record.state.fill)
+		writer.body.WriteString("/>\n")
+
+	case canvasStrokeRecord:
+		writer.body.WriteString(`<path d="`)
+		writer.body.WriteString(svgPathData(record.shape))
+		writer.body.WriteRune('"')
+		writeSVGTransform(&writer.body, record.state.matrix)
+		writer.writeStrokeAttributes(&record.state)
+		writer.body.WriteString("/>\n")
+
+	case canvasFillTextRecord, canvasStrokeTextRecord:
+		writer.writeText(record)
+
+	case canvasImageRecord, canvasPutImageRecord:
+		writer.writeImage(record)
+	}
+
+	if clipID != "" {
+		writer.body.WriteString("</g>\n")
+	}
+}
+
+// href returns the URL of the image for the SVG. The resource images are embedded as "data:" URL
+func (source *canvasImageSource) href(image Image) string {
+	if source.data != nil && source.mime != "" && !strings.HasPrefix(image.URL(), "data:") {
+		return "data:" + source.mime + ";base64," + base64.StdEncoding.EncodeToString(source.data)
+	}
+	return image.URL()
+}
@@ -0,0 +1,937 @@
+package rui
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ShapeClickEvent is the constant for "shape-click-event" property tag.
+	// The "shape-click-event" event of CanvasView occurs when the user clicks on a shape of the CanvasScene.
+	// The main listener format:
+	//   func(CanvasView, SceneShapeEvent).
+	// The additional listener formats:
+	//   func(SceneShapeEvent), func(CanvasView), and func().
+	ShapeClickEvent = "shape-click-event"
+
+	// ShapeEnterEvent is the constant for "shape-enter-event" property tag.
+	// The "shape-enter-event" event of CanvasView occurs when the mouse pointer is moved onto a shape of the CanvasScene.
+	// The main listener format:
+	//   func(CanvasView, SceneShapeEvent).
+	// The additional listener formats:
+	//   func(SceneShapeEvent), func(CanvasView), and func().
+	ShapeEnterEvent = "shape-enter-event"
+
+	// ShapeLeaveEvent is the constant for "shape-leave-event" property tag.
+	// The "shape-leave-event" event of CanvasView occurs when the mouse pointer is moved off a shape of the CanvasScene.
+	// The main listener format:
+	//   func(CanvasView, SceneShapeEvent).
+	// The additional listener formats:
+	//   func(SceneShapeEvent), func(CanvasView), and func().
+	ShapeLeaveEvent = "shape-leave-event"
+
+	// ShapeDragStartEvent is the constant for "shape-drag-start-event" property tag.
+	// The "shape-drag-start-event" event of CanvasView occurs when the user presses the primary mouse button
+	// on a draggable shape of the CanvasScene.
+	// The main listener format:
+	//   func(CanvasView, SceneShapeEvent).
+	// The additional listener formats:
+	//   func(SceneShapeEvent), func(CanvasView), and func().
+	ShapeDragStartEvent = "shape-drag-start-event"
+
+	// ShapeDragEvent is the constant for "shape-drag-event" property tag.
+	// The "shape-drag-event" event of CanvasView occurs when the draggable shape is moved by the mouse.
+	// The shape is already moved when the listeners are called.
+	// The main listener format:
+	//   func(CanvasView, SceneShapeEvent).
+	// The additional listener formats:
+	//   func(SceneShapeEvent), func(CanvasView), and func().
+	ShapeDragEvent = "shape-drag-event"
+
+	// ShapeDragEndEvent is the constant for "shape-drag-end-event" property tag.
+	// The "shape-drag-end-event" event of CanvasView occurs when the user releases the mouse button
+	// or the mouse pointer leaves CanvasView while dragging a shape.
+	// The main listener format:
+	//   func(CanvasView, SceneShapeEvent).
+	// The additional listener formats:
+	//   func(SceneShapeEvent), func(CanvasView), and func().
+	ShapeDragEndEvent = "shape-drag-end-event"
+
+	// sceneUpdateCommand is the command posted to the session event queue to send the scene changes
+	sceneUpdateCommand = "scene-update"
+
+	// sceneHitLineWidth is the minimal width of the line used for the hit testing of the stroked shapes
+	sceneHitLineWidth = 4
+)
+
+// SceneStyle describes the drawing style of a SceneShape
+type SceneStyle struct {
+	// FillColor is the color inside the shape (and the text color). The transparent color (0) means no filling
+	FillColor Color
+	// StrokeColor is the color of the shape outline. The transparent color (0) means no outline
+	StrokeColor Color
+	// LineWidth is the width of the outline in pixels. Values <= 0 are treated as 1
+	LineWidth float64
+	// LineJoin is the shape used to join line segments: MiterJoin (0), RoundJoin (1), BevelJoin (2)
+	LineJoin int
+	// LineCap is the shape used to draw the end points of lines: ButtCap (0), RoundCap (1), SquareCap (2)
+	LineCap int
+	// LineDash is the line dash pattern. nil means the solid line
+	LineDash []float64
+	// FontName is the font of the text shape. "" means "sans-serif"
+	FontName string
+	// FontSize is the font size of the text shape. Auto means 1rem
+	FontSize SizeUnit
+	// FontParams are the optional font properties of the text shape
+	FontParams FontParams
+	// TextAlign is the text alignment: LeftAlign (0), RightAlign (1), CenterAlign (2), StartAlign (3), and EndAlign(4)
+	TextAlign int
+	// TextBaseline is the text baseline: AlphabeticBaseline (0), TopBaseline (1), MiddleBaseline (2),
+	// BottomBaseline (3), HangingBaseline (4), and IdeographicBaseline (5)
+	TextBaseline int
+}
+
+// SceneShapeEvent describes the mouse event on a shape of the CanvasScene
+type SceneShapeEvent struct {
+	MouseEvent
+	// Shape is the shape under the mouse pointer (the dragged shape for drag events)
+	Shape SceneShape
+	// DeltaX and DeltaY are the shape shift since the previous drag event. They are used only by ShapeDragEvent
+	DeltaX, DeltaY float64
+}
+
+// SceneShape is a retained shape of the CanvasScene. All changes of the shape are redrawn automatically
+type SceneShape interface {
+	// ID returns the id of the shape
+	ID() string
+	// Style returns the drawing style of the shape
+	Style() SceneStyle
+	// SetStyle sets the drawing style of the shape
+	SetStyle(style SceneStyle)
+	// Offset returns the shift of the shape relative to its coordinates
+	Offset() (float64, float64)
+	// SetOffset moves the shape by setting its shift relative to its coordinates
+	SetOffset(dx, dy float64)
+	// Visible returns true if the shape is drawn
+	Visible() bool
+	// SetVisible shows or hides the shape. Hidden shapes are not hit tested
+	SetVisible(visible bool)
+	// Draggable returns true if the shape can be moved by the mouse
+	Draggable() bool
+	// SetDraggable allows or forbids the moving of the shape by the mouse
+	SetDraggable(draggable bool)
+	// SetPath replaces the geometry of the rectangle, ellipse or path shape
+	SetPath(path Path)
+	// Text returns the text of the text shape
+	Text() string
+	// SetText replaces the text of the text shape
+	SetText(text string)
+	// Bounds returns the approximate bounding rectangle of the shape including the offset
+	Bounds() Frame
+	// Contains returns true if the point (x, y) hits the shape. The filled shapes (and shapes without
+	// the fill and stroke colors) are tested by the nonzero winding rule, the stroked shapes by the
+	// distance to the outline. Text and image shapes are tested by their rectangle
+	Contains(x, y float64) bool
+}
+
+// CanvasScene is the retained drawing layer of CanvasView. Shapes are kept on the server and drawn over
+// the result of the "draw-function". A change of a shape redraws only the changed area of the canvas.
+// Changes are sent to the client after the current event handler is finished (or by the Update function).
+// CanvasScene resolves the mouse events to shapes and fires "shape-click-event", "shape-enter-event",
+// "shape-leave-event", "shape-drag-start-event", "shape-drag-event", and "shape-drag-end-event" events of CanvasView
+type CanvasScene interface {
+	// AddRect adds (or replaces the shape with the same id) the rectangle shape
+	AddRect(id string, x, y, width, height float64, style SceneStyle) SceneShape
+	// AddEllipse adds (or replaces the shape with the same id) the ellipse shape
+	AddEllipse(id string, x, y, radiusX, radiusY float64, style SceneStyle) SceneShape
+	// AddPath adds (or replaces the shape with the same id) the path shape
+	AddPath(id string, path Path, style SceneStyle) SceneShape
+	// AddText adds (or replaces the shape with the same id) the text shape
+	AddText(id string, x, y float64, text string, style SceneStyle) SceneShape
+	// AddImage adds (or replaces the shape with the same id) the image shape
+	AddImage(id string, x, y, width, height float64, image Image) SceneShape
+	// Shape returns the shape with the id or nil if it is not found
+	Shape(id string) SceneShape
+	// Shapes returns all shapes from back to front
+	Shapes() []SceneShape
+	// RemoveShape removes the shape with the id
+	RemoveShape(id string)
+	// Clear removes all shapes
+	Clear()
+	// BringToFront moves the shape with the id above all other shapes
+	BringToFront(id string)
+	// SendToBack moves the shape with the id below all other shapes
+	SendToBack(id string)
+	// ShapeAt returns the topmost visible shape containing the point (x, y) or nil
+	ShapeAt(x, y float64) SceneShape
+	// Update immediately sends the pending changes to the client
+	Update()
+}
+
+const (
+	scenePathShape = iota
+	sceneTextShape
+	sceneImageShape
+)
+
+type sceneShapeData struct {
+	scene     *canvasSceneData
+	id        string
+	kind      int
+	style     SceneStyle
+	path      Path
+	segments  []canvasSegment
+	text      string
+	point     canvasPoint
+	size      canvasPoint
+	image     Image
+	dx, dy    float64
+	visible   bool
+	draggable bool
+}
+
+type sceneDrag struct {
+	shape *sceneShapeData
+	x, y  float64
+	moved bool
+}
+
+type canvasSceneData struct {
+	view    *canvasViewData
+	shapes  []*sceneShapeData
+	dirty   Frame
+	pending bool
+	hover   *sceneShapeData
+	drag    *sceneDrag
+	dragged bool
+	counter int
+}
+
+var sceneShapeEvents = []string{
+	ShapeClickEvent,
+	ShapeEnterEvent,
+	ShapeLeaveEvent,
+	ShapeDragStartEvent,
+	ShapeDragEvent,
+	ShapeDragEndEvent,
+}
+
+func newCanvasScene(view *canvasViewData) *canvasSceneData {
+	return &canvasSceneData{view: view}
+}
+
+func (scene *canvasSceneData) index(id string) int {
+	for i, shape := range scene.shapes {
+		if shape.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (scene *canvasSceneData) add(shape *sceneShapeData) SceneShape {
+	if shape.id == "" {
+		scene.counter++
+		shape.id = "shape" + strconv.Itoa(scene.counter)
+	}
+	shape.scene = scene
+	shape.visible = true
+
+	if index := scene.index(shape.id); index >= 0 {
+		old := scene.shapes[index]
+		scene.invalidate(old)
+		old.scene = nil
+		scene.shapes[index] = shape
+	} else {
+		scene.shapes = append(scene.shapes, shape)
+	}
+	scene.invalidate(shape)
+	return shape
+}
+
+func (scene *canvasSceneData) AddRect(id string, x, y, width, height float64, style SceneStyle) SceneShape {
+	path := NewPath()
+	path.MoveTo(x, y)
+	path.LineTo(x+width, y)
+	path.LineTo(x+width, y+height)
+	path.LineTo(x, y+height)
+	path.Close()
+	return scene.AddPath(id, path, style)
+}
+
+func (scene *canvasSceneData) AddEllipse(id string, x, y, radiusX, radiusY float64, style SceneStyle) SceneShape {
+	path := NewPath()
+	path.Ellipse(x, y, radiusX, radiusY, 0, 0, 2*math.Pi, true)
+	path.Close()
+	return scene.AddPath(id, path, style)
+}
+
+func (scene *canvasSceneData) AddPath(id string, path Path, style SceneStyle) SceneShape {
+	if path == nil {
+		path = NewPath()
+	}
+	return scene.add(&sceneShapeData{
+		id:       id,
+		kind:     scenePathShape,
+		style:    style,
+		path:     path,
+		segments: canvasShapeFromPath(path).segments,
+	})
+}
+
+func (scene *canvasSceneData) AddText(id string, x, y float64, text string, style SceneStyle) SceneShape {
+	return scene.add(&sceneShapeData{
+		id:    id,
+		kind:  sceneTextShape,
+		style: style,
+		text:  text,
+		point: canvasPoint{x, y},
+	})
+}
+
+func (scene *canvasSceneData) AddImage(id string, x, y, width, height float64, image Image) SceneShape {
+	return scene.add(&sceneShapeData{
+		id:    id,
+		kind:  sceneImageShape,
+		point: canvasPoint{x, y},
+		size:  canvasPoint{width, height},
+		image: image,
+	})
+}
+
+func (scene *canvasSceneData) Shape(id string) SceneShape {
+	if index := scene.index(id); index >= 0 {
+		return scene.shapes[index]
+	}
+	return nil
+}
+
+func (scene *canvasSceneData) Shapes() []SceneShape {
+	result := make([]SceneShape, len(scene.shapes))
+	for i, shape := range scene.shapes {
+		result[i] = shape
+	}
+	return result
+}
+
+func (scene *canvasSceneData) forget(shape *sceneShapeData) {
+	if scene.hover == shape {
+		scene.hover = nil
+	}
+	if scene.drag != nil && scene.drag.shape == shape {
+		scene.drag = nil
+	}
+	shape.scene = nil
+}
+
+func (scene *canvasSceneData) RemoveShape(id string) {
+	if index := scene.index(id); index >= 0 {
+		shape := scene.shapes[index]
+		scene.invalidate(shape)
+		scene.forget(shape)
+		scene.shapes = append(scene.shapes[:index], scene.shapes[index+1:]...)
+	}
+}
+
+func (scene *canvasSceneData) Clear() {
+	for _, shape := range scene.shapes {
+		scene.invalidate(shape)
+		scene.forget(shape)
+	}
+	scene.shapes = []*sceneShapeData{}
+}
+
+func (scene *canvasSceneData) BringToFront(id string) {
+	if index := scene.index(id); index >= 0 && index < len(scene.shapes)-1 {
+		shape := scene.shapes[index]
+		scene.shapes = append(append(scene.shapes[:index], scene.shapes[index+1:]...), shape)
+		scene.invalidate(shape)
+	}
+}
+
+func (scene *canvasSceneData) SendToBack(id string) {
+	if index := scene.index(id); index > 0 {
+		shape := scene.shapes[index]
+		copy(scene.shapes[1:index+1], scene.shapes[:index])
+		scene.shapes[0] = shape
+		scene.invalidate(shape)
+	}
+}
+
+func (scene *canvasSceneData) ShapeAt(x, y float64) SceneShape {
+	if shape := scene.shapeAt(x, y); shape != nil {
+		return shape
+	}
+	return nil
+}
+
+func (scene *canvasSceneData) shapeAt(x, y float64) *sceneShapeData {
+	for i := len(scene.shapes) - 1; i >= 0; i-- {
+		if shape := scene.shapes[i]; shape.visible && shape.Contains(x, y) {
+			return shape
+		}
+	}
+	return nil
+}
+
+// invalidate adds the bounds of the shape to the area which will be redrawn
+func (scene *canvasSceneData) invalidate(shape *sceneShapeData) {
+	if !shape.visible {
+		return
+	}
+
+	bounds := shape.drawBounds()
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		return
+	}
+
+	if scene.dirty.Width <= 0 || scene.dirty.Height <= 0 {
+		scene.dirty = bounds
+	} else {
+		left := math.Min(scene.dirty.Left, bounds.Left)
+		top := math.Min(scene.dirty.Top, bounds.Top)
+		scene.dirty = Frame{
+			Left:   left,
+			Top:    top,
+			Width:  math.Max(scene.dirty.Right(), bounds.Right()) - left,
+			Height: math.Max(scene.dirty.Bottom(), bounds.Bottom()) - top,
+		}
+	}
+
+	if !scene.pending {
+		scene.pending = true
+		view := scene.view
+		if !view.created || !view.session.postViewCommand(view.htmlID(), sceneUpdateCommand) {
+			scene.Update()
+		}
+	}
+}
+
+func (scene *canvasSceneData) Update() {
+	scene.pending = false
+	dirty := scene.dirty
+	scene.dirty = Frame{}
+
+	view := scene.view
+	if !view.created {
+		return
+	}
+
+	left := math.Max(0, math.Floor(dirty.Left))
+	top := math.Max(0, math.Floor(dirty.Top))
+	right := math.Min(view.frame.Width, math.Ceil(dirty.Right()))
+	bottom := math.Min(view.frame.Height, math.Ceil(dirty.Bottom()))
+	if right <= left || bottom <= top {
+		return
+	}
+
+	area := Frame{Left: left, Top: top, Width: right - left, Height: bottom - top}
+	canvas := newCanvas(view)
+	canvas.Save()
+	canvas.ClipRect(area.Left, area.Top, area.Width, area.Height)
+	canvas.ClearRect(area.Left, area.Top, area.Width, area.Height)
+	if view.drawer != nil {
+		view.drawer(canvas)
+	}
+	scene.draw(canvas, &area)
+	canvas.Restore()
+	canvas.finishDraw()
+}
+
+// draw draws the visible shapes intersecting the area (all visible shapes if the area is nil)
+func (scene *canvasSceneData) draw(canvas Canvas, area *Frame) {
+	for _, shape := range scene.shapes {
+		if !shape.visible {
+			continue
+		}
+		if area != nil {
+			bounds := shape.drawBounds()
+			if bounds.Left >= area.Right() || bounds.Right() <= area.Left ||
+				bounds.Top >= area.Bottom() || bounds.Bottom() <= area.Top {
+				continue
+			}
+		}
+		shape.draw(canvas)
+	}
+}
+
+func (scene *canvasSceneData) hasListeners() bool {
+	for _, tag := range sceneShapeEvents {
+		if scene.view.getRaw(tag) != nil {
+			return true
+		}
+	}
+	for _, shape := range scene.shapes {
+		if shape.draggable {
+			return true
+		}
+	}
+	return false
+}
+
+func (scene *canvasSceneData) fireEvent(tag string, shape *sceneShapeData, event MouseEvent, dx, dy float64) {
+	shapeEvent := SceneShapeEvent{MouseEvent: event, Shape: shape, DeltaX: dx, DeltaY: dy}
+	for _, listener := range getEventListeners[CanvasView, SceneShapeEvent](scene.view, nil, tag) {
+		listener(scene.view, shapeEvent)
+	}
+}
+
+func (scene *canvasSceneData) setHover(shape *sceneShapeData, event MouseEvent) {
+	if scene.hover != shape {
+		if scene.hover != nil {
+			scene.fireEvent(ShapeLeaveEvent, scene.hover, event, 0, 0)
+		}
+		scene.hover = shape
+		if shape != nil {
+			scene.fireEvent(ShapeEnterEvent, shape, event, 0, 0)
+		}
+	}
+}
+
+func (scene *canvasSceneData) endDrag(event MouseEvent) {
+	if drag := scene.drag; drag != nil {
+		scene.drag = nil
+		scene.dragged = drag.moved
+		scene.fireEvent(ShapeDragEndEvent, drag.shape, event, 0, 0)
+	}
+}
+
+// handleMouseEvent resolves the mouse event to the scene shapes and sends the changes made by the listeners
+func (scene *canvasSceneData) handleMouseEvent(command string, data DataObject) {
+	var event MouseEvent
+	event.init(data)
+
+	switch command {
+	case MouseDown:
+		scene.dragged = false
+		if event.Button == PrimaryMouseButton {
+			if shape := scene.shapeAt(event.X, event.Y); shape != nil && shape.draggable {
+				scene.drag = &sceneDrag{shape: shape, x: event.X, y: event.Y}
+				scene.fireEvent(ShapeDragStartEvent, shape, event, 0, 0)
+			}
+		}
+
+	case MouseMove:
+		if drag := scene.drag; drag != nil {
+			if event.Buttons&PrimaryMouseMask == 0 {
+				scene.endDrag(event)
+				break
+			}
+			dx, dy := event.X-drag.x, event.Y-drag.y
+			if dx != 0 || dy != 0 {
+				drag.x, drag.y = event.X, event.Y
+				drag.moved = true
+				drag.shape.SetOffset(drag.shape.dx+dx, drag.shape.dy+dy)
+				scene.fireEvent(ShapeDragEvent, drag.shape, event, dx, dy)
+			}
+		} else {
+			scene.setHover(scene.shapeAt(event.X, event.Y), event)
+		}
+
+	case MouseUp:
+		scene.endDrag(event)
+		scene.setHover(scene.shapeAt(event.X, event.Y), event)
+
+	case MouseOut:
+		scene.endDrag(event)
+		scene.setHover(nil, event)
+
+	case ClickEvent:
+		// the click after the dragging is not passed to the shapes
+		if scene.dragged {
+			scene.dragged = false
+			break
+		}
+		if shape := scene.shapeAt(event.X, event.Y); shape != nil {
+			scene.fireEvent(ShapeClickEvent, shape, event, 0, 0)
+		}
+	}
+
+	if scene.pending {
+		scene.Update()
+	}
+}
+
+// sceneMouseEvents are the mouse events which are required by the scene
+var sceneMouseEvents = []string{MouseDown, MouseMove, MouseUp, MouseOut, ClickEvent}
+
+// writeMouseEvents writes the mouse event handlers required by the scene and not set by the view listeners
+func (scene *canvasSceneData) writeMouseEvents(buffer *strings.Builder) {
+	if scene.hasListeners() {
+		for _, tag := range sceneMouseEvents {
+			if scene.view.getRaw(tag) == nil {
+				writeCanvasMouseEvent(tag, buffer)
+			}
+		}
+	}
+}
+
+// handlesMouseEvent returns true if the mouse event handler is written by the scene
+func (scene *canvasSceneData) handlesMouseEvent(tag string) bool {
+	if scene.hasListeners() {
+		for _, sceneTag := range sceneMouseEvents {
+			if sceneTag == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// updateMouseEvents sets the mouse event handlers required by the scene on the client
+func (scene *canvasSceneData) updateMouseEvents() {
+	view := scene.view
+	if view.created && scene.hasListeners() {
+		for _, tag := range sceneMouseEvents {
+			if view.getRaw(tag) == nil {
+				js := mouseEvents[tag]
+				view.session.updateProperty(view.htmlID(), js.jsEvent, js.jsFunc+"(this, event)")
+			}
+		}
+	}
+}
+
+func writeCanvasMouseEvent(tag string, buffer *strings.Builder) {
+	js := mouseEvents[tag]
+	buffer.WriteString(` `)
+	buffer.WriteString(js.jsEvent)
+	buffer.WriteString(`="`)
+	buffer.WriteString(js.jsFunc)
+	buffer.WriteString(`(this, event)"`)
+}
+
+func (shape *sceneShapeData) ID() string {
+	return shape.id
+}
+
+func (shape *sceneShapeData) Style() SceneStyle {
+	return shape.style
+}
+
+// change invalidates the shape area before and after the change
+func (shape *sceneShapeData) change(apply func()) {
+	if shape.scene != nil {
+		shape.scene.invalidate(shape)
+	}
+	apply()
+	if shape.scene != nil {
+		shape.scene.invalidate(shape)
+	}
+}
+
+func (shape *sceneShapeData) SetStyle(style SceneStyle) {
+	shape.change(func() {
+		shape.style = style
+	})
+}
+
+func (shape *sceneShapeData) Offset() (float64, float64) {
+	return shape.dx, shape.dy
+}
+
+func (shape *sceneShapeData) SetOffset(dx, dy float64) {
+	if dx != shape.dx || dy != shape.dy {
+		shape.change(func() {
+			shape.dx, shape.dy = dx, dy
+		})
+	}
+}
+
+func (shape *sceneShapeData) Visible() bool {
+	return shape.visible
+}
+
+func (shape *sceneShapeData) SetVisible(visible bool) {
+	if shape.visible != visible {
+		if shape.scene != nil {
+			if visible {
+				shape.visible = true
+				shape.scene.invalidate(shape)
+			} else {
+				shape.scene.invalidate(shape)
+				shape.visible = false
+			}
+		} else {
+			shape.visible = visible
+		}
+	}
+}
+
+func (shape *sceneShapeData) Draggable() bool {
+	return shape.draggable
+}
+
+func (shape *sceneShapeData) SetDraggable(draggable bool) {
+	shape.draggable = draggable
+	if draggable && shape.scene != nil {
+		shape.scene.updateMouseEvents()
+	}
+}
+
+func (shape *sceneShapeData) SetPath(path Path) {
+	if shape.kind == scenePathShape && path != nil {
+		shape.change(func() {
+			shape.path = path
+			shape.segments = canvasShapeFromPath(path).segments
+		})
+	}
+}
+
+func (shape *sceneShapeData) Text() string {
+	return shape.text
+}
+
+func (shape *sceneShapeData) SetText(text string) {
+	if shape.kind == sceneTextShape && shape.text != text {
+		shape.change(func() {
+			shape.text = text
+		})
+	}
+}
+
+func (shape *sceneShapeData) lineWidth() float64 {
+	if shape.style.LineWidth > 0 {
+		return shape.style.LineWidth
+	}
+	return 1
+}
+
+// textFrame returns the rectangle of the text. The text width is calculated by the width of the character:
+// the average width is used for the hit testing, the maximal width for the redrawing
+func (shape *sceneShapeData) textFrame(charWidth float64) Frame {
+	size := canvasFontSize(shape.style.FontSize)
+	width := float64(len([]rune(shape.text))) * size * charWidth
+	ascent, descent := size, size*0.3
+
+	left := shape.point.x
+	switch shape.style.TextAlign {
+	case CenterAlign:
+		left -= width / 2
+
+	case RightAlign, EndAlign:
+		left -= width
+	}
+
+	baseline := shape.point.y
+	switch shape.style.TextBaseline {
+	case TopBaseline, HangingBaseline:
+		baseline += ascent
+
+	case MiddleBaseline:
+		baseline += (ascent - descent) / 2
+
+	case BottomBaseline, IdeographicBaseline:
+		baseline -= descent
+	}
+
+	return Frame{Left: left + shape.dx, Top: baseline - ascent + shape.dy, Width: width, Height: ascent + descent}
+}
+
+// geometryBounds returns the bounds of the shape without the outline width
+func (shape *sceneShapeData) geometryBounds() Frame {
+	switch shape.kind {
+	case sceneTextShape:
+		return shape.textFrame(canvasFontAdvance)
+
+	case sceneImageShape:
+		return Frame{Left: shape.point.x + shape.dx, Top: shape.point.y + shape.dy, Width: shape.size.x, Height: shape.size.y}
+	}
+
+	if len(shape.segments) == 0 {
+		return Frame{}
+	}
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, segment := range shape.segments {
+		count := 1
+		switch segment.kind {
+		case canvasClosePath:
+			count = 0
+
+		case canvasCurveTo:
+			count = 3
+		}
+		for _, point := range segment.points[:count] {
+			minX, minY = math.Min(minX, point.x), math.Min(minY, point.y)
+			maxX, maxY = math.Max(maxX, point.x), math.Max(maxY, point.y)
+		}
+	}
+	return Frame{Left: minX + shape.dx, Top: minY + shape.dy, Width: maxX - minX, Height: maxY - minY}
+}
+
+func (shape *sceneShapeData) Bounds() Frame {
+	bounds := shape.geometryBounds()
+	if shape.kind == scenePathShape && shape.style.StrokeColor.Alpha() > 0 {
+		half := shape.lineWidth() / 2
+		bounds = Frame{Left: bounds.Left - half, Top: bounds.Top - half, Width: bounds.Width + 2*half, Height: bounds.Height + 2*half}
+	}
+	return bounds
+}
+
+// drawBounds returns the area which must be redrawn when the shape is changed
+func (shape *sceneShapeData) drawBounds() Frame {
+	var bounds Frame
+	margin := 1.0
+	switch shape.kind {
+	case sceneTextShape:
+		bounds = shape.textFrame(1)
+		if shape.style.StrokeColor.Alpha() > 0 {
+			margin += shape.lineWidth()
+		}
+
+	case sceneImageShape:
+		bounds = shape.geometryBounds()
+
+	default:
+		bounds = shape.geometryBounds()
+		if shape.style.StrokeColor.Alpha() > 0 {
+			half := shape.lineWidth() / 2
+			switch {
+			case shape.style.LineJoin == MiterJoin:
+				// the default canvas miter limit is 10
+				margin += half * 10
+
+			case shape.style.LineCap == SquareCap:
+				margin += half * math.Sqrt2
+
+			default:
+				margin += half
+			}
+		}
+	}
+
+	return Frame{
+		Left:   bounds.Left - margin,
+		Top:    bounds.Top - margin,
+		Width:  bounds.Width + 2*margin,
+		Height: bounds.Height + 2*margin,
+	}
+}
+
+func (shape *sceneShapeData) Contains(x, y float64) bool {
+	switch shape.kind {
+	case sceneTextShape, sceneImageShape:
+		bounds := shape.geometryBounds()
+		return x >= bounds.Left && x < bounds.Right() && y >= bounds.Top && y < bounds.Bottom()
+	}
+
+	point := canvasPoint{x - shape.dx, y - shape.dy}
+	lines := flattenCanvasShape(shape.segments, canvasIdentity, 1)
+	filled := shape.style.FillColor.Alpha() > 0
+	stroked := shape.style.StrokeColor.Alpha() > 0
+
+	if (filled || !stroked) && canvasWinding(lines, point) != 0 {
+		return true
+	}
+
+	if stroked {
+		half := math.Max(shape.lineWidth(), sceneHitLineWidth) / 2
+		for _, line := range lines {
+			count := len(line.points)
+			last := count - 1
+			if line.closed {
+				last = count
+			}
+			for i := 0; i < last; i++ {
+				if canvasSegmentDistance(point, line.points[i], line.points[(i+1)%count]) <= half {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (shape *sceneShapeData) draw(canvas Canvas) {
+	style := &shape.style
+	canvas.Save()
+	if shape.dx != 0 || shape.dy != 0 {
+		canvas.SetTranslation(shape.dx, shape.dy)
+	}
+
+	setStroke := func() {
+		canvas.SetSolidColorStrokeStyle(style.StrokeColor)
+		canvas.SetLineWidth(shape.lineWidth())
+		canvas.SetLineJoin(style.LineJoin)
+		canvas.SetLineCap(style.LineCap)
+		if len(style.LineDash) > 0 {
+			canvas.SetLineDash(style.LineDash, 0)
+		}
+	}
+
+	filled := style.FillColor.Alpha() > 0
+	stroked := style.StrokeColor.Alpha() > 0
+
+	switch shape.kind {
+	case scenePathShape:
+		if filled {
+			canvas.SetSolidColorFillStyle(style.FillColor)
+		}
+		if stroked {
+			setStroke()
+		}
+		switch {
+		case filled && stroked:
+			canvas.FillAndStrokePath(shape.path)
+
+		case filled:
+			canvas.FillPath(shape.path)
+
+		case stroked:
+			canvas.StrokePath(shape.path)
+		}
+
+	case sceneTextShape:
+		fontName := style.FontName
+		if fontName == "" {
+			fontName = "sans-serif"
+		}
+		canvas.SetFontWithParams(fontName, style.FontSize, style.FontParams)
+		canvas.SetTextAlign(style.TextAlign)
+		canvas.SetTextBaseline(style.TextBaseline)
+		if filled {
+			canvas.SetSolidColorFillStyle(style.FillColor)
+			canvas.FillText(shape.point.x, shape.point.y, shape.text)
+		}
+		if stroked {
+			setStroke()
+			canvas.StrokeText(shape.point.x, shape.point.y, shape.text)
+		}
+
+	case sceneImageShape:
+		canvas.DrawImageInRect(shape.point.x, shape.point.y, shape.size.x, shape.size.y, shape.image)
+	}
+	canvas.Restore()
+}
+
+// canvasWinding returns the winding number of the closed polylines around the point
+func canvasWinding(lines []canvasPolyline, point canvasPoint) int {
+	winding := 0
+	for _, line := range lines {
+		count := len(line.points)
+		for i, p0 := range line.points {
+			p1 := line.points[(i+1)%count]
+			if p0.y <= point.y {
+				if p1.y > point.y && (p1.x-p0.x)*(point.y-p0.y)-(point.x-p0.x)*(p1.y-p0.y) > 0 {
+					winding++
+				}
+			} else if p1.y <= point.y && (p1.x-p0.x)*(point.y-p0.y)-(point.x-p0.x)*(p1.y-p0.y) < 0 {
+				winding--
+			}
+		}
+	}
+	return winding
+}
+
+// canvasSegmentDistance returns the distance from the point to the segment (p0, p1)
+func canvasSegmentDistance(point, p0, p1 canvasPoint) float64 {
+	dx, dy := p1.x-p0.x, p1.y-p0.y
+	length := dx*dx + dy*dy
+	t := 0.0
+	if length > 0 {
+		t = math.Max(0, math.Min(1, ((point.x-p0.x)*dx+(point.y-p0.y)*dy)/length))
+	}
+	return math.Hypot(point.x-p0.x-t*dx, point.y-p0.y-t*dy)
+}
@@ -0,0 +1,186 @@
+package rui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type canvasTestBridge struct {
+	themeTestBridge
+	calls   []string
+	funcs   []string
+	dataURL string
+}
+
+func (bridge *canvasTestBridge) callFunc(funcName string, args ...any) bool {
+	bridge.funcs = append(bridge.funcs, fmt.Sprint(funcName, args))
+	return true
+}
+
+func (bridge *canvasTestBridge) canvasStart(htmlID string) {
+	bridge.calls = nil
+}
+
+func (bridge *canvasTestBridge) callCanvasFunc(funcName string, args ...any) {
+	bridge.calls = append(bridge.calls, fmt.Sprint(funcName, args))
+}
+
+func (bridge *canvasTestBridge) updateCanvasProperty(property string, value any) {
+	bridge.calls = append(bridge.calls, fmt.Sprint(property, "=", value))
+}
+
+func (bridge *canvasTestBridge) createCanvasVar(funcName string, args ...any) any {
+	bridge.calls = append(bridge.calls, fmt.Sprint(funcName, args))
+	return nil
+}
+
+func (bridge *canvasTestBridge) callCanvasVarFunc(v any, funcName string, args ...any) {
+	bridge.calls = append(bridge.calls, fmt.Sprint(funcName, args))
+}
+
+func (bridge *canvasTestBridge) canvasFinish() {
+}
+
+func (bridge *canvasTestBridge) canvasDataURL(htmlID, mimeType string, quality float64) []byte {
+	bridge.funcs = append(bridge.funcs, fmt.Sprint("getCanvasDataURL", []any{htmlID, mimeType, quality}))
+	return canvasDataURLBytes(bridge.dataURL)
+}
+
+func (bridge *canvasTestBridge) updateProperty(htmlID, property string, value any) {
+}
+
+func (bridge *canvasTestBridge) drawing() string {
+	return strings.Join(bridge.calls, "\n")
+}
+
+func TestCanvasScene(t *testing.T) {
+	createTestLog(t, false)
+
+	session := newSession(nil, 0, "", nil)
+	events := make(chan DataObject, 8)
+	bridge := new(canvasTestBridge)
+	session.setBridge(events, bridge)
+
+	clicked := ""
+	entered := []string{}
+	dragged := 0
+	view := NewCanvasView(session, Params{
+		ShapeClickEvent: func(event SceneShapeEvent) {
+			clicked = event.Shape.ID()
+		},
+		ShapeEnterEvent: func(event SceneShapeEvent) {
+			entered = append(entered, "+"+event.Shape.ID())
+		},
+		ShapeLeaveEvent: func(event SceneShapeEvent) {
+			entered = append(entered, "-"+event.Shape.ID())
+		},
+		ShapeDragEvent: func(event SceneShapeEvent) {
+			dragged++
+		},
+	})
+
+	session.(*sessionData).rootView = view
+
+	buffer := new(strings.Builder)
+	viewHTML(view, buffer)
+	if html := buffer.String(); !strings.Contains(html, `onmousemove="mouseMoveEvent(this, event)"`) ||
+		!strings.Contains(html, `onclick="clickEvent(this, event)"`) {
+		t.Errorf("the scene mouse handlers are not written: %s", html)
+	}
+	view.onResize(view, 0, 0, 200, 100)
+
+	scene := view.Scene()
+	scene.AddRect("box", 10, 10, 40, 30, SceneStyle{FillColor: 0xFFFF0000})
+	line := NewPath()
+	line.MoveTo(100, 10)
+	line.LineTo(100, 90)
+	scene.AddPath("wire", line, SceneStyle{StrokeColor: 0xFF000000, LineJoin: RoundJoin})
+	scene.AddText("label", 120, 50, "Hello", SceneStyle{FillColor: 0xFF000000, FontSize: Px(10)})
+
+	// the changes are posted to the session queue
+	if len(events) == 0 {
+		t.Fatal("the scene update is not posted")
+	}
+	data := <-events
+	if data.Tag() != sceneUpdateCommand {
+		t.Fatalf("unexpected event: %s", data.Tag())
+	}
+	session.handleEvent(data.Tag(), data)
+	drawing := bridge.drawing()
+	for _, text := range []string{"clearRect", "fillStyle=rgb(255,0,0)", "fillText"} {
+		if !strings.Contains(drawing, text) {
+			t.Errorf(`"%s" is not drawn:\n%s`, text, drawing)
+		}
+	}
+
+	tests := []struct {
+		x, y float64
+		id   string
+	}{
+		{20, 20, "box"},
+		{5, 20, ""},
+		{101, 50, "wire"},
+		{95, 50, ""},
+		{125, 47, "label"},
+	}
+	for _, test := range tests {
+		shape := scene.ShapeAt(test.x, test.y)
+		if (test.id == "" && shape != nil) || (test.id != "" && (shape == nil || shape.ID() != test.id)) {
+			t.Errorf("ShapeAt(%g, %g) = %v, expected %q", test.x, test.y, shape, test.id)
+		}
+	}
+
+	mouse := func(command string, x, y float64, buttons int) {
+		view.handleCommand(view, command, ParseDataText(fmt.Sprintf(`%s{x=%g, y=%g, buttons=%d}`, command, x, y, buttons)))
+	}
+
+	mouse(MouseMove, 20, 20, 0)
+	mouse(MouseMove, 101, 50, 0)
+	if got := strings.Join(entered, ","); got != "+box,-box,+wire" {
+		t.Errorf("hover events: %s", got)
+	}
+	mouse(ClickEvent, 20, 20, 0)
+	if clicked != "box" {
+		t.Errorf("the clicked shape: %q", clicked)
+	}
+
+	// incremental redraw of the moved shape
+	box := scene.Shape("box")
+	box.SetDraggable(true)
+	mouse(MouseDown, 20, 20, 1)
+	mouse(MouseMove, 30, 25, 1)
+	mouse(MouseUp, 30, 25, 0)
+	if dx, dy := box.Offset(); dx != 10 || dy != 5 || dragged != 1 {
+		t.Errorf("the dragged shape offset: (%g, %g), drag events: %d", dx, dy, dragged)
+	}
+	drawing = bridge.drawing()
+	if !strings.Contains(drawing, "clip") || !strings.Contains(drawing, "clearRect[9 9 52 37]") ||
+		strings.Contains(drawing, "fillText") {
+		t.Errorf("the changed area is not redrawn incrementally:\n%s", drawing)
+	}
+
+	clicked = ""
+	mouse(ClickEvent, 30, 25, 0)
+	if clicked != "" {
+		t.Errorf("the click after the dragging is passed to the shape %q", clicked)
+	}
+	if bounds := box.Bounds(); bounds != (Frame{Left: 20, Top: 15, Width: 40, Height: 30}) {
+		t.Errorf("the shape bounds: %v", bounds)
+	}
+
+	scene.BringToFront("box")
+	scene.SendToBack("label")
+	ids := []string{}
+	for _, shape := range scene.Shapes() {
+		ids = append(ids, shape.ID())
+	}
+	if got := strings.Join(ids, ","); got != "label,wire,box" {
+		t.Errorf("the shape order: %s", got)
+	}
+
+	scene.RemoveShape("wire")
+	if scene.Shape("wire") != nil || scene.ShapeAt(101, 50) != nil {
+		t.Error("the shape is not removed")
+	}
+}
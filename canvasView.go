@@ -1,21 +1,62 @@
 package rui
 
-import "strings"
+import (
+	"encoding/base64"
+	"path/filepath"
+	"strings"
+)
 
-// DrawFunction is the constant for the "draw-function" property tag.
-// The "draw-function" property sets the draw function of CanvasView.
-// The function should have the following format: func(Canvas)
-const DrawFunction = "draw-function"
+const (
+	// DrawFunction is the constant for the "draw-function" property tag.
+	// The "draw-function" property sets the draw function of CanvasView.
+	// The function should have the following format: func(Canvas)
+	DrawFunction = "draw-function"
+
+	// AnimationFrameFunction is the constant for the "animation-frame-function" property tag.
+	// The "animation-frame-function" property sets the frame function of CanvasView and starts the animation.
+	// The frame function is called for each animation frame of the browser (requestAnimationFrame).
+	// The next frame is not requested until the previous frame is drawn by the client.
+	// The animation is paused while the browser tab is hidden.
+	// The function should have the following format: func(Canvas, CanvasFrame)
+	AnimationFrameFunction = "animation-frame-function"
+
+	// canvasFrameCommand is the command sent by the client for each animation frame
+	canvasFrameCommand = "canvas-frame"
+)
+
+// CanvasFrame describes the animation frame of CanvasView
+type CanvasFrame struct {
+	// Number is the number of the frame since the animation start (the first frame is 0)
+	Number int
+	// Time is the animation time in milliseconds since the animation start. The time while
+	// the animation is paused (the browser tab is hidden) is not counted
+	Time float64
+	// Delta is the time in milliseconds since the previous frame. It is 0 for the first frame
+	// and for the first frame after the pause
+	Delta float64
+	// Timestamp is the time of the frame in milliseconds passed by the browser to the requestAnimationFrame callback
+	Timestamp float64
+}
 
 // CanvasView interface of a custom draw view
 type CanvasView interface {
 	View
 	Redraw()
+	// Scene returns the retained drawing layer of the view
+	Scene() CanvasScene
+	// ToDataURL asks the client to encode the current content of the canvas to the image of the given format
+	// ("png", "jpeg", "webp" or the MIME type, "png" by default) and passes the encoded bytes to the result function.
+	// The quality (0...1) is applied to "jpeg" and "webp" images, a negative value means the default quality.
+	// The function waits for the answer of the client and calls result with nil if the image is not received.
+	// The client can return PNG if the format is not supported by the browser
+	ToDataURL(format string, quality float64, result func([]byte))
 }
 
 type canvasViewData struct {
 	viewData
-	drawer func(Canvas)
+	drawer   func(Canvas)
+	animator func(Canvas, CanvasFrame)
+	scene    *canvasSceneData
 }
 
 // NewCanvasView creates the new custom draw view
@@ -36,6 +77,13 @@ func (canvasView *canvasViewData) init(session Session) {
 	canvasView.tag = "CanvasView"
 }
 
+func (canvasView *canvasViewData) supportedProperties() []string {
+	return append([]string{
+		DrawFunction, AnimationFrameFunction, ShapeClickEvent, ShapeEnterEvent, ShapeLeaveEvent,
+		ShapeDragStartEvent, ShapeDragEvent, ShapeDragEndEvent,
+	}, canvasView.viewData.supportedProperties()...)
+}
+
 func (canvasView *canvasViewData) String() string {
 	return getViewString(canvasView)
 }
@@ -45,6 +93,9 @@ func (canvasView *canvasViewData) normalizeTag(tag string) string {
 	switch tag {
 	case "draw-func":
 		tag = DrawFunction
+
+	case "frame-function", "animation-frame-func":
+		tag = AnimationFrameFunction
 	}
 	return tag
 }
@@ -54,12 +105,32 @@ func (canvasView *canvasViewData) Remove(tag string) {
 }
 
 func (canvasView *canvasViewData) remove(tag string) {
-	if tag == DrawFunction {
+	switch tag {
+	case DrawFunction:
 		canvasView.drawer = nil
 		canvasView.Redraw()
 		canvasView.propertyChangedEvent(tag)
-	} else {
+
+	case AnimationFrameFunction:
+		if canvasView.animator != nil {
+			canvasView.animator = nil
+			if canvasView.created {
+				canvasView.session.callFunc("stopCanvasAnimation", canvasView.htmlID())
+			}
+			canvasView.propertyChangedEvent(tag)
+		}
+
+	case ShapeClickEvent, ShapeEnterEvent, ShapeLeaveEvent, ShapeDragStartEvent, ShapeDragEvent, ShapeDragEndEvent:
+		if canvasView.getRaw(tag) != nil {
+			canvasView.properties.Delete(tag)
+			canvasView.propertyChangedEvent(tag)
+		}
+
+	default:
 		canvasView.viewData.remove(tag)
+		if _, ok := mouseEvents[tag]; ok && canvasView.scene != nil {
+			canvasView.scene.updateMouseEvents()
+		}
 	}
 }
 
@@ -69,7 +140,8 @@ func (canvasView *canvasViewData) Set(tag string, value any) bool {
 }
 
 func (canvasView *canvasViewData) set(tag string, value any) bool {
-	if tag == DrawFunction {
+	switch tag {
+	case DrawFunction:
 		if value == nil {
 			canvasView.drawer = nil
 		} else if fn, ok := value.(func(Canvas)); ok {
@@ -81,9 +153,46 @@ func (canvasView *canvasViewData) set(tag string, value any) bool {
 		canvasView.Redraw()
 		canvasView.propertyChangedEvent(tag)
 		return true
+
+	case AnimationFrameFunction:
+		if value == nil {
+			canvasView.remove(tag)
+			return true
+		}
+		fn, ok := value.(func(Canvas, CanvasFrame))
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+		canvasView.animator = fn
+		canvasView.startAnimation()
+		canvasView.propertyChangedEvent(tag)
+		return true
+
+	case ShapeClickEvent, ShapeEnterEvent, ShapeLeaveEvent, ShapeDragStartEvent, ShapeDragEvent, ShapeDragEndEvent:
+		listeners, ok := valueToEventListeners[CanvasView, SceneShapeEvent](value)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+		if listeners == nil {
+			canvasView.properties.Delete(tag)
+		} else {
+			canvasView.properties.Store(tag, listeners)
+			canvasView.Scene()
+			canvasView.scene.updateMouseEvents()
+		}
+		canvasView.propertyChangedEvent(tag)
+		return true
 	}
 
-	return canvasView.viewData.set(tag, value)
+	if !canvasView.viewData.set(tag, value) {
+		return false
+	}
+	if _, ok := mouseEvents[tag]; ok && canvasView.scene != nil {
+		canvasView.scene.updateMouseEvents()
+	}
+	return true
 }
 
 func (canvasView *canvasViewData) Get(tag string) any {
@@ -91,8 +200,15 @@ func (canvasView *canvasViewData) Get(tag string) any {
 }
 
 func (canvasView *canvasViewData) get(tag string) any {
-	if tag == DrawFunction {
+	switch tag {
+	case DrawFunction:
 		return canvasView.drawer
+
+	case AnimationFrameFunction:
+		if canvasView.animator != nil {
+			return canvasView.animator
+		}
+		return nil
 	}
 	return canvasView.viewData.get(tag)
 }
@@ -101,20 +217,155 @@ func (canvasView *canvasViewData) htmlTag() string {
 	return "canvas"
 }
 
+func (canvasView *canvasViewData) Scene() CanvasScene {
+	if canvasView.scene == nil {
+		canvasView.scene = newCanvasScene(canvasView)
+	}
+	return canvasView.scene
+}
+
 func (canvasView *canvasViewData) Redraw() {
-	if canvasView.drawer != nil {
+	scene := canvasView.scene
+	if canvasView.drawer != nil || (scene != nil && len(scene.shapes) > 0) {
+		if scene != nil {
+			scene.pending = false
+			scene.dirty = Frame{}
+		}
+		canvas := newCanvas(canvasView)
+		canvas.ClearRect(0, 0, canvasView.frame.Width, canvasView.frame.Height)
+		if canvasView.drawer != nil {
+			canvasView.drawer(canvas)
+		}
+		if scene != nil {
+			scene.draw(canvas, nil)
+		}
+		canvas.finishDraw()
+	}
+}
+
+func (canvasView *canvasViewData) htmlProperties(self View, buffer *strings.Builder) {
+	canvasView.viewData.htmlProperties(self, buffer)
+	if canvasView.scene != nil {
+		canvasView.scene.writeMouseEvents(buffer)
+	}
+}
+
+func (canvasView *canvasViewData) startAnimation() {
+	if canvasView.animator != nil && canvasView.created {
+		canvasView.session.callFunc("startCanvasAnimation", canvasView.htmlID())
+	}
+}
+
+func (canvasView *canvasViewData) drawFrame(data DataObject) {
+	if canvasView.animator != nil {
+		var frame CanvasFrame
+		frame.Number, _ = dataIntProperty(data, "frame")
+		frame.Time = dataFloatProperty(data, "time")
+		frame.Delta = dataFloatProperty(data, "delta")
+		frame.Timestamp = dataFloatProperty(data, "timestamp")
+
+		scene := canvasView.scene
+		if scene != nil {
+			scene.pending = false
+			scene.dirty = Frame{}
+		}
+
 		canvas := newCanvas(canvasView)
 		canvas.ClearRect(0, 0, canvasView.frame.Width, canvasView.frame.Height)
 		if canvasView.drawer != nil {
 			canvasView.drawer(canvas)
 		}
+		canvasView.animator(canvas, frame)
+		if scene != nil {
+			scene.draw(canvas, nil)
+		}
 		canvas.finishDraw()
 	}
+
+	// the acknowledgement allows the client to request the next frame
+	canvasView.session.callFunc("canvasFrameDone", canvasView.htmlID())
+}
+
+func (canvasView *canvasViewData) handleCommand(self View, command string, data DataObject) bool {
+	if command == canvasFrameCommand {
+		canvasView.drawFrame(data)
+		return true
+	}
+
+	if scene := canvasView.scene; scene != nil {
+		switch command {
+		case sceneUpdateCommand:
+			if scene.pending {
+				scene.Update()
+			}
+			return true
+
+		case MouseDown, MouseMove, MouseUp, MouseOut, ClickEvent:
+			scene.handleMouseEvent(command, data)
+		}
+	}
+	return canvasView.viewData.handleCommand(self, command, data)
 }
 
 func (canvasView *canvasViewData) onResize(self View, x, y, width, height float64) {
 	canvasView.viewData.onResize(self, x, y, width, height)
 	canvasView.Redraw()
+	canvasView.startAnimation()
+}
+
+func (canvasView *canvasViewData) ToDataURL(format string, quality float64, result func([]byte)) {
+	data := canvasView.session.canvasDataURL(canvasView.htmlID(), canvasImageMimeType(format), quality)
+	if result != nil {
+		result(data)
+	}
+}
+
+// canvasImageMimeType converts the image format ("png", "jpg", "image/webp", etc.) to MIME type
+func canvasImageMimeType(format string) string {
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "":
+		return "image/png"
+
+	case "jpg":
+		return "image/jpeg"
+	}
+
+	if strings.Contains(format, "/") {
+		return format
+	}
+	return "image/" + format
+}
+
+// canvasDataURLBytes returns the content of the "data:" URL returned by canvas.toDataURL
+func canvasDataURLBytes(url string) []byte {
+	if header, content, ok := strings.Cut(url, ","); ok && strings.HasPrefix(header, "data:") &&
+		strings.HasSuffix(header, ";base64") && content != "" {
+		data, err := base64.StdEncoding.DecodeString(content)
+		if err == nil {
+			return data
+		}
+		ErrorLog(err.Error())
+	}
+	return nil
+}
+
+// DownloadCanvasImage encodes the content of CanvasView (see ToDataURL) and downloads it on the client side
+// as the file with the given name. If the format is empty then it is defined by the file extension
+func DownloadCanvasImage(view CanvasView, filename, format string, quality float64) {
+	if view == nil {
+		return
+	}
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(filename), ".")
+	}
+	view.ToDataURL(format, quality, func(data []byte) {
+		if data != nil {
+			view.Session().DownloadFileData(filename, data)
+		} else {
+			ErrorLog(`The image of "` + view.htmlID() + `" canvas is not received`)
+		}
+	})
 }
 
 // RedrawCanvasView finds CanvasView with canvasViewID and redraws it
@@ -0,0 +1,94 @@
+package rui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanvasAnimation(t *testing.T) {
+	createTestLog(t, false)
+
+	session := newSession(nil, 0, "", nil)
+	bridge := new(canvasTestBridge)
+	session.setBridge(nil, bridge)
+
+	view := NewCanvasView(session, nil)
+	session.(*sessionData).rootView = view
+	viewHTML(view, new(strings.Builder))
+
+	frames := []CanvasFrame{}
+	view.Set(AnimationFrameFunction, func(canvas Canvas, frame CanvasFrame) {
+		frames = append(frames, frame)
+		canvas.FillRect(0, 0, frame.Time/10, 10)
+	})
+	start := "startCanvasAnimation[" + view.htmlID() + "]"
+	if len(bridge.funcs) != 1 || bridge.funcs[0] != start {
+		t.Fatalf("the animation is not started: %v", bridge.funcs)
+	}
+
+	bridge.funcs = nil
+	data := ParseDataText(`canvas-frame{session=0, id=` + view.htmlID() + `, frame=3, time=50, delta=16.5, timestamp=1234.5}`)
+	session.handleEvent(data.Tag(), data)
+
+	if len(frames) != 1 || frames[0] != (CanvasFrame{Number: 3, Time: 50, Delta: 16.5, Timestamp: 1234.5}) {
+		t.Errorf("the frame info: %v", frames)
+	}
+	if drawing := strings.Join(bridge.calls, "\n"); !strings.Contains(drawing, "fillRect[0 0 5 10]") {
+		t.Errorf("the frame is not drawn:\n%s", drawing)
+	}
+	if len(bridge.funcs) != 1 || bridge.funcs[0] != "canvasFrameDone["+view.htmlID()+"]" {
+		t.Errorf("the frame is not acknowledged: %v", bridge.funcs)
+	}
+
+	bridge.funcs = nil
+	view.Remove(AnimationFrameFunction)
+	if view.Get(AnimationFrameFunction) != nil || len(bridge.funcs) != 1 || bridge.funcs[0] != "stopCanvasAnimation["+view.htmlID()+"]" {
+		t.Errorf("the animation is not stopped: %v", bridge.funcs)
+	}
+
+	// the late frame is acknowledged without drawing
+	bridge.funcs = nil
+	session.handleEvent(data.Tag(), data)
+	if len(frames) != 1 || len(bridge.funcs) != 1 {
+		t.Errorf("the late frame: %d frames, %v", len(frames), bridge.funcs)
+	}
+}
+
+func TestCanvasToDataURL(t *testing.T) {
+	createTestLog(t, false)
+
+	session := newSession(nil, 0, "", nil)
+	bridge := new(canvasTestBridge)
+	session.setBridge(nil, bridge)
+
+	view := NewCanvasView(session, nil)
+	bridge.dataURL = "data:image/jpeg;base64,AQID"
+
+	var received []byte
+	view.ToDataURL("jpg", 0.8, func(data []byte) {
+		received = data
+	})
+	if string(received) != "\x01\x02\x03" {
+		t.Errorf("invalid data: %v", received)
+	}
+	if len(bridge.funcs) != 1 || bridge.funcs[0] != "getCanvasDataURL["+view.htmlID()+" image/jpeg 0.8]" {
+		t.Errorf("invalid request: %v", bridge.funcs)
+	}
+
+	bridge.funcs = nil
+	DownloadCanvasImage(view, "chart.webp", "", -1)
+	if len(bridge.funcs) != 2 || bridge.funcs[0] != "getCanvasDataURL["+view.htmlID()+" image/webp -1]" ||
+		!strings.HasPrefix(bridge.funcs[1], "startDownload[") || !strings.Contains(bridge.funcs[1], " chart.webp "+downloadCookiePrefix) {
+		t.Errorf("the download is not started: %v", bridge.funcs)
+	}
+
+	// the empty canvas
+	bridge.dataURL = "data:,"
+	received = []byte{}
+	view.ToDataURL("", -1, func(data []byte) {
+		received = data
+	})
+	if received != nil {
+		t.Errorf("invalid data of the empty canvas: %v", received)
+	}
+}
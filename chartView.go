@@ -0,0 +1,1267 @@
+package rui
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ChartData is the constant for "chart-data" property tag.
+	// The "chart-data" property sets the ChartAdapter of the chart view
+	ChartData = "chart-data"
+	// ChartTitle is the constant for "chart-title" property tag.
+	// The "chart-title" string property sets the title drawn above the chart
+	ChartTitle = "chart-title"
+	// ChartLegend is the constant for "chart-legend" property tag.
+	// The "chart-legend" bool property shows the legend below the chart. The default value is true
+	ChartLegend = "chart-legend"
+	// ChartTooltip is the constant for "chart-tooltip" property tag.
+	// The "chart-tooltip" bool property shows the value of the point under the mouse pointer. The default value is true
+	ChartTooltip = "chart-tooltip"
+	// ChartZoom is the constant for "chart-zoom" property tag.
+	// The "chart-zoom" bool property allows to zoom (by the mouse wheel) and to pan (by the dragging) the x axis
+	// of LineChart and ScatterChart. The double click restores the automatic range. The default value is false
+	ChartZoom = "chart-zoom"
+	// ChartXAxisType is the constant for "chart-x-axis-type" property tag.
+	// The "chart-x-axis-type" int property sets the type of x values of LineChart and ScatterChart:
+	// NumberChartAxis (0) or TimeChartAxis (1)
+	ChartXAxisType = "chart-x-axis-type"
+
+	// NumberChartAxis is value of "chart-x-axis-type" property: x values are numbers
+	NumberChartAxis = 0
+	// TimeChartAxis is value of "chart-x-axis-type" property: x values are Unix time in seconds
+	TimeChartAxis = 1
+
+	// chartWheelCommand is the command sent by the client when the mouse wheel is rotated over the chart
+	chartWheelCommand = "chart-wheel"
+)
+
+const (
+	lineChart = iota
+	barChart
+	pieChart
+	scatterChart
+)
+
+const (
+	chartFontSize      = 12
+	chartPadding       = 8
+	chartHoverRadius   = 10
+	chartMarkerRadius  = 3
+	chartTooltipOffset = 8
+)
+
+// chartPalette is used if the theme does not contain the "ruiChartColor1"..."ruiChartColor8" colors
+var chartPalette = []Color{
+	0xFF1A74E8, 0xFFE8711A, 0xFF2E9E44, 0xFFD32F2F, 0xFF8E44AD, 0xFF8C564B, 0xFFE377C2, 0xFF17BECF,
+}
+
+// ChartAdapter is the data-series adapter of LineChart, BarChart, PieChart, and ScatterChart
+type ChartAdapter interface {
+	// ChartSeriesCount returns the number of the data series
+	ChartSeriesCount() int
+	// ChartSeriesName returns the name of the series displayed in the legend and the tooltip
+	ChartSeriesName(series int) string
+	// ChartPointCount returns the number of points of the series
+	ChartPointCount(series int) int
+	// ChartPoint returns the point of the series. BarChart and PieChart use only y value
+	ChartPoint(series, index int) (x, y float64)
+}
+
+// ChartLabelAdapter is the optional interface of ChartAdapter which sets the category labels
+// of BarChart (the x axis labels) and PieChart (the slice labels)
+type ChartLabelAdapter interface {
+	// ChartLabel returns the label of the point index
+	ChartLabel(index int) string
+}
+
+// ChartColorAdapter is the optional interface of ChartAdapter which sets the colors of the series
+type ChartColorAdapter interface {
+	// ChartSeriesColor returns the color of the series. The transparent color (0) means the theme color
+	ChartSeriesColor(series int) Color
+}
+
+// ChartSeries is the data series of the adapter created by NewChartAdapter.
+// Points can be appended to X and Y after the adapter creation (see ChartView.PointsAppended)
+type ChartSeries struct {
+	// Name is the name of the series displayed in the legend
+	Name string
+	// Color is the color of the series. The transparent color (0) means the theme color
+	Color Color
+	// X are x values of points. If X is nil then the index of the point is used
+	X []float64
+	// Y are y values of points
+	Y []float64
+}
+
+type chartSeriesAdapter struct {
+	labels []string
+	series []*ChartSeries
+}
+
+// NewChartAdapter creates the ChartAdapter for the list of series. labels are the category labels
+// of BarChart and PieChart (can be nil)
+func NewChartAdapter(labels []string, series ...*ChartSeries) ChartAdapter {
+	return &chartSeriesAdapter{labels: labels, series: series}
+}
+
+func (adapter *chartSeriesAdapter) ChartSeriesCount() int {
+	return len(adapter.series)
+}
+
+func (adapter *chartSeriesAdapter) ChartSeriesName(series int) string {
+	if series >= 0 && series < len(adapter.series) {
+		return adapter.series[series].Name
+	}
+	return ""
+}
+
+func (adapter *chartSeriesAdapter) ChartPointCount(series int) int {
+	if series >= 0 && series < len(adapter.series) {
+		if s := adapter.series[series]; s.X != nil {
+			return min(len(s.X), len(s.Y))
+		}
+		return len(adapter.series[series].Y)
+	}
+	return 0
+}
+
+func (adapter *chartSeriesAdapter) ChartPoint(series, index int) (float64, float64) {
+	if series >= 0 && series < len(adapter.series) {
+		s := adapter.series[series]
+		if index >= 0 && index < len(s.Y) {
+			if s.X == nil {
+				return float64(index), s.Y[index]
+			}
+			if index < len(s.X) {
+				return s.X[index], s.Y[index]
+			}
+		}
+	}
+	return 0, 0
+}
+
+func (adapter *chartSeriesAdapter) ChartLabel(index int) string {
+	if index >= 0 && index < len(adapter.labels) {
+		return adapter.labels[index]
+	}
+	return ""
+}
+
+func (adapter *chartSeriesAdapter) ChartSeriesColor(series int) Color {
+	if series >= 0 && series < len(adapter.series) {
+		return adapter.series[series].Color
+	}
+	return 0
+}
+
+// ChartView is the common interface of LineChart, BarChart, PieChart, and ScatterChart views
+type ChartView interface {
+	CanvasView
+	// DataChanged redraws the chart after the data of the adapter is changed
+	DataChanged()
+	// PointsAppended draws the points appended to the end of the series since the last drawing
+	// without the redrawing of the whole chart. The chart is redrawn entirely if new points are out of the axes
+	PointsAppended()
+	// SetXRange sets the visible range of the x axis of LineChart and ScatterChart
+	SetXRange(min, max float64)
+	// XRange returns the visible range of the x axis
+	XRange() (float64, float64)
+	// ResetZoom restores the automatic range of the x axis
+	ResetZoom()
+}
+
+type chartAxes struct {
+	plot       Frame
+	xMin, xMax float64
+	yMin, yMax float64
+	valid      bool
+}
+
+type chartSlice struct {
+	index      int
+	start, end float64
+	value      float64
+}
+
+type chartPoint struct {
+	series, index int
+}
+
+type chartPan struct {
+	x          float64
+	xMin, xMax float64
+}
+
+type chartViewData struct {
+	canvasViewData
+	kind             int
+	adapter          ChartAdapter
+	zoomed           bool
+	zoomMin, zoomMax float64
+	axes             chartAxes
+	drawn            []int
+	slices           []chartSlice
+	pieX, pieY, pieR float64
+	hover            *chartPoint
+	pan              *chartPan
+}
+
+var chartViewTags = []string{"LineChart", "BarChart", "PieChart", "ScatterChart"}
+
+func newChartView(session Session, kind int, params Params) ChartView {
+	view := new(chartViewData)
+	view.kind = kind
+	view.init(session)
+	setInitParams(view, params)
+	return view
+}
+
+// NewLineChart creates the new chart which draws the series as lines
+func NewLineChart(session Session, params Params) ChartView {
+	return newChartView(session, lineChart, params)
+}
+
+// NewBarChart creates the new chart which draws the series as groups of vertical bars
+func NewBarChart(session Session, params Params) ChartView {
+	return newChartView(session, barChart, params)
+}
+
+// NewPieChart creates the new chart which draws the first series as the pie
+func NewPieChart(session Session, params Params) ChartView {
+	return newChartView(session, pieChart, params)
+}
+
+// NewScatterChart creates the new chart which draws the series as points
+func NewScatterChart(session Session, params Params) ChartView {
+	return newChartView(session, scatterChart, params)
+}
+
+func newLineChart(session Session) View {
+	return NewLineChart(session, nil)
+}
+
+func newBarChart(session Session) View {
+	return NewBarChart(session, nil)
+}
+
+func newPieChart(session Session) View {
+	return NewPieChart(session, nil)
+}
+
+func newScatterChart(session Session) View {
+	return NewScatterChart(session, nil)
+}
+
+// Init initialize fields of chart view by default values
+func (chart *chartViewData) init(session Session) {
+	chart.canvasViewData.init(session)
+	chart.tag = chartViewTags[chart.kind]
+	chart.drawer = chart.draw
+}
+
+func (chart *chartViewData) supportedProperties() []string {
+	return append([]string{
+		ChartData, ChartTitle, ChartLegend, ChartTooltip, ChartZoom, ChartXAxisType,
+	}, chart.canvasViewData.supportedProperties()...)
+}
+
+func (chart *chartViewData) String() string {
+	return getViewString(chart)
+}
+
+func (chart *chartViewData) normalizeTag(tag string) string {
+	tag = strings.ToLower(tag)
+	switch tag {
+	case "data", "adapter":
+		return ChartData
+
+	case Title:
+		return ChartTitle
+
+	case "x-axis-type":
+		return ChartXAxisType
+	}
+	return chart.canvasViewData.normalizeTag(tag)
+}
+
+func (chart *chartViewData) Remove(tag string) {
+	chart.remove(chart.normalizeTag(tag))
+}
+
+func (chart *chartViewData) remove(tag string) {
+	switch tag {
+	case DrawFunction:
+		// the draw function of the chart can not be removed
+		return
+
+	case ChartData:
+		if chart.adapter == nil {
+			return
+		}
+		chart.adapter = nil
+		chart.DataChanged()
+
+	case ChartTitle, ChartLegend, ChartTooltip, ChartXAxisType:
+		if _, ok := chart.properties.Load(tag); !ok {
+			return
+		}
+		chart.properties.Delete(tag)
+		chart.hover = nil
+		chart.redraw()
+
+	case ChartZoom:
+		if _, ok := chart.properties.Load(tag); !ok {
+			return
+		}
+		chart.properties.Delete(tag)
+		chart.updateZoomEvents()
+		chart.ResetZoom()
+
+	default:
+		chart.canvasViewData.remove(tag)
+		return
+	}
+
+	chart.propertyChangedEvent(tag)
+}
+
+func (chart *chartViewData) Set(tag string, value any) bool {
+	return chart.set(chart.normalizeTag(tag), value)
+}
+
+func (chart *chartViewData) set(tag string, value any) bool {
+	if value == nil {
+		chart.remove(tag)
+		return true
+	}
+
+	switch tag {
+	case DrawFunction:
+		ErrorLogF(`The "%s" property of %s can not be changed`, tag, chart.tag)
+		return false
+
+	case ChartData:
+		adapter, ok := value.(ChartAdapter)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+		chart.adapter = adapter
+		chart.DataChanged()
+
+	case ChartTitle:
+		text, ok := value.(string)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+		chart.properties.Store(tag, text)
+		chart.redraw()
+
+	case ChartLegend, ChartTooltip:
+		if !chart.setBoolProperty(tag, value) {
+			return false
+		}
+		chart.hover = nil
+		chart.redraw()
+
+	case ChartZoom:
+		if !chart.setBoolProperty(tag, value) {
+			return false
+		}
+		chart.updateZoomEvents()
+		if !chart.zoomEnabled() {
+			chart.ResetZoom()
+		}
+
+	case ChartXAxisType:
+		if !chart.setEnumProperty(tag, value, enumProperties[tag].values) {
+			return false
+		}
+		chart.redraw()
+
+	default:
+		return chart.canvasViewData.set(tag, value)
+	}
+
+	chart.propertyChangedEvent(tag)
+	return true
+}
+
+func (chart *chartViewData) Get(tag string) any {
+	return chart.get(chart.normalizeTag(tag))
+}
+
+func (chart *chartViewData) get(tag string) any {
+	switch tag {
+	case ChartData:
+		if chart.adapter != nil {
+			return chart.adapter
+		}
+		return nil
+
+	case DrawFunction:
+		return nil
+	}
+	return chart.canvasViewData.get(tag)
+}
+
+func (chart *chartViewData) boolProperty(tag string, defaultValue bool) bool {
+	if value, ok := boolProperty(chart, tag, chart.session); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (chart *chartViewData) zoomEnabled() bool {
+	return (chart.kind == lineChart || chart.kind == scatterChart) && chart.boolProperty(ChartZoom, false)
+}
+
+func (chart *chartViewData) timeAxis() bool {
+	if chart.kind == lineChart || chart.kind == scatterChart {
+		value, _ := enumProperty(chart, ChartXAxisType, chart.session, NumberChartAxis)
+		return value == TimeChartAxis
+	}
+	return false
+}
+
+// chartMouseEvents returns the mouse events required by the chart
+func (chart *chartViewData) chartMouseEvents() []string {
+	tags := []string{}
+	if chart.boolProperty(ChartTooltip, true) || chart.zoomEnabled() {
+		tags = append(tags, MouseMove, MouseOut)
+	}
+	if chart.zoomEnabled() {
+		tags = append(tags, MouseDown, MouseUp, DoubleClickEvent)
+	}
+	return tags
+}
+
+func (chart *chartViewData) ownMouseEvent(tag string) bool {
+	return chart.getRaw(tag) == nil && (chart.scene == nil || !chart.scene.handlesMouseEvent(tag))
+}
+
+func (chart *chartViewData) htmlProperties(self View, buffer *strings.Builder) {
+	chart.canvasViewData.htmlProperties(self, buffer)
+	for _, tag := range chart.chartMouseEvents() {
+		if chart.ownMouseEvent(tag) {
+			writeCanvasMouseEvent(tag, buffer)
+		}
+	}
+	if chart.zoomEnabled() {
+		buffer.WriteString(` onwheel="chartWheelEvent(this, event)"`)
+	}
+}
+
+func (chart *chartViewData) updateZoomEvents() {
+	if chart.created {
+		htmlID := chart.htmlID()
+		for _, tag := range chart.chartMouseEvents() {
+			if chart.ownMouseEvent(tag) {
+				js := mouseEvents[tag]
+				chart.session.updateProperty(htmlID, js.jsEvent, js.jsFunc+"(this, event)")
+			}
+		}
+		if chart.zoomEnabled() {
+			chart.session.updateProperty(htmlID, "onwheel", "chartWheelEvent(this, event)")
+		} else {
+			chart.session.removeProperty(htmlID, "onwheel")
+		}
+	}
+}
+
+func (chart *chartViewData) redraw() {
+	if chart.created {
+		chart.Redraw()
+	}
+}
+
+func (chart *chartViewData) DataChanged() {
+	chart.hover = nil
+	chart.drawn = nil
+	chart.redraw()
+}
+
+func (chart *chartViewData) SetXRange(min, max float64) {
+	if max > min {
+		chart.zoomed = true
+		chart.zoomMin, chart.zoomMax = min, max
+		chart.redraw()
+	}
+}
+
+func (chart *chartViewData) XRange() (float64, float64) {
+	if chart.zoomed {
+		return chart.zoomMin, chart.zoomMax
+	}
+	if chart.axes.valid {
+		return chart.axes.xMin, chart.axes.xMax
+	}
+	return 0, 0
+}
+
+func (chart *chartViewData) ResetZoom() {
+	chart.pan = nil
+	if chart.zoomed {
+		chart.zoomed = false
+		chart.redraw()
+	}
+}
+
+func (chart *chartViewData) seriesCount() int {
+	if chart.adapter == nil {
+		return 0
+	}
+	return chart.adapter.ChartSeriesCount()
+}
+
+func (chart *chartViewData) label(index int) string {
+	if adapter, ok := chart.adapter.(ChartLabelAdapter); ok {
+		if label := adapter.ChartLabel(index); label != "" {
+			return label
+		}
+	}
+	return strconv.Itoa(index + 1)
+}
+
+func (chart *chartViewData) themeColor(tag string, defaultColor Color) Color {
+	if color, ok := chart.session.Color(tag); ok {
+		return color
+	}
+	return defaultColor
+}
+
+// paletteColor returns the theme color of the series (of the pie slice)
+func (chart *chartViewData) paletteColor(index int) Color {
+	n := index % len(chartPalette)
+	return chart.themeColor("ruiChartColor"+strconv.Itoa(n+1), chartPalette[n])
+}
+
+func (chart *chartViewData) seriesColor(series int) Color {
+	if adapter, ok := chart.adapter.(ChartColorAdapter); ok {
+		if color := adapter.ChartSeriesColor(series); color != 0 {
+			return color
+		}
+	}
+	return chart.paletteColor(series)
+}
+
+func (chart *chartViewData) legendItems() ([]string, []Color) {
+	names := []string{}
+	colors := []Color{}
+	if chart.kind == pieChart {
+		for _, slice := range chart.pieSlices() {
+			names = append(names, chart.label(slice.index))
+			colors = append(colors, chart.paletteColor(slice.index))
+		}
+	} else {
+		for series := 0; series < chart.seriesCount(); series++ {
+			names = append(names, chart.adapter.ChartSeriesName(series))
+			colors = append(colors, chart.seriesColor(series))
+		}
+	}
+	return names, colors
+}
+
+func (chart *chartViewData) draw(canvas Canvas) {
+	width, height := chart.frame.Width, chart.frame.Height
+	area := Frame{Left: chartPadding, Top: chartPadding, Width: width - 2*chartPadding, Height: height - 2*chartPadding}
+	textColor := chart.themeColor("ruiTextColor", Black)
+
+	canvas.SetFont("sans-serif", Px(chartFontSize))
+	if title, ok := stringProperty(chart, ChartTitle, chart.session); ok && title != "" {
+		canvas.SetSolidColorFillStyle(textColor)
+		canvas.SetTextAlign(CenterAlign)
+		canvas.SetTextBaseline(TopBaseline)
+		canvas.FillText(width/2, area.Top, title)
+		area.Top += chartFontSize * 1.75
+		area.Height -= chartFontSize * 1.75
+	}
+
+	if chart.boolProperty(ChartLegend, true) {
+		area.Height -= chart.drawLegend(canvas, area, textColor)
+	}
+
+	chart.axes.valid = false
+	chart.slices = nil
+	if area.Width > 0 && area.Height > 0 {
+		if chart.kind == pieChart {
+			chart.drawPie(canvas, area)
+		} else {
+			chart.drawAxes(canvas, area, textColor)
+		}
+	}
+
+	if chart.hover != nil {
+		chart.drawTooltip(canvas)
+	}
+}
+
+// drawLegend draws the legend at the bottom of the area and returns its height
+func (chart *chartViewData) drawLegend(canvas Canvas, area Frame, textColor Color) float64 {
+	names, colors := chart.legendItems()
+	if len(names) == 0 {
+		return 0
+	}
+
+	rowHeight := chartFontSize * 1.5
+	widths := make([]float64, len(names))
+	rows := [][]int{{}}
+	rowWidths := []float64{0}
+	for i, name := range names {
+		widths[i] = chartFontSize + 4 + canvasTextWidth(name, chartFontSize)
+		last := len(rows) - 1
+		if len(rows[last]) > 0 && rowWidths[last]+16+widths[i] > area.Width {
+			rows = append(rows, []int{})
+			rowWidths = append(rowWidths, 0)
+			last++
+		}
+		if len(rows[last]) > 0 {
+			rowWidths[last] += 16
+		}
+		rows[last] = append(rows[last], i)
+		rowWidths[last] += widths[i]
+	}
+
+	legendHeight := rowHeight*float64(len(rows)) + chartPadding
+	y := area.Bottom() - legendHeight + chartPadding
+	canvas.SetTextAlign(LeftAlign)
+	canvas.SetTextBaseline(MiddleBaseline)
+	for r, row := range rows {
+		x := area.Left + (area.Width-rowWidths[r])/2
+		for _, i := range row {
+			canvas.SetSolidColorFillStyle(colors[i])
+			canvas.FillRect(x, y+(rowHeight-chartFontSize)/2, chartFontSize, chartFontSize)
+			canvas.SetSolidColorFillStyle(textColor)
+			canvas.FillText(x+chartFontSize+4, y+rowHeight/2, names[i])
+			x += widths[i] + 16
+		}
+		y += rowHeight
+	}
+	return legendHeight
+}
+
+// chartNiceStep returns the step of count ticks in the span: 1, 2 or 5 multiplied by a power of 10
+func chartNiceStep(span float64, count int) float64 {
+	if span <= 0 || count <= 0 {
+		return 1
+	}
+	raw := span / float64(count)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*magnitude {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// chartTimeSteps are the steps of the time axis in seconds
+var chartTimeSteps = []float64{
+	1, 2, 5, 10, 15, 30, 60, 120, 300, 600, 900, 1800, 3600, 7200, 10800, 21600, 43200,
+	86400, 172800, 604800, 2592000, 7776000, 31536000,
+}
+
+func chartTimeStep(span float64, count int) float64 {
+	raw := span / float64(max(count, 1))
+	for _, step := range chartTimeSteps {
+		if raw <= step {
+			return step
+		}
+	}
+	return chartNiceStep(span/31536000, count) * 31536000
+}
+
+func chartTicks(min, max, step float64) []float64 {
+	ticks := []float64{}
+	first := math.Ceil(min/step - 1e-9)
+	for i := 0; i < 1000; i++ {
+		value := (first + float64(i)) * step
+		if value > max+step*1e-9 {
+			break
+		}
+		ticks = append(ticks, value)
+	}
+	return ticks
+}
+
+func chartFormatNumber(value, step float64) string {
+	decimals := 0
+	if step > 0 && step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step) - 1e-9))
+	}
+	if math.Abs(value) < step*1e-9 {
+		value = 0
+	}
+	return strconv.FormatFloat(value, 'f', decimals, 64)
+}
+
+func chartFormatTime(value, step float64) string {
+	t := time.Unix(int64(math.Floor(value)), 0)
+	switch {
+	case step == 0:
+		return t.Format("2006-01-02 15:04:05")
+
+	case step < 60:
+		return t.Format("15:04:05")
+
+	case step < 86400:
+		return t.Format("15:04")
+
+	case step < 2592000:
+		return t.Format("Jan 2")
+	}
+	return t.Format("Jan 2006")
+}
+
+func (chart *chartViewData) formatX(value, step float64) string {
+	if chart.timeAxis() {
+		return chartFormatTime(value, step)
+	}
+	if step == 0 {
+		return strconv.FormatFloat(value, 'g', 6, 64)
+	}
+	return chartFormatNumber(value, step)
+}
+
+// dataRange returns the range of x values and the range of y values of points with x in [xMin, xMax]
+func (chart *chartViewData) dataRange(xMin, xMax float64, limitX bool) (float64, float64, float64, float64, bool) {
+	x0, x1 := math.Inf(1), math.Inf(-1)
+	y0, y1 := math.Inf(1), math.Inf(-1)
+	found := false
+	for series := 0; series < chart.seriesCount(); series++ {
+		count := chart.adapter.ChartPointCount(series)
+		for i := 0; i < count; i++ {
+			x, y := chart.adapter.ChartPoint(series, i)
+			if chart.kind == barChart {
+				x = float64(i)
+			}
+			if math.IsNaN(x) || math.IsNaN(y) || (limitX && (x < xMin || x > xMax)) {
+				continue
+			}
+			found = true
+			x0, x1 = math.Min(x0, x), math.Max(x1, x)
+			y0, y1 = math.Min(y0, y), math.Max(y1, y)
+		}
+	}
+	return x0, x1, y0, y1, found
+}
+
+func (chart *chartViewData) drawAxes(canvas Canvas, area Frame, textColor Color) {
+	axes := chartAxes{}
+	xCount := max(2, int(area.Width/90))
+	yCount := max(2, int(area.Height/40))
+
+	var xStep float64
+	categories := 0
+	if chart.kind == barChart {
+		for series := 0; series < chart.seriesCount(); series++ {
+			categories = max(categories, chart.adapter.ChartPointCount(series))
+		}
+		axes.xMin, axes.xMax = 0, float64(max(categories, 1))
+	} else if chart.zoomed {
+		axes.xMin, axes.xMax = chart.zoomMin, chart.zoomMax
+	} else {
+		x0, x1, _, _, ok := chart.dataRange(0, 0, false)
+		if !ok {
+			x0, x1 = 0, 1
+		} else if x0 == x1 {
+			x0, x1 = x0-1, x1+1
+		}
+		axes.xMin, axes.xMax = x0, x1
+	}
+
+	if chart.kind != barChart {
+		if chart.timeAxis() {
+			xStep = chartTimeStep(axes.xMax-axes.xMin, xCount)
+		} else {
+			xStep = chartNiceStep(axes.xMax-axes.xMin, xCount)
+		}
+		if !chart.zoomed {
+			// the range is extended to the whole ticks, so appended points usually fit the axis
+			axes.xMin = math.Floor(axes.xMin/xStep) * xStep
+			axes.xMax = math.Ceil(axes.xMax/xStep) * xStep
+		}
+	}
+
+	_, _, y0, y1, ok := chart.dataRange(axes.xMin, axes.xMax, chart.kind != barChart)
+	if !ok {
+		y0, y1 = 0, 1
+	}
+	if chart.kind == barChart {
+		y0, y1 = math.Min(y0, 0), math.Max(y1, 0)
+	}
+	if y0 == y1 {
+		y0, y1 = y0-1, y1+1
+	}
+	yStep := chartNiceStep(y1-y0, yCount)
+	axes.yMin = math.Floor(y0/yStep) * yStep
+	axes.yMax = math.Ceil(y1/yStep) * yStep
+
+	yTicks := chartTicks(axes.yMin, axes.yMax, yStep)
+	labelWidth := 0.0
+	for _, tick := range yTicks {
+		labelWidth = math.Max(labelWidth, canvasTextWidth(chartFormatNumber(tick, yStep), chartFontSize))
+	}
+
+	axes.plot = Frame{
+		Left:   area.Left + labelWidth + 6,
+		Top:    area.Top + chartFontSize/2,
+		Width:  area.Width - labelWidth - 6 - chartFontSize,
+		Height: area.Height - chartFontSize*2,
+	}
+	if axes.plot.Width <= 0 || axes.plot.Height <= 0 {
+		return
+	}
+	axes.valid = true
+	chart.axes = axes
+	plot := axes.plot
+
+	gridColor := chart.themeColor("ruiChartGridColor", 0xFFE0E0E0)
+	axisColor := chart.themeColor("ruiChartAxisColor", 0xFF808080)
+
+	canvas.SetLineWidth(1)
+	canvas.SetTextAlign(RightAlign)
+	canvas.SetTextBaseline(MiddleBaseline)
+	for _, tick := range yTicks {
+		y := math.Round(chart.toY(tick)) + 0.5
+		canvas.SetSolidColorStrokeStyle(gridColor)
+		canvas.DrawLine(plot.Left, y, plot.Right(), y)
+		canvas.SetSolidColorFillStyle(textColor)
+		canvas.FillText(plot.Left-6, y, chartFormatNumber(tick, yStep))
+	}
+
+	canvas.SetTextAlign(CenterAlign)
+	canvas.SetTextBaseline(TopBaseline)
+	labelY := plot.Bottom() + 4
+	if chart.kind == barChart {
+		band := plot.Width / axes.xMax
+		skip := 1
+		for i := 0; i < categories; i++ {
+			skip = max(skip, int(math.Ceil((canvasTextWidth(chart.label(i), chartFontSize)+4)/band)))
+		}
+		canvas.SetSolidColorFillStyle(textColor)
+		for i := 0; i < categories; i += skip {
+			canvas.FillText(plot.Left+band*(float64(i)+0.5), labelY, chart.label(i))
+		}
+	} else {
+		for _, tick := range chartTicks(axes.xMin, axes.xMax, xStep) {
+			x := math.Round(chart.toX(tick)) + 0.5
+			canvas.SetSolidColorStrokeStyle(gridColor)
+			canvas.DrawLine(x, plot.Top, x, plot.Bottom())
+			canvas.SetSolidColorFillStyle(textColor)
+			canvas.FillText(x, labelY, chart.formatX(tick, xStep))
+		}
+	}
+
+	canvas.SetSolidColorStrokeStyle(axisColor)
+	canvas.DrawLine(plot.Left, math.Round(plot.Bottom())+0.5, plot.Right(), math.Round(plot.Bottom())+0.5)
+	canvas.DrawLine(math.Round(plot.Left)+0.5, plot.Top, math.Round(plot.Left)+0.5, plot.Bottom())
+
+	canvas.Save()
+	canvas.ClipRect(plot.Left, plot.Top, plot.Width, plot.Height)
+	count := chart.seriesCount()
+	chart.drawn = make([]int, count)
+	for series := 0; series < count; series++ {
+		chart.drawn[series] = chart.adapter.ChartPointCount(series)
+		switch chart.kind {
+		case barChart:
+			chart.drawBars(canvas, series)
+
+		default:
+			chart.drawPoints(canvas, series, 0, chart.drawn[series])
+		}
+	}
+	canvas.Restore()
+}
+
+func (chart *chartViewData) toX(x float64) float64 {
+	axes := &chart.axes
+	return axes.plot.Left + (x-axes.xMin)/(axes.xMax-axes.xMin)*axes.plot.Width
+}
+
+func (chart *chartViewData) toY(y float64) float64 {
+	axes := &chart.axes
+	return axes.plot.Bottom() - (y-axes.yMin)/(axes.yMax-axes.yMin)*axes.plot.Height
+}
+
+// drawPoints draws the points [from, to) of the series. The line is started from the point from - 1
+func (chart *chartViewData) drawPoints(canvas Canvas, series, from, to int) {
+	color := chart.seriesColor(series)
+	if chart.kind == scatterChart {
+		canvas.SetSolidColorFillStyle(color)
+		for i := from; i < to; i++ {
+			x, y := chart.adapter.ChartPoint(series, i)
+			if !math.IsNaN(x) && !math.IsNaN(y) {
+				canvas.FillEllipse(chart.toX(x), chart.toY(y), chartMarkerRadius, chartMarkerRadius, 0)
+			}
+		}
+		return
+	}
+
+	path := NewPath()
+	started := false
+	for i := max(from-1, 0); i < to; i++ {
+		x, y := chart.adapter.ChartPoint(series, i)
+		if math.IsNaN(x) || math.IsNaN(y) {
+			// NaN breaks the line
+			started = false
+			continue
+		}
+		if started {
+			path.LineTo(chart.toX(x), chart.toY(y))
+		} else {
+			path.MoveTo(chart.toX(x), chart.toY(y))
+			started = true
+		}
+	}
+	canvas.SetSolidColorStrokeStyle(color)
+	canvas.SetLineWidth(2)
+	canvas.SetLineJoin(RoundJoin)
+	canvas.StrokePath(path)
+}
+
+// barRect returns the rectangle of the bar
+func (chart *chartViewData) barRect(series, index int) Frame {
+	count := chart.seriesCount()
+	band := chart.axes.plot.Width / chart.axes.xMax
+	width := band * 0.8 / float64(count)
+	_, value := chart.adapter.ChartPoint(series, index)
+	y0, y1 := chart.toY(0), chart.toY(value)
+	return Frame{
+		Left:   chart.axes.plot.Left + band*(float64(index)+0.1) + width*float64(series),
+		Top:    math.Min(y0, y1),
+		Width:  width,
+		Height: math.Abs(y1 - y0),
+	}
+}
+
+func (chart *chartViewData) drawBars(canvas Canvas, series int) {
+	canvas.SetSolidColorFillStyle(chart.seriesColor(series))
+	for i := 0; i < chart.adapter.ChartPointCount(series); i++ {
+		if rect := chart.barRect(series, i); rect.Height > 0 {
+			canvas.FillRect(rect.Left, rect.Top, rect.Width, rect.Height)
+		}
+	}
+}
+
+func (chart *chartViewData) pieSlices() []chartSlice {
+	slices := []chartSlice{}
+	if chart.seriesCount() == 0 {
+		return slices
+	}
+
+	total := 0.0
+	count := chart.adapter.ChartPointCount(0)
+	for i := 0; i < count; i++ {
+		if _, y := chart.adapter.ChartPoint(0, i); y > 0 {
+			total += y
+		}
+	}
+	if total == 0 {
+		return slices
+	}
+
+	angle := -math.Pi / 2
+	for i := 0; i < count; i++ {
+		if _, y := chart.adapter.ChartPoint(0, i); y > 0 {
+			end := angle + y/total*2*math.Pi
+			slices = append(slices, chartSlice{index: i, start: angle, end: end, value: y})
+			angle = end
+		}
+	}
+	return slices
+}
+
+func (chart *chartViewData) drawPie(canvas Canvas, area Frame) {
+	chart.slices = chart.pieSlices()
+	chart.pieX = area.Left + area.Width/2
+	chart.pieY = area.Top + area.Height/2
+	chart.pieR = math.Min(area.Width, area.Height) / 2
+
+	background := chart.themeColor("ruiBackgroundColor", White)
+	for _, slice := range chart.slices {
+		path := NewPath()
+		path.MoveTo(chart.pieX, chart.pieY)
+		path.Arc(chart.pieX, chart.pieY, chart.pieR, slice.start, slice.end, true)
+		path.Close()
+		canvas.SetSolidColorFillStyle(chart.paletteColor(slice.index))
+		canvas.FillPath(path)
+		if len(chart.slices) > 1 {
+			canvas.SetSolidColorStrokeStyle(background)
+			canvas.SetLineWidth(1)
+			canvas.StrokePath(path)
+		}
+	}
+}
+
+// hitTest returns the point under the mouse pointer or nil
+func (chart *chartViewData) hitTest(x, y float64) *chartPoint {
+	switch chart.kind {
+	case pieChart:
+		dx, dy := x-chart.pieX, y-chart.pieY
+		if math.Hypot(dx, dy) > chart.pieR {
+			return nil
+		}
+		angle := math.Atan2(dy, dx)
+		for _, slice := range chart.slices {
+			for _, a := range []float64{angle, angle + 2*math.Pi} {
+				if a >= slice.start && a < slice.end {
+					return &chartPoint{series: 0, index: slice.index}
+				}
+			}
+		}
+		return nil
+
+	case barChart:
+		if chart.axes.valid {
+			for series := 0; series < chart.seriesCount(); series++ {
+				for i := 0; i < chart.adapter.ChartPointCount(series); i++ {
+					rect := chart.barRect(series, i)
+					if x >= rect.Left && x < rect.Right() && y >= rect.Top && y <= rect.Bottom() {
+						return &chartPoint{series: series, index: i}
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	if !chart.axes.valid {
+		return nil
+	}
+
+	var result *chartPoint
+	distance := float64(chartHoverRadius)
+	for series := 0; series < chart.seriesCount(); series++ {
+		for i := 0; i < chart.adapter.ChartPointCount(series); i++ {
+			px, py := chart.adapter.ChartPoint(series, i)
+			if px < chart.axes.xMin || px > chart.axes.xMax {
+				continue
+			}
+			if d := math.Hypot(chart.toX(px)-x, chart.toY(py)-y); d <= distance {
+				distance = d
+				result = &chartPoint{series: series, index: i}
+			}
+		}
+	}
+	return result
+}
+
+// tooltip returns the position and the lines of the tooltip of the hovered point
+func (chart *chartViewData) tooltip() (float64, float64, []string) {
+	hover := chart.hover
+	if hover.series >= chart.seriesCount() || hover.index >= chart.adapter.ChartPointCount(hover.series) {
+		return 0, 0, nil
+	}
+
+	x, y := chart.adapter.ChartPoint(hover.series, hover.index)
+	value := strconv.FormatFloat(y, 'g', 6, 64)
+	switch chart.kind {
+	case pieChart:
+		total := 0.0
+		for _, slice := range chart.slices {
+			total += slice.value
+		}
+		for _, slice := range chart.slices {
+			if slice.index == hover.index {
+				angle := (slice.start + slice.end) / 2
+				percent := strconv.FormatFloat(y/total*100, 'f', 1, 64)
+				return chart.pieX + math.Cos(angle)*chart.pieR/2, chart.pieY + math.Sin(angle)*chart.pieR/2,
+					[]string{chart.label(hover.index), value + " (" + percent + "%)"}
+			}
+		}
+		return 0, 0, nil
+
+	case barChart:
+		rect := chart.barRect(hover.series, hover.index)
+		return rect.Left + rect.Width/2, rect.Top,
+			[]string{chart.adapter.ChartSeriesName(hover.series), chart.label(hover.index) + ": " + value}
+	}
+
+	return chart.toX(x), chart.toY(y),
+		[]string{chart.adapter.ChartSeriesName(hover.series), chart.formatX(x, 0) + "; " + value}
+}
+
+func (chart *chartViewData) drawTooltip(canvas Canvas) {
+	x, y, lines := chart.tooltip()
+	if len(lines) == 0 {
+		return
+	}
+	if lines[0] == "" {
+		lines = lines[1:]
+	}
+
+	if chart.kind == lineChart || chart.kind == scatterChart {
+		canvas.SetSolidColorFillStyle(chart.seriesColor(chart.hover.series))
+		canvas.FillEllipse(x, y, chartMarkerRadius+2, chartMarkerRadius+2, 0)
+	}
+
+	lineHeight := chartFontSize * 1.4
+	width := 0.0
+	for _, line := range lines {
+		width = math.Max(width, canvasTextWidth(line, chartFontSize))
+	}
+	width += 2 * chartPadding
+	height := lineHeight*float64(len(lines)) + chartPadding
+
+	left := x + chartTooltipOffset
+	if left+width > chart.frame.Width {
+		left = x - chartTooltipOffset - width
+	}
+	top := y - height - chartTooltipOffset
+	if top < 0 {
+		top = y + chartTooltipOffset
+	}
+	left = math.Max(0, left)
+
+	canvas.SetSolidColorFillStyle(chart.themeColor("ruiTooltipBackground", White))
+	canvas.SetSolidColorStrokeStyle(chart.themeColor("ruiTooltipShadowColor", Gray))
+	canvas.SetLineWidth(1)
+	canvas.FillAndStrokeRoundedRect(left, top, width, height, 4)
+
+	canvas.SetSolidColorFillStyle(chart.themeColor("ruiTooltipTextColor", Black))
+	canvas.SetTextAlign(LeftAlign)
+	canvas.SetTextBaseline(TopBaseline)
+	for i, line := range lines {
+		canvas.FillText(left+chartPadding, top+chartPadding/2+lineHeight*float64(i)+(lineHeight-chartFontSize)/2, line)
+	}
+}
+
+func (chart *chartViewData) PointsAppended() {
+	if !chart.created {
+		return
+	}
+
+	count := chart.seriesCount()
+	if (chart.kind != lineChart && chart.kind != scatterChart) || !chart.axes.valid || len(chart.drawn) != count {
+		chart.Redraw()
+		return
+	}
+
+	axes := &chart.axes
+	changed := false
+	for series := 0; series < count; series++ {
+		n := chart.adapter.ChartPointCount(series)
+		if n < chart.drawn[series] {
+			chart.Redraw()
+			return
+		}
+		for i := chart.drawn[series]; i < n; i++ {
+			x, y := chart.adapter.ChartPoint(series, i)
+			if math.IsNaN(x) || math.IsNaN(y) {
+				continue
+			}
+			if x < axes.xMin || x > axes.xMax {
+				if !chart.zoomed {
+					chart.Redraw()
+					return
+				}
+			} else if y < axes.yMin || y > axes.yMax {
+				chart.Redraw()
+				return
+			}
+			changed = true
+		}
+	}
+
+	if changed {
+		canvas := newCanvas(chart)
+		canvas.Save()
+		canvas.ClipRect(axes.plot.Left, axes.plot.Top, axes.plot.Width, axes.plot.Height)
+		for series := 0; series < count; series++ {
+			n := chart.adapter.ChartPointCount(series)
+			if n > chart.drawn[series] {
+				chart.drawPoints(canvas, series, chart.drawn[series], n)
+			}
+		}
+		canvas.Restore()
+		canvas.finishDraw()
+	}
+
+	for series := 0; series < count; series++ {
+		chart.drawn[series] = chart.adapter.ChartPointCount(series)
+	}
+}
+
+func (chart *chartViewData) setHover(hover *chartPoint) {
+	if hover == nil && chart.hover == nil {
+		return
+	}
+	if hover != nil && chart.hover != nil && *hover == *chart.hover {
+		return
+	}
+	chart.hover = hover
+	chart.Redraw()
+}
+
+func (chart *chartViewData) zoom(x, delta float64) {
+	if !chart.axes.valid || delta == 0 {
+		return
+	}
+	xMin, xMax := chart.XRange()
+	center := xMin + (x-chart.axes.plot.Left)/chart.axes.plot.Width*(xMax-xMin)
+	factor := 0.8
+	if delta > 0 {
+		factor = 1.25
+	}
+	chart.SetXRange(center-(center-xMin)*factor, center+(xMax-center)*factor)
+}
+
+func (chart *chartViewData) handleCommand(self View, command string, data DataObject) bool {
+	switch command {
+	case chartWheelCommand:
+		if chart.zoomEnabled() {
+			chart.zoom(dataFloatProperty(data, "x"), dataFloatProperty(data, "delta"))
+		}
+		return true
+
+	case MouseDown, MouseMove, MouseUp, MouseOut, DoubleClickEvent:
+		var event MouseEvent
+		event.init(data)
+		chart.handleMouseEvent(command, event)
+	}
+	return chart.canvasViewData.handleCommand(self, command, data)
+}
+
+func (chart *chartViewData) handleMouseEvent(command string, event MouseEvent) {
+	plot := chart.axes.plot
+	switch command {
+	case MouseDown:
+		if chart.zoomEnabled() && chart.axes.valid && event.Button == PrimaryMouseButton &&
+			event.X >= plot.Left && event.X <= plot.Right() && event.Y >= plot.Top && event.Y <= plot.Bottom() {
+			xMin, xMax := chart.XRange()
+			chart.pan = &chartPan{x: event.X, xMin: xMin, xMax: xMax}
+		}
+
+	case MouseMove:
+		if pan := chart.pan; pan != nil {
+			if event.Buttons&PrimaryMouseMask == 0 {
+				chart.pan = nil
+			} else {
+				shift := (pan.x - event.X) / plot.Width * (pan.xMax - pan.xMin)
+				chart.hover = nil
+				chart.SetXRange(pan.xMin+shift, pan.xMax+shift)
+				return
+			}
+		}
+		if chart.boolProperty(ChartTooltip, true) {
+			chart.setHover(chart.hitTest(event.X, event.Y))
+		}
+
+	case MouseUp:
+		chart.pan = nil
+
+	case MouseOut:
+		chart.pan = nil
+		chart.setHover(nil)
+
+	case DoubleClickEvent:
+		if chart.zoomEnabled() {
+			chart.ResetZoom()
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package rui
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestChartView(t *testing.T) {
+	createTestLog(t, false)
+
+	if step := chartNiceStep(10, 4); step != 5 {
+		t.Errorf("chartNiceStep(10, 4) = %g", step)
+	}
+	if ticks := chartTicks(-0.5, 1.1, 0.5); len(ticks) != 4 || ticks[0] != -0.5 || ticks[3] != 1 {
+		t.Errorf("chartTicks: %v", ticks)
+	}
+	if text := chartFormatNumber(0.30000000000000004, 0.1); text != "0.3" {
+		t.Errorf("chartFormatNumber: %s", text)
+	}
+
+	session := newSession(nil, 0, "", nil)
+	bridge := new(canvasTestBridge)
+	session.setBridge(nil, bridge)
+
+	series := &ChartSeries{Name: "Temperature", X: []float64{0, 1, 2, 3}, Y: []float64{10, 20, 15, 25}}
+	chart := NewLineChart(session, Params{
+		ChartData:  NewChartAdapter(nil, series),
+		ChartTitle: "Sensors",
+		ChartZoom:  true,
+	})
+	session.(*sessionData).rootView = chart
+
+	buffer := new(strings.Builder)
+	viewHTML(chart, buffer)
+	if html := buffer.String(); !strings.Contains(html, `onwheel="chartWheelEvent(this, event)"`) ||
+		!strings.Contains(html, `onmousemove="mouseMoveEvent(this, event)"`) {
+		t.Errorf("the chart mouse handlers are not written: %s", html)
+	}
+	chart.onResize(chart, 0, 0, 400, 300)
+
+	drawing := strings.Join(bridge.calls, "\n")
+	for _, text := range []string{"fillText[Sensors 200 8]", "fillText[Temperature ", "stroke[]"} {
+		if !strings.Contains(drawing, text) {
+			t.Errorf(`"%s" is not drawn:\n%s`, text, drawing)
+		}
+	}
+
+	data := chart.(*chartViewData)
+	if min, max := chart.XRange(); min != 0 || max != 3 {
+		t.Errorf("x range: %g...%g", min, max)
+	}
+	if data.axes.yMin != 10 || data.axes.yMax != 25 {
+		t.Errorf("y range: %g...%g", data.axes.yMin, data.axes.yMax)
+	}
+
+	// the tooltip
+	x, y := data.toX(2), data.toY(15)
+	mouse := func(command string, x, y float64, buttons int) {
+		data.handleCommand(chart, command, ParseDataText(`e{x=`+chartFormatNumber(x, 0.01)+`, y=`+
+			chartFormatNumber(y, 0.01)+`, buttons=`+chartFormatNumber(float64(buttons), 1)+`}`))
+	}
+	mouse(MouseMove, x+3, y-2, 0)
+	if data.hover == nil || data.hover.index != 2 {
+		t.Errorf("the hovered point: %v", data.hover)
+	} else if drawing := strings.Join(bridge.calls, "\n"); !strings.Contains(drawing, "fillText[2; 15 ") {
+		t.Errorf("the tooltip is not drawn:\n%s", drawing)
+	}
+	mouse(MouseOut, 0, 0, 0)
+	if data.hover != nil {
+		t.Error("the tooltip is not hidden")
+	}
+
+	// the appended point inside the axes is drawn without the redrawing
+	series.X = append(series.X, 2.5)
+	series.Y = append(series.Y, 12)
+	chart.PointsAppended()
+	drawing = strings.Join(bridge.calls, "\n")
+	if strings.Contains(drawing, "clearRect") || !strings.Contains(drawing, "stroke[]") {
+		t.Errorf("the appended point is not drawn incrementally:\n%s", drawing)
+	}
+
+	series.X = append(series.X, 10)
+	series.Y = append(series.Y, 12)
+	chart.PointsAppended()
+	if drawing := strings.Join(bridge.calls, "\n"); !strings.Contains(drawing, "clearRect") {
+		t.Errorf("the chart is not redrawn:\n%s", drawing)
+	}
+	if _, max := chart.XRange(); max != 10 {
+		t.Errorf("the x range is not extended: %g", max)
+	}
+
+	// zoom and pan
+	plot := data.axes.plot
+	data.handleCommand(chart, chartWheelCommand, ParseDataText(`chart-wheel{x=`+chartFormatNumber(plot.Left, 0.01)+`, delta=-100}`))
+	if min, max := chart.XRange(); min != 0 || max != 8 {
+		t.Errorf("the zoomed range: %g...%g", min, max)
+	}
+	mouse(MouseDown, plot.Left+plot.Width/2, plot.Top+10, 1)
+	mouse(MouseMove, plot.Left+plot.Width/4, plot.Top+10, 1)
+	mouse(MouseUp, plot.Left+plot.Width/4, plot.Top+10, 0)
+	if min, max := chart.XRange(); math.Abs(min-2) > 1e-9 || math.Abs(max-10) > 1e-9 {
+		t.Errorf("the panned range: %g...%g", min, max)
+	}
+	mouse(DoubleClickEvent, 0, 0, 0)
+	if min, max := chart.XRange(); min != 0 || max != 10 || data.zoomed {
+		t.Errorf("the zoom is not reset: %g...%g", min, max)
+	}
+
+	// PieChart
+	pie := NewPieChart(session, Params{
+		ChartData: NewChartAdapter([]string{"A", "B"}, &ChartSeries{Y: []float64{1, 3}}),
+	})
+	recorder := NewCanvasRecorder(200, 200)
+	pie.Set(Width, Px(200))
+	pieData := pie.(*chartViewData)
+	pieData.frame = Frame{Width: 200, Height: 200}
+	pieData.draw(recorder)
+	if hit := pieData.hitTest(pieData.pieX+10, pieData.pieY-20); hit == nil || hit.index != 0 {
+		t.Errorf("the pie slice A: %v", hit)
+	}
+	if hit := pieData.hitTest(pieData.pieX-10, pieData.pieY); hit == nil || hit.index != 1 {
+		t.Errorf("the pie slice B: %v", hit)
+	}
+	if svg := recorder.SVG(); !strings.Contains(svg, ">A</text>") || !strings.Contains(svg, ">B</text>") {
+		t.Errorf("the pie legend is not drawn:\n%s", svg)
+	}
+
+	ignoreTestLog = true
+	if chart.Set(DrawFunction, func(Canvas) {}) {
+		t.Error("the draw function of the chart is changed")
+	}
+	ignoreTestLog = false
+}
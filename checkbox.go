@@ -42,6 +42,13 @@ func (button *checkboxData) init(session Session) {
 	button.checkedListeners = []func(Checkbox, bool){}
 }
 
+func (button *checkboxData) supportedProperties() []string {
+	return append([]string{
+		Checked, CheckboxChangedEvent, CheckboxHorizontalAlign, CheckboxVerticalAlign, VerticalAlign,
+		HorizontalAlign, CellVerticalAlign, CellHorizontalAlign, CellWidth, CellHeight,
+	}, button.viewsContainerData.supportedProperties()...)
+}
+
 func (button *checkboxData) String() string {
 	return getViewString(button)
 }
@@ -0,0 +1,48 @@
+// Command ruicheck checks .rui resource files.
+//
+// Usage:
+//
+//	ruicheck [path ...]
+//
+// Each path is a .rui file or a directory (all .rui files of the directory and its subdirectories are checked).
+// The current directory is checked if no path is given. The errors are printed in the "file:line:column: message"
+// format and the command exits with the status 1 if any error is found.
+//
+// Only standard views are known to the command. To check views with custom views, call rui.ValidateRUIDir
+// from a test of the application after registering the custom views.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ruraomsk/rui"
+)
+
+func main() {
+	paths := os.Args[1:]
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	failed := false
+	for _, path := range paths {
+		var errors []rui.RUIError
+		if info, err := os.Stat(path); err != nil {
+			errors = []rui.RUIError{{File: path, Message: err.Error()}}
+		} else if info.IsDir() {
+			errors = rui.ValidateRUIDir(path)
+		} else {
+			errors = rui.ValidateRUIFile(path)
+		}
+
+		for _, err := range errors {
+			fmt.Fprintln(os.Stderr, err.Error())
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,504 @@
+package rui
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// CodeChangedEvent is the constant for "code-changed" property tag.
+	// The "code-changed" event occurs when the text of CodeEditor is changed.
+	// The main listener format: func(CodeEditor, CodeEdit), where the second argument describes the changed range.
+	CodeChangedEvent = "code-changed"
+	// CodeHighlight is the constant for "code-highlighter" property tag.
+	// The "code-highlighter" property sets the syntax highlighter of CodeEditor.
+	// The value can be CodeHighlighter, CodeTokenRule, or []CodeTokenRule
+	CodeHighlight = "code-highlighter"
+	// ShowLineNumbers is the constant for "show-line-numbers" property tag.
+	// The "show-line-numbers" bool property defines whether the line numbers gutter of CodeEditor is displayed.
+	// The default value is true
+	ShowLineNumbers = "show-line-numbers"
+	// CodeInsertSpaces is the constant for "code-insert-spaces" property tag.
+	// The "code-insert-spaces" bool property defines whether the Tab key inserts spaces (the number is defined
+	// by the "tab-size" property) instead of the tab character. The default value is false
+	CodeInsertSpaces = "code-insert-spaces"
+)
+
+// CodeEdit describes a change of the CodeEditor text: the characters in the range [Start, End)
+// of the old text are replaced by Text. Start and End are offsets in runes
+type CodeEdit struct {
+	// Start - the offset of the first replaced rune
+	Start int
+	// End - the offset after the last replaced rune
+	End int
+	// Text - the inserted text
+	Text string
+}
+
+// CodeEditor - multi-line code editor with line numbers and syntax highlighting
+type CodeEditor interface {
+	View
+	// ApplyEdit replaces the range of the text. The "code-changed" event is fired
+	ApplyEdit(edit CodeEdit)
+	// Selection returns the selected range (in runes). If nothing is selected then start == end is the caret position
+	Selection() (int, int)
+	// SetSelection selects the range (in runes) of the text and scrolls the editor to it
+	SetSelection(start, end int)
+	// Find searches the text starting from the "from" offset (in runes), selects the found text and returns its offset.
+	// If the text is not found then -1 is returned. The search is continued from the text beginning
+	Find(text string, from int, matchCase bool) int
+	// Replace replaces the found text by "replacement". If "all" is false then only the first occurrence
+	// after the selection start is replaced. Returns the number of replacements
+	Replace(text, replacement string, matchCase, all bool) int
+}
+
+type codeEditorData struct {
+	viewData
+	highlighter      CodeHighlighter
+	changedListeners []func(CodeEditor, CodeEdit)
+	selectionStart   int
+	selectionEnd     int
+}
+
+// NewCodeEditor create new CodeEditor object and return it
+func NewCodeEditor(session Session, params Params) CodeEditor {
+	view := new(codeEditorData)
+	view.init(session)
+	setInitParams(view, params)
+	return view
+}
+
+func newCodeEditor(session Session) View {
+	return NewCodeEditor(session, nil)
+}
+
+// Init initialize fields of CodeEditor by default values
+func (editor *codeEditorData) init(session Session) {
+	editor.viewData.init(session)
+	editor.tag = "CodeEditor"
+	editor.systemClass = "ruiCodeEditor"
+	editor.changedListeners = []func(CodeEditor, CodeEdit){}
+}
+
+func (editor *codeEditorData) supportedProperties() []string {
+	return append([]string{
+		Text, CodeHighlight, ShowLineNumbers, CodeInsertSpaces, ReadOnly, CodeChangedEvent,
+	}, editor.viewData.supportedProperties()...)
+}
+
+func (editor *codeEditorData) String() string {
+	return getViewString(editor)
+}
+
+func (editor *codeEditorData) Focusable() bool {
+	return false
+}
+
+func (editor *codeEditorData) Remove(tag string) {
+	editor.remove(strings.ToLower(tag))
+}
+
+func (editor *codeEditorData) remove(tag string) {
+	switch tag {
+	case Text:
+		if text := GetText(editor); text != "" {
+			editor.ApplyEdit(CodeEdit{Start: 0, End: len([]rune(text))})
+		}
+		return
+
+	case CodeHighlight:
+		if editor.highlighter == nil {
+			return
+		}
+		editor.highlighter = nil
+		editor.updateText()
+
+	case CodeChangedEvent:
+		if len(editor.changedListeners) == 0 {
+			return
+		}
+		editor.changedListeners = []func(CodeEditor, CodeEdit){}
+
+	case ShowLineNumbers, CodeInsertSpaces, ReadOnly:
+		if _, ok := editor.properties.Load(tag); !ok {
+			return
+		}
+		editor.properties.Delete(tag)
+		if editor.created {
+			updateInnerHTML(editor.htmlID(), editor.session)
+		}
+
+	case Disabled:
+		editor.viewData.remove(tag)
+		if editor.created {
+			updateInnerHTML(editor.htmlID(), editor.session)
+		}
+		return
+
+	default:
+		editor.viewData.remove(tag)
+		return
+	}
+
+	editor.propertyChangedEvent(tag)
+}
+
+func (editor *codeEditorData) Set(tag string, value any) bool {
+	return editor.set(strings.ToLower(tag), value)
+}
+
+func (editor *codeEditorData) set(tag string, value any) bool {
+	if value == nil {
+		editor.remove(tag)
+		return true
+	}
+
+	switch tag {
+	case Text:
+		text, ok := value.(string)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+		if oldText := GetText(editor); text != oldText {
+			editor.ApplyEdit(CodeEdit{Start: 0, End: len([]rune(oldText)), Text: text})
+		}
+		return true
+
+	case CodeHighlight:
+		switch value := value.(type) {
+		case CodeHighlighter:
+			editor.highlighter = value
+
+		case CodeTokenRule:
+			editor.highlighter = NewCodeHighlighter(value)
+
+		case []CodeTokenRule:
+			editor.highlighter = NewCodeHighlighter(value...)
+
+		default:
+			notCompatibleType(tag, value)
+			return false
+		}
+		editor.updateText()
+
+	case CodeChangedEvent:
+		listeners, ok := valueToEventListeners[CodeEditor, CodeEdit](value)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		} else if listeners == nil {
+			listeners = []func(CodeEditor, CodeEdit){}
+		}
+		editor.changedListeners = listeners
+
+	case ShowLineNumbers, CodeInsertSpaces, ReadOnly:
+		if !editor.setBoolProperty(tag, value) {
+			return false
+		}
+		if editor.created {
+			updateInnerHTML(editor.htmlID(), editor.session)
+		}
+
+	case Disabled:
+		if !editor.viewData.set(tag, value) {
+			return false
+		}
+		if editor.created {
+			updateInnerHTML(editor.htmlID(), editor.session)
+		}
+		return true
+
+	default:
+		return editor.viewData.set(tag, value)
+	}
+
+	editor.propertyChangedEvent(tag)
+	return true
+}
+
+func (editor *codeEditorData) Get(tag string) any {
+	return editor.get(strings.ToLower(tag))
+}
+
+func (editor *codeEditorData) get(tag string) any {
+	switch tag {
+	case CodeChangedEvent:
+		return editor.changedListeners
+
+	case CodeHighlight:
+		return editor.highlighter
+	}
+	return editor.viewData.get(tag)
+}
+
+func (editor *codeEditorData) textID() string {
+	return editor.htmlID() + "-text"
+}
+
+// updateText sends the text and its highlighting to the client
+func (editor *codeEditorData) updateText() {
+	if editor.created {
+		text := GetText(editor)
+		editor.session.callFunc("setCodeEditorText", editor.textID(), text, codeHighlightText(text, editor.highlighter))
+	}
+}
+
+// applyEdit changes the text without updating of the client
+func (editor *codeEditorData) applyEdit(edit CodeEdit) bool {
+	text := []rune(GetText(editor))
+	if edit.Start < 0 || edit.End < edit.Start || edit.End > len(text) {
+		ErrorLogF(`Invalid CodeEditor edit range [%d, %d), the text length is %d`, edit.Start, edit.End, len(text))
+		return false
+	}
+
+	newText := string(text[:edit.Start]) + edit.Text + string(text[edit.End:])
+	if newText == "" {
+		editor.properties.Delete(Text)
+	} else {
+		editor.properties.Store(Text, newText)
+	}
+
+	// shift the selection
+	shift := func(pos int) int {
+		if pos >= edit.End {
+			return pos + len([]rune(edit.Text)) - (edit.End - edit.Start)
+		}
+		if pos > edit.Start {
+			return edit.Start
+		}
+		return pos
+	}
+	editor.selectionStart = shift(editor.selectionStart)
+	editor.selectionEnd = shift(editor.selectionEnd)
+
+	for _, listener := range editor.changedListeners {
+		listener(editor, edit)
+	}
+	editor.propertyChangedEvent(Text)
+	return true
+}
+
+func (editor *codeEditorData) ApplyEdit(edit CodeEdit) {
+	if editor.applyEdit(edit) {
+		editor.updateText()
+	}
+}
+
+func (editor *codeEditorData) Selection() (int, int) {
+	return editor.selectionStart, editor.selectionEnd
+}
+
+func (editor *codeEditorData) SetSelection(start, end int) {
+	length := len([]rune(GetText(editor)))
+	start = max(0, min(start, length))
+	end = max(start, min(end, length))
+
+	editor.selectionStart = start
+	editor.selectionEnd = end
+	if editor.created {
+		editor.session.callFunc("setCodeSelection", editor.textID(), start, end)
+	}
+}
+
+// codeFindIndex returns the offset (in runes) of the first occurrence of the text starting from the "from" offset
+func codeFindIndex(text []rune, find []rune, from int, matchCase bool) int {
+	if len(find) == 0 || len(find) > len(text) {
+		return -1
+	}
+
+	equal := func(a, b rune) bool {
+		if matchCase {
+			return a == b
+		}
+		return a == b || strings.EqualFold(string(a), string(b))
+	}
+
+	for i := max(0, from); i <= len(text)-len(find); i++ {
+		found := true
+		for k, ch := range find {
+			if !equal(text[i+k], ch) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return i
+		}
+	}
+	return -1
+}
+
+func (editor *codeEditorData) Find(text string, from int, matchCase bool) int {
+	source := []rune(GetText(editor))
+	find := []rune(text)
+
+	index := codeFindIndex(source, find, from, matchCase)
+	if index < 0 && from > 0 {
+		index = codeFindIndex(source, find, 0, matchCase)
+	}
+
+	if index >= 0 {
+		editor.SetSelection(index, index+len(find))
+	}
+	return index
+}
+
+func (editor *codeEditorData) Replace(text, replacement string, matchCase, all bool) int {
+	find := []rune(text)
+	if len(find) == 0 {
+		return 0
+	}
+
+	if !all {
+		source := []rune(GetText(editor))
+		index := codeFindIndex(source, find, editor.selectionStart, matchCase)
+		if index < 0 {
+			index = codeFindIndex(source, find, 0, matchCase)
+		}
+		if index < 0 {
+			return 0
+		}
+		editor.ApplyEdit(CodeEdit{Start: index, End: index + len(find), Text: replacement})
+		editor.SetSelection(index, index+len([]rune(replacement)))
+		return 1
+	}
+
+	count := 0
+	source := []rune(GetText(editor))
+	shift := len([]rune(replacement)) - len(find)
+	for index := codeFindIndex(source, find, 0, matchCase); index >= 0; index = codeFindIndex(source, find, index, matchCase) {
+		editor.applyEdit(CodeEdit{Start: index + count*shift, End: index + count*shift + len(find), Text: replacement})
+		index += len(find)
+		count++
+	}
+
+	if count > 0 {
+		editor.updateText()
+	}
+	return count
+}
+
+func (editor *codeEditorData) htmlSubviews(self View, buffer *strings.Builder) {
+	text := GetText(editor)
+
+	if IsCodeEditorShowLineNumbers(editor) {
+		buffer.WriteString(`<div id="`)
+		buffer.WriteString(editor.htmlID())
+		buffer.WriteString(`-gutter" class="ruiCodeGutter">`)
+		lineCount := strings.Count(text, "\n") + 1
+		for n := 1; n <= lineCount; n++ {
+			buffer.WriteString(strconv.Itoa(n))
+			buffer.WriteRune('\n')
+		}
+		buffer.WriteString(`</div>`)
+	}
+
+	buffer.WriteString(`<div class="ruiCodeArea"><div id="`)
+	buffer.WriteString(editor.htmlID())
+	buffer.WriteString(`-line" class="ruiCodeCurrentLine"></div><pre id="`)
+	buffer.WriteString(editor.htmlID())
+	buffer.WriteString(`-code" class="ruiCodeHighlight" aria-hidden="true">`)
+	buffer.WriteString(codeHighlightText(text, editor.highlighter))
+	buffer.WriteString(`</pre><textarea id="`)
+	buffer.WriteString(editor.textID())
+	buffer.WriteString(`" class="ruiCodeText" wrap="off" spellcheck="false" autocomplete="off" autocapitalize="off"`)
+
+	if IsReadOnly(editor) {
+		buffer.WriteString(` readonly`)
+	}
+	if IsDisabled(editor) {
+		buffer.WriteString(` disabled`)
+	}
+	if IsCodeEditorInsertSpaces(editor) {
+		buffer.WriteString(` data-tab="`)
+		buffer.WriteString(strings.Repeat(" ", max(1, intStyledProperty(editor, nil, TabSize, 4))))
+		buffer.WriteRune('"')
+	}
+
+	buffer.WriteString(` oninput="codeEditorInputEvent(this)" onkeydown="codeEditorKeyDownEvent(this, event)"`)
+	buffer.WriteString(` onkeyup="codeEditorSelectionEvent(this)" onmouseup="codeEditorSelectionEvent(this)"`)
+	buffer.WriteString(` onfocus="codeEditorSelectionEvent(this)" onscroll="codeEditorScrollEvent(this)">`)
+	buffer.WriteString(htmlEscape(text))
+	buffer.WriteString(`</textarea></div>`)
+}
+
+func (editor *codeEditorData) handleCommand(self View, command string, data DataObject) bool {
+	switch command {
+	case "codeEdit":
+		start, ok1 := dataIntProperty(data, "start")
+		end, ok2 := dataIntProperty(data, "end")
+		text, _ := data.PropertyValue("text")
+		if !ok1 || !ok2 || !editor.applyEdit(CodeEdit{Start: start, End: end, Text: text}) {
+			// the client text is out of sync
+			editor.updateText()
+			return true
+		}
+
+		if version, ok := data.PropertyValue("version"); ok && editor.highlighter != nil {
+			editor.session.callFunc("setCodeHighlight", editor.textID(), version, codeHighlightText(GetText(editor), editor.highlighter))
+		}
+
+	case "codeSelection":
+		length := len([]rune(GetText(editor)))
+		if start, ok := dataIntProperty(data, "start"); ok {
+			editor.selectionStart = max(0, min(start, length))
+		}
+		if end, ok := dataIntProperty(data, "end"); ok {
+			editor.selectionEnd = max(editor.selectionStart, min(end, length))
+		}
+
+	default:
+		return editor.viewData.handleCommand(self, command, data)
+	}
+
+	return true
+}
+
+// GetCodeChangedListeners returns the "code-changed" listeners of the CodeEditor.
+// If there are no listeners then the empty list is returned
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetCodeChangedListeners(view View, subviewID ...string) []func(CodeEditor, CodeEdit) {
+	return getEventListeners[CodeEditor, CodeEdit](view, subviewID, CodeChangedEvent)
+}
+
+// GetCodeHighlighter returns the syntax highlighter of the CodeEditor.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetCodeHighlighter(view View, subviewID ...string) CodeHighlighter {
+	if len(subviewID) > 0 && subviewID[0] != "" {
+		view = ViewByID(view, subviewID[0])
+	}
+	if view != nil {
+		if value := view.Get(CodeHighlight); value != nil {
+			if highlighter, ok := value.(CodeHighlighter); ok {
+				return highlighter
+			}
+		}
+	}
+	return nil
+}
+
+// IsCodeEditorShowLineNumbers returns a value of the "show-line-numbers" property of the CodeEditor.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func IsCodeEditorShowLineNumbers(view View, subviewID ...string) bool {
+	if len(subviewID) > 0 && subviewID[0] != "" {
+		view = ViewByID(view, subviewID[0])
+	}
+	if view != nil {
+		if value, ok := boolProperty(view, ShowLineNumbers, view.Session()); ok {
+			return value
+		}
+		if value := valueFromStyle(view, ShowLineNumbers); value != nil {
+			if b, ok := valueToBool(value, view.Session()); ok {
+				return b
+			}
+		}
+	}
+	return true
+}
+
+// IsCodeEditorInsertSpaces returns a value of the "code-insert-spaces" property of the CodeEditor.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func IsCodeEditorInsertSpaces(view View, subviewID ...string) bool {
+	return boolStyledProperty(view, subviewID, CodeInsertSpaces, false)
+}
@@ -0,0 +1,100 @@
+package rui
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestCodeHighlighter(t *testing.T) {
+	highlighter := NewCodeHighlighter(
+		CodeTokenRule{Pattern: regexp.MustCompile(`//.*`), Style: "ruiCodeComment"},
+		CodeTokenRule{Pattern: regexp.MustCompile(`"(?:[^"\\]|\\.)*"`), Style: "ruiCodeString"},
+		NewKeywordsCodeRule("ruiCodeKeyword", "if", "return"),
+		CodeTokenRule{Pattern: regexp.MustCompile(`\b\d+\b`), Style: "ruiCodeNumber"},
+	)
+
+	tests := [][2]string{
+		{`if a < 10 { return "x//y" } // end`,
+			`<span class="ruiCodeKeyword">if</span> a &lt; <span class="ruiCodeNumber">10</span> { ` +
+				`<span class="ruiCodeKeyword">return</span> <span class="ruiCodeString">&quot;x//y&quot;</span> } ` +
+				`<span class="ruiCodeComment">// end</span>` + "\n"},
+		{"iff\nreturn", "iff\n<span class=\"ruiCodeKeyword\">return</span>\n"},
+	}
+
+	for _, test := range tests {
+		if result := codeHighlightText(test[0], highlighter); result != test[1] {
+			t.Errorf("codeHighlightText(%q):\nresult:   %s\nexpected: %s", test[0], result, test[1])
+		}
+	}
+
+	// "^" and "\b" are checked in the context of the whole line, not at the end of the previous token.
+	// The rule which matches the empty string is searched further
+	highlighter = NewCodeHighlighter(
+		CodeTokenRule{Pattern: regexp.MustCompile(`^#\w+`), Style: "ruiCodeKeyword"},
+		CodeTokenRule{Pattern: regexp.MustCompile(`\d*`), Style: "ruiCodeNumber"},
+		NewKeywordsCodeRule("ruiCodeKeyword", "for"),
+	)
+	tokens := highlighter.LineTokens("#if 1for xfor for 2#x")
+	expected := []CodeToken{
+		{Start: 0, End: 3, Style: "ruiCodeKeyword"},
+		{Start: 4, End: 5, Style: "ruiCodeNumber"},
+		{Start: 14, End: 17, Style: "ruiCodeKeyword"},
+		{Start: 18, End: 19, Style: "ruiCodeNumber"},
+	}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("LineTokens: %v, expected: %v", tokens, expected)
+	}
+}
+
+func TestCodeEditorEdit(t *testing.T) {
+	createTestLog(t, false)
+	session := newSession(nil, 0, "", nil)
+	editor := NewCodeEditor(session, Params{Text: "привет world"})
+
+	edits := []CodeEdit{}
+	editor.Set(CodeChangedEvent, func(edit CodeEdit) {
+		edits = append(edits, edit)
+	})
+
+	editor.ApplyEdit(CodeEdit{Start: 7, End: 12, Text: "мир"})
+	if text := GetText(editor); text != "привет мир" {
+		t.Errorf(`GetText: %q`, text)
+	}
+	if len(edits) != 1 || edits[0].Start != 7 || edits[0].End != 12 || edits[0].Text != "мир" {
+		t.Errorf(`code-changed event: %v`, edits)
+	}
+
+	ignoreTestLog = true
+	editor.ApplyEdit(CodeEdit{Start: 8, End: 20, Text: "x"})
+	ignoreTestLog = false
+	if text := GetText(editor); text != "привет мир" || len(edits) != 1 {
+		t.Error("invalid edit range is applied")
+	}
+
+	if index := editor.Find("МИР", 0, false); index != 7 {
+		t.Errorf(`Find("МИР", false) = %d`, index)
+	}
+	if start, end := editor.Selection(); start != 7 || end != 10 {
+		t.Errorf(`Selection() = %d, %d`, start, end)
+	}
+	if index := editor.Find("МИР", 0, true); index != -1 {
+		t.Errorf(`Find("МИР", true) = %d`, index)
+	}
+
+	editor.Set(Text, "a-b-c-a")
+	if count := editor.Replace("a", "xyz", true, true); count != 2 {
+		t.Errorf(`Replace(all) = %d`, count)
+	}
+	if text := GetText(editor); text != "xyz-b-c-xyz" {
+		t.Errorf(`GetText after Replace(all): %q`, text)
+	}
+
+	editor.SetSelection(1, 1)
+	if count := editor.Replace("xyz", "a", true, false); count != 1 {
+		t.Errorf(`Replace() = %d`, count)
+	}
+	if text := GetText(editor); text != "xyz-b-c-a" {
+		t.Errorf(`GetText after Replace: %q`, text)
+	}
+}
@@ -0,0 +1,178 @@
+package rui
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// CodeToken describes a highlighted fragment of a code line
+type CodeToken struct {
+	// Start - the byte offset of the token start in the line
+	Start int
+	// End - the byte offset of the token end in the line
+	End int
+	// Style - the name of the style (CSS class) applied to the token
+	Style string
+}
+
+// CodeHighlighter - the syntax highlighter of CodeEditor
+type CodeHighlighter interface {
+	// LineTokens splits the code line into tokens. Tokens must not overlap and must be sorted by Start.
+	// Text outside of tokens is displayed without a style
+	LineTokens(line string) []CodeToken
+}
+
+// CodeTokenRule - a rule of the regular expression based CodeHighlighter
+type CodeTokenRule struct {
+	// Pattern - the regular expression of the token
+	Pattern *regexp.Regexp
+	// Style - the name of the style (CSS class) applied to the token
+	Style string
+}
+
+type ruleCodeHighlighter struct {
+	rules []CodeTokenRule
+	// contextPatterns - the patterns of the rules preceded by one character of the context:
+	// "(?s:.)(pattern)". They are used to search from the middle of the line, so "^" and "\b"
+	// are checked in the context of the whole line
+	contextPatterns []*regexp.Regexp
+}
+
+// NewCodeHighlighter creates the CodeHighlighter using the list of token rules.
+// The leftmost match wins; if several rules match at the same position then the first rule is used.
+// Patterns are matched in the context of the whole line ("^" matches only at the line start), empty matches are ignored.
+// Predefined styles for tokens are "ruiCodeKeyword", "ruiCodeString", "ruiCodeComment", and "ruiCodeNumber"
+func NewCodeHighlighter(rules ...CodeTokenRule) CodeHighlighter {
+	highlighter := new(ruleCodeHighlighter)
+	highlighter.rules = make([]CodeTokenRule, 0, len(rules))
+	highlighter.contextPatterns = make([]*regexp.Regexp, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Pattern != nil {
+			pattern, err := regexp.Compile(`(?s:.)(` + rule.Pattern.String() + `)`)
+			if err != nil {
+				ErrorLog(err.Error())
+				continue
+			}
+			highlighter.rules = append(highlighter.rules, rule)
+			highlighter.contextPatterns = append(highlighter.contextPatterns, pattern)
+		}
+	}
+	return highlighter
+}
+
+// NewKeywordsCodeRule creates the CodeTokenRule which matches the whole words from the list
+func NewKeywordsCodeRule(style string, keywords ...string) CodeTokenRule {
+	quoted := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		quoted[i] = regexp.QuoteMeta(keyword)
+	}
+	return CodeTokenRule{
+		Pattern: regexp.MustCompile(`\b(?:` + strings.Join(quoted, "|") + `)\b`),
+		Style:   style,
+	}
+}
+
+func (highlighter *ruleCodeHighlighter) LineTokens(line string) []CodeToken {
+	count := len(highlighter.rules)
+	if count == 0 || line == "" {
+		return nil
+	}
+
+	// the next match of each rule at or after the current position
+	matches := make([][]int, count)
+	for i := range highlighter.rules {
+		matches[i] = highlighter.find(i, line, 0)
+	}
+
+	tokens := []CodeToken{}
+	pos := 0
+	for pos < len(line) {
+		best := -1
+		for i, match := range matches {
+			if match != nil && match[0] < pos {
+				// the match is overlapped by the previous token
+				match = highlighter.find(i, line, pos)
+				matches[i] = match
+			}
+			if match != nil && (best < 0 || match[0] < matches[best][0]) {
+				best = i
+			}
+		}
+
+		if best < 0 {
+			break
+		}
+
+		match := matches[best]
+		tokens = append(tokens, CodeToken{Start: match[0], End: match[1], Style: highlighter.rules[best].Style})
+		pos = match[1]
+	}
+
+	return tokens
+}
+
+// find returns the first non-empty match of the rule which starts at or after "pos"
+func (highlighter *ruleCodeHighlighter) find(rule int, line string, pos int) []int {
+	if pos == 0 {
+		for _, match := range highlighter.rules[rule].Pattern.FindAllStringIndex(line, -1) {
+			if match[1] > match[0] {
+				return match
+			}
+		}
+		return nil
+	}
+
+	// the search starts from the previous character which is the context of "^" and "\b"
+	_, size := utf8.DecodeLastRuneInString(line[:pos])
+	start := pos - size
+	for _, match := range highlighter.contextPatterns[rule].FindAllStringSubmatchIndex(line[start:], -1) {
+		if match[3] > match[2] {
+			return []int{match[2] + start, match[3] + start}
+		}
+	}
+	return nil
+}
+
+func codeHighlightLine(line string, highlighter CodeHighlighter, buffer *strings.Builder) {
+	if highlighter == nil {
+		buffer.WriteString(htmlEscape(line))
+		return
+	}
+
+	tokens := highlighter.LineTokens(line)
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].Start < tokens[j].Start
+	})
+
+	pos := 0
+	for _, token := range tokens {
+		if token.Start < pos || token.End <= token.Start || token.End > len(line) {
+			continue
+		}
+		buffer.WriteString(htmlEscape(line[pos:token.Start]))
+		buffer.WriteString(`<span class="`)
+		buffer.WriteString(htmlEscape(token.Style))
+		buffer.WriteString(`">`)
+		buffer.WriteString(htmlEscape(line[token.Start:token.End]))
+		buffer.WriteString(`</span>`)
+		pos = token.End
+	}
+	buffer.WriteString(htmlEscape(line[pos:]))
+}
+
+func codeHighlightText(text string, highlighter CodeHighlighter) string {
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			buffer.WriteRune('\n')
+		}
+		codeHighlightLine(line, highlighter, buffer)
+	}
+	// the trailing line break keeps the height of the highlighted text equal to the textarea content
+	buffer.WriteRune('\n')
+	return buffer.String()
+}
@@ -38,6 +38,12 @@ func (picker *colorPickerData) init(session Session) {
 	picker.properties.Store(Padding, Px(0))
 }
 
+func (picker *colorPickerData) supportedProperties() []string {
+	return append([]string{
+		ColorPickerValue, ColorChangedEvent,
+	}, picker.viewData.supportedProperties()...)
+}
+
 func (picker *colorPickerData) String() string {
 	return getViewString(picker)
 }
@@ -80,6 +80,12 @@ func (ColumnLayout *columnLayoutData) init(session Session) {
 	//ColumnLayout.systemClass = "ruiColumnLayout"
 }
 
+func (ColumnLayout *columnLayoutData) supportedProperties() []string {
+	return append([]string{
+		ColumnCount, ColumnWidth, ColumnGap, ColumnFill, ColumnSeparator,
+	}, ColumnLayout.viewsContainerData.supportedProperties()...)
+}
+
 func (columnLayout *columnLayoutData) String() string {
 	return getViewString(columnLayout)
 }
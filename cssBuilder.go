@@ -184,13 +184,26 @@ func (builder *cssStyleBuilder) endMedia() {
 	builder.media = false
 }
 
-func (builder *cssStyleBuilder) startStyle(name string) {
+// cssStyleSelector returns the CSS selector of the style or an empty string for the disabled styles
+func cssStyleSelector(name string) string {
 	for _, disabledName := range disabledStyles {
 		if name == disabledName {
-			return
+			return ""
 		}
 	}
 
+	if sysName, ok := systemStyles[name]; ok {
+		return sysName
+	}
+	return "." + name
+}
+
+func (builder *cssStyleBuilder) startStyle(name string) {
+	selector := cssStyleSelector(name)
+	if selector == "" {
+		return
+	}
+
 	if builder.buffer == nil {
 		builder.init()
 	}
@@ -198,13 +211,7 @@ func (builder *cssStyleBuilder) startStyle(name string) {
 		builder.buffer.WriteString(`\t`)
 	}
 
-	if sysName, ok := systemStyles[name]; ok {
-		builder.buffer.WriteString(sysName)
-	} else {
-		builder.buffer.WriteRune('.')
-		builder.buffer.WriteString(name)
-	}
-
+	builder.buffer.WriteString(selector)
 	builder.buffer.WriteString(` {\n`)
 }
 
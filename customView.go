@@ -172,6 +172,10 @@ func (customView *CustomViewData) htmlTag() string {
 	return customView.superView.htmlTag()
 }
 
+func (customView *CustomViewData) supportedProperties() []string {
+	return customView.superView.supportedProperties()
+}
+
 func (customView *CustomViewData) closeHTMLTag() bool {
 	return customView.superView.closeHTMLTag()
 }
@@ -1,6 +1,7 @@
 package rui
 
 import (
+	"fmt"
 	"strings"
 	"unicode"
 )
@@ -68,6 +69,8 @@ func (value *dataStringValue) Object() DataObject {
 type dataObject struct {
 	tag      string
 	property []DataNode
+	line     int
+	column   int
 }
 
 // NewDataObject create new DataObject with the tag and empty property list
@@ -205,9 +208,11 @@ func (object *dataObject) ToParams() Params {
 
 /******************************************************************************/
 type dataNode struct {
-	tag   string
-	value DataValue
-	array []DataValue
+	tag    string
+	value  DataValue
+	array  []DataValue
+	line   int
+	column int
 }
 
 func (node *dataNode) Tag() string {
@@ -277,6 +282,14 @@ func (node *dataNode) ArrayAsParams() []Params {
 
 // ParseDataText - parse text and return DataNode
 func ParseDataText(text string) DataObject {
+	return parseDataText(text, func(line, column int, message string) {
+		ErrorLogF("%s (line: %d, position: %d)", message, line, column-1)
+	})
+}
+
+// parseDataText parses the text. Each syntax error is passed to the errorFunc function
+// with the line and the column (starting from 1) where the error is found
+func parseDataText(text string, errorFunc func(line, column int, message string)) DataObject {
 
 	if strings.ContainsAny(text, "\r") {
 		text = strings.Replace(text, "\r\n", "\n", -1)
@@ -288,6 +301,10 @@ func ParseDataText(text string) DataObject {
 	line := 1
 	lineStart := 0
 
+	parseError := func(message string) {
+		errorFunc(line, pos-lineStart+1, message)
+	}
+
 	skipSpaces := func(skipNewLine bool) {
 		for pos < size {
 			switch data[pos] {
@@ -312,7 +329,7 @@ func ParseDataText(text string) DataObject {
 						pos += 3
 						for {
 							if pos >= size {
-								ErrorLog("Unexpected end of file")
+								parseError("Unexpected end of file")
 								return
 							}
 							if data[pos-1] == '*' && data[pos] == '/' {
@@ -351,7 +368,7 @@ func ParseDataText(text string) DataObject {
 			for data[pos] != '`' {
 				pos++
 				if pos >= size {
-					ErrorLog("Unexpected end of text")
+					parseError("Unexpected end of text")
 					return string(data[startPos:size]), false
 				}
 			}
@@ -373,7 +390,7 @@ func ParseDataText(text string) DataObject {
 					pos++
 				}
 				if pos >= size {
-					ErrorLog("Unexpected end of text")
+					parseError("Unexpected end of text")
 					return string(data[startPos:size]), false
 				}
 			}
@@ -392,7 +409,7 @@ func ParseDataText(text string) DataObject {
 			invalidEscape := func() (string, bool) {
 				str := string(data[startPos:pos])
 				pos++
-				ErrorLogF("Invalid escape sequence in \"%s\" (position %d)", str, n2-2-startPos)
+				parseError(fmt.Sprintf("Invalid escape sequence in \"%s\" (position %d)", str, n2-2-startPos))
 				return str, false
 			}
 
@@ -463,7 +480,7 @@ func ParseDataText(text string) DataObject {
 
 					default:
 						str := string(data[startPos:pos])
-						ErrorLogF("Invalid escape sequence in \"%s\" (position %d)", str, n2-2-startPos)
+						parseError(fmt.Sprintf("Invalid escape sequence in \"%s\" (position %d)", str, n2-2-startPos))
 						return str, false
 					}
 				}
@@ -500,29 +517,33 @@ func ParseDataText(text string) DataObject {
 		return string(data[startPos:endPos]), true
 	}
 
-	var parseObject func(tag string) DataObject
+	var parseObject func(tag string, tagLine, tagColumn int) DataObject
 	var parseArray func() []DataValue
 
 	parseNode := func() DataNode {
 		var tag string
 		var ok bool
 
+		skipSpaces(true)
+		nodeLine, nodeColumn := line, pos-lineStart+1
 		if tag, ok = parseTag(); !ok {
 			return nil
 		}
 
 		skipSpaces(true)
 		if data[pos] != '=' {
-			ErrorLogF("expected '=' after a tag name (line: %d, position: %d)", line, pos-lineStart)
+			parseError("expected '=' after a tag name")
 			return nil
 		}
 
 		pos++
 		skipSpaces(true)
+		valueLine, valueColumn := line, pos-lineStart+1
 		switch data[pos] {
 		case '[':
 			node := new(dataNode)
 			node.tag = tag
+			node.line, node.column = nodeLine, nodeColumn
 
 			if node.array = parseArray(); node.array == nil {
 				return nil
@@ -532,13 +553,14 @@ func ParseDataText(text string) DataObject {
 		case '{':
 			node := new(dataNode)
 			node.tag = tag
-			if node.value = parseObject("_"); node.value == nil {
+			node.line, node.column = nodeLine, nodeColumn
+			if node.value = parseObject("_", valueLine, valueColumn); node.value == nil {
 				return nil
 			}
 			return node
 
 		case '}', ']', '=':
-			ErrorLogF("Expected '[', '{' or a tag name after '=' (line: %d, position: %d)", line, pos-lineStart)
+			parseError("Expected '[', '{' or a tag name after '='")
 			return nil
 
 		default:
@@ -549,9 +571,10 @@ func ParseDataText(text string) DataObject {
 
 			node := new(dataNode)
 			node.tag = tag
+			node.line, node.column = nodeLine, nodeColumn
 
 			if data[pos] == '{' {
-				if node.value = parseObject(str); node.value == nil {
+				if node.value = parseObject(str, valueLine, valueColumn); node.value == nil {
 					return nil
 				}
 			} else {
@@ -564,9 +587,9 @@ func ParseDataText(text string) DataObject {
 		}
 	}
 
-	parseObject = func(tag string) DataObject {
+	parseObject = func(tag string, tagLine, tagColumn int) DataObject {
 		if data[pos] != '{' {
-			ErrorLogF("Expected '{' (line: %d, position: %d)", line, pos-lineStart)
+			parseError("Expected '{'")
 			return nil
 		}
 		pos++
@@ -574,6 +597,7 @@ func ParseDataText(text string) DataObject {
 		obj := new(dataObject)
 		obj.tag = tag
 		obj.property = []DataNode{}
+		obj.line, obj.column = tagLine, tagColumn
 
 		for pos < size {
 			var node DataNode
@@ -594,7 +618,7 @@ func ParseDataText(text string) DataObject {
 				skipSpaces(true)
 				return obj
 			} else if data[pos] != ',' && data[pos] != '\n' {
-				ErrorLogF(`Expected '}', '\n' or ',' (line: %d, position: %d)`, line, pos-lineStart)
+				parseError(`Expected '}', '\n' or ','`)
 				return nil
 			}
 			if data[pos] != '\n' {
@@ -607,7 +631,7 @@ func ParseDataText(text string) DataObject {
 			}
 		}
 
-		ErrorLog("Unexpected end of text")
+		parseError("Unexpected end of text")
 		return nil
 	}
 
@@ -637,12 +661,13 @@ func ParseDataText(text string) DataObject {
 				return array
 			}
 
+			elementLine, elementColumn := line, pos-lineStart+1
 			if tag, ok = parseTag(); !ok {
 				return nil
 			}
 
 			if data[pos] == '{' {
-				obj := parseObject(tag)
+				obj := parseObject(tag, elementLine, elementColumn)
 				if obj == nil {
 					return nil
 				}
@@ -657,7 +682,7 @@ func ParseDataText(text string) DataObject {
 			case ']', ',', '\n':
 
 			default:
-				ErrorLogF("Expected ']' or ',' (line: %d, position: %d)", line, pos-lineStart)
+				parseError("Expected ']' or ','")
 				return nil
 			}
 
@@ -674,12 +699,14 @@ func ParseDataText(text string) DataObject {
 			*/
 		}
 
-		ErrorLog("Unexpected end of text")
+		parseError("Unexpected end of text")
 		return nil
 	}
 
+	skipSpaces(true)
+	tagLine, tagColumn := line, pos-lineStart+1
 	if tag, ok := parseTag(); ok {
-		return parseObject(tag)
+		return parseObject(tag, tagLine, tagColumn)
 	}
 	return nil
 }
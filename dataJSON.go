@@ -0,0 +1,244 @@
+package rui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DataTagKey is the key of the object tag in the JSON and YAML representations of DataObject.
+// For example, the .rui text "TextView { text = Hello }" corresponds to the JSON text
+// {"_tag": "TextView", "text": "Hello"}. The key is omitted for objects with the "_" tag
+const DataTagKey = "_tag"
+
+// ParseDataJSON parses the JSON text and returns DataObject. The root JSON value must be an object.
+// The "_tag" key (DataTagKey constant) of a JSON object sets the tag of DataObject, if it is absent the "_" tag is used.
+// Numbers and boolean values are converted to text, null values are ignored.
+// Arrays of arrays are not supported. In case of an error, the error is written to the log and nil is returned
+func ParseDataJSON(text string) DataObject {
+	object, err := parseDataJSON(text)
+	if err != nil {
+		ErrorLog(err.Error())
+		return nil
+	}
+	return object
+}
+
+func parseDataJSON(text string) (DataObject, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+
+	jsonError := func(err error) error {
+		offset := int(decoder.InputOffset())
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			offset = int(syntaxErr.Offset)
+		}
+		if offset > len(text) {
+			offset = len(text)
+		}
+		line := strings.Count(text[:offset], "\n") + 1
+		return fmt.Errorf("JSON error: %s (line: %d)", err.Error(), line)
+	}
+
+	var decodeValue func(inArray bool) (any, error)
+
+	decodeObject := func() (DataObject, error) {
+		object := new(dataObject)
+		object.tag = "_"
+		object.property = []DataNode{}
+
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, jsonError(err)
+			}
+			key, ok := token.(string)
+			if !ok {
+				return nil, jsonError(errors.New("expected an object key"))
+			}
+
+			value, err := decodeValue(false)
+			if err != nil {
+				return nil, err
+			}
+
+			if key == DataTagKey {
+				if tag, ok := value.(string); ok && tag != "" {
+					object.tag = tag
+				} else {
+					return nil, jsonError(errors.New(`the value of "` + DataTagKey + `" key must be a string`))
+				}
+				continue
+			}
+
+			node := new(dataNode)
+			node.tag = key
+			switch value := value.(type) {
+			case nil:
+				continue
+
+			case string:
+				node.value = &dataStringValue{value: value}
+
+			case DataObject:
+				node.value = value
+
+			case []DataValue:
+				node.array = value
+			}
+			object.property = append(object.property, node)
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			return nil, jsonError(err)
+		}
+		return object, nil
+	}
+
+	decodeValue = func(inArray bool) (any, error) {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, jsonError(err)
+		}
+
+		switch token := token.(type) {
+		case json.Delim:
+			switch token {
+			case '{':
+				return decodeObject()
+
+			case '[':
+				if inArray {
+					return nil, jsonError(errors.New("arrays of arrays are not supported"))
+				}
+				array := []DataValue{}
+				for decoder.More() {
+					value, err := decodeValue(true)
+					if err != nil {
+						return nil, err
+					}
+					switch value := value.(type) {
+					case string:
+						array = append(array, &dataStringValue{value: value})
+
+					case DataObject:
+						array = append(array, value)
+					}
+				}
+				if _, err := decoder.Token(); err != nil {
+					return nil, jsonError(err)
+				}
+				return array, nil
+			}
+
+		case string:
+			return token, nil
+
+		case json.Number:
+			return token.String(), nil
+
+		case bool:
+			if token {
+				return "true", nil
+			}
+			return "false", nil
+
+		case nil:
+			return nil, nil
+		}
+
+		return nil, jsonError(fmt.Errorf("unexpected token %v", token))
+	}
+
+	value, err := decodeValue(false)
+	if err != nil {
+		return nil, err
+	}
+
+	object, ok := value.(DataObject)
+	if !ok {
+		return nil, jsonError(errors.New("the root value must be an object"))
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, jsonError(errors.New("unexpected data after the root object"))
+	}
+	return object, nil
+}
+
+// DataObjectToJSON returns the JSON representation of DataObject.
+// The properties are written in the order of DataObject, all values are written as strings.
+// ParseDataJSON restores the same DataObject from the result
+func DataObjectToJSON(object DataObject) string {
+	if object == nil {
+		return ""
+	}
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	writeString := func(text string) {
+		data, _ := json.Marshal(text)
+		buffer.Write(data)
+	}
+
+	var writeObject func(object DataObject)
+	writeObject = func(object DataObject) {
+		buffer.WriteRune('{')
+		comma := false
+		if tag := object.Tag(); tag != "_" && tag != "" {
+			writeString(DataTagKey)
+			buffer.WriteRune(':')
+			writeString(tag)
+			comma = true
+		}
+
+		count := object.PropertyCount()
+		for i := 0; i < count; i++ {
+			node := object.Property(i)
+			if node == nil {
+				continue
+			}
+			if comma {
+				buffer.WriteRune(',')
+			}
+			comma = true
+
+			writeString(node.Tag())
+			buffer.WriteRune(':')
+			switch node.Type() {
+			case TextNode:
+				writeString(node.Text())
+
+			case ObjectNode:
+				writeObject(node.Object())
+
+			case ArrayNode:
+				buffer.WriteRune('[')
+				for n, element := range node.ArrayElements() {
+					if n > 0 {
+						buffer.WriteRune(',')
+					}
+					if element.IsObject() {
+						writeObject(element.Object())
+					} else {
+						writeString(element.Value())
+					}
+				}
+				buffer.WriteRune(']')
+			}
+		}
+		buffer.WriteRune('}')
+	}
+
+	writeObject(object)
+
+	var result bytes.Buffer
+	if err := json.Indent(&result, []byte(buffer.String()), "", "\t"); err != nil {
+		return buffer.String()
+	}
+	return result.String()
+}
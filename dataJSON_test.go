@@ -0,0 +1,119 @@
+package rui
+
+import (
+	"testing"
+)
+
+const dataFormatsTestText = `ListLayout {
+	id = root,
+	orientation = up-down,
+	"key with spaces" = "text: with # special \"chars\"\n\tsecond line",
+	number = "1.50",
+	bool = true,
+	empty = "",
+	content = [
+		TextView { text = Hello },
+		"plain",
+		_{ },
+		Button { },
+	],
+	border = _{ style = solid, width = 1px, color = "#FF000000" },
+	empty-object = _{},
+	empty-array = [],
+}`
+
+func equalDataObjects(obj1, obj2 DataObject) bool {
+	if obj1.Tag() != obj2.Tag() || obj1.PropertyCount() != obj2.PropertyCount() {
+		return false
+	}
+
+	equalValues := func(value1, value2 DataValue) bool {
+		if value1.IsObject() != value2.IsObject() {
+			return false
+		}
+		if value1.IsObject() {
+			return equalDataObjects(value1.Object(), value2.Object())
+		}
+		return value1.Value() == value2.Value()
+	}
+
+	for i := 0; i < obj1.PropertyCount(); i++ {
+		node1 := obj1.Property(i)
+		node2 := obj2.Property(i)
+		if node1.Tag() != node2.Tag() || node1.Type() != node2.Type() {
+			return false
+		}
+
+		switch node1.Type() {
+		case TextNode:
+			if node1.Text() != node2.Text() {
+				return false
+			}
+
+		case ObjectNode:
+			if !equalDataObjects(node1.Object(), node2.Object()) {
+				return false
+			}
+
+		case ArrayNode:
+			if node1.ArraySize() != node2.ArraySize() {
+				return false
+			}
+			for n := 0; n < node1.ArraySize(); n++ {
+				if !equalValues(node1.ArrayElement(n), node2.ArrayElement(n)) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+func TestDataJSON(t *testing.T) {
+	createTestLog(t, false)
+
+	object := ParseDataText(dataFormatsTestText)
+	if object == nil {
+		t.Fatal("ParseDataText error")
+	}
+
+	text := DataObjectToJSON(object)
+	if restored := ParseDataJSON(text); restored == nil || !equalDataObjects(object, restored) {
+		t.Errorf("JSON round trip error:\n%s", text)
+	}
+
+	object = ParseDataJSON(`{
+		"_tag": "EditView",
+		"max-length": 10,
+		"read-only": false,
+		"tooltip": null,
+		"items": ["a", 2, {"_tag": "TextView", "text": "b"}]
+	}`)
+	if object == nil {
+		t.Fatal("ParseDataJSON error")
+	}
+	if object.Tag() != "EditView" || object.PropertyCount() != 3 {
+		t.Errorf("ParseDataJSON: tag = %s, property count = %d", object.Tag(), object.PropertyCount())
+	}
+	if value, _ := object.PropertyValue("max-length"); value != "10" {
+		t.Errorf(`ParseDataJSON: max-length = "%s"`, value)
+	}
+	if node := object.PropertyByTag("items"); node == nil || node.ArraySize() != 3 ||
+		node.ArrayElement(1).Value() != "2" || node.ArrayElement(2).Object().Tag() != "TextView" {
+		t.Error("ParseDataJSON: invalid array")
+	}
+
+	ignoreTestLog = true
+	for _, text := range []string{`[]`, `{"a": [[1]]}`, `{"a": 1`, `{} {}`} {
+		if ParseDataJSON(text) != nil {
+			t.Errorf(`ParseDataJSON(%s) must fail`, text)
+		}
+	}
+	ignoreTestLog = false
+
+	session := newSession(nil, 0, "", nil)
+	view := CreateViewFromObject(session, ParseDataJSON(`{"_tag": "TextView", "id": "json", "text": "From JSON"}`))
+	if view == nil || GetText(view) != "From JSON" {
+		t.Error("CreateViewFromObject from JSON error")
+	}
+}
@@ -0,0 +1,838 @@
+package rui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseDataYAML parses the YAML text and returns DataObject. The root YAML value must be a mapping.
+// The "_tag" key (DataTagKey constant) of a mapping sets the tag of DataObject, if it is absent the "_" tag is used.
+// The following subset of YAML is supported: block mappings and sequences, flow mappings and sequences,
+// plain, single-quoted and double-quoted scalars, literal ("|") and folded (">") block scalars, and comments.
+// Anchors, aliases, tags and multi-document streams are not supported. Null values are ignored.
+// In case of an error, the error is written to the log and nil is returned
+func ParseDataYAML(text string) DataObject {
+	object, err := parseDataYAML(text)
+	if err != nil {
+		ErrorLog(err.Error())
+		return nil
+	}
+	return object
+}
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	index int
+}
+
+func yamlError(line int, format string, args ...any) error {
+	return fmt.Errorf("YAML error: %s (line: %d)", fmt.Sprintf(format, args...), line)
+}
+
+func parseDataYAML(text string) (DataObject, error) {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	parser := new(yamlParser)
+
+	for i, line := range strings.Split(text, "\n") {
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+		if strings.HasPrefix(content, "\t") {
+			return nil, yamlError(i+1, "tabs are not allowed in indentation")
+		}
+		if i == 0 && (content == "---" || strings.HasPrefix(content, "--- ")) {
+			continue
+		}
+		if content == "..." {
+			break
+		}
+		parser.lines = append(parser.lines, yamlLine{number: i + 1, indent: indent, text: strings.TrimRight(content, " \t")})
+	}
+
+	parser.skipEmpty()
+	if parser.index >= len(parser.lines) {
+		return nil, yamlError(1, "empty document")
+	}
+
+	first := parser.lines[parser.index]
+	value, err := parser.parseBlock(first.indent)
+	if err != nil {
+		return nil, err
+	}
+
+	parser.skipEmpty()
+	if parser.index < len(parser.lines) {
+		return nil, yamlError(parser.lines[parser.index].number, "unexpected indentation")
+	}
+
+	object, ok := value.(DataObject)
+	if !ok {
+		return nil, yamlError(first.number, "the root value must be a mapping")
+	}
+	return object, nil
+}
+
+// stripYAMLComment removes a comment ("#" at the start of the text or after a space) outside of quotes
+func stripYAMLComment(text string) string {
+	quote := byte(0)
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' && quote == '"' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+
+		case ch == '"' || ch == '\'':
+			// a quote starts a quoted scalar only at the beginning of a value
+			if i == 0 || strings.IndexByte(" [{,:", text[i-1]) >= 0 {
+				quote = ch
+			}
+
+		case ch == '#':
+			if i == 0 || text[i-1] == ' ' || text[i-1] == '\t' {
+				return strings.TrimRight(text[:i], " \t")
+			}
+		}
+	}
+	return text
+}
+
+func (parser *yamlParser) skipEmpty() {
+	for parser.index < len(parser.lines) {
+		line := &parser.lines[parser.index]
+		if text := stripYAMLComment(line.text); text != "" {
+			return
+		}
+		parser.index++
+	}
+}
+
+func (parser *yamlParser) current() *yamlLine {
+	parser.skipEmpty()
+	if parser.index < len(parser.lines) {
+		return &parser.lines[parser.index]
+	}
+	return nil
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// findYAMLKey returns the position of the ':' which separates a key and a value, or -1
+func findYAMLKey(text string) int {
+	if text == "" {
+		return -1
+	}
+
+	if text[0] == '"' || text[0] == '\'' {
+		if _, end, err := unquoteYAML(text); err == nil {
+			rest := text[end:]
+			trimmed := strings.TrimLeft(rest, " ")
+			if strings.HasPrefix(trimmed, ":") {
+				n := end + len(rest) - len(trimmed)
+				if n+1 == len(text) || text[n+1] == ' ' {
+					return n
+				}
+			}
+		}
+		return -1
+	}
+
+	if text[0] == '[' || text[0] == '{' {
+		return -1
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return i
+		}
+		if text[i] == '#' && i > 0 && text[i-1] == ' ' {
+			return -1
+		}
+	}
+	return -1
+}
+
+func (parser *yamlParser) parseBlock(indent int) (any, error) {
+	line := parser.current()
+	if line == nil {
+		return nil, nil
+	}
+
+	text := stripYAMLComment(line.text)
+	if isYAMLSequenceItem(text) {
+		return parser.parseSequence(line.indent)
+	}
+	if findYAMLKey(text) >= 0 {
+		return parser.parseMapping(line.indent)
+	}
+
+	parser.index++
+	return parser.parseInlineValue(text, line.number, indent)
+}
+
+func (parser *yamlParser) parseMapping(indent int) (DataObject, error) {
+	object := new(dataObject)
+	object.tag = "_"
+	object.property = []DataNode{}
+
+	for {
+		line := parser.current()
+		if line == nil || line.indent < indent {
+			break
+		}
+
+		text := stripYAMLComment(line.text)
+		if line.indent > indent {
+			return nil, yamlError(line.number, "unexpected indentation")
+		}
+		if isYAMLSequenceItem(text) {
+			break
+		}
+
+		n := findYAMLKey(text)
+		if n < 0 {
+			return nil, yamlError(line.number, "expected a mapping key")
+		}
+
+		key := strings.TrimRight(text[:n], " ")
+		if key != "" && (key[0] == '"' || key[0] == '\'') {
+			var err error
+			if key, _, err = unquoteYAML(key); err != nil {
+				return nil, yamlError(line.number, err.Error())
+			}
+		}
+
+		rest := strings.TrimLeft(text[n+1:], " ")
+		parser.index++
+
+		var value any
+		var err error
+		if rest == "" {
+			if next := parser.current(); next != nil &&
+				(next.indent > indent || (next.indent == indent && isYAMLSequenceItem(stripYAMLComment(next.text)))) {
+				value, err = parser.parseBlock(next.indent)
+			}
+		} else {
+			value, err = parser.parseInlineValue(rest, line.number, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if key == DataTagKey {
+			tag, ok := value.(string)
+			if !ok || tag == "" {
+				return nil, yamlError(line.number, `the value of "%s" key must be a string`, DataTagKey)
+			}
+			object.tag = tag
+			continue
+		}
+
+		if node := newYAMLNode(key, value); node != nil {
+			object.property = append(object.property, node)
+		}
+	}
+
+	return object, nil
+}
+
+func newYAMLNode(key string, value any) DataNode {
+	node := new(dataNode)
+	node.tag = key
+	switch value := value.(type) {
+	case string:
+		node.value = &dataStringValue{value: value}
+
+	case DataObject:
+		node.value = value
+
+	case []DataValue:
+		node.array = value
+
+	default:
+		return nil
+	}
+	return node
+}
+
+func (parser *yamlParser) parseSequence(indent int) ([]DataValue, error) {
+	array := []DataValue{}
+
+	for {
+		line := parser.current()
+		if line == nil || line.indent < indent {
+			break
+		}
+
+		text := stripYAMLComment(line.text)
+		if line.indent > indent {
+			return nil, yamlError(line.number, "unexpected indentation")
+		}
+		if !isYAMLSequenceItem(text) {
+			break
+		}
+
+		var value any
+		var err error
+		rest := strings.TrimLeft(strings.TrimPrefix(text, "-"), " ")
+		if rest == "" {
+			parser.index++
+			if next := parser.current(); next != nil && next.indent > indent {
+				value, err = parser.parseBlock(next.indent)
+			}
+		} else {
+			offset := len(text) - len(rest)
+			if isYAMLSequenceItem(rest) {
+				return nil, yamlError(line.number, "arrays of arrays are not supported")
+			}
+			if findYAMLKey(rest) >= 0 {
+				// the mapping starts in the line of the sequence item
+				line.indent += offset
+				line.text = rest
+				value, err = parser.parseMapping(line.indent)
+			} else {
+				parser.index++
+				value, err = parser.parseInlineValue(rest, line.number, indent)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch value := value.(type) {
+		case string:
+			array = append(array, &dataStringValue{value: value})
+
+		case DataObject:
+			array = append(array, value)
+
+		case []DataValue:
+			return nil, yamlError(line.number, "arrays of arrays are not supported")
+		}
+	}
+
+	return array, nil
+}
+
+// parseInlineValue parses the value which starts in the line of a key or a sequence item
+func (parser *yamlParser) parseInlineValue(text string, lineNumber, indent int) (any, error) {
+	switch text[0] {
+	case '|', '>':
+		return parser.parseBlockScalar(text, lineNumber, indent)
+
+	case '[', '{':
+		// a flow collection can take several lines
+		for !isYAMLFlowComplete(text) {
+			line := parser.current()
+			if line == nil {
+				return nil, yamlError(lineNumber, "unexpected end of the flow collection")
+			}
+			text += " " + stripYAMLComment(line.text)
+			parser.index++
+		}
+		flow := yamlFlowParser{text: text, line: lineNumber}
+		value, err := flow.parseValue(false)
+		if err == nil {
+			flow.skipSpaces()
+			if flow.pos < len(flow.text) {
+				err = yamlError(lineNumber, "unexpected text after the flow collection")
+			}
+		}
+		return value, err
+
+	case '"', '\'':
+		value, end, err := unquoteYAML(text)
+		if err != nil {
+			return nil, yamlError(lineNumber, err.Error())
+		}
+		if strings.TrimSpace(text[end:]) != "" {
+			return nil, yamlError(lineNumber, "unexpected text after the quoted scalar")
+		}
+		return value, nil
+
+	case '&', '*', '!':
+		return nil, yamlError(lineNumber, "anchors, aliases and tags are not supported")
+	}
+
+	if text == "~" || text == "null" || text == "Null" || text == "NULL" {
+		return nil, nil
+	}
+
+	// a plain scalar can be continued in the following more indented lines
+	for {
+		line := parser.current()
+		if line == nil || line.indent <= indent {
+			break
+		}
+		next := stripYAMLComment(line.text)
+		if findYAMLKey(next) >= 0 || isYAMLSequenceItem(next) {
+			break
+		}
+		text += " " + next
+		parser.index++
+	}
+	return text, nil
+}
+
+func isYAMLFlowComplete(text string) bool {
+	depth := 0
+	quote := byte(0)
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' && quote == '"' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+
+		case ch == '"' || ch == '\'':
+			quote = ch
+
+		case ch == '[' || ch == '{':
+			depth++
+
+		case ch == ']' || ch == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+func (parser *yamlParser) parseBlockScalar(header string, lineNumber, indent int) (string, error) {
+	folded := header[0] == '>'
+	chomping := byte(0)
+	if len(header) > 1 {
+		switch header[1] {
+		case '-', '+':
+			chomping = header[1]
+		default:
+			if strings.TrimSpace(header[1:]) != "" {
+				return "", yamlError(lineNumber, "unsupported block scalar header")
+			}
+		}
+	}
+
+	lines := []string{}
+	blockIndent := -1
+	for parser.index < len(parser.lines) {
+		line := parser.lines[parser.index]
+		if line.text == "" {
+			lines = append(lines, "")
+			parser.index++
+			continue
+		}
+		if line.indent <= indent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = line.indent
+		} else if line.indent < blockIndent {
+			break
+		}
+		lines = append(lines, strings.Repeat(" ", line.indent-blockIndent)+line.text)
+		parser.index++
+	}
+
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var result string
+	if folded {
+		buffer := allocStringBuilder()
+		defer freeStringBuilder(buffer)
+		for i, line := range lines {
+			if i > 0 {
+				switch prev := lines[i-1]; {
+				case line == "":
+					buffer.WriteRune('\n')
+
+				case prev == "":
+					// the line break is already written by the empty line
+
+				case strings.HasPrefix(line, " ") || strings.HasPrefix(prev, " "):
+					buffer.WriteRune('\n')
+
+				default:
+					buffer.WriteRune(' ')
+				}
+			}
+			buffer.WriteString(line)
+		}
+		result = buffer.String()
+	} else {
+		result = strings.Join(lines, "\n")
+	}
+
+	switch chomping {
+	case '-':
+		return result, nil
+
+	case '+':
+		return result + "\n" + strings.Repeat("\n", trailing), nil
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return result + "\n", nil
+}
+
+// unquoteYAML decodes the quoted scalar at the start of the text.
+// It returns the value and the position after the closing quote
+func unquoteYAML(text string) (string, int, error) {
+	quote := text[0]
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	for i := 1; i < len(text); i++ {
+		ch := text[i]
+		if ch == quote {
+			if quote == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+				buffer.WriteByte('\'')
+				i++
+				continue
+			}
+			return buffer.String(), i + 1, nil
+		}
+
+		if ch != '\\' || quote == '\'' {
+			buffer.WriteByte(ch)
+			continue
+		}
+
+		i++
+		if i >= len(text) {
+			break
+		}
+		switch text[i] {
+		case 'n':
+			buffer.WriteByte('\n')
+		case 't':
+			buffer.WriteByte('\t')
+		case 'r':
+			buffer.WriteByte('\r')
+		case '0':
+			buffer.WriteByte(0)
+		case ' ', '"', '\\', '/':
+			buffer.WriteByte(text[i])
+		case 'x', 'u', 'U':
+			size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[text[i]]
+			if i+size >= len(text) {
+				return "", 0, fmt.Errorf("invalid escape sequence")
+			}
+			code, err := strconv.ParseUint(text[i+1:i+1+size], 16, 32)
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid escape sequence")
+			}
+			buffer.WriteRune(rune(code))
+			i += size
+		default:
+			return "", 0, fmt.Errorf(`invalid escape sequence "\%c"`, text[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted scalar")
+}
+
+type yamlFlowParser struct {
+	text string
+	pos  int
+	line int
+}
+
+func (flow *yamlFlowParser) skipSpaces() {
+	for flow.pos < len(flow.text) && flow.text[flow.pos] == ' ' {
+		flow.pos++
+	}
+}
+
+func (flow *yamlFlowParser) parseScalar(inMapping bool) (any, error) {
+	flow.skipSpaces()
+	if flow.pos >= len(flow.text) {
+		return nil, yamlError(flow.line, "unexpected end of the flow collection")
+	}
+
+	if ch := flow.text[flow.pos]; ch == '"' || ch == '\'' {
+		value, end, err := unquoteYAML(flow.text[flow.pos:])
+		if err != nil {
+			return nil, yamlError(flow.line, err.Error())
+		}
+		flow.pos += end
+		return value, nil
+	}
+
+	start := flow.pos
+	for flow.pos < len(flow.text) {
+		ch := flow.text[flow.pos]
+		if ch == ',' || ch == ']' || ch == '}' ||
+			(ch == ':' && (inMapping || flow.pos+1 == len(flow.text) || flow.text[flow.pos+1] == ' ')) {
+			break
+		}
+		flow.pos++
+	}
+	value := strings.TrimRight(flow.text[start:flow.pos], " ")
+	if value == "~" || value == "null" || value == "" {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func (flow *yamlFlowParser) parseValue(inArray bool) (any, error) {
+	flow.skipSpaces()
+	if flow.pos >= len(flow.text) {
+		return nil, yamlError(flow.line, "unexpected end of the flow collection")
+	}
+
+	switch flow.text[flow.pos] {
+	case '[':
+		if inArray {
+			return nil, yamlError(flow.line, "arrays of arrays are not supported")
+		}
+		flow.pos++
+		array := []DataValue{}
+		for {
+			flow.skipSpaces()
+			if flow.pos < len(flow.text) && flow.text[flow.pos] == ']' {
+				flow.pos++
+				return array, nil
+			}
+
+			value, err := flow.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+			switch value := value.(type) {
+			case string:
+				array = append(array, &dataStringValue{value: value})
+			case DataObject:
+				array = append(array, value)
+			}
+
+			if err := flow.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+
+	case '{':
+		flow.pos++
+		object := new(dataObject)
+		object.tag = "_"
+		object.property = []DataNode{}
+		for {
+			flow.skipSpaces()
+			if flow.pos < len(flow.text) && flow.text[flow.pos] == '}' {
+				flow.pos++
+				return object, nil
+			}
+
+			key, err := flow.parseScalar(true)
+			if err != nil {
+				return nil, err
+			}
+			keyText, _ := key.(string)
+
+			flow.skipSpaces()
+			var value any
+			if flow.pos < len(flow.text) && flow.text[flow.pos] == ':' {
+				flow.pos++
+				if value, err = flow.parseValue(false); err != nil {
+					return nil, err
+				}
+			}
+
+			if keyText == DataTagKey {
+				tag, ok := value.(string)
+				if !ok || tag == "" {
+					return nil, yamlError(flow.line, `the value of "%s" key must be a string`, DataTagKey)
+				}
+				object.tag = tag
+			} else if node := newYAMLNode(keyText, value); node != nil {
+				object.property = append(object.property, node)
+			}
+
+			if err := flow.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return flow.parseScalar(false)
+}
+
+func (flow *yamlFlowParser) separator(end byte) error {
+	flow.skipSpaces()
+	if flow.pos < len(flow.text) {
+		switch flow.text[flow.pos] {
+		case ',':
+			flow.pos++
+			return nil
+
+		case end:
+			return nil
+		}
+	}
+	return yamlError(flow.line, "expected ',' or '%c'", end)
+}
+
+// DataObjectToYAML returns the YAML representation of DataObject.
+// The properties are written in the order of DataObject, all values are written as strings.
+// ParseDataYAML restores the same DataObject from the result
+func DataObjectToYAML(object DataObject) string {
+	if object == nil {
+		return ""
+	}
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	var writeObject func(object DataObject, indent string, firstIndent string)
+	writeObject = func(object DataObject, indent string, firstIndent string) {
+		lead := firstIndent
+		empty := true
+		writeKey := func(key string) {
+			buffer.WriteString(lead)
+			lead = indent
+			empty = false
+			buffer.WriteString(yamlScalar(key))
+			buffer.WriteRune(':')
+		}
+
+		if tag := object.Tag(); tag != "_" && tag != "" {
+			writeKey(DataTagKey)
+			buffer.WriteRune(' ')
+			buffer.WriteString(yamlScalar(tag))
+			buffer.WriteRune('\n')
+		}
+
+		count := object.PropertyCount()
+		for i := 0; i < count; i++ {
+			node := object.Property(i)
+			if node == nil {
+				continue
+			}
+
+			writeKey(node.Tag())
+			switch node.Type() {
+			case TextNode:
+				buffer.WriteRune(' ')
+				buffer.WriteString(yamlScalar(node.Text()))
+				buffer.WriteRune('\n')
+
+			case ObjectNode:
+				if obj := node.Object(); isEmptyYAMLObject(obj) {
+					buffer.WriteString(" {}\n")
+				} else {
+					buffer.WriteRune('\n')
+					writeObject(obj, indent+"  ", indent+"  ")
+				}
+
+			case ArrayNode:
+				elements := node.ArrayElements()
+				if len(elements) == 0 {
+					buffer.WriteString(" []\n")
+					continue
+				}
+				buffer.WriteRune('\n')
+				for _, element := range elements {
+					if !element.IsObject() {
+						buffer.WriteString(indent)
+						buffer.WriteString("- ")
+						buffer.WriteString(yamlScalar(element.Value()))
+						buffer.WriteRune('\n')
+					} else if obj := element.Object(); isEmptyYAMLObject(obj) {
+						buffer.WriteString(indent)
+						buffer.WriteString("- {}\n")
+					} else {
+						writeObject(obj, indent+"  ", indent+"- ")
+					}
+				}
+			}
+		}
+
+		if empty {
+			buffer.WriteString(firstIndent)
+			buffer.WriteString("{}\n")
+		}
+	}
+
+	writeObject(object, "", "")
+	return buffer.String()
+}
+
+func isEmptyYAMLObject(object DataObject) bool {
+	return object.PropertyCount() == 0 && (object.Tag() == "_" || object.Tag() == "")
+}
+
+// yamlScalar returns the text as a plain scalar if it is possible, otherwise as a double-quoted scalar
+func yamlScalar(text string) string {
+	plain := text != ""
+	if plain {
+		lower := strings.ToLower(text)
+		switch lower {
+		case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n":
+			plain = false
+
+		default:
+			if _, err := strconv.ParseFloat(text, 64); err == nil {
+				plain = false
+			} else if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@` ", rune(text[0])) {
+				plain = false
+			} else if strings.HasSuffix(text, " ") || strings.HasSuffix(text, ":") ||
+				strings.Contains(text, ": ") || strings.Contains(text, " #") {
+				plain = false
+			} else {
+				for _, ch := range text {
+					if ch < ' ' || ch == 0x7F || ch == utf8.RuneError {
+						plain = false
+						break
+					}
+				}
+			}
+		}
+	}
+
+	if plain {
+		return text
+	}
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+	buffer.WriteRune('"')
+	for _, ch := range text {
+		switch ch {
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		default:
+			if ch < ' ' || ch == 0x7F {
+				buffer.WriteString(fmt.Sprintf(`\x%02X`, ch))
+			} else {
+				buffer.WriteRune(ch)
+			}
+		}
+	}
+	buffer.WriteRune('"')
+	return buffer.String()
+}
@@ -0,0 +1,121 @@
+package rui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDataYAML(t *testing.T) {
+	createTestLog(t, false)
+
+	object := ParseDataText(dataFormatsTestText)
+	if object == nil {
+		t.Fatal("ParseDataText error")
+	}
+
+	text := DataObjectToYAML(object)
+	if restored := ParseDataYAML(text); restored == nil || !equalDataObjects(object, restored) {
+		t.Errorf("YAML round trip error:\n%s", text)
+	}
+
+	object = ParseDataYAML(`---
+# comment
+_tag: ListLayout
+id: 'it''s'   # comment
+gap: 8px
+content:
+- _tag: TextView
+  text: "Hello\tworld"
+- plain text
+  continued
+-
+  _tag: Button
+  content: [ OK, { _tag: TextView, text: "a, b" } ]
+border: { style: solid, width: 1px }
+summary: |
+  line 1
+    line 2
+description: >-
+  folded
+  text
+
+  new paragraph
+empty:
+url: http://example.com/#anchor
+`)
+	if object == nil {
+		t.Fatal("ParseDataYAML error")
+	}
+
+	expected := ParseDataText(`ListLayout {
+		id = "it's",
+		gap = 8px,
+		content = [
+			TextView { text = "Hello\tworld" },
+			"plain text continued",
+			Button { content = [ OK, TextView { text = "a, b" } ] },
+		],
+		border = _{ style = solid, width = 1px },
+		summary = "line 1\n  line 2\n",
+		description = "folded text\nnew paragraph",
+		url = "http://example.com/#anchor",
+	}`)
+	if !equalDataObjects(object, expected) {
+		t.Errorf("ParseDataYAML result:\n%s\nexpected:\n%s", DataObjectToYAML(object), DataObjectToYAML(expected))
+	}
+
+	ignoreTestLog = true
+	for _, text := range []string{"- a\n- b", "a:\n  - - b", "a: [b\n", "a: &x b", "a: b\n   c: d\n  e: f"} {
+		if ParseDataYAML(text) != nil {
+			t.Errorf("ParseDataYAML(%q) must fail", text)
+		}
+	}
+	ignoreTestLog = false
+}
+
+func TestDataFormatResources(t *testing.T) {
+	createTestLog(t, false)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"themes/colors.json": `{"_tag": "theme", "colors": {"formatColor": "#FF00FF00"}}`,
+		"strings/de.yaml":    "_tag: strings:formats\nGreeting: Hallo\n",
+		"views/main.json":    `{"_tag": "TextView", "id": "main", "text": "From JSON"}`,
+		"views/second.yml":   "_tag: TextView\ntext: From YAML\n",
+	}
+	for _, sub := range []string{imageDir, themeDir, stringsDir, viewDir} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldPath := resources.path
+	defer func() {
+		resources.path = oldPath
+		delete(defaultTheme.data().colors, "formatColor")
+		delete(stringResources, "formats")
+		delete(pluralResources, "formats")
+	}()
+	SetResourcePath(dir)
+
+	if color, _ := defaultTheme.Color("formatColor"); color != "#FF00FF00" {
+		t.Errorf("theme color from JSON: %s", color)
+	}
+	if text, _ := GetString("Greeting", "formats"); text != "Hallo" {
+		t.Errorf("string from YAML: %s", text)
+	}
+
+	session := newSession(nil, 0, "", nil)
+	if view := CreateViewFromResources(session, "main"); view == nil || GetText(view) != "From JSON" {
+		t.Error("view from JSON is not created")
+	}
+	if view := CreateViewFromResources(session, "second.yml"); view == nil || GetText(view) != "From YAML" {
+		t.Error("view from YAML is not created")
+	}
+}
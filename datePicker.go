@@ -43,6 +43,12 @@ func (picker *datePickerData) init(session Session) {
 	picker.dateChangedListeners = []func(DatePicker, time.Time, time.Time){}
 }
 
+func (picker *datePickerData) supportedProperties() []string {
+	return append([]string{
+		DatePickerMin, DatePickerMax, DatePickerStep, DatePickerValue, DateChangedEvent,
+	}, picker.viewData.supportedProperties()...)
+}
+
 func (picker *datePickerData) String() string {
 	return getViewString(picker)
 }
@@ -0,0 +1,371 @@
+package rui
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// DateRangeChangedEvent is the constant for "date-range-changed" property tag.
+	// The "date-range-changed" event occurs when the range of DateRangePicker is changed.
+	// The main listener format: func(DateRangePicker, time.Time, time.Time), where the second and the third
+	// arguments are the first and the last day of the new range
+	DateRangeChangedEvent = "date-range-changed"
+	// DateRangeStart is the constant for "date-range-start" property tag.
+	// The "date-range-start" property sets the first day of the DateRangePicker range
+	DateRangeStart = "date-range-start"
+	// DateRangeEnd is the constant for "date-range-end" property tag.
+	// The "date-range-end" property sets the last day of the DateRangePicker range
+	DateRangeEnd = "date-range-end"
+)
+
+// DateRangePicker - the view which displays the range of days and allows to choose it in a popup calendar
+type DateRangePicker interface {
+	View
+	// DateRange returns the first and the last day of the range and true if the range is set
+	DateRange() (time.Time, time.Time, bool)
+	// SetDateRange sets the first and the last day of the range. The range is ignored if it can not be selected
+	// in the calendar: it is outside of the "calendar-min"..."calendar-max" range or contains disabled days
+	SetDateRange(start, end time.Time)
+}
+
+type dateRangePickerData struct {
+	viewData
+	disabledFunc     func(time.Time) bool
+	changedListeners []func(DateRangePicker, time.Time, time.Time)
+	popup            Popup
+}
+
+// NewDateRangePicker create new DateRangePicker object and return it
+func NewDateRangePicker(session Session, params Params) DateRangePicker {
+	view := new(dateRangePickerData)
+	view.init(session)
+	setInitParams(view, params)
+	return view
+}
+
+func newDateRangePicker(session Session) View {
+	return NewDateRangePicker(session, nil)
+}
+
+// Init initialize fields of DateRangePicker by default values
+func (picker *dateRangePickerData) init(session Session) {
+	picker.viewData.init(session)
+	picker.tag = "DateRangePicker"
+	picker.systemClass = "ruiDateRangePicker"
+	picker.changedListeners = []func(DateRangePicker, time.Time, time.Time){}
+}
+
+func (picker *dateRangePickerData) supportedProperties() []string {
+	return append([]string{
+		DateRangeStart, DateRangeEnd, CalendarMin, CalendarMax, DateDisabled, FirstDayOfWeek, Hint,
+		DateRangeChangedEvent,
+	}, picker.viewData.supportedProperties()...)
+}
+
+func (picker *dateRangePickerData) String() string {
+	return getViewString(picker)
+}
+
+func (picker *dateRangePickerData) Focusable() bool {
+	return true
+}
+
+func (picker *dateRangePickerData) normalizeTag(tag string) string {
+	tag = strings.ToLower(tag)
+	switch tag {
+	case Min, Max:
+		return "calendar-" + tag
+	}
+	return tag
+}
+
+func (picker *dateRangePickerData) Remove(tag string) {
+	picker.remove(picker.normalizeTag(tag))
+}
+
+func (picker *dateRangePickerData) remove(tag string) {
+	switch tag {
+	case DateRangeChangedEvent:
+		if len(picker.changedListeners) == 0 {
+			return
+		}
+		picker.changedListeners = []func(DateRangePicker, time.Time, time.Time){}
+
+	case DateDisabled:
+		if picker.disabledFunc == nil {
+			return
+		}
+		picker.disabledFunc = nil
+
+	case DateRangeStart, DateRangeEnd:
+		if _, ok := picker.properties.Load(tag); !ok {
+			return
+		}
+		start, end, _ := picker.DateRange()
+		picker.properties.Delete(tag)
+		picker.rangeChanged(start, end)
+
+	case Hint:
+		if _, ok := picker.properties.Load(tag); !ok {
+			return
+		}
+		picker.properties.Delete(tag)
+		if picker.created {
+			updateInnerHTML(picker.htmlID(), picker.session)
+		}
+
+	default:
+		picker.viewData.remove(tag)
+		return
+	}
+
+	picker.propertyChangedEvent(tag)
+}
+
+func (picker *dateRangePickerData) Set(tag string, value any) bool {
+	return picker.set(picker.normalizeTag(tag), value)
+}
+
+func (picker *dateRangePickerData) set(tag string, value any) bool {
+	if value == nil {
+		picker.remove(tag)
+		return true
+	}
+
+	switch tag {
+	case DateRangeChangedEvent:
+		listeners, ok := valueToEventWithOldListeners[DateRangePicker, time.Time](value)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		} else if listeners == nil {
+			listeners = []func(DateRangePicker, time.Time, time.Time){}
+		}
+		picker.changedListeners = listeners
+
+	case DateDisabled:
+		fn, ok := value.(func(time.Time) bool)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+		picker.disabledFunc = fn
+
+	case DateRangeStart, DateRangeEnd:
+		date, ok := valueToDate(value, picker.session)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+
+		start, end := date, date
+		other := DateRangeEnd
+		if tag == DateRangeEnd {
+			other = DateRangeStart
+		}
+		if otherDate, ok := picker.date(other); ok {
+			if otherDate.Before(date) {
+				start = otherDate
+			} else {
+				end = otherDate
+			}
+		}
+		if !picker.isRangeEnabled(start, end) {
+			invalidPropertyValue(tag, value)
+			return false
+		}
+
+		start, end, _ = picker.DateRange()
+		picker.properties.Store(tag, date)
+		picker.rangeChanged(start, end)
+
+	case CalendarMin, CalendarMax:
+		date, ok := valueToDate(value, picker.session)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+		picker.properties.Store(tag, date)
+
+	case FirstDayOfWeek:
+		if !picker.setIntProperty(tag, value) {
+			return false
+		}
+
+	case Hint:
+		text, ok := value.(string)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+		picker.properties.Store(tag, text)
+		if picker.created {
+			updateInnerHTML(picker.htmlID(), picker.session)
+		}
+
+	default:
+		return picker.viewData.set(tag, value)
+	}
+
+	picker.propertyChangedEvent(tag)
+	return true
+}
+
+func (picker *dateRangePickerData) Get(tag string) any {
+	return picker.get(picker.normalizeTag(tag))
+}
+
+func (picker *dateRangePickerData) get(tag string) any {
+	switch tag {
+	case DateRangeChangedEvent:
+		return picker.changedListeners
+
+	case DateDisabled:
+		return picker.disabledFunc
+	}
+	return picker.viewData.get(tag)
+}
+
+func (picker *dateRangePickerData) date(tag string) (time.Time, bool) {
+	if value := picker.getRaw(tag); value != nil {
+		if date, ok := value.(time.Time); ok {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (picker *dateRangePickerData) DateRange() (time.Time, time.Time, bool) {
+	start, ok1 := picker.date(DateRangeStart)
+	end, ok2 := picker.date(DateRangeEnd)
+	if ok1 && ok2 {
+		if end.Before(start) {
+			return end, start, true
+		}
+		return start, end, true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+func (picker *dateRangePickerData) SetDateRange(start, end time.Time) {
+	start = dateOnly(start)
+	end = dateOnly(end)
+	if end.Before(start) {
+		start, end = end, start
+	}
+	if !picker.isRangeEnabled(start, end) {
+		ErrorLogF(`The range %s - %s can not be selected`, start.Format(dateFormat), end.Format(dateFormat))
+		return
+	}
+
+	oldStart, oldEnd, _ := picker.DateRange()
+	picker.properties.Store(DateRangeStart, start)
+	picker.properties.Store(DateRangeEnd, end)
+	picker.rangeChanged(oldStart, oldEnd)
+}
+
+// isRangeEnabled checks the range as the calendar popup does
+func (picker *dateRangePickerData) isRangeEnabled(start, end time.Time) bool {
+	return isDateRangeEnabled(start, end, picker.date, picker.disabledFunc)
+}
+
+func (picker *dateRangePickerData) rangeChanged(oldStart, oldEnd time.Time) {
+	start, end, _ := picker.DateRange()
+	if start.Equal(oldStart) && end.Equal(oldEnd) {
+		return
+	}
+
+	if picker.created {
+		updateInnerHTML(picker.htmlID(), picker.session)
+	}
+	for _, listener := range picker.changedListeners {
+		listener(picker, start, end)
+	}
+}
+
+func (picker *dateRangePickerData) showCalendar() {
+	if picker.popup != nil || IsDisabled(picker) {
+		return
+	}
+
+	params := Params{
+		CalendarSelectionMode: RangeSelection,
+		FirstDayOfWeek:        GetFirstDayOfWeek(picker),
+		CalendarSelectionChangedEvent: func(calendar CalendarView, dates []time.Time) {
+			if len(dates) == 2 {
+				picker.SetDateRange(dates[0], dates[1])
+				if picker.popup != nil {
+					picker.popup.Dismiss()
+				}
+			}
+		},
+	}
+
+	for _, tag := range []string{CalendarMin, CalendarMax} {
+		if date, ok := picker.date(tag); ok {
+			params[tag] = date
+		}
+	}
+	if picker.disabledFunc != nil {
+		params[DateDisabled] = picker.disabledFunc
+	}
+
+	calendar := NewCalendarView(picker.session, params)
+	if start, end, ok := picker.DateRange(); ok {
+		calendar.Set(CalendarDate, start)
+		calendar.SetSelectedDates(start, end)
+	}
+
+	picker.popup = ShowPopup(calendar, Params{
+		OutsideClose: true,
+		DismissEvent: func() {
+			picker.popup = nil
+		},
+	})
+}
+
+func (picker *dateRangePickerData) htmlProperties(self View, buffer *strings.Builder) {
+	picker.viewData.htmlProperties(self, buffer)
+	buffer.WriteString(` onclick="dateRangePickerClickEvent(this, event)" onkeydown="dateRangePickerKeyDownEvent(this, event)"`)
+}
+
+func (picker *dateRangePickerData) htmlSubviews(self View, buffer *strings.Builder) {
+	if start, end, ok := picker.DateRange(); ok {
+		buffer.WriteString(start.Format(dateFormat))
+		buffer.WriteString(" – ")
+		buffer.WriteString(end.Format(dateFormat))
+	} else if hint := GetHint(picker); hint != "" {
+		hint, _ = picker.session.GetString(hint)
+		buffer.WriteString(`<span class="ruiDateRangeHint">`)
+		buffer.WriteString(htmlEscape(hint))
+		buffer.WriteString(`</span>`)
+	}
+}
+
+func (picker *dateRangePickerData) handleCommand(self View, command string, data DataObject) bool {
+	if command == "dateRangePickerClick" {
+		picker.showCalendar()
+		return true
+	}
+	return picker.viewData.handleCommand(self, command, data)
+}
+
+// GetDateRangeChangedListeners returns the "date-range-changed" listeners of the DateRangePicker.
+// If there are no listeners then the empty list is returned
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetDateRangeChangedListeners(view View, subviewID ...string) []func(DateRangePicker, time.Time, time.Time) {
+	return getEventWithOldListeners[DateRangePicker, time.Time](view, subviewID, DateRangeChangedEvent)
+}
+
+// GetDateRange returns the first and the last day of the DateRangePicker range and true if the range is set.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetDateRange(view View, subviewID ...string) (time.Time, time.Time, bool) {
+	if len(subviewID) > 0 && subviewID[0] != "" {
+		view = ViewByID(view, subviewID[0])
+	}
+	if picker, ok := view.(DateRangePicker); ok {
+		return picker.DateRange()
+	}
+	return time.Time{}, time.Time{}, false
+}
@@ -0,0 +1,396 @@
+package rui
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// DateTimeChangedEvent is the constant for "date-time-changed" property tag.
+	// The "date-time-changed" event occurs when the value of DateTimePicker is changed.
+	// The main listener format: func(DateTimePicker, time.Time, time.Time), where the second argument is the new value,
+	// the third argument is the old value
+	DateTimeChangedEvent = "date-time-changed"
+	// DateTimePickerMin is the constant for "date-time-picker-min" property tag.
+	// The "date-time-picker-min" property sets the minimal value of DateTimePicker
+	DateTimePickerMin = "date-time-picker-min"
+	// DateTimePickerMax is the constant for "date-time-picker-max" property tag.
+	// The "date-time-picker-max" property sets the maximal value of DateTimePicker
+	DateTimePickerMax = "date-time-picker-max"
+	// DateTimePickerValue is the constant for "date-time-picker-value" property tag.
+	// The "date-time-picker-value" property sets the value of DateTimePicker
+	DateTimePickerValue = "date-time-picker-value"
+	// TimeZone is the constant for "time-zone" property tag.
+	// The "time-zone" property sets the time zone in which DateTimePicker displays and enters the time.
+	// The value can be *time.Location or the IANA time zone name (for example, "Europe/Moscow").
+	// The default value is time.Local
+	TimeZone = "time-zone"
+
+	dateTimeFormat        = "2006-01-02T15:04"
+	dateTimeSecondsFormat = "2006-01-02T15:04:05"
+)
+
+// DateTimePicker - the view for the entering of a date and a time
+type DateTimePicker interface {
+	View
+}
+
+type dateTimePickerData struct {
+	viewData
+	changedListeners []func(DateTimePicker, time.Time, time.Time)
+}
+
+// NewDateTimePicker create new DateTimePicker object and return it
+func NewDateTimePicker(session Session, params Params) DateTimePicker {
+	view := new(dateTimePickerData)
+	view.init(session)
+	setInitParams(view, params)
+	return view
+}
+
+func newDateTimePicker(session Session) View {
+	return NewDateTimePicker(session, nil)
+}
+
+func (picker *dateTimePickerData) init(session Session) {
+	picker.viewData.init(session)
+	picker.tag = "DateTimePicker"
+	picker.changedListeners = []func(DateTimePicker, time.Time, time.Time){}
+}
+
+func (picker *dateTimePickerData) supportedProperties() []string {
+	return append([]string{
+		DateTimePickerMin, DateTimePickerMax, DateTimePickerValue, TimeZone, DateTimeChangedEvent,
+	}, picker.viewData.supportedProperties()...)
+}
+
+func (picker *dateTimePickerData) String() string {
+	return getViewString(picker)
+}
+
+func (picker *dateTimePickerData) Focusable() bool {
+	return true
+}
+
+func (picker *dateTimePickerData) normalizeTag(tag string) string {
+	tag = strings.ToLower(tag)
+	switch tag {
+	case Min, Max, Value:
+		return "date-time-picker-" + tag
+	}
+	return tag
+}
+
+func (picker *dateTimePickerData) Remove(tag string) {
+	picker.remove(picker.normalizeTag(tag))
+}
+
+func (picker *dateTimePickerData) remove(tag string) {
+	switch tag {
+	case DateTimeChangedEvent:
+		if len(picker.changedListeners) == 0 {
+			return
+		}
+		picker.changedListeners = []func(DateTimePicker, time.Time, time.Time){}
+
+	case DateTimePickerMin, DateTimePickerMax, TimeZone:
+		if _, ok := picker.properties.Load(tag); !ok {
+			return
+		}
+		picker.properties.Delete(tag)
+		picker.updateAttributes()
+
+	case DateTimePickerValue:
+		if _, ok := picker.properties.Load(tag); !ok {
+			return
+		}
+		oldValue := GetDateTimePickerValue(picker)
+		picker.properties.Delete(tag)
+		picker.valueChanged(oldValue)
+
+	default:
+		picker.viewData.remove(tag)
+		return
+	}
+
+	picker.propertyChangedEvent(tag)
+}
+
+func (picker *dateTimePickerData) Set(tag string, value any) bool {
+	return picker.set(picker.normalizeTag(tag), value)
+}
+
+func (picker *dateTimePickerData) set(tag string, value any) bool {
+	if value == nil {
+		picker.remove(tag)
+		return true
+	}
+
+	switch tag {
+	case DateTimeChangedEvent:
+		listeners, ok := valueToEventWithOldListeners[DateTimePicker, time.Time](value)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		} else if listeners == nil {
+			listeners = []func(DateTimePicker, time.Time, time.Time){}
+		}
+		picker.changedListeners = listeners
+
+	case TimeZone:
+		switch value := value.(type) {
+		case *time.Location:
+			if value == nil {
+				picker.remove(tag)
+				return true
+			}
+			picker.properties.Store(tag, value)
+
+		case string:
+			location, err := time.LoadLocation(value)
+			if err != nil {
+				ErrorLog(err.Error())
+				return false
+			}
+			picker.properties.Store(tag, location)
+
+		default:
+			notCompatibleType(tag, value)
+			return false
+		}
+		picker.updateAttributes()
+
+	case DateTimePickerMin, DateTimePickerMax, DateTimePickerValue:
+		switch value := value.(type) {
+		case time.Time:
+		case string:
+			// the text is parsed on reading because the time zone can be set later
+			text, ok := picker.session.resolveConstants(value)
+			if !ok {
+				return false
+			}
+			if _, err := parseDateTime(text, time.UTC); err != nil {
+				invalidPropertyValue(tag, value)
+				return false
+			}
+
+		default:
+			notCompatibleType(tag, value)
+			return false
+		}
+
+		if tag == DateTimePickerValue {
+			oldValue := GetDateTimePickerValue(picker)
+			picker.properties.Store(tag, value)
+			picker.valueChanged(oldValue)
+		} else {
+			picker.properties.Store(tag, value)
+			picker.updateAttributes()
+		}
+
+	default:
+		return picker.viewData.set(tag, value)
+	}
+
+	picker.propertyChangedEvent(tag)
+	return true
+}
+
+func (picker *dateTimePickerData) Get(tag string) any {
+	return picker.get(picker.normalizeTag(tag))
+}
+
+func (picker *dateTimePickerData) get(tag string) any {
+	if tag == DateTimeChangedEvent {
+		return picker.changedListeners
+	}
+	return picker.viewData.get(tag)
+}
+
+// parseDateTime parses the value of the datetime-local input or RFC 3339 text
+func parseDateTime(text string, location *time.Location) (time.Time, error) {
+	text = strings.Trim(text, " \t")
+	if date, err := time.Parse(time.RFC3339, text); err == nil {
+		return date, nil
+	}
+	if date, err := time.ParseInLocation(dateTimeSecondsFormat, text, location); err == nil {
+		return date, nil
+	}
+	return time.ParseInLocation(dateTimeFormat, text, location)
+}
+
+func (picker *dateTimePickerData) formatValue(date time.Time) string {
+	date = date.In(GetTimeZone(picker))
+	if date.Second() != 0 {
+		return date.Format(dateTimeSecondsFormat)
+	}
+	return date.Format(dateTimeFormat)
+}
+
+func (picker *dateTimePickerData) dateProperty(tag string) (time.Time, bool) {
+	return getDateTimePickerProperty(picker, nil, tag)
+}
+
+func (picker *dateTimePickerData) updateAttributes() {
+	if !picker.created {
+		return
+	}
+
+	for _, tag := range []string{DateTimePickerMin, DateTimePickerMax} {
+		attr := tag[len("date-time-picker-"):]
+		if date, ok := picker.dateProperty(tag); ok {
+			picker.session.updateProperty(picker.htmlID(), attr, picker.formatValue(date))
+		} else {
+			picker.session.removeProperty(picker.htmlID(), attr)
+		}
+	}
+
+	if date, ok := picker.dateProperty(DateTimePickerValue); ok {
+		picker.session.callFunc("setInputValue", picker.htmlID(), picker.formatValue(date))
+	} else {
+		picker.session.callFunc("setInputValue", picker.htmlID(), "")
+	}
+}
+
+func (picker *dateTimePickerData) valueChanged(oldValue time.Time) {
+	value := GetDateTimePickerValue(picker)
+	if value.Equal(oldValue) {
+		return
+	}
+
+	picker.updateAttributes()
+	for _, listener := range picker.changedListeners {
+		listener(picker, value, oldValue)
+	}
+}
+
+func (picker *dateTimePickerData) htmlTag() string {
+	return "input"
+}
+
+func (picker *dateTimePickerData) htmlProperties(self View, buffer *strings.Builder) {
+	picker.viewData.htmlProperties(self, buffer)
+
+	buffer.WriteString(` type="datetime-local" step="1"`)
+
+	if min, ok := picker.dateProperty(DateTimePickerMin); ok {
+		buffer.WriteString(` min="`)
+		buffer.WriteString(picker.formatValue(min))
+		buffer.WriteByte('"')
+	}
+
+	if max, ok := picker.dateProperty(DateTimePickerMax); ok {
+		buffer.WriteString(` max="`)
+		buffer.WriteString(picker.formatValue(max))
+		buffer.WriteByte('"')
+	}
+
+	if value, ok := picker.dateProperty(DateTimePickerValue); ok {
+		buffer.WriteString(` value="`)
+		buffer.WriteString(picker.formatValue(value))
+		buffer.WriteByte('"')
+	}
+
+	buffer.WriteString(` oninput="editViewInputEvent(this)"`)
+	if picker.getRaw(ClickEvent) == nil {
+		buffer.WriteString(` onclick="stopEventPropagation(this, event)"`)
+	}
+}
+
+func (picker *dateTimePickerData) htmlDisabledProperties(self View, buffer *strings.Builder) {
+	if IsDisabled(self) {
+		buffer.WriteString(` disabled`)
+	}
+	picker.viewData.htmlDisabledProperties(self, buffer)
+}
+
+func (picker *dateTimePickerData) handleCommand(self View, command string, data DataObject) bool {
+	if command == "textChanged" {
+		if text, ok := data.PropertyValue("text"); ok {
+			if value, err := parseDateTime(text, GetTimeZone(picker)); err == nil {
+				oldValue := GetDateTimePickerValue(picker)
+				picker.properties.Store(DateTimePickerValue, value)
+				if !value.Equal(oldValue) {
+					for _, listener := range picker.changedListeners {
+						listener(picker, value, oldValue)
+					}
+					picker.propertyChangedEvent(DateTimePickerValue)
+				}
+			}
+		}
+		return true
+	}
+
+	return picker.viewData.handleCommand(self, command, data)
+}
+
+// GetTimeZone returns the time zone of the DateTimePicker.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTimeZone(view View, subviewID ...string) *time.Location {
+	if len(subviewID) > 0 && subviewID[0] != "" {
+		view = ViewByID(view, subviewID[0])
+	}
+	if view != nil {
+		if value := view.getRaw(TimeZone); value != nil {
+			if location, ok := value.(*time.Location); ok {
+				return location
+			}
+		}
+		if value := valueFromStyle(view, TimeZone); value != nil {
+			if name, ok := value.(string); ok {
+				if location, err := time.LoadLocation(name); err == nil {
+					return location
+				}
+			}
+		}
+	}
+	return time.Local
+}
+
+// GetDateTimePickerMin returns the minimal value of the DateTimePicker and "true" as the second value if it is set.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetDateTimePickerMin(view View, subviewID ...string) (time.Time, bool) {
+	return getDateTimePickerProperty(view, subviewID, DateTimePickerMin)
+}
+
+// GetDateTimePickerMax returns the maximal value of the DateTimePicker and "true" as the second value if it is set.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetDateTimePickerMax(view View, subviewID ...string) (time.Time, bool) {
+	return getDateTimePickerProperty(view, subviewID, DateTimePickerMax)
+}
+
+// GetDateTimePickerValue returns the value of the DateTimePicker in its time zone.
+// If the value is not set then the zero time is returned.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetDateTimePickerValue(view View, subviewID ...string) time.Time {
+	date, _ := getDateTimePickerProperty(view, subviewID, DateTimePickerValue)
+	return date
+}
+
+func getDateTimePickerProperty(view View, subviewID []string, tag string) (time.Time, bool) {
+	if len(subviewID) > 0 && subviewID[0] != "" {
+		view = ViewByID(view, subviewID[0])
+	}
+	if view != nil {
+		location := GetTimeZone(view)
+		switch value := view.getRaw(tag).(type) {
+		case time.Time:
+			return value.In(location), true
+
+		case string:
+			if text, ok := view.Session().resolveConstants(value); ok {
+				if date, err := parseDateTime(text, location); err == nil {
+					return date.In(location), true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// GetDateTimeChangedListeners returns the "date-time-changed" listeners of the DateTimePicker.
+// If there are no listeners then the empty list is returned
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetDateTimeChangedListeners(view View, subviewID ...string) []func(DateTimePicker, time.Time, time.Time) {
+	return getEventWithOldListeners[DateTimePicker, time.Time](view, subviewID, DateTimeChangedEvent)
+}
@@ -40,6 +40,12 @@ func (detailsView *detailsViewData) init(session Session) {
 	//detailsView.systemClass = "ruiDetailsView"
 }
 
+func (detailsView *detailsViewData) supportedProperties() []string {
+	return append([]string{
+		Summary, Expanded, NotTranslate,
+	}, detailsView.viewsContainerData.supportedProperties()...)
+}
+
 func (detailsView *detailsViewData) Views() []View {
 	views := detailsView.viewsContainerData.Views()
 	if summary := detailsView.get(Summary); summary != nil {
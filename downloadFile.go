@@ -2,42 +2,204 @@ package rui
 
 import (
 	"bytes"
-	"math/rand"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 )
 
+// DownloadExpiration is the time during which the started download waits for the request of the client.
+// The unclaimed download is removed after this time and its completion callback gets an error
+var DownloadExpiration = 5 * time.Minute
+
+// downloadCookiePrefix is the prefix of the name of the cookie which passes the download key of the session
+// with the request of the download. The name of the cookie is the prefix + the download id
+const downloadCookiePrefix = "rui-download-"
+
 type downloadFile struct {
-	filename string
-	path     string
-	data     []byte
+	filename  string
+	path      string
+	data      []byte
+	mimeType  string
+	content   func(io.Writer) error
+	session   *sessionData
+	sessionID string
+	finished  func(error)
+	timer     *time.Timer
+}
+
+var downloadFiles = map[string]*downloadFile{}
+var downloadFilesMutex sync.Mutex
+
+// downloadWriter counts the bytes written to the response of the streamed download
+type downloadWriter struct {
+	writer  io.Writer
+	written int64
 }
 
-var currentDownloadId = int(rand.Int31())
-var downloadFiles = map[string]downloadFile{}
+func (writer *downloadWriter) Write(data []byte) (int, error) {
+	n, err := writer.writer.Write(data)
+	writer.written += int64(n)
+	return n, err
+}
 
-func (session *sessionData) startDownload(file downloadFile) {
-	currentDownloadId++
-	id := strconv.Itoa(currentDownloadId)
+func randomHexID() (string, bool) {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		ErrorLog(err.Error())
+		return "", false
+	}
+	return hex.EncodeToString(buffer), true
+}
+
+// startDownload registers the download and starts it on the client. The download is served only
+// to the request with the download key of the session. The key is passed to the client through the websocket
+// and is returned by the client in the cookie, so the link of the download is not enough to get the file
+func (session *sessionData) startDownload(file *downloadFile) {
+	if session.downloadKey == "" {
+		key, ok := randomHexID()
+		if !ok {
+			return
+		}
+		session.downloadKey = key
+	}
+
+	id, ok := randomHexID()
+	if !ok {
+		return
+	}
+
+	file.session = session
+	file.sessionID = strconv.Itoa(session.sessionID)
+	if file.finished != nil {
+		if session.downloads == nil {
+			session.downloads = map[string]func(error){}
+		}
+		session.downloads[id] = file.finished
+	}
+
+	downloadFilesMutex.Lock()
 	downloadFiles[id] = file
-	session.callFunc("startDownload", id, file.filename)
+	key := session.downloadKey
+	file.timer = time.AfterFunc(DownloadExpiration, func() {
+		if file := takeDownloadFile(id, key); file != nil {
+			file.complete(id, errors.New(`The download of "`+file.filename+`" is expired`))
+		}
+	})
+	downloadFilesMutex.Unlock()
+
+	session.callFunc("startDownload", id, file.filename, downloadCookiePrefix+id, key, int(DownloadExpiration.Seconds()))
+}
+
+// takeDownloadFile removes the download from the list of the waiting downloads and returns it.
+// Returns nil if the download is not found or the key is not the download key of the session that started it
+func takeDownloadFile(id, key string) *downloadFile {
+	downloadFilesMutex.Lock()
+	defer downloadFilesMutex.Unlock()
+
+	file, ok := downloadFiles[id]
+	if !ok || key == "" || file.session.downloadKey != key {
+		return nil
+	}
+	delete(downloadFiles, id)
+	file.timer.Stop()
+	return file
+}
+
+// complete passes the result of the download to the completion callback.
+// The callback is called in the session goroutine if the session is connected.
+// The error is written to the log if there is no callback
+func (file *downloadFile) complete(id string, err error) {
+	if file.finished == nil {
+		if err != nil {
+			ErrorLog(err.Error())
+		}
+		return
+	}
+
+	data := NewDataObject("download-finished")
+	data.SetPropertyValue("session", file.sessionID)
+	data.SetPropertyValue("download", id)
+	if err != nil {
+		data.SetPropertyValue("error", err.Error())
+	}
+
+	if !file.session.postEvent(data) {
+		file.finished(err)
+	}
 }
 
 func serveDownloadFile(id string, w http.ResponseWriter, r *http.Request) bool {
-	if file, ok := downloadFiles[id]; ok {
-		delete(downloadFiles, id)
-		if file.data != nil {
-			http.ServeContent(w, r, file.filename, time.Now(), bytes.NewReader(file.data))
-			return true
-		} else if _, err := os.Stat(file.path); err == nil {
-			http.ServeFile(w, r, file.path)
-			return true
+	cookie, err := r.Cookie(downloadCookiePrefix + id)
+	if err != nil {
+		return false
+	}
+
+	file := takeDownloadFile(id, cookie.Value)
+	if file == nil {
+		return false
+	}
+	http.SetCookie(w, &http.Cookie{Name: cookie.Name, Path: "/", MaxAge: -1})
+
+	if file.mimeType != "" {
+		w.Header().Set("Content-Type", file.mimeType)
+	}
+
+	switch {
+	case file.content != nil:
+		if file.mimeType == "" {
+			if mimeType := mime.TypeByExtension(filepath.Ext(file.filename)); mimeType != "" {
+				w.Header().Set("Content-Type", mimeType)
+			} else {
+				w.Header().Set("Content-Type", "application/octet-stream")
+			}
+		}
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.filename}))
+
+		writer := &downloadWriter{writer: w}
+		err := file.content(writer)
+		file.complete(id, err)
+		if err != nil {
+			if writer.written == 0 {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			} else {
+				// the connection is broken so the client does not save the incomplete file
+				panic(http.ErrAbortHandler)
+			}
 		}
+
+	case file.data != nil:
+		http.ServeContent(w, r, file.filename, time.Now(), bytes.NewReader(file.data))
+		file.complete(id, nil)
+
+	default:
+		if _, err := os.Stat(file.path); err != nil {
+			file.complete(id, err)
+			return false
+		}
+		http.ServeFile(w, r, file.path)
+		file.complete(id, nil)
+	}
+	return true
+}
+
+func (session *sessionData) downloadFinished(data DataObject) {
+	id, _ := data.PropertyValue("download")
+	if finished, ok := session.downloads[id]; ok {
+		delete(session.downloads, id)
+		var err error
+		if text, ok := data.PropertyValue("error"); ok {
+			err = errors.New(text)
+		}
+		finished(err)
 	}
-	return false
 }
 
 // DownloadFile starts downloading the file on the client side.
@@ -48,7 +210,7 @@ func (session *sessionData) DownloadFile(path string) {
 	}
 
 	_, filename := filepath.Split(path)
-	session.startDownload(downloadFile{
+	session.startDownload(&downloadFile{
 		filename: filename,
 		path:     path,
 		data:     nil,
@@ -62,9 +224,24 @@ func (session *sessionData) DownloadFileData(filename string, data []byte) {
 		return
 	}
 
-	session.startDownload(downloadFile{
+	session.startDownload(&downloadFile{
 		filename: filename,
 		path:     "",
 		data:     data,
 	})
 }
+
+// DownloadStream starts downloading the file generated by the content function on the client side
+func (session *sessionData) DownloadStream(filename, mimeType string, content func(io.Writer) error, finished func(error)) {
+	if content == nil {
+		ErrorLog("Invalid download content function. Must be not nil.")
+		return
+	}
+
+	session.startDownload(&downloadFile{
+		filename: filename,
+		mimeType: mimeType,
+		content:  content,
+		finished: finished,
+	})
+}
@@ -0,0 +1,124 @@
+package rui
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadStream(t *testing.T) {
+	createTestLog(t, false)
+
+	session := newSession(nil, 7, "", nil)
+	bridge := new(canvasTestBridge)
+	session.setBridge(nil, bridge)
+
+	// startDownload[<id> <filename> <cookie name> <key> <max age>]
+	started := func(bridge *canvasTestBridge) (string, string, string) {
+		if len(bridge.funcs) == 0 {
+			t.Fatal("the download is not started")
+		}
+		args := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(bridge.funcs[len(bridge.funcs)-1], "startDownload["), "]"))
+		if len(args) != 5 || args[2] != downloadCookiePrefix+args[0] || args[3] == "" {
+			t.Fatalf("invalid download: %v", args)
+		}
+		return args[0], args[1], args[3]
+	}
+	get := func(id, key string) (*httptest.ResponseRecorder, bool) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/"+id, nil)
+		if key != "" {
+			request.AddCookie(&http.Cookie{Name: downloadCookiePrefix + id, Value: key})
+		}
+		return recorder, serveDownloadFile(id, recorder, request)
+	}
+
+	var finished error = io.ErrUnexpectedEOF
+	session.DownloadStream("report.csv", "text/csv; charset=utf-8", func(w io.Writer) error {
+		_, err := io.WriteString(w, "a,b\n1,2\n")
+		return err
+	}, func(err error) {
+		finished = err
+	})
+
+	id, filename, key := started(bridge)
+	if filename != "report.csv" {
+		t.Fatalf("invalid download: %s %s", id, filename)
+	}
+
+	other := newSession(nil, 8, "", nil)
+	otherBridge := new(canvasTestBridge)
+	other.setBridge(nil, otherBridge)
+	other.DownloadFileData("other.txt", []byte("other"))
+	otherID, _, otherKey := started(otherBridge)
+	if otherKey == key {
+		t.Fatal("the sessions have the same download key")
+	}
+	if _, ok := get(id, otherKey); ok {
+		t.Error("the download is served to the other session")
+	}
+	if _, ok := get(id, ""); ok {
+		t.Error("the download without the key is served")
+	}
+	if recorder, ok := get(otherID, otherKey); !ok || recorder.Body.String() != "other" {
+		t.Error("the download of the other session is not served")
+	}
+
+	recorder, ok := get(id, key)
+	if !ok || recorder.Body.String() != "a,b\n1,2\n" || finished != nil {
+		t.Fatalf("invalid download: %v %q %v", ok, recorder.Body.String(), finished)
+	}
+	if mimeType := recorder.Header().Get("Content-Type"); mimeType != "text/csv; charset=utf-8" {
+		t.Errorf("invalid Content-Type: %s", mimeType)
+	}
+	if disposition := recorder.Header().Get("Content-Disposition"); disposition != `attachment; filename=report.csv` {
+		t.Errorf("invalid Content-Disposition: %s", disposition)
+	}
+	if cookie := recorder.Header().Get("Set-Cookie"); !strings.HasPrefix(cookie, downloadCookiePrefix+id+"=;") {
+		t.Errorf("the cookie of the download is not removed: %s", cookie)
+	}
+	if _, ok := get(id, key); ok {
+		t.Error("the download is served twice")
+	}
+
+	// the content error
+	session.DownloadStream("data.bin", "", func(w io.Writer) error {
+		return errors.New("no data")
+	}, func(err error) {
+		finished = err
+	})
+	id, _, key = started(bridge)
+	if recorder, ok := get(id, key); !ok || recorder.Code != http.StatusInternalServerError || finished == nil {
+		t.Errorf("the content error is ignored: %d %v", recorder.Code, finished)
+	}
+
+	// the unclaimed download
+	expiration := DownloadExpiration
+	DownloadExpiration = 10 * time.Millisecond
+	defer func() {
+		DownloadExpiration = expiration
+	}()
+
+	expired := make(chan error, 1)
+	session.DownloadStream("late.txt", "", func(w io.Writer) error {
+		return nil
+	}, func(err error) {
+		expired <- err
+	})
+	id, _, key = started(bridge)
+	select {
+	case err := <-expired:
+		if err == nil {
+			t.Error("the expired download is completed")
+		}
+	case <-time.After(time.Second):
+		t.Error("the download is not expired")
+	}
+	if _, ok := get(id, key); ok {
+		t.Error("the expired download is served")
+	}
+}
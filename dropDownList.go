@@ -44,6 +44,12 @@ func (list *dropDownListData) init(session Session) {
 	list.dropDownListener = []func(DropDownList, int, int){}
 }
 
+func (list *dropDownListData) supportedProperties() []string {
+	return append([]string{
+		Items, DisabledItems, Current, DropDownEvent,
+	}, list.viewData.supportedProperties()...)
+}
+
 func (list *dropDownListData) String() string {
 	return getViewString(list)
 }
@@ -282,7 +288,7 @@ func (list *dropDownListData) setDisabledItems(value any) bool {
 		list.disabledItems = disabledItems
 
 	case []DataValue:
-		disabledItems := make([]string, 0, len(value))
+		disabledItems := make([]any, 0, len(value))
 		for _, val := range value {
 			if !val.IsObject() {
 				disabledItems = append(disabledItems, val.Value())
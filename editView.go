@@ -62,6 +62,13 @@ func (edit *editViewData) init(session Session) {
 	edit.tag = "EditView"
 }
 
+func (edit *editViewData) supportedProperties() []string {
+	return append([]string{
+		Text, Hint, MaxLength, ReadOnly, Spellcheck, EditViewPattern, EditViewType, EditWrap,
+		EditTextChangedEvent,
+	}, edit.viewData.supportedProperties()...)
+}
+
 func (edit *editViewData) String() string {
 	return getViewString(edit)
 }
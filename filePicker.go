@@ -40,6 +40,11 @@ type FilePicker interface {
 	// LoadFile loads the content of the selected file. This function is asynchronous.
 	// The "result" function will be called after loading the data.
 	LoadFile(file FileInfo, result func(FileInfo, []byte))
+	// UploadFile starts the chunked upload of the selected file through the HTTP endpoint of the application.
+	// Unlike LoadFile the file is not kept in memory: the chunks are passed to the Chunk function
+	// of the options or to the Reader of the result. The upload is resumed after the reconnection.
+	// Returns nil if the file is not selected
+	UploadFile(file FileInfo, options FileUploadOptions) FileUpload
 }
 
 type filePickerData struct {
@@ -88,6 +93,12 @@ func (picker *filePickerData) init(session Session) {
 	picker.fileSelectedListeners = []func(FilePicker, []FileInfo){}
 }
 
+func (picker *filePickerData) supportedProperties() []string {
+	return append([]string{
+		Accept, Multiple, FileSelectedEvent,
+	}, picker.viewData.supportedProperties()...)
+}
+
 func (picker *filePickerData) String() string {
 	return getViewString(picker)
 }
@@ -100,17 +111,23 @@ func (picker *filePickerData) Files() []FileInfo {
 	return picker.files
 }
 
+func (picker *filePickerData) fileIndex(file FileInfo) int {
+	for i, info := range picker.files {
+		if info.Name == file.Name && info.Size == file.Size && info.LastModified == file.LastModified {
+			return i
+		}
+	}
+	return -1
+}
+
 func (picker *filePickerData) LoadFile(file FileInfo, result func(FileInfo, []byte)) {
 	if result == nil {
 		return
 	}
 
-	for i, info := range picker.files {
-		if info.Name == file.Name && info.Size == file.Size && info.LastModified == file.LastModified {
-			picker.loader[i] = result
-			picker.Session().callFunc("loadSelectedFile", picker.htmlID(), i)
-			return
-		}
+	if i := picker.fileIndex(file); i >= 0 {
+		picker.loader[i] = result
+		picker.Session().callFunc("loadSelectedFile", picker.htmlID(), i)
 	}
 }
 
@@ -312,6 +329,10 @@ func (picker *filePickerData) handleCommand(self View, command string, data Data
 		}
 		return true
 
+	case "fileUploadProgress", "fileUploaded", "fileUploadError":
+		handleFileUploadCommand(picker, command, data)
+		return true
+
 	case "fileLoadingError":
 		if error, ok := data.PropertyValue("error"); ok {
 			ErrorLog(error)
@@ -0,0 +1,389 @@
+package rui
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultUploadChunkSize is the default size of the chunk of the file upload (1 MB)
+	DefaultUploadChunkSize = 1 << 20
+	// MaxUploadChunkSize is the maximal size of the chunk of the file upload (16 MB)
+	MaxUploadChunkSize = 16 << 20
+
+	// uploadPathPrefix is the path of the HTTP endpoint receiving the chunks of the uploads
+	uploadPathPrefix = "upload/"
+)
+
+// UploadExpiration is the time during which the started upload waits for the next chunk of the client.
+// The idle upload (for example, the tab of the client is closed) is cancelled after this time
+// and its Finished callback gets an error. The callback of the expired upload of the disconnected session
+// is called in the timer goroutine
+var UploadExpiration = 5 * time.Minute
+
+// FileUploadOptions sets the parameters of the chunked file upload (see FilePicker.UploadFile)
+type FileUploadOptions struct {
+	// ChunkSize is the size of the chunk in bytes. DefaultUploadChunkSize is used if the value <= 0.
+	// The value is limited by MaxUploadChunkSize
+	ChunkSize int
+	// MaxSize is the maximal size of the file in bytes. The upload of the larger file is rejected.
+	// The value <= 0 means no limit
+	MaxSize int64
+	// Chunk is called for each received chunk in the order of the file. The offset is the position
+	// of the chunk in the file. If the function returns an error then the upload is cancelled.
+	// The function is called in the goroutine of the HTTP request (not in the session goroutine).
+	// If Chunk is nil then the content of the file is read from the Reader of FileUpload
+	Chunk func(upload FileUpload, offset int64, data []byte) error
+	// Progress is called after the chunk is received. loaded is the number of received bytes, total is the file size
+	Progress func(upload FileUpload, loaded, total int64)
+	// Finished is called when the upload is finished. err is nil if the whole file is received.
+	// The function is not called if the upload is cancelled by the Cancel function of FileUpload
+	Finished func(upload FileUpload, err error)
+}
+
+// FileUpload is the chunked upload of the file selected in FilePicker or recorded by MediaRecorder
+type FileUpload interface {
+	// File returns the description of the uploaded file
+	File() FileInfo
+	// Loaded returns the number of the received bytes
+	Loaded() int64
+	// Reader returns the reader of the file content. It is used if the Chunk function of FileUploadOptions is nil.
+	// The reader must be read in a separate goroutine: the next chunk is not received until the previous one is read.
+	// The reader returns io.EOF after the whole file is received and an error if the upload is cancelled or failed
+	Reader() io.Reader
+	// Cancel stops the upload
+	Cancel()
+}
+
+type fileUploadData struct {
+	id           string
+	session      Session
+	ownerID      string
+	file         FileInfo
+	options      FileUploadOptions
+	mutex        sync.Mutex
+	receiveMutex sync.Mutex
+	loaded       int64
+	finished     bool
+	err          error
+	reader       *io.PipeReader
+	writer       *io.PipeWriter
+	timer        *time.Timer
+}
+
+var fileUploads = map[string]*fileUploadData{}
+var fileUploadsMutex sync.Mutex
+
+// newFileUpload creates the upload. It returns nil if the id of the upload can not be generated
+func newFileUpload(owner View, file FileInfo, options FileUploadOptions) *fileUploadData {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		ErrorLog(err.Error())
+		return nil
+	}
+
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = DefaultUploadChunkSize
+	} else if options.ChunkSize > MaxUploadChunkSize {
+		options.ChunkSize = MaxUploadChunkSize
+	}
+
+	upload := &fileUploadData{
+		id:      hex.EncodeToString(id),
+		session: owner.Session(),
+		ownerID: owner.htmlID(),
+		file:    file,
+		options: options,
+	}
+	if options.Chunk == nil {
+		upload.reader, upload.writer = io.Pipe()
+	}
+	return upload
+}
+
+func (upload *fileUploadData) File() FileInfo {
+	return upload.file
+}
+
+func (upload *fileUploadData) Loaded() int64 {
+	upload.mutex.Lock()
+	defer upload.mutex.Unlock()
+	return upload.loaded
+}
+
+func (upload *fileUploadData) Reader() io.Reader {
+	return upload.reader
+}
+
+func (upload *fileUploadData) Cancel() {
+	upload.stop(errors.New(`The upload of "` + upload.file.Name + `" is cancelled`))
+	if upload.unregister() {
+		upload.session.callFunc("cancelFileUpload", upload.id)
+	}
+}
+
+// stop finishes the upload with the error. It returns false if the upload is already finished
+func (upload *fileUploadData) stop(err error) bool {
+	upload.mutex.Lock()
+	finished := upload.finished
+	if !finished {
+		upload.finished = true
+		upload.err = err
+	}
+	upload.mutex.Unlock()
+
+	if finished {
+		return false
+	}
+
+	if upload.writer != nil {
+		upload.writer.CloseWithError(err)
+	}
+	return true
+}
+
+// unregister removes the upload from the list of the active uploads.
+// It returns false if the upload is already removed
+func (upload *fileUploadData) unregister() bool {
+	fileUploadsMutex.Lock()
+	defer fileUploadsMutex.Unlock()
+
+	if _, ok := fileUploads[upload.id]; ok {
+		delete(fileUploads, upload.id)
+		upload.timer.Stop()
+		return true
+	}
+	return false
+}
+
+// expire cancels the idle upload. The reader of the upload gets the error at once,
+// the Finished callback is called in the session goroutine if the session is connected
+func (upload *fileUploadData) expire() {
+	upload.stop(errors.New(`The upload of "` + upload.file.Name + `" is expired`))
+
+	if session, ok := upload.session.(*sessionData); ok {
+		data := NewDataObject("file-upload-expired")
+		data.SetPropertyValue("session", strconv.Itoa(session.sessionID))
+		data.SetPropertyValue("upload", upload.id)
+		if session.postEvent(data) {
+			return
+		}
+	}
+	upload.finishExpired()
+}
+
+// finishExpired removes the expired upload and passes the result to the Finished callback
+func (upload *fileUploadData) finishExpired() {
+	if upload.unregister() && upload.options.Finished != nil {
+		upload.options.Finished(upload, upload.error())
+	}
+}
+
+func (session *sessionData) fileUploadExpired(data DataObject) {
+	id, _ := data.PropertyValue("upload")
+
+	fileUploadsMutex.Lock()
+	upload, ok := fileUploads[id]
+	fileUploadsMutex.Unlock()
+
+	if ok && upload.session == session {
+		session.callFunc("cancelFileUpload", id)
+		upload.finishExpired()
+	}
+}
+
+// cancelFileUploads stops the uploads of the closed session. The readers of the uploads get an error
+func (session *sessionData) cancelFileUploads() {
+	fileUploadsMutex.Lock()
+	uploads := []*fileUploadData{}
+	for _, upload := range fileUploads {
+		if upload.session == session {
+			uploads = append(uploads, upload)
+		}
+	}
+	fileUploadsMutex.Unlock()
+
+	for _, upload := range uploads {
+		upload.stop(errors.New(`The upload of "` + upload.file.Name + `" is cancelled: the session is closed`))
+		if upload.unregister() && upload.options.Finished != nil {
+			upload.options.Finished(upload, upload.error())
+		}
+	}
+}
+
+func (upload *fileUploadData) error() error {
+	upload.mutex.Lock()
+	defer upload.mutex.Unlock()
+	return upload.err
+}
+
+// receive handles the chunk of the file. It returns the HTTP status.
+// The upload stays registered until the client confirms the end of the upload,
+// so the lost response to the last chunk can be repeated
+func (upload *fileUploadData) receive(offset int64, data []byte) (int, error) {
+	// the chunks are handled one by one
+	upload.receiveMutex.Lock()
+	defer upload.receiveMutex.Unlock()
+
+	upload.mutex.Lock()
+	loaded, finished, err := upload.loaded, upload.finished, upload.err
+	upload.mutex.Unlock()
+
+	switch {
+	case err != nil:
+		return http.StatusGone, err
+
+	case finished || offset != loaded:
+		// the repeated or lost chunk. The client continues from the current offset
+		return http.StatusConflict, nil
+
+	case loaded+int64(len(data)) > upload.file.Size:
+		err = fmt.Errorf(`The size of "%s" is greater than %d`, upload.file.Name, upload.file.Size)
+		upload.stop(err)
+		return http.StatusRequestEntityTooLarge, err
+	}
+
+	if len(data) > 0 {
+		if upload.options.Chunk != nil {
+			err = upload.options.Chunk(upload, offset, data)
+		} else {
+			_, err = upload.writer.Write(data)
+		}
+		if err != nil {
+			upload.stop(err)
+			return http.StatusInternalServerError, err
+		}
+	}
+
+	upload.mutex.Lock()
+	upload.loaded += int64(len(data))
+	completed := upload.loaded == upload.file.Size
+	if completed {
+		upload.finished = true
+	}
+	upload.mutex.Unlock()
+
+	if completed && upload.writer != nil {
+		upload.writer.Close()
+	}
+	return http.StatusOK, nil
+}
+
+// serveFileUpload receives the chunk of the file upload. The request is "POST /upload/<id>?offset=<offset>",
+// the body is the content of the chunk. The "Upload-Offset" header of the response is the number of received bytes
+func serveFileUpload(id string, w http.ResponseWriter, r *http.Request) {
+	fileUploadsMutex.Lock()
+	upload, ok := fileUploads[id]
+	fileUploadsMutex.Unlock()
+
+	if !ok {
+		http.Error(w, "Upload not found", http.StatusGone)
+		return
+	}
+	upload.timer.Reset(UploadExpiration)
+
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "Invalid offset", http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(upload.options.ChunkSize)))
+	if err != nil {
+		w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Loaded(), 10))
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			http.Error(w, "Chunk too large", http.StatusRequestEntityTooLarge)
+		} else {
+			// the client repeats the chunk
+			http.Error(w, err.Error(), http.StatusRequestTimeout)
+		}
+		return
+	}
+
+	status, err := upload.receive(offset, data)
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Loaded(), 10))
+	if err != nil {
+		http.Error(w, err.Error(), status)
+	} else {
+		w.WriteHeader(status)
+	}
+}
+
+// register adds the upload to the list of the active uploads. If the file is larger than
+// the MaxSize option then the upload is finished with an error and false is returned
+func (upload *fileUploadData) register() bool {
+	if upload.options.MaxSize > 0 && upload.file.Size > upload.options.MaxSize {
+		upload.stop(fmt.Errorf(`The size of "%s" (%d) is greater than %d`, upload.file.Name, upload.file.Size, upload.options.MaxSize))
+		if upload.options.Finished != nil {
+			upload.options.Finished(upload, upload.error())
+		}
+		return false
+	}
+
+	fileUploadsMutex.Lock()
+	fileUploads[upload.id] = upload
+	upload.timer = time.AfterFunc(UploadExpiration, upload.expire)
+	fileUploadsMutex.Unlock()
+	return true
+}
+
+func (picker *filePickerData) UploadFile(file FileInfo, options FileUploadOptions) FileUpload {
+	index := picker.fileIndex(file)
+	if index < 0 {
+		ErrorLogF(`File "%s" is not selected`, file.Name)
+		return nil
+	}
+
+	upload := newFileUpload(picker, picker.files[index], options)
+	if upload == nil {
+		return nil
+	}
+	if upload.register() {
+		picker.session.callFunc("uploadSelectedFile", picker.htmlID(), index, upload.id, upload.options.ChunkSize)
+	}
+	return upload
+}
+
+// handleFileUploadCommand handles the "fileUploadProgress", "fileUploaded" and "fileUploadError"
+// messages of the client for the uploads started by the owner view
+func handleFileUploadCommand(owner View, command string, data DataObject) {
+	id, _ := data.PropertyValue("upload")
+
+	fileUploadsMutex.Lock()
+	upload, ok := fileUploads[id]
+	fileUploadsMutex.Unlock()
+
+	if !ok || upload.ownerID != owner.htmlID() || upload.session != owner.Session() {
+		return
+	}
+
+	switch command {
+	case "fileUploadProgress":
+		if upload.options.Progress != nil {
+			upload.options.Progress(upload, upload.Loaded(), upload.file.Size)
+		}
+
+	case "fileUploaded", "fileUploadError":
+		if command == "fileUploaded" {
+			if upload.Loaded() != upload.file.Size {
+				upload.stop(fmt.Errorf(`The upload of "%s" is not completed`, upload.file.Name))
+			}
+		} else {
+			text, _ := data.PropertyValue("error")
+			upload.stop(errors.New(text))
+		}
+
+		upload.unregister()
+		if upload.options.Finished != nil {
+			upload.options.Finished(upload, upload.error())
+		}
+	}
+}
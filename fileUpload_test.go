@@ -0,0 +1,235 @@
+package rui
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileUpload(t *testing.T) {
+	createTestLog(t, false)
+
+	session := newSession(nil, 0, "", nil)
+	bridge := new(canvasTestBridge)
+	session.setBridge(nil, bridge)
+
+	picker := NewFilePicker(session, nil)
+	selected := ParseDataText(`fileSelected{session=0, id=` + picker.htmlID() + `, files=[
+		_{name="a.txt", last-modified=1700000000000, size=10, mime-type="text/plain"},
+		_{name="b.bin", last-modified=1700000000000, size=5, mime-type="application/octet-stream"}]}`)
+	picker.handleCommand(picker, selected.Tag(), selected)
+	files := picker.Files()
+	if len(files) != 2 {
+		t.Fatalf("invalid files: %v", files)
+	}
+
+	post := func(upload FileUpload, offset int, data string) (int, string) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("POST", "/upload/"+upload.(*fileUploadData).id+"?offset="+strconv.Itoa(offset), strings.NewReader(data))
+		serveFileUpload(upload.(*fileUploadData).id, recorder, request)
+		return recorder.Code, recorder.Header().Get("Upload-Offset")
+	}
+	event := func(command string, upload FileUpload, params string) {
+		data := ParseDataText(command + `{session=0, id=` + picker.htmlID() + `, upload=` + upload.(*fileUploadData).id + params + `}`)
+		picker.handleCommand(picker, data.Tag(), data)
+	}
+
+	received := new(bytes.Buffer)
+	progress := []int64{}
+	var finished error = io.ErrUnexpectedEOF
+	upload := picker.UploadFile(files[0], FileUploadOptions{
+		ChunkSize: 4,
+		Chunk: func(upload FileUpload, offset int64, data []byte) error {
+			if int64(received.Len()) != offset || upload.Loaded() != offset {
+				t.Errorf("invalid chunk offset: %d", offset)
+			}
+			received.Write(data)
+			return nil
+		},
+		Progress: func(upload FileUpload, loaded, total int64) {
+			progress = append(progress, loaded)
+		},
+		Finished: func(upload FileUpload, err error) {
+			finished = err
+		},
+	})
+	if len(bridge.funcs) != 1 || bridge.funcs[0] != "uploadSelectedFile["+picker.htmlID()+" 0 "+upload.(*fileUploadData).id+" 4]" {
+		t.Errorf("the upload is not started: %v", bridge.funcs)
+	}
+
+	for _, test := range []struct {
+		offset int
+		data   string
+		code   int
+		loaded string
+	}{
+		{0, "0123", http.StatusOK, "4"},
+		{0, "0123", http.StatusConflict, "4"}, // the repeated chunk
+		{8, "89", http.StatusConflict, "4"},   // the lost chunk
+		{4, "45678", http.StatusRequestEntityTooLarge, "4"},
+		{4, "4567", http.StatusOK, "8"},
+		{8, "89", http.StatusOK, "10"},
+		{8, "89", http.StatusConflict, "10"}, // the lost response
+	} {
+		if code, loaded := post(upload, test.offset, test.data); code != test.code || loaded != test.loaded {
+			t.Errorf("POST %d %s: status %d, offset %s", test.offset, test.data, code, loaded)
+		}
+		if test.code == http.StatusOK {
+			event("fileUploadProgress", upload, "")
+		}
+	}
+	event("fileUploaded", upload, "")
+
+	if received.String() != "0123456789" || finished != nil || len(progress) != 3 || progress[2] != 10 {
+		t.Errorf("invalid upload: %q, %v, %v", received.String(), progress, finished)
+	}
+	if code, _ := post(upload, 10, ""); code != http.StatusGone {
+		t.Errorf("the finished upload is not removed: %d", code)
+	}
+
+	// the content is read from Reader
+	finished = io.ErrUnexpectedEOF
+	upload = picker.UploadFile(files[1], FileUploadOptions{
+		Finished: func(upload FileUpload, err error) {
+			finished = err
+		},
+	})
+	done := make(chan string)
+	go func() {
+		data, err := io.ReadAll(upload.Reader())
+		if err != nil {
+			t.Error(err)
+		}
+		done <- string(data)
+	}()
+	if code, _ := post(upload, 0, "abcde"); code != http.StatusOK {
+		t.Errorf("POST status %d", code)
+	}
+	if text := <-done; text != "abcde" {
+		t.Errorf("invalid reader content: %q", text)
+	}
+	event("fileUploaded", upload, "")
+	if finished != nil {
+		t.Errorf("the upload is not finished: %v", finished)
+	}
+
+	// the size limit
+	bridge.funcs = nil
+	finished = nil
+	picker.UploadFile(files[0], FileUploadOptions{
+		MaxSize: 8,
+		Finished: func(upload FileUpload, err error) {
+			finished = err
+		},
+	})
+	if finished == nil || len(bridge.funcs) != 0 {
+		t.Errorf("the large file is uploaded: %v", bridge.funcs)
+	}
+
+	// the cancelled upload
+	upload = picker.UploadFile(files[0], FileUploadOptions{
+		Chunk: func(upload FileUpload, offset int64, data []byte) error {
+			return nil
+		},
+	})
+	bridge.funcs = nil
+	upload.Cancel()
+	if len(bridge.funcs) != 1 || bridge.funcs[0] != "cancelFileUpload["+upload.(*fileUploadData).id+"]" {
+		t.Errorf("the upload is not cancelled on the client: %v", bridge.funcs)
+	}
+	if code, _ := post(upload, 0, "0123"); code != http.StatusGone {
+		t.Errorf("the cancelled upload receives data: %d", code)
+	}
+}
+
+func TestFileUploadExpiration(t *testing.T) {
+	createTestLog(t, false)
+
+	saveExpiration := UploadExpiration
+	UploadExpiration = 20 * time.Millisecond
+	defer func() {
+		UploadExpiration = saveExpiration
+	}()
+
+	session := newSession(nil, 0, "", nil)
+	bridge := new(canvasTestBridge)
+	events := make(chan DataObject, 8)
+	session.setBridge(events, bridge)
+
+	picker := NewFilePicker(session, nil)
+	selected := ParseDataText(`fileSelected{session=0, id=` + picker.htmlID() + `, files=[
+		_{name="a.txt", last-modified=1700000000000, size=10, mime-type="text/plain"}]}`)
+	picker.handleCommand(picker, selected.Tag(), selected)
+	file := picker.Files()[0]
+
+	finished := make(chan error, 1)
+	start := func() (FileUpload, chan error) {
+		upload := picker.UploadFile(file, FileUploadOptions{
+			Finished: func(upload FileUpload, err error) {
+				finished <- err
+			},
+		})
+		read := make(chan error, 1)
+		go func() {
+			_, err := io.ReadAll(upload.Reader())
+			read <- err
+		}()
+		return upload, read
+	}
+	post := func(upload FileUpload) int {
+		recorder := httptest.NewRecorder()
+		id := upload.(*fileUploadData).id
+		serveFileUpload(id, recorder, httptest.NewRequest("POST", "/upload/"+id+"?offset=0", strings.NewReader("0123")))
+		return recorder.Code
+	}
+
+	// the idle upload is expired, the Finished callback is called in the session goroutine
+	upload, read := start()
+	if err := <-read; err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("the reader of the expired upload returns %v", err)
+	}
+	data := <-events
+	if len(finished) != 0 {
+		t.Error("the Finished callback is called outside the session goroutine")
+	}
+	bridge.funcs = nil
+	session.handleEvent(data.Tag(), data)
+	if err := <-finished; err == nil {
+		t.Error("the expired upload is finished without an error")
+	}
+	if len(bridge.funcs) != 1 || bridge.funcs[0] != "cancelFileUpload["+upload.(*fileUploadData).id+"]" {
+		t.Errorf("the expired upload is not cancelled on the client: %v", bridge.funcs)
+	}
+	if code := post(upload); code != http.StatusGone {
+		t.Errorf("the expired upload receives data: %d", code)
+	}
+
+	// the upload of the disconnected session
+	session.setBridge(nil, bridge)
+	upload, read = start()
+	if err := <-read; err == nil {
+		t.Error("the reader of the expired upload is not closed")
+	}
+	if err := <-finished; err == nil {
+		t.Error("the expired upload is finished without an error")
+	}
+
+	// the upload of the closed session
+	UploadExpiration = time.Hour
+	upload, read = start()
+	session.onFinish()
+	if err := <-read; err == nil || !strings.Contains(err.Error(), "session is closed") {
+		t.Errorf("the reader of the closed session returns %v", err)
+	}
+	if err := <-finished; err == nil {
+		t.Error("the upload of the closed session is finished without an error")
+	}
+	if code := post(upload); code != http.StatusGone {
+		t.Errorf("the upload of the closed session receives data: %d", code)
+	}
+}
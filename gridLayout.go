@@ -33,6 +33,13 @@ func (gridLayout *gridLayoutData) init(session Session) {
 	gridLayout.systemClass = "ruiGridLayout"
 }
 
+func (gridLayout *gridLayoutData) supportedProperties() []string {
+	return append([]string{
+		CellWidth, CellHeight, CellVerticalAlign, CellHorizontalAlign, GridRowGap, GridColumnGap,
+		GridAutoFlow,
+	}, gridLayout.viewsContainerData.supportedProperties()...)
+}
+
 func (gridLayout *gridLayoutData) String() string {
 	return getViewString(gridLayout)
 }
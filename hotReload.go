@@ -0,0 +1,138 @@
+//go:build !wasm
+
+package rui
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type resourceWatcher struct {
+	path  string
+	files map[string]time.Time
+}
+
+// StartResourceWatcher starts the development mode of hot reloading of resources.
+// The resource directory set by SetResourcePath is checked for changes with the given interval.
+// Changed themes and strings are re-parsed, the CSS of all live sessions is updated,
+// and the root views of all sessions are re-created (by SessionContent.CreateRootView) if a view file is changed.
+// The session state (the SessionContent, the language, the custom theme, the client storage, etc.) is kept.
+// Embedded resources (added by AddEmbedResources) are not watched.
+// Entries removed from a theme or a strings file remain loaded until the application is restarted.
+// The function returns the function which stops watching.
+func StartResourceWatcher(interval time.Duration) func() {
+	if resources.path == "" {
+		ErrorLog("The resource path is not set. Use SetResourcePath before StartResourceWatcher")
+		return func() {}
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	watcher := newResourceWatcher(resources.path)
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				ticker.Stop()
+				return
+
+			case <-ticker.C:
+				if changed, viewsChanged := watcher.reload(); changed {
+					notifyResourcesChanged(viewsChanged)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+func newResourceWatcher(path string) *resourceWatcher {
+	watcher := &resourceWatcher{path: path}
+	watcher.files = watcher.scan()
+	return watcher
+}
+
+func (watcher *resourceWatcher) scan() map[string]time.Time {
+	files := map[string]time.Time{}
+	for _, dir := range []string{themeDir, stringsDir, viewDir} {
+		filepath.WalkDir(watcher.path+dir, func(path string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && isDataResourceFile(path) {
+				if info, err := entry.Info(); err == nil {
+					files[filepath.ToSlash(path)] = info.ModTime()
+				}
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// reload re-parses the changed themes and strings. It returns true if any resource is changed
+// and true in the second result if a view file is changed, added or removed
+func (watcher *resourceWatcher) reload() (bool, bool) {
+	files := watcher.scan()
+	changed := false
+	viewsChanged := false
+
+	for path, modTime := range files {
+		if oldTime, ok := watcher.files[path]; ok && oldTime.Equal(modTime) {
+			continue
+		}
+
+		changed = true
+		dir := strings.TrimPrefix(path, filepath.ToSlash(watcher.path))
+		if n := strings.IndexRune(dir, '/'); n > 0 {
+			dir = dir[:n]
+		}
+
+		switch dir {
+		case themeDir:
+			if data, err := os.ReadFile(path); err == nil {
+				if registerThemeData(parseDataResource(path, string(data))) {
+					DebugLog(`Theme reloaded: ` + path)
+				}
+			} else {
+				ErrorLog(err.Error())
+			}
+
+		case stringsDir:
+			if data, err := os.ReadFile(path); err == nil {
+				AddStringResources(parseDataResource(path, string(data)))
+				DebugLog(`Strings reloaded: ` + path)
+			} else {
+				ErrorLog(err.Error())
+			}
+
+		case viewDir:
+			viewsChanged = true
+		}
+	}
+
+	for path := range watcher.files {
+		if _, ok := files[path]; !ok {
+			changed = true
+			if strings.HasPrefix(path, filepath.ToSlash(watcher.path+viewDir)) {
+				viewsChanged = true
+			}
+		}
+	}
+
+	watcher.files = files
+	return changed, viewsChanged
+}
+
+func notifyResourcesChanged(viewsChanged bool) {
+	for _, session := range allSessions() {
+		session.resourcesChanged(viewsChanged)
+	}
+}
@@ -0,0 +1,124 @@
+//go:build !wasm
+
+package rui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type hotReloadTestContent struct {
+	count int
+}
+
+func (content *hotReloadTestContent) CreateRootView(session Session) View {
+	content.count++
+	return CreateViewFromResources(session, "main")
+}
+
+func TestResourceWatcher(t *testing.T) {
+	createTestLog(t, false)
+
+	dir := t.TempDir()
+	for _, sub := range []string{imageDir, themeDir, stringsDir, viewDir} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	modTime := time.Now().Add(-time.Hour)
+	writeFile := func(name, text string) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		modTime = modTime.Add(time.Minute)
+		os.Chtimes(path, modTime, modTime)
+	}
+
+	writeFile("themes/hot.rui", `theme { colors = _{ hotColor = #FF000001 } }`)
+	writeFile("strings/hot.rui", `strings:hot { hotString = "first" }`)
+	writeFile("views/main.rui", `TextView { id = hotText, text = "first" }`)
+
+	oldPath := resources.path
+	defer func() {
+		resources.path = oldPath
+		delete(defaultTheme.data().colors, "hotColor")
+		delete(stringResources, "hot")
+	}()
+	SetResourcePath(dir)
+
+	watcher := newResourceWatcher(resources.path)
+	if changed, _ := watcher.reload(); changed {
+		t.Error("changes without modifications")
+	}
+
+	writeFile("themes/hot.rui", `theme { colors = _{ hotColor = #FF000002 } }`)
+	writeFile("strings/hot.rui", `strings:hot { hotString = "second" }`)
+	if changed, viewsChanged := watcher.reload(); !changed || viewsChanged {
+		t.Errorf("theme and strings changes: %v, %v", changed, viewsChanged)
+	}
+	if color, _ := defaultTheme.Color("hotColor"); color != "#FF000002" {
+		t.Errorf("reloaded theme color: %s", color)
+	}
+	if text, _ := GetString("hotString", "hot"); text != "second" {
+		t.Errorf("reloaded string: %s", text)
+	}
+
+	session := newSession(nil, 1, "", nil)
+	content := new(hotReloadTestContent)
+	session.setContent(content)
+	events := make(chan DataObject, 4)
+	session.setBridge(events, nil)
+
+	// the queue of the disconnected session is never read
+	disconnected := newSession(nil, 2, "", nil)
+	disconnectedEvents := make(chan DataObject, 1)
+	disconnectedEvents <- NewDataObject("root-size")
+	disconnected.setBridge(disconnectedEvents, nil)
+
+	app := &application{sessions: map[int]Session{1: session, 2: disconnected}}
+	appsMutex.Lock()
+	apps = append(apps, app)
+	appsMutex.Unlock()
+	defer func() {
+		appsMutex.Lock()
+		apps = apps[:len(apps)-1]
+		appsMutex.Unlock()
+	}()
+
+	stop := StartResourceWatcher(5 * time.Millisecond)
+	writeFile("views/main.rui", `TextView { id = hotText, text = "second" }`)
+	writeFile("strings/hot.rui", `strings:hot { hotString = "third" }`)
+
+	// the test goroutine works as the session goroutine
+	timeout := time.After(5 * time.Second)
+	for GetText(session.RootView(), "hotText") != "second" {
+		select {
+		case data := <-events:
+			session.handleEvent(data.Tag(), data)
+			GetString("hotString", "hot")
+
+		case <-timeout:
+			t.Fatalf("root view is not re-created: %d, %s", content.count, GetText(session.RootView(), "hotText"))
+		}
+	}
+	stop()
+
+	if content.count < 2 {
+		t.Errorf("root view is not re-created: %d", content.count)
+	}
+	if text, _ := GetString("hotString", "hot"); text != "third" {
+		t.Errorf("reloaded string: %s", text)
+	}
+	if len(disconnectedEvents) != 1 {
+		t.Errorf("%d events are queued to the disconnected session", len(disconnectedEvents))
+	}
+
+	os.Remove(filepath.Join(dir, "views", "main.rui"))
+	if changed, viewsChanged := watcher.reload(); !changed || !viewsChanged {
+		t.Errorf("view removing: %v, %v", changed, viewsChanged)
+	}
+}
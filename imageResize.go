@@ -0,0 +1,453 @@
+//go:build !wasm
+
+package rui
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// resizedImageCacheLimit is the maximal total size (in bytes) of the resized images kept in memory
+	resizedImageCacheLimit = 64 << 20
+	// imageResizeStep is the step of the image widths generated for the srcset of ImageView.
+	// It limits the number of different resized images when the view is resized
+	imageResizeStep = 64
+	// defaultJPEGQuality is the quality of the JPEG image if the "q" parameter is not set
+	defaultJPEGQuality = 85
+)
+
+// imageResizeParams describes the query parameters of the image resource URL:
+//
+//	w - the maximal width of the result in pixels;
+//	h - the maximal height of the result in pixels;
+//	format - the format of the result: "png", "jpeg" ("jpg") or "gif" (the format of the source by default);
+//	q - the quality of the JPEG image (1...100), it is ignored by other formats.
+//
+// The image is scaled down preserving the aspect ratio and is never enlarged.
+// The width of the result is rounded up to a multiple of imageResizeStep (as in the srcset of ImageView),
+// so the number of different resized images is limited
+type imageResizeParams struct {
+	width, height int
+	format        string
+	quality       int
+}
+
+type resizedImage struct {
+	data     []byte
+	mimeType string
+	modTime  time.Time
+}
+
+// imageSource describes the source image resource
+type imageSource struct {
+	width, height int
+	format        string
+}
+
+// resizeCall is the resizing in progress. The concurrent requests of the same image wait for it
+type resizeCall struct {
+	done chan struct{}
+	img  *resizedImage
+	err  error
+}
+
+type resizedImageCache struct {
+	mutex   sync.Mutex
+	images  map[string]*resizedImage
+	order   []string
+	size    int
+	sources map[string]imageSource
+	calls   map[string]*resizeCall
+}
+
+var resizedImages = resizedImageCache{
+	images:  map[string]*resizedImage{},
+	order:   []string{},
+	sources: map[string]imageSource{},
+	calls:   map[string]*resizeCall{},
+}
+
+func isResizableImage(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".png", ".jpg", ".jpeg", ".gif":
+		return true
+	}
+	return false
+}
+
+func parseImageResizeParams(query url.Values) (imageResizeParams, bool) {
+	params := imageResizeParams{}
+	ok := false
+
+	intParam := func(name string) int {
+		if text := query.Get(name); text != "" {
+			if n, err := strconv.Atoi(text); err == nil && n > 0 {
+				ok = true
+				return n
+			}
+		}
+		return 0
+	}
+
+	params.width = intParam("w")
+	params.height = intParam("h")
+	params.quality = min(intParam("q"), 100)
+
+	switch format := strings.ToLower(query.Get("format")); format {
+	case "png", "jpeg", "gif":
+		params.format = format
+		ok = true
+
+	case "jpg":
+		params.format = "jpeg"
+		ok = true
+	}
+
+	return params, ok
+}
+
+func (params imageResizeParams) key() string {
+	return strconv.Itoa(params.width) + "x" + strconv.Itoa(params.height) + "." + params.format + "." + strconv.Itoa(params.quality)
+}
+
+// normalize converts the parameters to the canonical form for the source image, so the equivalent requests
+// share the same cache entry. Returns false if the result is the source image itself
+func (params imageResizeParams) normalize(source imageSource) (imageResizeParams, bool) {
+	if params.format == "" {
+		params.format = source.format
+	}
+	if params.format != "jpeg" {
+		params.quality = 0
+	}
+
+	width, _ := params.size(source.width, source.height)
+	width = int(math.Ceil(float64(width)/imageResizeStep)) * imageResizeStep
+	if width >= source.width {
+		params.width = 0
+	} else {
+		params.width = width
+	}
+	params.height = 0
+
+	return params, params.width > 0 || params.format != source.format || params.quality > 0
+}
+
+// size returns the size of the result for the source image of the given size
+func (params imageResizeParams) size(width, height int) (int, int) {
+	scale := 1.0
+	if params.width > 0 {
+		scale = math.Min(scale, float64(params.width)/float64(width))
+	}
+	if params.height > 0 {
+		scale = math.Min(scale, float64(params.height)/float64(height))
+	}
+	if scale >= 1 {
+		return width, height
+	}
+	return max(1, int(math.Round(float64(width)*scale))), max(1, int(math.Round(float64(height)*scale)))
+}
+
+// serveResizedImage serves the image resource resized and converted according to the query parameters
+// of the request. Returns false if the request has no resize parameters or the image can not be resized
+func serveResizedImage(filename string, w http.ResponseWriter, r *http.Request) bool {
+	if r.URL.RawQuery == "" || !isResizableImage(filename) {
+		return false
+	}
+
+	params, ok := parseImageResizeParams(r.URL.Query())
+	if !ok {
+		return false
+	}
+
+	source, ok := resizedImages.source(filename)
+	if !ok {
+		return false
+	}
+	if params, ok = params.normalize(source); !ok {
+		// the source file is served
+		return false
+	}
+
+	img, err := resizedImages.image(filename, params)
+	if err != nil {
+		ErrorLogF(`Resize of "%s" image error: %s`, filename, err.Error())
+		return false
+	}
+
+	w.Header().Set("Content-Type", img.mimeType)
+	http.ServeContent(w, r, "", img.modTime, bytes.NewReader(img.data))
+	return true
+}
+
+func (cache *resizedImageCache) image(filename string, params imageResizeParams) (*resizedImage, error) {
+	key := filename + "?" + params.key()
+
+	cache.mutex.Lock()
+	if img, ok := cache.images[key]; ok {
+		cache.mutex.Unlock()
+		return img, nil
+	}
+	if call, ok := cache.calls[key]; ok {
+		cache.mutex.Unlock()
+		<-call.done
+		return call.img, call.err
+	}
+	call := &resizeCall{done: make(chan struct{})}
+	cache.calls[key] = call
+	cache.mutex.Unlock()
+
+	defer close(call.done)
+	img, err := resizeImageResource(filename, params)
+	call.img, call.err = img, err
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	delete(cache.calls, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := cache.images[key]; !ok {
+		cache.images[key] = img
+		cache.order = append(cache.order, key)
+		cache.size += len(img.data)
+		for cache.size > resizedImageCacheLimit && len(cache.order) > 1 {
+			cache.size -= len(cache.images[cache.order[0]].data)
+			delete(cache.images, cache.order[0])
+			cache.order = cache.order[1:]
+		}
+	}
+	return img, nil
+}
+
+// source returns the size in pixels and the format of the image resource
+func (cache *resizedImageCache) source(filename string) (imageSource, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	source, ok := cache.sources[filename]
+	if !ok {
+		data := readImageResource(filename)
+		if data == nil {
+			// only the existing resources are remembered
+			return source, false
+		}
+		if config, format, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+			source = imageSource{width: config.Width, height: config.Height, format: format}
+		}
+		cache.sources[filename] = source
+	}
+	return source, source.width > 0 && source.height > 0
+}
+
+// naturalSize returns the size in pixels of the image resource
+func (cache *resizedImageCache) naturalSize(filename string) (int, int, bool) {
+	source, ok := cache.source(filename)
+	return source.width, source.height, ok
+}
+
+func resizeImageResource(filename string, params imageResizeParams) (*resizedImage, error) {
+	data := readImageResource(filename)
+	if data == nil {
+		return nil, errors.New("the image is not found")
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	width, height := params.size(bounds.Dx(), bounds.Dy())
+	if params.format == "" {
+		params.format = format
+	}
+
+	result := &resizedImage{mimeType: "image/" + params.format, modTime: time.Now()}
+	if width == bounds.Dx() && height == bounds.Dy() && params.format == format && params.quality == 0 {
+		result.data = data
+		return result, nil
+	}
+
+	if width != bounds.Dx() || height != bounds.Dy() {
+		src = resizeImage(src, width, height)
+	}
+
+	buffer := new(bytes.Buffer)
+	switch params.format {
+	case "jpeg":
+		quality := params.quality
+		if quality <= 0 {
+			quality = defaultJPEGQuality
+		}
+		err = jpeg.Encode(buffer, src, &jpeg.Options{Quality: quality})
+
+	case "gif":
+		err = gif.Encode(buffer, src, nil)
+
+	case "png":
+		err = png.Encode(buffer, src)
+
+	default:
+		err = errors.New(`unsupported image format "` + params.format + `"`)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	result.data = buffer.Bytes()
+	return result, nil
+}
+
+// imageResampleWeights returns the weights of the source pixels for each pixel of the result.
+// Every pixel of the result is the average of the source pixels covered by it (area averaging)
+func imageResampleWeights(srcSize, dstSize int) ([]int, [][]float64) {
+	scale := float64(srcSize) / float64(dstSize)
+	starts := make([]int, dstSize)
+	weights := make([][]float64, dstSize)
+	for i := range weights {
+		x0 := float64(i) * scale
+		x1 := x0 + scale
+		start := int(x0)
+		end := min(srcSize, int(math.Ceil(x1)))
+		starts[i] = start
+		weights[i] = make([]float64, end-start)
+		for k := start; k < end; k++ {
+			weights[i][k-start] = (math.Min(x1, float64(k+1)) - math.Max(x0, float64(k))) / scale
+		}
+	}
+	return starts, weights
+}
+
+// resizeImage scales down the image to the given size
+func resizeImage(src image.Image, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	rgba, ok := src.(*image.RGBA)
+	if !ok || bounds.Min != (image.Point{}) {
+		rgba = image.NewRGBA(image.Rect(0, 0, srcWidth, srcHeight))
+		draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+	}
+
+	// the horizontal pass: srcHeight rows of the result width. The premultiplied colors are averaged
+	xStarts, xWeights := imageResampleWeights(srcWidth, width)
+	rows := make([]float64, 4*width*srcHeight)
+	for y := 0; y < srcHeight; y++ {
+		line := rgba.Pix[y*rgba.Stride:]
+		for x := 0; x < width; x++ {
+			offset := 4 * (y*width + x)
+			for k, weight := range xWeights[x] {
+				pixel := line[4*(xStarts[x]+k):]
+				for c := 0; c < 4; c++ {
+					rows[offset+c] += weight * float64(pixel[c])
+				}
+			}
+		}
+	}
+
+	// the vertical pass
+	yStarts, yWeights := imageResampleWeights(srcHeight, height)
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var color [4]float64
+			for k, weight := range yWeights[y] {
+				offset := 4 * ((yStarts[y]+k)*width + x)
+				for c := 0; c < 4; c++ {
+					color[c] += weight * rows[offset+c]
+				}
+			}
+			pixel := result.Pix[y*result.Stride+4*x:]
+			for c := 0; c < 4; c++ {
+				pixel[c] = uint8(math.Max(0, math.Min(255, math.Round(color[c]))))
+			}
+		}
+	}
+	return result
+}
+
+// responsiveImageSrcSet returns the "srcset" and "sizes" attributes of the image resource displayed
+// in the box (width, height) with the "fit" mode. The srcset contains the resized variants
+// of the image for 1x and the pixel ratio of the device. Returns empty strings if the image can not be resized
+func responsiveImageSrcSet(src string, width, height float64, fit int, pixelRatio float64) (string, string) {
+	if width <= 0 || fit == NoneFit || strings.Contains(src, "?") || !isResizableImage(src) {
+		return "", ""
+	}
+	if _, ok := resources.images[src]; !ok {
+		return "", ""
+	}
+
+	naturalWidth, naturalHeight, ok := resizedImages.naturalSize(src)
+	if !ok {
+		return "", ""
+	}
+
+	// the displayed width of the image in CSS pixels
+	displayWidth := width
+	if height > 0 {
+		fitWidth := height * float64(naturalWidth) / float64(naturalHeight)
+		switch fit {
+		case ContainFit, ScaleDownFit:
+			displayWidth = math.Min(width, fitWidth)
+
+		default:
+			displayWidth = math.Max(width, fitWidth)
+		}
+	}
+	displayWidth = math.Ceil(displayWidth)
+
+	scales := []float64{1}
+	if pixelRatio > 1 {
+		scales = append(scales, pixelRatio)
+	}
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	lastWidth := 0
+	for _, scale := range scales {
+		w := int(math.Ceil(displayWidth*scale/imageResizeStep)) * imageResizeStep
+		if w >= naturalWidth {
+			if lastWidth == 0 {
+				// the image is not larger than the displayed size
+				return "", ""
+			}
+			w = naturalWidth
+		}
+		if w == lastWidth {
+			continue
+		}
+		if lastWidth > 0 {
+			buffer.WriteString(", ")
+		}
+		buffer.WriteString(src)
+		if w < naturalWidth {
+			buffer.WriteString("?w=")
+			buffer.WriteString(strconv.Itoa(w))
+		}
+		buffer.WriteRune(' ')
+		buffer.WriteString(strconv.Itoa(w))
+		buffer.WriteRune('w')
+		lastWidth = w
+		if w == naturalWidth {
+			break
+		}
+	}
+
+	return buffer.String(), strconv.Itoa(int(displayWidth)) + "px"
+}
@@ -0,0 +1,13 @@
+//go:build wasm
+
+package rui
+
+import "net/http"
+
+func serveResizedImage(filename string, w http.ResponseWriter, r *http.Request) bool {
+	return false
+}
+
+func responsiveImageSrcSet(src string, width, height float64, fit int, pixelRatio float64) (string, string) {
+	return "", ""
+}
@@ -0,0 +1,149 @@
+//go:build !wasm
+
+package rui
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestImageResize(t *testing.T) {
+	createTestLog(t, false)
+
+	photo := image.NewRGBA(image.Rect(0, 0, 800, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 800; x++ {
+			if x%2 == 0 {
+				photo.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				photo.SetRGBA(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	buffer := new(bytes.Buffer)
+	png.Encode(buffer, photo)
+	path := filepath.Join(t.TempDir(), "photo.png")
+	if err := os.WriteFile(path, buffer.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	registerImage(nil, path, "test/photo.png")
+	defer delete(resources.images, "test/photo.png")
+
+	get := func(url string) (string, image.Image) {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", url, nil)
+		if !serveResourceFile("test/photo.png", recorder, request) {
+			t.Fatalf(`"%s" is not served`, url)
+		}
+		img, _, err := image.Decode(recorder.Body)
+		if err != nil {
+			t.Fatalf(`"%s" decoding error: %s`, url, err.Error())
+		}
+		return recorder.Header().Get("Content-Type"), img
+	}
+
+	// the width is rounded up to a multiple of imageResizeStep
+	mimeType, img := get("/test/photo.png?w=100")
+	if mimeType != "image/png" || img.Bounds() != image.Rect(0, 0, 128, 64) {
+		t.Errorf("invalid resized image: %s %v", mimeType, img.Bounds())
+	}
+	// the neighbouring red and blue pixels are averaged
+	if r, g, b, _ := img.At(10, 10).RGBA(); r>>8 < 120 || r>>8 > 135 || g != 0 || b>>8 < 120 || b>>8 > 135 {
+		t.Errorf("invalid averaged color: %v", img.At(10, 10))
+	}
+
+	mimeType, img = get("/test/photo.png?w=200&h=50&format=jpg&q=90")
+	if mimeType != "image/jpeg" || img.Bounds() != image.Rect(0, 0, 128, 64) {
+		t.Errorf("invalid converted image: %s %v", mimeType, img.Bounds())
+	}
+	if _, ok := img.(*image.YCbCr); !ok {
+		t.Errorf("the image is not converted to JPEG: %T", img)
+	}
+
+	// the image is not enlarged
+	if _, img = get("/test/photo.png?w=2000"); img.Bounds() != image.Rect(0, 0, 800, 400) {
+		t.Errorf("the image is enlarged: %v", img.Bounds())
+	}
+
+	if _, ok := resizedImages.images["test/photo.png?128x0.png.0"]; !ok {
+		t.Error("the resized image is not cached")
+	}
+
+	// the equivalent requests share the cache entry, the quality is ignored by PNG
+	count := len(resizedImages.images)
+	var wait sync.WaitGroup
+	for _, url := range []string{"/test/photo.png?w=65", "/test/photo.png?w=128&q=50", "/test/photo.png?h=60&format=png"} {
+		for i := 0; i < 4; i++ {
+			wait.Add(1)
+			go func(url string) {
+				defer wait.Done()
+				recorder := httptest.NewRecorder()
+				serveResourceFile("test/photo.png", recorder, httptest.NewRequest("GET", url, nil))
+			}(url)
+		}
+	}
+	wait.Wait()
+	if len(resizedImages.images) != count {
+		t.Errorf("the equivalent requests are cached separately: %d images, expected %d", len(resizedImages.images), count)
+	}
+
+	// the request which does not change the image is served by the source file
+	recorder := httptest.NewRecorder()
+	serveResourceFile("test/photo.png", recorder, httptest.NewRequest("GET", "/test/photo.png?w=900&q=10", nil))
+	if !bytes.Equal(recorder.Body.Bytes(), buffer.Bytes()) {
+		t.Error("the source file is not served")
+	}
+	if len(resizedImages.images) != count {
+		t.Error("the source image is cached")
+	}
+
+	session := newSession(nil, 0, "", nil)
+	session.setBridge(nil, new(themeTestBridge))
+	view := NewImageView(session, Params{
+		Source: "test/photo.png",
+		Fit:    ContainFit,
+	})
+	viewData := view.(*imageViewData)
+	viewData.frame = Frame{Width: 100, Height: 100}
+	session.(*sessionData).pixelRatio = 2
+
+	html := new(strings.Builder)
+	viewHTML(view, html)
+	if !strings.Contains(html.String(), `srcset="test/photo.png?w=128 128w, test/photo.png?w=256 256w" sizes="100px"`) {
+		t.Errorf("invalid srcset: %s", html)
+	}
+
+	if srcset, sizes := responsiveImageSrcSet("test/photo.png", 100, 100, CoverFit, 1); srcset != "test/photo.png?w=256 256w" || sizes != "200px" {
+		t.Errorf("invalid cover srcset: %s, %s", srcset, sizes)
+	}
+	if srcset, _ := responsiveImageSrcSet("test/photo.png", 100, 100, NoneFit, 2); srcset != "" {
+		t.Errorf("the srcset of NoneFit image: %s", srcset)
+	}
+	if srcset, _ := responsiveImageSrcSet("test/photo.png", 500, 300, FillFit, 2); srcset != "test/photo.png?w=640 640w, test/photo.png 800w" {
+		t.Errorf("invalid srcset of the large view: %s", srcset)
+	}
+	if srcset, _ := responsiveImageSrcSet("test/photo.png", 1000, 500, FillFit, 1); srcset != "" {
+		t.Errorf("the srcset of the small image: %s", srcset)
+	}
+
+	view = NewImageView(session, Params{
+		Source: "test/photo.png",
+		SrcSet: "test/photo@2x.png",
+		Fit:    ContainFit,
+	})
+	view.(*imageViewData).frame = Frame{Width: 100, Height: 100}
+	html.Reset()
+	viewHTML(view, html)
+	if strings.Contains(html.String(), "sizes=") {
+		t.Errorf("the srcset property is ignored: %s", html)
+	}
+}
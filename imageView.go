@@ -49,6 +49,8 @@ type imageViewData struct {
 	naturalWidth  float64
 	naturalHeight float64
 	currentSrc    string
+	srcset        string
+	sizes         string
 }
 
 // NewImageView create new ImageView object and return it
@@ -70,6 +72,13 @@ func (imageView *imageViewData) init(session Session) {
 	imageView.systemClass = "ruiImageView"
 }
 
+func (imageView *imageViewData) supportedProperties() []string {
+	return append([]string{
+		Source, SrcSet, AltText, Fit, ImageVerticalAlign, ImageHorizontalAlign, LoadedEvent,
+		ErrorEvent,
+	}, imageView.viewData.supportedProperties()...)
+}
+
 func (imageView *imageViewData) String() string {
 	return getViewString(imageView)
 }
@@ -105,7 +114,13 @@ func (imageView *imageViewData) remove(tag string) {
 		switch tag {
 		case Source:
 			imageView.session.updateProperty(imageView.htmlID(), "src", "")
-			imageView.session.removeProperty(imageView.htmlID(), "srcset")
+			imageView.updateSrcSet("", "")
+
+		case SrcSet:
+			imageView.refreshSrcSet()
+
+		case Fit:
+			imageView.refreshSrcSet()
 
 		case AltText:
 			updateInnerHTML(imageView.htmlID(), imageView.session)
@@ -132,14 +147,9 @@ func (imageView *imageViewData) set(tag string, value any) bool {
 		if text, ok := value.(string); ok {
 			imageView.properties.Store(tag, text)
 			if imageView.created {
-				src, srcset := imageView.src(text)
+				src, srcset, sizes := imageView.src(text)
 				imageView.session.updateProperty(imageView.htmlID(), "src", src)
-
-				if srcset != "" {
-					imageView.session.updateProperty(imageView.htmlID(), "srcset", srcset)
-				} else {
-					imageView.session.removeProperty(imageView.htmlID(), "srcset")
-				}
+				imageView.updateSrcSet(srcset, sizes)
 			}
 			imageView.propertyChangedEvent(Source)
 			return true
@@ -154,12 +164,7 @@ func (imageView *imageViewData) set(tag string, value any) bool {
 				imageView.properties.Store(tag, text)
 			}
 			if imageView.created {
-				_, srcset := imageView.src(text)
-				if srcset != "" {
-					imageView.session.updateProperty(imageView.htmlID(), "srcset", srcset)
-				} else {
-					imageView.session.removeProperty(imageView.htmlID(), "srcset")
-				}
+				imageView.refreshSrcSet()
 			}
 			imageView.propertyChangedEvent(Source)
 			return true
@@ -193,6 +198,9 @@ func (imageView *imageViewData) set(tag string, value any) bool {
 				switch tag {
 				case ImageVerticalAlign, ImageHorizontalAlign:
 					updateCSSStyle(imageView.htmlID(), imageView.session)
+
+				case Fit:
+					imageView.refreshSrcSet()
 				}
 			}
 			return true
@@ -259,7 +267,10 @@ func (imageView *imageViewData) htmlTag() string {
 	return "img"
 }
 
-func (imageView *imageViewData) src(src string) (string, string) {
+// src returns the "src", "srcset" and "sizes" attributes of the image. If neither the "srcset" property
+// nor the scaled image files (name@2x.png, etc.) are set then the srcset of the resized resource
+// image is generated by the frame of the view
+func (imageView *imageViewData) src(src string) (string, string, string) {
 	if src != "" && src[0] == '@' {
 		if image, ok := imageView.Session().ImageConstant(src[1:]); ok {
 			src = image
@@ -268,10 +279,54 @@ func (imageView *imageViewData) src(src string) (string, string) {
 		}
 	}
 
-	if src != "" {
-		return src, imageView.srcSet(src)
+	if src == "" {
+		return "", "", ""
+	}
+
+	if srcset := imageView.srcSet(src); srcset != "" {
+		return src, srcset, ""
+	}
+
+	frame := imageView.frame
+	srcset, sizes := responsiveImageSrcSet(src, frame.Width, frame.Height,
+		GetImageViewFit(imageView), imageView.session.PixelRatio())
+	return src, srcset, sizes
+}
+
+func (imageView *imageViewData) updateSrcSet(srcset, sizes string) {
+	if srcset == imageView.srcset && sizes == imageView.sizes {
+		return
+	}
+
+	htmlID := imageView.htmlID()
+	if srcset != "" {
+		imageView.session.updateProperty(htmlID, "srcset", srcset)
+	} else {
+		imageView.session.removeProperty(htmlID, "srcset")
 	}
-	return "", ""
+	if sizes != "" {
+		imageView.session.updateProperty(htmlID, "sizes", sizes)
+	} else if imageView.sizes != "" {
+		imageView.session.removeProperty(htmlID, "sizes")
+	}
+	imageView.srcset = srcset
+	imageView.sizes = sizes
+}
+
+func (imageView *imageViewData) refreshSrcSet() {
+	if imageView.created {
+		if src, ok := imageProperty(imageView, Source, imageView.session); ok {
+			_, srcset, sizes := imageView.src(src)
+			imageView.updateSrcSet(srcset, sizes)
+		} else {
+			imageView.updateSrcSet("", "")
+		}
+	}
+}
+
+func (imageView *imageViewData) onResize(self View, x, y, width, height float64) {
+	imageView.viewData.onResize(self, x, y, width, height)
+	imageView.refreshSrcSet()
 }
 
 func (imageView *imageViewData) htmlProperties(self View, buffer *strings.Builder) {
@@ -279,7 +334,7 @@ func (imageView *imageViewData) htmlProperties(self View, buffer *strings.Builde
 	imageView.viewData.htmlProperties(self, buffer)
 
 	if imageResource, ok := imageProperty(imageView, Source, imageView.Session()); ok && imageResource != "" {
-		if src, srcset := imageView.src(imageResource); src != "" {
+		if src, srcset, sizes := imageView.src(imageResource); src != "" {
 			buffer.WriteString(` src="`)
 			buffer.WriteString(src)
 			buffer.WriteString(`"`)
@@ -288,6 +343,13 @@ func (imageView *imageViewData) htmlProperties(self View, buffer *strings.Builde
 				buffer.WriteString(srcset)
 				buffer.WriteString(`"`)
 			}
+			if sizes != "" {
+				buffer.WriteString(` sizes="`)
+				buffer.WriteString(sizes)
+				buffer.WriteString(`"`)
+			}
+			imageView.srcset = srcset
+			imageView.sizes = sizes
 		}
 	}
 
@@ -49,6 +49,12 @@ func (listLayout *listLayoutData) init(session Session) {
 	listLayout.systemClass = "ruiListLayout"
 }
 
+func (listLayout *listLayoutData) supportedProperties() []string {
+	return append([]string{
+		Orientation, ListWrap, VerticalAlign, HorizontalAlign, ListRowGap, ListColumnGap,
+	}, listLayout.viewsContainerData.supportedProperties()...)
+}
+
 func (listLayout *listLayoutData) String() string {
 	return getViewString(listLayout)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -91,6 +92,15 @@ func (listView *listViewData) init(session Session) {
 	listView.checkedListeners = []func(ListView, []int){}
 }
 
+func (listView *listViewData) supportedProperties() []string {
+	return append([]string{
+		Items, Current, Checked, Orientation, ListWrap, ListRowGap, ListColumnGap, ItemWidth,
+		ItemHeight, ItemHorizontalAlign, ItemVerticalAlign, ItemCheckbox, CheckboxHorizontalAlign,
+		CheckboxVerticalAlign, ListItemStyle, CurrentStyle, CurrentInactiveStyle, ListItemClickedEvent,
+		ListItemSelectedEvent, ListItemCheckedEvent,
+	}, listView.viewData.supportedProperties()...)
+}
+
 func (listView *listViewData) String() string {
 	return getViewString(listView)
 }
@@ -440,14 +450,17 @@ func (listView *listViewData) setItems(value any) bool {
 			case string:
 				items[i] = NewTextView(listView.session, Params{Text: value})
 
+			case time.Time:
+				items[i] = NewTextView(listView.session, Params{Text: listView.session.Locale().formatTime(value)})
+
 			case fmt.Stringer:
 				items[i] = NewTextView(listView.session, Params{Text: value.String()})
 
 			case float32:
-				items[i] = NewTextView(listView.session, Params{Text: fmt.Sprintf("%g", float64(value))})
+				items[i] = NewTextView(listView.session, Params{Text: listView.session.FormatNumber(float64(value), -1)})
 
 			case float64:
-				items[i] = NewTextView(listView.session, Params{Text: fmt.Sprintf("%g", value)})
+				items[i] = NewTextView(listView.session, Params{Text: listView.session.FormatNumber(value, -1)})
 
 			default:
 				if n, ok := isInt(val); ok {
@@ -463,6 +476,13 @@ func (listView *listViewData) setItems(value any) bool {
 	case []View:
 		listView.adapter = NewViewListAdapter(value)
 
+	case DataObject:
+		view := CreateViewFromObject(listView.session, value)
+		if view == nil {
+			return false
+		}
+		listView.adapter = NewViewListAdapter([]View{view})
+
 	case ListAdapter:
 		listView.adapter = value
 
@@ -0,0 +1,161 @@
+package rui
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale describes the language-dependent formatting rules of numbers, dates, and currency
+type Locale struct {
+	// Language - the language tag of the locale, for example "en", "en-GB", "de"
+	Language string
+	// DecimalSeparator - the separator of the integer and fractional parts of a number
+	DecimalSeparator string
+	// GroupSeparator - the separator of thousands groups of a number
+	GroupSeparator string
+	// DatePattern - the layout of a date in the time.Format format, for example "02.01.2006"
+	DatePattern string
+	// TimePattern - the layout of a time in the time.Format format, for example "15:04"
+	TimePattern string
+	// FirstDayOfWeek - the first day of a week
+	FirstDayOfWeek time.Weekday
+	// CurrencySymbol - the symbol of the local currency, for example "$", "€"
+	CurrencySymbol string
+	// CurrencyPattern - the pattern of a currency amount. The "{symbol}" and "{amount}" placeholders
+	// are replaced by the currency symbol and the formatted amount, for example "{symbol}{amount}", "{amount} {symbol}"
+	CurrencyPattern string
+	// CurrencyDigits - the number of fractional digits of a currency amount
+	CurrencyDigits int
+}
+
+var locales = map[string]Locale{
+	"en":    {"en", ".", ",", "01/02/2006", "3:04 PM", time.Sunday, "$", "{symbol}{amount}", 2},
+	"en-gb": {"en-GB", ".", ",", "02/01/2006", "15:04", time.Monday, "£", "{symbol}{amount}", 2},
+	"de":    {"de", ",", ".", "02.01.2006", "15:04", time.Monday, "€", "{amount}\u00a0{symbol}", 2},
+	"fr":    {"fr", ",", "\u202f", "02/01/2006", "15:04", time.Monday, "€", "{amount}\u00a0{symbol}", 2},
+	"es":    {"es", ",", ".", "02/01/2006", "15:04", time.Monday, "€", "{amount}\u00a0{symbol}", 2},
+	"it":    {"it", ",", ".", "02/01/2006", "15:04", time.Monday, "€", "{amount}\u00a0{symbol}", 2},
+	"pt":    {"pt", ",", ".", "02/01/2006", "15:04", time.Sunday, "R$", "{symbol}\u00a0{amount}", 2},
+	"pl":    {"pl", ",", "\u00a0", "02.01.2006", "15:04", time.Monday, "zł", "{amount}\u00a0{symbol}", 2},
+	"ru":    {"ru", ",", "\u00a0", "02.01.2006", "15:04", time.Monday, "₽", "{amount}\u00a0{symbol}", 2},
+	"uk":    {"uk", ",", "\u00a0", "02.01.2006", "15:04", time.Monday, "₴", "{amount}\u00a0{symbol}", 2},
+	"ja":    {"ja", ".", ",", "2006/01/02", "15:04", time.Sunday, "¥", "{symbol}{amount}", 0},
+	"zh":    {"zh", ".", ",", "2006/1/2", "15:04", time.Monday, "¥", "{symbol}{amount}", 2},
+	"ko":    {"ko", ".", ",", "2006. 1. 2.", "15:04", time.Sunday, "₩", "{symbol}{amount}", 0},
+}
+
+// RegisterLocale adds a new locale or replaces the existing one with the same language
+func RegisterLocale(locale Locale) {
+	if lang := normalizeLanguage(locale.Language); lang != "" {
+		locales[lang] = locale
+	}
+}
+
+// GetLocale returns the locale of the language. If there is no locale for the language then
+// the locale of the parent language ("de-AT" -> "de") is returned. The "en" locale is used by default
+func GetLocale(lang string) Locale {
+	return findLocale(languageFallbacks(lang))
+}
+
+func findLocale(langs []string) Locale {
+	for _, lang := range langs {
+		if locale, ok := locales[lang]; ok {
+			return locale
+		}
+	}
+	return locales["en"]
+}
+
+// FormatNumber formats the number using the separators of the locale.
+// "precision" sets the number of digits after the decimal point, -1 means the minimal number of digits necessary
+func (locale Locale) FormatNumber(n float64, precision int) string {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return strconv.FormatFloat(n, 'f', precision, 64)
+	}
+
+	text := strconv.FormatFloat(math.Abs(n), 'f', precision, 64)
+	intPart, fracPart, _ := strings.Cut(text, ".")
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	if n < 0 && strings.Trim(text, "0.") != "" {
+		buffer.WriteRune('-')
+	}
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			buffer.WriteString(locale.GroupSeparator)
+		}
+		buffer.WriteRune(digit)
+	}
+	if fracPart != "" {
+		buffer.WriteString(locale.DecimalSeparator)
+		buffer.WriteString(fracPart)
+	}
+	return buffer.String()
+}
+
+// FormatCurrency formats the amount using the currency symbol and the currency pattern of the locale
+func (locale Locale) FormatCurrency(amount float64) string {
+	abs := math.Abs(amount)
+	text := locale.FormatNumber(abs, locale.CurrencyDigits)
+	pattern := locale.CurrencyPattern
+	if pattern == "" {
+		pattern = "{symbol}{amount}"
+	}
+	text = strings.Replace(strings.Replace(pattern, "{symbol}", locale.CurrencySymbol, 1), "{amount}", text, 1)
+	if amount < 0 && math.Round(abs*math.Pow10(locale.CurrencyDigits)) != 0 {
+		return "-" + text
+	}
+	return text
+}
+
+// FormatDate formats the date using the date pattern of the locale
+func (locale Locale) FormatDate(date time.Time) string {
+	return date.Format(locale.DatePattern)
+}
+
+// FormatDateTime formats the date and time using the date and time patterns of the locale
+func (locale Locale) FormatDateTime(date time.Time) string {
+	return date.Format(locale.DatePattern + " " + locale.TimePattern)
+}
+
+// formatTime formats a time as a date if the time of day is midnight, otherwise as a date and time
+func (locale Locale) formatTime(value time.Time) string {
+	if value.Hour() == 0 && value.Minute() == 0 && value.Second() == 0 && value.Nanosecond() == 0 {
+		return locale.FormatDate(value)
+	}
+	return locale.FormatDateTime(value)
+}
+
+func (session *sessionData) Locale() Locale {
+	if session.locale != nil {
+		return *session.locale
+	}
+	return findLocale(session.stringLanguages())
+}
+
+func (session *sessionData) SetLocale(locale *Locale) {
+	session.locale = locale
+	if session.bridge != nil {
+		session.reload()
+	}
+}
+
+func (session *sessionData) FormatNumber(n float64, precision int) string {
+	return session.Locale().FormatNumber(n, precision)
+}
+
+func (session *sessionData) FormatCurrency(amount float64) string {
+	return session.Locale().FormatCurrency(amount)
+}
+
+func (session *sessionData) FormatDate(date time.Time) string {
+	return session.Locale().FormatDate(date)
+}
+
+func (session *sessionData) FormatDateTime(date time.Time) string {
+	return session.Locale().FormatDateTime(date)
+}
@@ -0,0 +1,79 @@
+package rui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocaleFormat(t *testing.T) {
+	createTestLog(t, false)
+
+	numbers := []struct {
+		lang      string
+		n         float64
+		precision int
+		text      string
+	}{
+		{"en", 1234567.891, -1, "1,234,567.891"},
+		{"en", -0.001, 2, "0.00"},
+		{"de-AT", -1234.5, 2, "-1.234,50"},
+		{"ru", 123, -1, "123"},
+		{"ru_RU", 1234.25, -1, "1\u00a0234,25"},
+		{"xx", 1000, 0, "1,000"},
+	}
+	for _, test := range numbers {
+		if text := GetLocale(test.lang).FormatNumber(test.n, test.precision); text != test.text {
+			t.Errorf(`FormatNumber(%g, %d) for "%s" = "%s", expected "%s"`, test.n, test.precision, test.lang, text, test.text)
+		}
+	}
+
+	currency := []struct {
+		lang   string
+		amount float64
+		text   string
+	}{
+		{"en", -12.5, "-$12.50"},
+		{"de", 1234, "1.234,00\u00a0€"},
+		{"ja", 1500.4, "¥1,500"},
+	}
+	for _, test := range currency {
+		if text := GetLocale(test.lang).FormatCurrency(test.amount); text != test.text {
+			t.Errorf(`FormatCurrency(%g) for "%s" = "%s", expected "%s"`, test.amount, test.lang, text, test.text)
+		}
+	}
+
+	session := newSession(nil, 0, "", nil)
+	date := time.Date(2024, time.March, 5, 14, 30, 0, 0, time.UTC)
+	if text := session.FormatDateTime(date); text != "03/05/2024 2:30 PM" {
+		t.Errorf(`FormatDateTime for "en": "%s"`, text)
+	}
+
+	session.SetLanguage("ru")
+	if text := session.FormatDate(date); text != "05.03.2024" {
+		t.Errorf(`FormatDate for "ru": "%s"`, text)
+	}
+
+	RegisterLocale(Locale{Language: "xx", DecimalSeparator: "'", GroupSeparator: "_", DatePattern: "2006-01-02"})
+	defer delete(locales, "xx")
+	session.SetLocale(&Locale{Language: "custom", DecimalSeparator: "/", GroupSeparator: ""})
+	if text := session.FormatNumber(1234.5, 1); text != "1234/5" {
+		t.Errorf(`FormatNumber for the custom locale: "%s"`, text)
+	}
+	session.SetLocale(nil)
+	session.SetLanguage("xx-YY")
+	if text := session.FormatNumber(1234.5, 1); text != "1_234'5" {
+		t.Errorf(`FormatNumber for the registered locale: "%s"`, text)
+	}
+
+	table := NewTableView(session, Params{
+		Content: [][]any{{1234.5, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)}},
+	})
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+	table.(*tableViewData).writeCellHtml(GetTableContent(table), 0, 0, buffer)
+	table.(*tableViewData).writeCellHtml(GetTableContent(table), 0, 1, buffer)
+	if text := buffer.String(); !strings.HasPrefix(text, "1_234'5") || !strings.HasSuffix(text, "2024-03-05") {
+		t.Errorf(`TableView cells: "%s"`, text)
+	}
+}
@@ -0,0 +1,756 @@
+package rui
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// markdownParser converts Markdown text to HTML. All text and attribute values are escaped,
+// so raw HTML and scripts contained in the source are displayed as text.
+type markdownParser struct {
+	buffer *strings.Builder
+}
+
+var (
+	markdownHeadingRe    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	markdownRuleRe       = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	markdownFenceRe      = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*)$")
+	markdownListRe       = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])([ \t]+|$)`)
+	markdownQuoteRe      = regexp.MustCompile(`^ {0,3}> ?`)
+	markdownSetextRe     = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	markdownTableSepRe   = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	markdownSafeSchemeRe = regexp.MustCompile(`^(?i)(https?|mailto|tel|ftp):`)
+	markdownSchemeRe     = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.\-]*:`)
+)
+
+func markdownToHTML(text string) string {
+	parser := markdownParser{buffer: allocStringBuilder()}
+	defer freeStringBuilder(parser.buffer)
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	parser.blocks(strings.Split(text, "\n"), false)
+	return parser.buffer.String()
+}
+
+func markdownIsBlank(line string) bool {
+	return strings.Trim(line, " \t") == ""
+}
+
+// markdownIndent returns the indent width of the line (a tab is counted as 4 spaces)
+func markdownIndent(line string) int {
+	width := 0
+	for _, ch := range line {
+		switch ch {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// markdownUnindent removes up to "count" leading spaces from the line
+func markdownUnindent(line string, count int) string {
+	width := 0
+	for i, ch := range line {
+		if width >= count {
+			return line[i:]
+		}
+		switch ch {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return line[i:]
+		}
+	}
+	return ""
+}
+
+func (parser *markdownParser) isBlockStart(line string) bool {
+	return markdownHeadingRe.MatchString(line) ||
+		markdownRuleRe.MatchString(line) ||
+		markdownFenceRe.MatchString(line) ||
+		markdownQuoteRe.MatchString(line) ||
+		markdownListRe.MatchString(line)
+}
+
+func (parser *markdownParser) blocks(lines []string, tight bool) {
+	count := len(lines)
+	for i := 0; i < count; {
+		line := lines[i]
+
+		if markdownIsBlank(line) {
+			i++
+			continue
+		}
+
+		if match := markdownFenceRe.FindStringSubmatch(line); match != nil {
+			i = parser.fencedCode(lines, i, match)
+			continue
+		}
+
+		if markdownIndent(line) >= 4 {
+			i = parser.indentedCode(lines, i)
+			continue
+		}
+
+		if match := markdownHeadingRe.FindStringSubmatch(line); match != nil {
+			level := strconv.Itoa(len(match[1]))
+			parser.buffer.WriteString("<h" + level + ">")
+			parser.inline(match[2])
+			parser.buffer.WriteString("</h" + level + ">")
+			i++
+			continue
+		}
+
+		if markdownRuleRe.MatchString(line) {
+			parser.buffer.WriteString("<hr>")
+			i++
+			continue
+		}
+
+		if markdownQuoteRe.MatchString(line) {
+			i = parser.blockquote(lines, i)
+			continue
+		}
+
+		if markdownListRe.MatchString(line) {
+			i = parser.list(lines, i)
+			continue
+		}
+
+		if i+1 < count && strings.Contains(line, "|") && markdownTableSepRe.MatchString(lines[i+1]) &&
+			strings.Contains(lines[i+1], "-") {
+			i = parser.table(lines, i)
+			continue
+		}
+
+		i = parser.paragraph(lines, i, tight)
+	}
+}
+
+func (parser *markdownParser) fencedCode(lines []string, start int, match []string) int {
+	indent := len(match[1])
+	fence := match[2]
+	info := strings.Fields(match[3])
+
+	parser.buffer.WriteString(`<pre class="ruiMarkdownCodeBlock"><code`)
+	if len(info) > 0 {
+		parser.buffer.WriteString(` class="language-`)
+		parser.buffer.WriteString(htmlEscape(info[0]))
+		parser.buffer.WriteRune('"')
+	}
+	parser.buffer.WriteRune('>')
+
+	i := start + 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) <= 3 && strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, string(fence[0])+" \t") == "" {
+			i++
+			break
+		}
+		parser.buffer.WriteString(htmlEscape(markdownUnindent(line, indent)))
+		parser.buffer.WriteRune('\n')
+	}
+
+	parser.buffer.WriteString(`</code></pre>`)
+	return i
+}
+
+func (parser *markdownParser) indentedCode(lines []string, start int) int {
+	end := start
+	for i := start; i < len(lines); i++ {
+		if markdownIndent(lines[i]) >= 4 {
+			end = i + 1
+		} else if !markdownIsBlank(lines[i]) {
+			break
+		}
+	}
+
+	parser.buffer.WriteString(`<pre class="ruiMarkdownCodeBlock"><code>`)
+	for _, line := range lines[start:end] {
+		parser.buffer.WriteString(htmlEscape(markdownUnindent(line, 4)))
+		parser.buffer.WriteRune('\n')
+	}
+	parser.buffer.WriteString(`</code></pre>`)
+	return end
+}
+
+func (parser *markdownParser) blockquote(lines []string, start int) int {
+	content := []string{}
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if loc := markdownQuoteRe.FindStringIndex(line); loc != nil {
+			content = append(content, line[loc[1]:])
+		} else if !markdownIsBlank(line) && len(content) > 0 && !markdownIsBlank(content[len(content)-1]) && !parser.isBlockStart(line) {
+			// lazy continuation of a paragraph
+			content = append(content, line)
+		} else {
+			break
+		}
+	}
+
+	parser.buffer.WriteString(`<blockquote class="ruiMarkdownQuote">`)
+	parser.blocks(content, false)
+	parser.buffer.WriteString(`</blockquote>`)
+	return i
+}
+
+func (parser *markdownParser) list(lines []string, start int) int {
+	match := markdownListRe.FindStringSubmatch(lines[start])
+	ordered := match[2][0] >= '0' && match[2][0] <= '9'
+	marker := match[2][len(match[2])-1]
+
+	type listItem struct {
+		lines []string
+	}
+
+	items := []listItem{}
+	loose := false
+	i := start
+	contentIndent := 0
+
+	for i < len(lines) {
+		line := lines[i]
+		m := markdownListRe.FindStringSubmatch(line)
+		if m != nil && (len(items) == 0 || markdownIndent(line) < contentIndent) {
+			itemOrdered := m[2][0] >= '0' && m[2][0] <= '9'
+			if itemOrdered != ordered || m[2][len(m[2])-1] != marker {
+				break
+			}
+
+			markerEnd := len(m[1]) + len(m[2])
+			rest := line[markerEnd:]
+			spaces := markdownIndent(rest)
+			if markdownIsBlank(rest) || spaces > 4 {
+				// an empty first line or an indented code block
+				contentIndent = markerEnd + 1
+				rest = markdownUnindent(rest, 1)
+			} else {
+				contentIndent = markerEnd + spaces
+				rest = strings.TrimLeft(rest, " \t")
+			}
+			items = append(items, listItem{lines: []string{rest}})
+			i++
+			continue
+		}
+
+		if markdownIsBlank(line) {
+			// the list continues if the next non-blank line is indented or starts a new item
+			next := i + 1
+			for next < len(lines) && markdownIsBlank(lines[next]) {
+				next++
+			}
+			if next >= len(lines) {
+				i = next
+				break
+			}
+			nextLine := lines[next]
+			if markdownIndent(nextLine) >= contentIndent {
+				loose = true
+				item := &items[len(items)-1]
+				for ; i < next; i++ {
+					item.lines = append(item.lines, "")
+				}
+				continue
+			}
+			if m := markdownListRe.FindStringSubmatch(nextLine); m != nil &&
+				(m[2][0] >= '0' && m[2][0] <= '9') == ordered && m[2][len(m[2])-1] == marker {
+				loose = true
+				i = next
+				continue
+			}
+			i = next
+			break
+		}
+
+		if markdownIndent(line) >= contentIndent {
+			item := &items[len(items)-1]
+			item.lines = append(item.lines, markdownUnindent(line, contentIndent))
+			i++
+			continue
+		}
+
+		// lazy continuation of the item paragraph
+		if len(items) > 0 && !parser.isBlockStart(line) {
+			item := &items[len(items)-1]
+			if last := item.lines[len(item.lines)-1]; !markdownIsBlank(last) {
+				item.lines = append(item.lines, line)
+				i++
+				continue
+			}
+		}
+		break
+	}
+
+	if ordered {
+		parser.buffer.WriteString(`<ol class="ruiMarkdownList"`)
+		if n, err := strconv.Atoi(match[2][:len(match[2])-1]); err == nil && n != 1 {
+			parser.buffer.WriteString(` start="`)
+			parser.buffer.WriteString(strconv.Itoa(n))
+			parser.buffer.WriteRune('"')
+		}
+		parser.buffer.WriteRune('>')
+	} else {
+		parser.buffer.WriteString(`<ul class="ruiMarkdownList">`)
+	}
+
+	for _, item := range items {
+		parser.buffer.WriteString(`<li>`)
+		parser.blocks(item.lines, !loose)
+		parser.buffer.WriteString(`</li>`)
+	}
+
+	if ordered {
+		parser.buffer.WriteString(`</ol>`)
+	} else {
+		parser.buffer.WriteString(`</ul>`)
+	}
+	return i
+}
+
+func markdownTableCells(line string) []string {
+	line = strings.Trim(line, " \t")
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	cells := []string{}
+	cell := allocStringBuilder()
+	defer freeStringBuilder(cell)
+
+	code := false
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case ch == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+			continue
+
+		case ch == '`':
+			code = !code
+
+		case ch == '|' && !code:
+			cells = append(cells, strings.Trim(cell.String(), " \t"))
+			cell.Reset()
+			continue
+		}
+		cell.WriteByte(ch)
+	}
+	return append(cells, strings.Trim(cell.String(), " \t"))
+}
+
+func (parser *markdownParser) table(lines []string, start int) int {
+	header := markdownTableCells(lines[start])
+	aligns := []string{}
+	for _, cell := range markdownTableCells(lines[start+1]) {
+		left := strings.HasPrefix(cell, ":")
+		right := strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			aligns = append(aligns, "center")
+		case right:
+			aligns = append(aligns, "right")
+		case left:
+			aligns = append(aligns, "left")
+		default:
+			aligns = append(aligns, "")
+		}
+	}
+
+	row := func(cells []string, cellTag string) {
+		parser.buffer.WriteString(`<tr>`)
+		for n := range header {
+			parser.buffer.WriteString(`<` + cellTag)
+			if n < len(aligns) && aligns[n] != "" {
+				parser.buffer.WriteString(` style="text-align: ` + aligns[n] + `;"`)
+			}
+			parser.buffer.WriteRune('>')
+			if n < len(cells) {
+				parser.inline(cells[n])
+			}
+			parser.buffer.WriteString(`</` + cellTag + `>`)
+		}
+		parser.buffer.WriteString(`</tr>`)
+	}
+
+	parser.buffer.WriteString(`<table class="ruiMarkdownTable"><thead>`)
+	row(header, "th")
+	parser.buffer.WriteString(`</thead>`)
+
+	i := start + 2
+	if i < len(lines) && !markdownIsBlank(lines[i]) && strings.Contains(lines[i], "|") {
+		parser.buffer.WriteString(`<tbody>`)
+		for ; i < len(lines); i++ {
+			line := lines[i]
+			if markdownIsBlank(line) || !strings.Contains(line, "|") || parser.isBlockStart(line) {
+				break
+			}
+			row(markdownTableCells(line), "td")
+		}
+		parser.buffer.WriteString(`</tbody>`)
+	}
+
+	parser.buffer.WriteString(`</table>`)
+	return i
+}
+
+func (parser *markdownParser) paragraph(lines []string, start int, tight bool) int {
+	content := []string{strings.TrimLeft(lines[start], " \t")}
+	i := start + 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if markdownIsBlank(line) {
+			break
+		}
+
+		if match := markdownSetextRe.FindStringSubmatch(line); match != nil {
+			tag := "h2"
+			if match[1][0] == '=' {
+				tag = "h1"
+			}
+			parser.buffer.WriteString("<" + tag + ">")
+			parser.inline(strings.Join(content, "\n"))
+			parser.buffer.WriteString("</" + tag + ">")
+			return i + 1
+		}
+
+		if parser.isBlockStart(line) {
+			break
+		}
+		content = append(content, strings.TrimLeft(line, " \t"))
+	}
+
+	if !tight {
+		parser.buffer.WriteString("<p>")
+	}
+	parser.inline(strings.Join(content, "\n"))
+	if !tight {
+		parser.buffer.WriteString("</p>")
+	}
+	return i
+}
+
+// markdownSafeURL returns the escaped url or "" if the url uses a scheme that can run a script
+func markdownSafeURL(url string) string {
+	url = strings.Trim(url, " \t\n")
+	if strings.HasPrefix(url, "<") && strings.HasSuffix(url, ">") {
+		url = url[1 : len(url)-1]
+	}
+
+	// the browser ignores control characters and spaces in the scheme, e.g. "java\tscript:"
+	scheme := strings.Map(func(ch rune) rune {
+		if unicode.IsControl(ch) || unicode.IsSpace(ch) {
+			return -1
+		}
+		return ch
+	}, url)
+
+	if markdownSchemeRe.MatchString(scheme) && !markdownSafeSchemeRe.MatchString(scheme) {
+		return ""
+	}
+	return htmlEscape(url)
+}
+
+func isMarkdownPunct(ch byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", ch) >= 0
+}
+
+func (parser *markdownParser) inline(text string) {
+	parser.buffer.WriteString(markdownInline(text))
+}
+
+func markdownInline(text string) string {
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	size := len(text)
+	for i := 0; i < size; {
+		ch := text[i]
+		switch ch {
+		case '\\':
+			if i+1 < size {
+				if isMarkdownPunct(text[i+1]) {
+					buffer.WriteString(htmlEscape(text[i+1 : i+2]))
+					i += 2
+					continue
+				}
+				if text[i+1] == '\n' {
+					buffer.WriteString("<br>")
+					i += 2
+					continue
+				}
+			}
+
+		case '`':
+			n := i
+			for n < size && text[n] == '`' {
+				n++
+			}
+			fence := text[i:n]
+			if end := strings.Index(text[n:], fence); end >= 0 {
+				code := text[n : n+end]
+				code = strings.ReplaceAll(code, "\n", " ")
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				buffer.WriteString(`<code class="ruiMarkdownCode">`)
+				buffer.WriteString(htmlEscape(code))
+				buffer.WriteString(`</code>`)
+				i = n + end + len(fence)
+				continue
+			}
+			buffer.WriteString(fence)
+			i = n
+			continue
+
+		case '*', '_', '~':
+			if html, n, ok := markdownEmphasis(text, i); ok {
+				buffer.WriteString(html)
+				i = n
+				continue
+			}
+
+		case '!':
+			if i+1 < size && text[i+1] == '[' {
+				if label, url, title, n, ok := markdownLink(text, i+1); ok {
+					if src := markdownSafeURL(url); src != "" {
+						buffer.WriteString(`<img src="`)
+						buffer.WriteString(src)
+						buffer.WriteString(`" alt="`)
+						buffer.WriteString(htmlEscape(label))
+						if title != "" {
+							buffer.WriteString(`" title="`)
+							buffer.WriteString(htmlEscape(title))
+						}
+						buffer.WriteString(`" style="max-width: 100%;">`)
+					} else {
+						buffer.WriteString(htmlEscape(label))
+					}
+					i = n
+					continue
+				}
+			}
+
+		case '[':
+			if label, url, title, n, ok := markdownLink(text, i); ok {
+				if href := markdownSafeURL(url); href != "" {
+					buffer.WriteString(`<a class="ruiMarkdownLink" href="`)
+					buffer.WriteString(href)
+					if title != "" {
+						buffer.WriteString(`" title="`)
+						buffer.WriteString(htmlEscape(title))
+					}
+					buffer.WriteString(`" rel="noopener noreferrer">`)
+					buffer.WriteString(markdownInline(label))
+					buffer.WriteString(`</a>`)
+				} else {
+					buffer.WriteString(markdownInline(label))
+				}
+				i = n
+				continue
+			}
+
+		case '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				url := text[i+1 : i+end]
+				if !strings.ContainsAny(url, " \t\n<") {
+					if strings.Contains(url, "@") && !strings.Contains(url, ":") {
+						url = "mailto:" + url
+					}
+					if markdownSafeSchemeRe.MatchString(url) {
+						href := markdownSafeURL(url)
+						buffer.WriteString(`<a class="ruiMarkdownLink" href="`)
+						buffer.WriteString(href)
+						buffer.WriteString(`" rel="noopener noreferrer">`)
+						buffer.WriteString(htmlEscape(strings.TrimPrefix(text[i+1:i+end], "mailto:")))
+						buffer.WriteString(`</a>`)
+						i += end + 1
+						continue
+					}
+				}
+			}
+
+		case '\n':
+			if trimmed := strings.TrimRight(buffer.String(), " "); len(buffer.String())-len(trimmed) >= 2 {
+				str := trimmed
+				buffer.Reset()
+				buffer.WriteString(str)
+				buffer.WriteString("<br>")
+			} else {
+				buffer.WriteRune('\n')
+			}
+			i++
+			continue
+		}
+
+		// the plain text up to the next special character
+		n := i + 1
+		for n < size && !isMarkdownInlineSpecial(text[n]) {
+			n++
+		}
+		buffer.WriteString(htmlEscape(text[i:n]))
+		i = n
+	}
+
+	return buffer.String()
+}
+
+// isMarkdownInlineSpecial returns true if the character can start the inline markup
+func isMarkdownInlineSpecial(ch byte) bool {
+	switch ch {
+	case '\\', '`', '*', '_', '~', '!', '[', '<', '\n':
+		return true
+	}
+	return false
+}
+
+// markdownEmphasis parses "*em*", "**strong**", "_em_", "__strong__" and "~~del~~" at the position "start"
+func markdownEmphasis(text string, start int) (string, int, bool) {
+	ch := text[start]
+	n := start
+	for n < len(text) && text[n] == ch {
+		n++
+	}
+	count := n - start
+
+	var delimiter, tag string
+	switch {
+	case ch == '~':
+		if count != 2 {
+			return "", 0, false
+		}
+		delimiter, tag = "~~", "del"
+
+	case count >= 3:
+		delimiter, tag = text[start:start+3], ""
+
+	case count == 2:
+		delimiter, tag = text[start:start+2], "strong"
+
+	default:
+		delimiter, tag = text[start:start+1], "em"
+	}
+
+	open := start + len(delimiter)
+	if open >= len(text) || text[open] == ' ' || text[open] == '\n' || text[open] == '\t' {
+		return "", 0, false
+	}
+	if ch == '_' && start > 0 && isMarkdownWordChar(text[start-1]) {
+		return "", 0, false
+	}
+
+	for pos := open + 1; pos <= len(text)-len(delimiter); pos++ {
+		if text[pos] == '\\' {
+			pos++
+			continue
+		}
+		if text[pos] == '`' {
+			if end := strings.IndexByte(text[pos+1:], '`'); end >= 0 {
+				pos += end + 1
+				continue
+			}
+		}
+		if !strings.HasPrefix(text[pos:], delimiter) {
+			continue
+		}
+		prev := text[pos-1]
+		if prev == ' ' || prev == '\t' || prev == '\n' {
+			continue
+		}
+		after := pos + len(delimiter)
+		if after < len(text) && text[after] == ch {
+			// the closing delimiter is a part of a longer run
+			if tag != "em" || (after+1 < len(text) && text[after+1] == ch) {
+				continue
+			}
+		}
+		if ch == '_' && after < len(text) && isMarkdownWordChar(text[after]) {
+			continue
+		}
+
+		inner := markdownInline(text[open:pos])
+		if tag == "" {
+			return "<em><strong>" + inner + "</strong></em>", after, true
+		}
+		return "<" + tag + ">" + inner + "</" + tag + ">", after, true
+	}
+
+	return "", 0, false
+}
+
+func isMarkdownWordChar(ch byte) bool {
+	return ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= 0x80
+}
+
+// markdownLink parses "[label](url "title")" at the position "start"
+func markdownLink(text string, start int) (string, string, string, int, bool) {
+	depth := 0
+	labelEnd := -1
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				labelEnd = i
+			}
+		}
+		if labelEnd >= 0 {
+			break
+		}
+	}
+
+	if labelEnd < 0 || labelEnd+1 >= len(text) || text[labelEnd+1] != '(' {
+		return "", "", "", 0, false
+	}
+
+	depth = 0
+	end := -1
+	for i := labelEnd + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+		if end >= 0 {
+			break
+		}
+	}
+	if end < 0 {
+		return "", "", "", 0, false
+	}
+
+	dest := strings.Trim(text[labelEnd+2:end], " \t\n")
+	title := ""
+	if n := strings.IndexAny(dest, " \t\n"); n > 0 {
+		rest := strings.Trim(dest[n:], " \t\n")
+		if len(rest) >= 2 && (rest[0] == '"' && rest[len(rest)-1] == '"' || rest[0] == '\'' && rest[len(rest)-1] == '\'') {
+			title = rest[1 : len(rest)-1]
+			dest = dest[:n]
+		}
+	}
+
+	return text[start+1 : labelEnd], dest, title, end + 1, true
+}
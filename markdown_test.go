@@ -0,0 +1,60 @@
+package rui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToHTML(t *testing.T) {
+	tests := [][2]string{
+		{"# Title", "<h1>Title</h1>"},
+		{"### Title ###", "<h3>Title</h3>"},
+		{"Title\n===", "<h1>Title</h1>"},
+		{"Hello *world* and **bold** ~~old~~", "<p>Hello <em>world</em> and <strong>bold</strong> <del>old</del></p>"},
+		{"snake_case_name", "<p>snake_case_name</p>"},
+		{`Tom & "Jerry" > 'cat' <3`, "<p>Tom &amp; &quot;Jerry&quot; &gt; &#39;cat&#39; &lt;3</p>"},
+		{"use `a < b`", `<p>use <code class="ruiMarkdownCode">a &lt; b</code></p>`},
+		{"- one\n- two", `<ul class="ruiMarkdownList"><li>one</li><li>two</li></ul>`},
+		{"3. one\n4. two", `<ol class="ruiMarkdownList" start="3"><li>one</li><li>two</li></ol>`},
+		{"- one\n  - nested", `<ul class="ruiMarkdownList"><li>one<ul class="ruiMarkdownList"><li>nested</li></ul></li></ul>`},
+		{"```go\nif a < b {\n}\n```", `<pre class="ruiMarkdownCodeBlock"><code class="language-go">if a &lt; b {` + "\n}\n</code></pre>"},
+		{"> quote", `<blockquote class="ruiMarkdownQuote"><p>quote</p></blockquote>`},
+		{"---", "<hr>"},
+		{"| a | b |\n|:--|--:|\n| 1 | 2 |",
+			`<table class="ruiMarkdownTable"><thead><tr><th style="text-align: left;">a</th><th style="text-align: right;">b</th></tr></thead>` +
+				`<tbody><tr><td style="text-align: left;">1</td><td style="text-align: right;">2</td></tr></tbody></table>`},
+		{"[link](https://example.com \"Title\")",
+			`<p><a class="ruiMarkdownLink" href="https://example.com" title="Title" rel="noopener noreferrer">link</a></p>`},
+		{"<https://example.com>", `<p><a class="ruiMarkdownLink" href="https://example.com" rel="noopener noreferrer">https://example.com</a></p>`},
+	}
+
+	for _, test := range tests {
+		if result := markdownToHTML(test[0]); result != test[1] {
+			t.Errorf("markdownToHTML(%q):\nresult:   %s\nexpected: %s", test[0], result, test[1])
+		}
+	}
+}
+
+func TestMarkdownSanitize(t *testing.T) {
+	tests := []string{
+		"<script>alert(1)</script>",
+		"<img src=x onerror=alert(1)>",
+		"[click](javascript:alert(1))",
+		"[click](JavaScript:alert(1))",
+		"[click](java\tscript:alert(1))",
+		"![img](data:text/html;base64,PHNjcmlwdD4=)",
+		"[x](https://a.com\" onclick=\"alert(1))",
+		"| <b onmouseover=alert(1)> |\n|---|",
+		"```\n</code><script>alert(1)</script>\n```",
+	}
+
+	for _, text := range tests {
+		result := markdownToHTML(text)
+		lower := strings.ToLower(result)
+		for _, bad := range []string{"<script", "<b ", "<img src=x", "javascript:", "data:", `" onclick`} {
+			if strings.Contains(lower, bad) {
+				t.Errorf("markdownToHTML(%q) contains %q: %s", text, bad, result)
+			}
+		}
+	}
+}
@@ -168,6 +168,16 @@ func (player *mediaPlayerData) init(session Session) {
 	player.tag = "MediaPlayer"
 }
 
+func (player *mediaPlayerData) supportedProperties() []string {
+	return append([]string{
+		Controls, Loop, Muted, Preload, Source, AbortEvent, CanPlayEvent, CanPlayThroughEvent,
+		CompleteEvent, DurationChangedEvent, EmptiedEvent, EndedEvent, LoadedDataEvent,
+		LoadedMetadataEvent, LoadStartEvent, PauseEvent, PlayEvent, PlayingEvent, ProgressEvent,
+		RateChangedEvent, SeekedEvent, SeekingEvent, StalledEvent, SuspendEvent, TimeUpdateEvent,
+		VolumeChangedEvent, WaitingEvent, PlayerErrorEvent,
+	}, player.viewData.supportedProperties()...)
+}
+
 func (player *mediaPlayerData) String() string {
 	return getViewString(player)
 }
@@ -231,7 +241,7 @@ func (player *mediaPlayerData) set(tag string, value any) bool {
 		notCompatibleType(tag, value)
 
 	case PlayerErrorEvent:
-		if listeners, ok := valueToPlayerErrorListeners(value); ok {
+		if listeners, ok := valueToMediaErrorListeners[MediaPlayer](value); ok {
 			if listeners == nil {
 				player.properties.Delete(tag)
 			} else {
@@ -311,34 +321,34 @@ func (player *mediaPlayerData) setSource(value any) bool {
 	return true
 }
 
-func valueToPlayerErrorListeners(value any) ([]func(MediaPlayer, int, string), bool) {
+func valueToMediaErrorListeners[V View](value any) ([]func(V, int, string), bool) {
 	if value == nil {
 		return nil, true
 	}
 
 	switch value := value.(type) {
-	case func(MediaPlayer, int, string):
-		return []func(MediaPlayer, int, string){value}, true
+	case func(V, int, string):
+		return []func(V, int, string){value}, true
 
 	case func(int, string):
-		fn := func(_ MediaPlayer, code int, message string) {
+		fn := func(_ V, code int, message string) {
 			value(code, message)
 		}
-		return []func(MediaPlayer, int, string){fn}, true
+		return []func(V, int, string){fn}, true
 
-	case func(MediaPlayer):
-		fn := func(player MediaPlayer, _ int, _ string) {
-			value(player)
+	case func(V):
+		fn := func(view V, _ int, _ string) {
+			value(view)
 		}
-		return []func(MediaPlayer, int, string){fn}, true
+		return []func(V, int, string){fn}, true
 
 	case func():
-		fn := func(MediaPlayer, int, string) {
+		fn := func(V, int, string) {
 			value()
 		}
-		return []func(MediaPlayer, int, string){fn}, true
+		return []func(V, int, string){fn}, true
 
-	case []func(MediaPlayer, int, string):
+	case []func(V, int, string):
 		if len(value) == 0 {
 			return nil, true
 		}
@@ -354,29 +364,29 @@ func valueToPlayerErrorListeners(value any) ([]func(MediaPlayer, int, string), b
 		if count == 0 {
 			return nil, true
 		}
-		listeners := make([]func(MediaPlayer, int, string), count)
+		listeners := make([]func(V, int, string), count)
 		for i, v := range value {
 			if v == nil {
 				return nil, false
 			}
-			listeners[i] = func(_ MediaPlayer, code int, message string) {
+			listeners[i] = func(_ V, code int, message string) {
 				v(code, message)
 			}
 		}
 		return listeners, true
 
-	case []func(MediaPlayer):
+	case []func(V):
 		count := len(value)
 		if count == 0 {
 			return nil, true
 		}
-		listeners := make([]func(MediaPlayer, int, string), count)
+		listeners := make([]func(V, int, string), count)
 		for i, v := range value {
 			if v == nil {
 				return nil, false
 			}
-			listeners[i] = func(player MediaPlayer, _ int, _ string) {
-				v(player)
+			listeners[i] = func(view V, _ int, _ string) {
+				v(view)
 			}
 		}
 		return listeners, true
@@ -386,12 +396,12 @@ func valueToPlayerErrorListeners(value any) ([]func(MediaPlayer, int, string), b
 		if count == 0 {
 			return nil, true
 		}
-		listeners := make([]func(MediaPlayer, int, string), count)
+		listeners := make([]func(V, int, string), count)
 		for i, v := range value {
 			if v == nil {
 				return nil, false
 			}
-			listeners[i] = func(MediaPlayer, int, string) {
+			listeners[i] = func(V, int, string) {
 				v()
 			}
 		}
@@ -402,27 +412,27 @@ func valueToPlayerErrorListeners(value any) ([]func(MediaPlayer, int, string), b
 		if count == 0 {
 			return nil, true
 		}
-		listeners := make([]func(MediaPlayer, int, string), count)
+		listeners := make([]func(V, int, string), count)
 		for i, v := range value {
 			if v == nil {
 				return nil, false
 			}
 			switch v := v.(type) {
-			case func(MediaPlayer, int, string):
+			case func(V, int, string):
 				listeners[i] = v
 
 			case func(int, string):
-				listeners[i] = func(_ MediaPlayer, code int, message string) {
+				listeners[i] = func(_ V, code int, message string) {
 					v(code, message)
 				}
 
-			case func(MediaPlayer):
-				listeners[i] = func(player MediaPlayer, _ int, _ string) {
-					v(player)
+			case func(V):
+				listeners[i] = func(view V, _ int, _ string) {
+					v(view)
 				}
 
 			case func():
-				listeners[i] = func(MediaPlayer, int, string) {
+				listeners[i] = func(V, int, string) {
 					v()
 				}
 
@@ -0,0 +1,322 @@
+package rui
+
+import (
+	"strings"
+)
+
+const (
+	// RecorderMimeType is the constant for the "recorder-mime-type" property tag.
+	// The "recorder-mime-type" string property of MediaRecorder sets the preferred MIME type of the recording,
+	// for example "video/webm;codecs=vp9" or "audio/ogg". If the browser does not support the type
+	// then the default type of the browser is used.
+	RecorderMimeType = "recorder-mime-type"
+	// MediaRecordedEvent is the constant for the "media-recorded-event" property tag.
+	// The "media-recorded-event" event occurs when the recording is stopped and the recorded media
+	// is ready for the upload (see MediaRecorder.UploadRecording).
+	// The main listener format: func(MediaRecorder, FileInfo).
+	MediaRecordedEvent = "media-recorded-event"
+	// RecorderStateChangedEvent is the constant for the "recorder-state-changed-event" property tag.
+	// The "recorder-state-changed-event" event occurs when the state of MediaRecorder has changed.
+	// The main listener format: func(MediaRecorder, int), where the second argument is the new state:
+	// RecorderInactive (0), RecorderReady (1), RecorderRecording (2) or RecorderPaused (3).
+	RecorderStateChangedEvent = "recorder-state-changed-event"
+	// RecorderErrorEvent is the constant for the "recorder-error-event" property tag.
+	// The "recorder-error-event" event occurs when the access to the camera/microphone is denied
+	// or the recording is failed. The main listener format: func(MediaRecorder, int, string),
+	// where the second argument is the error code and the third argument is the error message.
+	RecorderErrorEvent = "recorder-error-event"
+
+	// RecorderInactive - MediaRecorder state: the camera/microphone is not used.
+	RecorderInactive = 0
+	// RecorderReady - MediaRecorder state: the access to the camera/microphone is granted, the live preview is shown.
+	RecorderReady = 1
+	// RecorderRecording - MediaRecorder state: the recording is in progress.
+	RecorderRecording = 2
+	// RecorderPaused - MediaRecorder state: the recording is paused.
+	RecorderPaused = 3
+
+	// RecorderErrorUnknown - MediaRecorder error code: An unknown error.
+	RecorderErrorUnknown = 0
+	// RecorderErrorPermissionDenied - MediaRecorder error code: The user or the browser has denied the access to the camera/microphone.
+	RecorderErrorPermissionDenied = 1
+	// RecorderErrorDeviceNotFound - MediaRecorder error code: No camera/microphone satisfying the requirements is found.
+	RecorderErrorDeviceNotFound = 2
+	// RecorderErrorNotSupported - MediaRecorder error code: The browser does not support the media capture
+	// (or the page is not opened over a secure connection).
+	RecorderErrorNotSupported = 3
+	// RecorderErrorDeviceBusy - MediaRecorder error code: The camera/microphone is used by another application.
+	RecorderErrorDeviceBusy = 4
+)
+
+// MediaRecorder is the common interface of AudioRecorder and VideoRecorder
+type MediaRecorder interface {
+	View
+	// Open requests the access to the camera/microphone and starts the live preview.
+	// The RecorderErrorEvent is fired if the access is denied.
+	Open()
+	// Close stops the recording and releases the camera/microphone.
+	// The recorder is also closed when its element is removed from the page.
+	Close()
+	// Start starts the recording. The camera/microphone is opened if necessary.
+	Start()
+	// Pause pauses the recording.
+	Pause()
+	// Resume resumes the paused recording.
+	Resume()
+	// Stop finishes the recording. The MediaRecordedEvent is fired when the recorded media is ready.
+	Stop()
+	// State returns the state of the recorder: RecorderInactive (0), RecorderReady (1),
+	// RecorderRecording (2) or RecorderPaused (3).
+	State() int
+	// Recording returns the description of the last recorded media. The second result is false if there is no recording.
+	Recording() (FileInfo, bool)
+	// UploadRecording starts the chunked upload of the last recorded media through the HTTP endpoint of the application
+	// (see FilePicker.UploadFile). Returns nil if there is no recording
+	UploadRecording(options FileUploadOptions) FileUpload
+}
+
+type mediaRecorderData struct {
+	viewData
+	video     bool
+	state     int
+	recording FileInfo
+	recorded  bool
+}
+
+func (recorder *mediaRecorderData) init(session Session) {
+	recorder.viewData.init(session)
+	recorder.tag = "MediaRecorder"
+	recorder.state = RecorderInactive
+}
+
+func (recorder *mediaRecorderData) supportedProperties() []string {
+	return append([]string{
+		Controls, RecorderMimeType, MediaRecordedEvent, RecorderStateChangedEvent, RecorderErrorEvent,
+	}, recorder.viewData.supportedProperties()...)
+}
+
+func (recorder *mediaRecorderData) String() string {
+	return getViewString(recorder)
+}
+
+func (recorder *mediaRecorderData) Remove(tag string) {
+	recorder.remove(strings.ToLower(tag))
+}
+
+func (recorder *mediaRecorderData) remove(tag string) {
+	recorder.viewData.remove(tag)
+	if tag == Controls && recorder.created {
+		recorder.session.removeProperty(recorder.htmlID(), Controls)
+	}
+}
+
+func (recorder *mediaRecorderData) Set(tag string, value any) bool {
+	return recorder.set(strings.ToLower(tag), value)
+}
+
+func (recorder *mediaRecorderData) set(tag string, value any) bool {
+	if value == nil {
+		recorder.remove(tag)
+		return true
+	}
+
+	switch tag {
+	case MediaRecordedEvent:
+		if listeners, ok := valueToEventListeners[MediaRecorder, FileInfo](value); ok {
+			recorder.storeListeners(tag, listeners, listeners == nil)
+			return true
+		}
+		notCompatibleType(tag, value)
+
+	case RecorderStateChangedEvent:
+		if listeners, ok := valueToEventListeners[MediaRecorder, int](value); ok {
+			recorder.storeListeners(tag, listeners, listeners == nil)
+			return true
+		}
+		notCompatibleType(tag, value)
+
+	case RecorderErrorEvent:
+		if listeners, ok := valueToMediaErrorListeners[MediaRecorder](value); ok {
+			recorder.storeListeners(tag, listeners, listeners == nil)
+			return true
+		}
+		notCompatibleType(tag, value)
+
+	case Controls:
+		if recorder.viewData.set(tag, value) {
+			if recorder.created {
+				if controls, _ := boolProperty(recorder, Controls, recorder.session); controls {
+					recorder.session.updateProperty(recorder.htmlID(), Controls, true)
+				} else {
+					recorder.session.removeProperty(recorder.htmlID(), Controls)
+				}
+			}
+			return true
+		}
+
+	default:
+		return recorder.viewData.set(tag, value)
+	}
+
+	return false
+}
+
+func (recorder *mediaRecorderData) storeListeners(tag string, listeners any, empty bool) {
+	if empty {
+		recorder.properties.Delete(tag)
+	} else {
+		recorder.properties.Store(tag, listeners)
+	}
+	recorder.propertyChangedEvent(tag)
+}
+
+func (recorder *mediaRecorderData) htmlProperties(self View, buffer *strings.Builder) {
+	recorder.viewData.htmlProperties(self, buffer)
+	if controls, _ := boolProperty(recorder, Controls, recorder.session); controls {
+		buffer.WriteString(` controls`)
+	}
+	if recorder.video {
+		buffer.WriteString(` muted playsinline`)
+	}
+}
+
+func (recorder *mediaRecorderData) handleCommand(self View, command string, data DataObject) bool {
+	switch command {
+	case RecorderStateChangedEvent:
+		if state, ok := dataIntProperty(data, "state"); ok && state != recorder.state {
+			recorder.state = state
+			if listeners, ok := recorder.getRaw(command).([]func(MediaRecorder, int)); ok {
+				for _, listener := range listeners {
+					listener(self.(MediaRecorder), state)
+				}
+			}
+		}
+		return true
+
+	case MediaRecordedEvent:
+		recorder.recording = FileInfo{}
+		recorder.recording.initBy(data)
+		recorder.recording.Name = recordingFilename(recorder.recording.MimeType)
+		recorder.recorded = true
+		if listeners, ok := recorder.getRaw(command).([]func(MediaRecorder, FileInfo)); ok {
+			for _, listener := range listeners {
+				listener(self.(MediaRecorder), recorder.recording)
+			}
+		}
+		return true
+
+	case RecorderErrorEvent:
+		code, _ := dataIntProperty(data, "code")
+		message, _ := data.PropertyValue("message")
+		if listeners, ok := recorder.getRaw(command).([]func(MediaRecorder, int, string)); ok && len(listeners) > 0 {
+			for _, listener := range listeners {
+				listener(self.(MediaRecorder), code, message)
+			}
+		} else {
+			ErrorLog(message)
+		}
+		return true
+
+	case "fileUploadProgress", "fileUploaded", "fileUploadError":
+		handleFileUploadCommand(self, command, data)
+		return true
+	}
+
+	return recorder.viewData.handleCommand(self, command, data)
+}
+
+// recordingFilename returns the name of the recorded media file with the extension corresponding to the MIME type
+func recordingFilename(mimeType string) string {
+	mimeType, _, _ = strings.Cut(mimeType, ";")
+	_, ext, _ := strings.Cut(strings.TrimSpace(mimeType), "/")
+	switch ext {
+	case "":
+		return "recording"
+
+	case "x-matroska":
+		ext = "mkv"
+
+	case "mpeg":
+		ext = "mp3"
+	}
+	return "recording." + ext
+}
+
+// constraints returns the arguments of the JavaScript functions opening the camera/microphone
+func (recorder *mediaRecorderData) constraints() (bool, bool, string) {
+	if !recorder.video {
+		return false, true, ""
+	}
+
+	audio := true
+	if value, ok := boolProperty(recorder, RecordAudio, recorder.session); ok {
+		audio = value
+	}
+	facingMode, _ := enumProperty(recorder, FacingMode, recorder.session, UserFacing)
+	return true, audio, enumProperties[FacingMode].values[facingMode]
+}
+
+func (recorder *mediaRecorderData) Open() {
+	video, audio, facingMode := recorder.constraints()
+	recorder.session.callFunc("openMediaRecorder", recorder.htmlID(), video, audio, facingMode)
+}
+
+func (recorder *mediaRecorderData) Close() {
+	recorder.session.callFunc("closeMediaRecorder", recorder.htmlID())
+}
+
+func (recorder *mediaRecorderData) Start() {
+	video, audio, facingMode := recorder.constraints()
+	mimeType, _ := stringProperty(recorder, RecorderMimeType, recorder.session)
+	recorder.session.callFunc("startMediaRecorder", recorder.htmlID(), video, audio, facingMode, mimeType)
+}
+
+func (recorder *mediaRecorderData) Pause() {
+	recorder.session.callFunc("pauseMediaRecorder", recorder.htmlID())
+}
+
+func (recorder *mediaRecorderData) Resume() {
+	recorder.session.callFunc("resumeMediaRecorder", recorder.htmlID())
+}
+
+func (recorder *mediaRecorderData) Stop() {
+	recorder.session.callFunc("stopMediaRecorder", recorder.htmlID())
+}
+
+func (recorder *mediaRecorderData) State() int {
+	return recorder.state
+}
+
+func (recorder *mediaRecorderData) Recording() (FileInfo, bool) {
+	return recorder.recording, recorder.recorded
+}
+
+func (recorder *mediaRecorderData) UploadRecording(options FileUploadOptions) FileUpload {
+	if !recorder.recorded {
+		ErrorLog("There is no recorded media")
+		return nil
+	}
+
+	upload := newFileUpload(recorder, recorder.recording, options)
+	if upload == nil {
+		return nil
+	}
+	if upload.register() {
+		recorder.session.callFunc("uploadRecordedMedia", recorder.htmlID(), upload.id, upload.options.ChunkSize)
+	}
+	return upload
+}
+
+// GetMediaRecorderState returns the state of the MediaRecorder subview: RecorderInactive (0), RecorderReady (1),
+// RecorderRecording (2) or RecorderPaused (3).
+// If the second argument (subviewID) is not specified or it is "" then the state of the first argument (view) is returned
+func GetMediaRecorderState(view View, subviewID ...string) int {
+	if len(subviewID) > 0 && subviewID[0] != "" {
+		view = ViewByID(view, subviewID[0])
+	}
+
+	if recorder, ok := view.(MediaRecorder); ok {
+		return recorder.State()
+	}
+	return RecorderInactive
+}
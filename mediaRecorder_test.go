@@ -0,0 +1,111 @@
+package rui
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMediaRecorder(t *testing.T) {
+	createTestLog(t, false)
+
+	session := newSession(nil, 0, "", nil)
+	bridge := new(canvasTestBridge)
+	session.setBridge(nil, bridge)
+
+	view := CreateViewFromText(session, `VideoRecorder {
+		id = recorder, facing-mode = environment, record-audio = false, recorder-mime-type = "video/webm"
+	}`)
+	recorder, ok := view.(VideoRecorder)
+	if !ok {
+		t.Fatalf("VideoRecorder is not created: %T", view)
+	}
+
+	html := new(strings.Builder)
+	viewHTML(recorder, html)
+	if !strings.HasPrefix(html.String(), "<video") || !strings.Contains(html.String(), " muted playsinline") {
+		t.Errorf("invalid VideoRecorder html: %s", html)
+	}
+
+	id := recorder.htmlID()
+	command := func(text string) {
+		data := ParseDataText(text)
+		recorder.handleCommand(recorder, data.Tag(), data)
+	}
+
+	states := []int{}
+	var recorded FileInfo
+	errorCode := -1
+	recorder.Set(RecorderStateChangedEvent, func(_ MediaRecorder, state int) {
+		states = append(states, state)
+	})
+	recorder.Set(MediaRecordedEvent, func(_ MediaRecorder, file FileInfo) {
+		recorded = file
+	})
+	recorder.Set(RecorderErrorEvent, func(_ MediaRecorder, code int, message string) {
+		errorCode = code
+	})
+
+	recorder.Start()
+	if len(bridge.funcs) != 1 || bridge.funcs[0] != "startMediaRecorder["+id+" true false environment video/webm]" {
+		t.Errorf("the recording is not started: %v", bridge.funcs)
+	}
+
+	command(`recorder-state-changed-event{session=0, id=` + id + `, state=1}`)
+	command(`recorder-state-changed-event{session=0, id=` + id + `, state=2}`)
+	if recorder.State() != RecorderRecording || GetMediaRecorderState(recorder) != RecorderRecording ||
+		len(states) != 2 || states[1] != RecorderRecording {
+		t.Errorf("invalid recorder state: %d %v", recorder.State(), states)
+	}
+
+	command(`media-recorded-event{session=0, id=` + id + `, size=5, mime-type="video/webm;codecs=vp8", last-modified=1700000000000}`)
+	if file, ok := recorder.Recording(); !ok || file != recorded || recorded.Name != "recording.webm" || recorded.Size != 5 {
+		t.Errorf("invalid recording: %v", recorded)
+	}
+
+	bridge.funcs = nil
+	var finished error = http.ErrBodyNotAllowed
+	received := new(bytes.Buffer)
+	upload := recorder.UploadRecording(FileUploadOptions{
+		Chunk: func(upload FileUpload, offset int64, data []byte) error {
+			received.Write(data)
+			return nil
+		},
+		Finished: func(upload FileUpload, err error) {
+			finished = err
+		},
+	})
+	uploadID := upload.(*fileUploadData).id
+	if len(bridge.funcs) != 1 || bridge.funcs[0] != "uploadRecordedMedia["+id+" "+uploadID+" 1048576]" {
+		t.Errorf("the upload is not started: %v", bridge.funcs)
+	}
+
+	response := httptest.NewRecorder()
+	serveFileUpload(uploadID, response, httptest.NewRequest("POST", "/upload/"+uploadID+"?offset=0", strings.NewReader("abcde")))
+	if response.Code != http.StatusOK {
+		t.Errorf("POST status %d", response.Code)
+	}
+	command(`fileUploaded{session=0, id=` + id + `, upload=` + uploadID + `}`)
+	if finished != nil || received.String() != "abcde" {
+		t.Errorf("invalid upload: %q %v", received.String(), finished)
+	}
+
+	command(`recorder-error-event{session=0, id=` + id + `, code=1, message="Permission denied"}`)
+	if errorCode != RecorderErrorPermissionDenied {
+		t.Errorf("the permission error is not handled: %d", errorCode)
+	}
+
+	audio := NewAudioRecorder(session, Params{Controls: true})
+	html.Reset()
+	viewHTML(audio, html)
+	if !strings.HasPrefix(html.String(), "<audio") || !strings.Contains(html.String(), " controls") {
+		t.Errorf("invalid AudioRecorder html: %s", html)
+	}
+	bridge.funcs = nil
+	audio.Open()
+	if len(bridge.funcs) != 1 || bridge.funcs[0] != "openMediaRecorder["+audio.htmlID()+" false true ]" {
+		t.Errorf("the microphone is not opened: %v", bridge.funcs)
+	}
+}
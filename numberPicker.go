@@ -50,6 +50,13 @@ func (picker *numberPickerData) init(session Session) {
 	picker.numberChangedListeners = []func(NumberPicker, float64, float64){}
 }
 
+func (picker *numberPickerData) supportedProperties() []string {
+	return append([]string{
+		NumberPickerType, NumberPickerMin, NumberPickerMax, NumberPickerStep, NumberPickerValue,
+		NumberChangedEvent,
+	}, picker.viewData.supportedProperties()...)
+}
+
 func (picker *numberPickerData) String() string {
 	return getViewString(picker)
 }
@@ -36,6 +36,12 @@ func (progress *progressBarData) init(session Session) {
 	progress.tag = "ProgressBar"
 }
 
+func (progress *progressBarData) supportedProperties() []string {
+	return append([]string{
+		ProgressBarMax, ProgressBarValue,
+	}, progress.viewData.supportedProperties()...)
+}
+
 func (progress *progressBarData) String() string {
 	return getViewString(progress)
 }
@@ -430,6 +430,12 @@ const (
 	// It can be clipped or display an ellipsis ('…'). Valid values are
 	TextOverflow = "text-overflow"
 
+	// TextFormat is the constant for the "text-format" property tag.
+	// The "text-format" int property sets how the text of a TextView is interpreted.
+	// Valid values are HTMLTextFormat (0), PlainTextFormat (1), and MarkdownTextFormat (2).
+	// The default value is HTMLTextFormat
+	TextFormat = "text-format"
+
 	// Hint is the constant for the "hint" property tag.
 	// The "hint" string property sets a hint to the user of what can be entered in the control.
 	Hint = "hint"
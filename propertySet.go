@@ -64,6 +64,9 @@ var boolProperties = []string{
 	Repeating,
 	UserSelect,
 	ColumnSpanAll,
+	ShowLineNumbers,
+	CodeInsertSpaces,
+	RecordAudio,
 }
 
 var intProperties = []string{
@@ -76,6 +79,7 @@ var intProperties = []string{
 	ColumnCount,
 	Order,
 	TabIndex,
+	FirstDayOfWeek,
 }
 
 var floatProperties = map[string]struct{ min, max float64 }{
@@ -356,6 +360,36 @@ var enumProperties = map[string]struct {
 		"",
 		[]string{"start", "end", "center", "stretch"},
 	},
+	TextFormat: {
+		[]string{"html", "plain", "markdown"},
+		"",
+		[]string{"html", "plain", "markdown"},
+	},
+	CalendarMode: {
+		[]string{"month", "week"},
+		"",
+		[]string{"month", "week"},
+	},
+	ChartXAxisType: {
+		[]string{"number", "time"},
+		"",
+		[]string{"number", "time"},
+	},
+	ToastSeverity: {
+		[]string{"info", "success", "warning", "error"},
+		"",
+		[]string{"info", "success", "warning", "error"},
+	},
+	CalendarSelectionMode: {
+		[]string{"single", "multiple", "range"},
+		"",
+		[]string{"single", "multiple", "range"},
+	},
+	TreeSelectionMode: {
+		[]string{"single", "multiple"},
+		"",
+		[]string{"single", "multiple"},
+	},
 	CheckboxVerticalAlign: {
 		[]string{"top", "bottom", "center"},
 		"",
@@ -436,6 +470,11 @@ var enumProperties = map[string]struct {
 		"",
 		[]string{"none", "metadata", "auto"},
 	},
+	FacingMode: {
+		[]string{"user", "environment"},
+		"",
+		[]string{"user", "environment"},
+	},
 	SelectionMode: {
 		[]string{"none", "cell", "row"},
 		"",
@@ -177,6 +177,17 @@ const (
 	// If there is not enough space to display the ellipsis, it is clipped.
 	TextOverflowEllipsis = 1
 
+	// HTMLTextFormat - value of the "text-format" property: the text is HTML and is output as is
+	HTMLTextFormat = 0
+
+	// PlainTextFormat - value of the "text-format" property: the text is displayed as is,
+	// all HTML special characters are escaped
+	PlainTextFormat = 1
+
+	// MarkdownTextFormat - value of the "text-format" property: the text is Markdown.
+	// It is converted to HTML on the server, raw HTML and unsafe links are not allowed
+	MarkdownTextFormat = 2
+
 	// DefaultSemantics - default value of the view Semantic property
 	DefaultSemantics = 0
 
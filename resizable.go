@@ -61,6 +61,13 @@ func (resizable *resizableData) init(session Session) {
 	resizable.content = []View{}
 }
 
+func (resizable *resizableData) supportedProperties() []string {
+	return append([]string{
+		Side, ResizeBorderWidth, Content, CellWidth, CellHeight, CellVerticalAlign,
+		CellHorizontalAlign, GridRowGap, GridColumnGap,
+	}, resizable.viewData.supportedProperties()...)
+}
+
 func (resizable *resizableData) String() string {
 	return getViewString(resizable)
 }
@@ -104,9 +104,9 @@ func scanEmbedThemesDir(fs *embed.FS, dir string) {
 			path := dir + "/" + name
 			if file.IsDir() {
 				scanEmbedThemesDir(fs, path)
-			} else if strings.ToLower(filepath.Ext(name)) == ".rui" {
+			} else if isDataResourceFile(name) {
 				if data, err := fs.ReadFile(path); err == nil {
-					registerThemeText(string(data))
+					registerThemeData(parseDataResource(name, string(data)))
 				}
 			}
 		}
@@ -195,9 +195,9 @@ func scanThemesDir(path string) {
 				newPath := path + `/` + filename
 				if file.IsDir() {
 					scanThemesDir(newPath)
-				} else if strings.ToLower(filepath.Ext(newPath)) == ".rui" {
+				} else if isDataResourceFile(newPath) {
 					if data, err := os.ReadFile(newPath); err == nil {
-						registerThemeText(string(data))
+						registerThemeData(parseDataResource(newPath, string(data)))
 					} else {
 						ErrorLog(err.Error())
 					}
@@ -209,6 +209,27 @@ func scanThemesDir(path string) {
 	}
 }
 
+// isDataResourceFile returns true if the file is a .rui, .json, .yaml or .yml file
+func isDataResourceFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".rui", ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// parseDataResource parses the text of the resource file using the parser of the file format
+func parseDataResource(name, text string) DataObject {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return ParseDataJSON(text)
+
+	case ".yaml", ".yml":
+		return ParseDataYAML(text)
+	}
+	return ParseDataText(text)
+}
+
 // SetResourcePath set path of the resource directory
 func SetResourcePath(path string) {
 	resources.path = path
@@ -223,16 +244,23 @@ func SetResourcePath(path string) {
 }
 
 func registerThemeText(text string) bool {
-	theme, ok := CreateThemeFromText(text)
+	return registerThemeData(ParseDataText(text))
+}
+
+func registerThemeData(data DataObject) bool {
+	theme, ok := CreateThemeFromObject(data)
 	if !ok {
 		return false
 	}
 
+	themesMutex.Lock()
+	defer themesMutex.Unlock()
+
 	name := theme.Name()
 	if name == "" {
-		defaultTheme.Append(theme)
+		defaultTheme.data().append(theme.data())
 	} else if t, ok := resources.themes[name]; ok {
-		t.Append(theme)
+		t.data().append(theme.data())
 	} else {
 		resources.themes[name] = theme
 	}
@@ -251,6 +279,10 @@ func serveResourceFile(filename string, w http.ResponseWriter, r *http.Request)
 		return false
 	}
 
+	if serveResizedImage(filename, w, r) {
+		return true
+	}
+
 	if image, ok := resources.images[filename]; ok {
 		if image.fs != nil {
 			if serveEmbed(image.fs, image.path) {
@@ -406,15 +438,76 @@ func AllImageResources() []string {
 	return result
 }
 
-func AddTheme(theme Theme) {
-	if theme != nil {
-		name := theme.Name()
-		if name == "" {
-			defaultTheme.Append(theme)
-		} else if t, ok := resources.themes[name]; ok {
-			t.Append(theme)
-		} else {
-			resources.themes[name] = theme
+// readImageResource returns the content of the image resource file or nil if the file is not found
+func readImageResource(filename string) []byte {
+	filename = strings.TrimPrefix(filename, "/")
+	if image, ok := resources.images[filename]; ok {
+		if image.fs != nil {
+			if data, err := image.fs.ReadFile(image.path); err == nil {
+				return data
+			}
+		} else if data, err := os.ReadFile(image.path); err == nil {
+			return data
+		}
+	}
+
+	for _, fs := range resources.embedFS {
+		if data, err := fs.ReadFile(filename); err == nil {
+			return data
+		}
+		for _, dir := range embedRootDirs(fs) {
+			if data, err := fs.ReadFile(dir + "/" + filename); err == nil {
+				return data
+			}
+			if data, err := fs.ReadFile(dir + "/" + imageDir + "/" + filename); err == nil {
+				return data
+			}
 		}
 	}
+
+	read := func(path string) []byte {
+		if data, err := os.ReadFile(path + filename); err == nil {
+			return data
+		}
+		if data, err := os.ReadFile(path + imageDir + "/" + filename); err == nil {
+			return data
+		}
+		return nil
+	}
+
+	if resources.path != "" {
+		if data := read(resources.path); data != nil {
+			return data
+		}
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		return read(filepath.Dir(exe) + "/resources/")
+	}
+	return nil
+}
+
+func AddTheme(theme Theme) {
+	if theme == nil {
+		return
+	}
+
+	themesMutex.Lock()
+	var changed Theme
+	name := theme.Name()
+	if name == "" {
+		changed = defaultTheme
+	} else if t, ok := resources.themes[name]; ok {
+		changed = t
+	} else {
+		resources.themes[name] = theme
+	}
+	if changed != nil {
+		changed.data().append(theme.data())
+	}
+	themesMutex.Unlock()
+
+	if changed != nil {
+		notifyThemeChanged(changed)
+	}
 }
@@ -0,0 +1,296 @@
+package rui
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RUIError describes an error found in a .rui document by ValidateRUI
+type RUIError struct {
+	// File - the name of the file (empty if the document is not a file)
+	File string
+	// Line - the line number of the error (starting from 1)
+	Line int
+	// Column - the column number of the error (starting from 1)
+	Column int
+	// Message - the error description
+	Message string
+}
+
+// Error returns the error text in the "file:line:column: message" format.
+// The line and the column are omitted if the error is not related to a position (for example, a file reading error)
+func (err RUIError) Error() string {
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	if err.File != "" {
+		buffer.WriteString(err.File)
+		buffer.WriteRune(':')
+	}
+	if err.Line > 0 {
+		buffer.WriteString(strconv.Itoa(err.Line))
+		buffer.WriteRune(':')
+		buffer.WriteString(strconv.Itoa(err.Column))
+		buffer.WriteRune(':')
+	}
+	if buffer.Len() > 0 {
+		buffer.WriteRune(' ')
+	}
+	buffer.WriteString(err.Message)
+	return buffer.String()
+}
+
+var viewKnownProperties = map[string][]string{}
+
+// RegisterViewProperties adds the names of the properties of the view type (usually a custom view)
+// which are considered known by ValidateRUI
+func RegisterViewProperties(viewTag string, properties ...string) {
+	for _, tag := range properties {
+		viewKnownProperties[viewTag] = append(viewKnownProperties[viewTag], strings.ToLower(tag))
+	}
+}
+
+// ruiUncheckedValueProperties is the list of the typed properties whose text values are also
+// parsed in another way (a list of sizes, a view ID, etc.), so ValidateRUI does not check them
+var ruiUncheckedValueProperties = []string{Margin, Padding, Radius, Current}
+
+// tagNormalizer is implemented by the views which have aliases of the property names
+type tagNormalizer interface {
+	normalizeTag(tag string) string
+}
+
+func isTypedProperty(tag string) bool {
+	if _, ok := sizeProperties[tag]; ok {
+		return true
+	}
+	if _, ok := enumProperties[tag]; ok {
+		return true
+	}
+	if _, ok := floatProperties[tag]; ok {
+		return true
+	}
+	for _, list := range [][]string{colorProperties, angleProperties, boolProperties, intProperties} {
+		if isPropertyInList(tag, list) {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidPropertyText checks the text value of the typed property without setting it
+func isValidPropertyText(tag, text string) bool {
+	text = strings.Trim(text, " \t\n\r")
+	if text == "" || isConstantName(text) || isPropertyInList(tag, ruiUncheckedValueProperties) {
+		return true
+	}
+
+	if _, ok := sizeProperties[tag]; ok {
+		if strings.ContainsRune(text, '(') {
+			return true
+		}
+		_, err := stringToSizeUnit(text)
+		return err == nil
+	}
+
+	if valuesData, ok := enumProperties[tag]; ok {
+		if tag == Orientation {
+			switch strings.ToLower(text) {
+			case "vertical", "horizontal":
+				return true
+			}
+		}
+		_, ok := enumStringToInt(text, valuesData.values, false)
+		return ok
+	}
+
+	if limits, ok := floatProperties[tag]; ok {
+		f, err := strconv.ParseFloat(text, 64)
+		return err == nil && f >= limits.min && f <= limits.max
+	}
+
+	if isPropertyInList(tag, colorProperties) {
+		_, err := stringToColor(text)
+		return err == nil
+	}
+
+	if isPropertyInList(tag, angleProperties) {
+		_, err := stringToAngleUnit(text)
+		return err == nil
+	}
+
+	if isPropertyInList(tag, boolProperties) {
+		switch strings.ToLower(text) {
+		case "true", "yes", "on", "1", "false", "no", "off", "0":
+			return true
+		}
+		return false
+	}
+
+	if isPropertyInList(tag, intProperties) {
+		_, err := strconv.Atoi(text)
+		return err == nil
+	}
+
+	return true
+}
+
+// ValidateRUIFile reads the .rui file and checks it by ValidateRUI
+func ValidateRUIFile(path string) []RUIError {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []RUIError{{File: path, Message: err.Error()}}
+	}
+	return ValidateRUI(path, string(data))
+}
+
+// ValidateRUI checks the .rui document. The "file" argument is used only in the error descriptions.
+// A view document is checked against the registered view creators: the view types, the property names,
+// and the property values (including enum values) are checked. Theme and strings documents are checked for syntax only.
+// The property names of custom views can be added by RegisterViewProperties.
+// The function returns nil if there are no errors.
+//
+// A property is known if it is handled by the view type or it is one of the typed properties
+// (sizes, colors, enums, etc.) which can be set to any view. Only the text values of the typed properties are checked
+func ValidateRUI(file, text string) []RUIError {
+	var errors []RUIError
+	object := parseDataText(text, func(line, column int, message string) {
+		errors = append(errors, RUIError{File: file, Line: line, Column: column, Message: message})
+	})
+	if object == nil {
+		if len(errors) == 0 {
+			errors = append(errors, RUIError{File: file, Line: 1, Column: 1, Message: "Empty document"})
+		}
+		return errors
+	}
+
+	tag := object.Tag()
+	if tag == "theme" || strings.HasPrefix(tag, "theme:") || tag == "strings" || strings.HasPrefix(tag, "strings:") {
+		return errors
+	}
+
+	validator := ruiValidator{file: file, session: newSession(nil, 0, "", nil)}
+	validator.validateView(object)
+	return validator.errors
+}
+
+type ruiValidator struct {
+	file    string
+	session Session
+	errors  []RUIError
+}
+
+func dataPosition(value any) (int, int) {
+	switch value := value.(type) {
+	case *dataNode:
+		return value.line, value.column
+
+	case *dataObject:
+		return value.line, value.column
+	}
+	return 0, 0
+}
+
+func (validator *ruiValidator) addError(position any, message string) {
+	line, column := dataPosition(position)
+	validator.errors = append(validator.errors, RUIError{File: validator.file, Line: line, Column: column, Message: message})
+}
+
+func isViewObject(value DataValue) bool {
+	if value != nil && value.IsObject() {
+		if object := value.Object(); object != nil {
+			_, ok := viewCreators[object.Tag()]
+			return ok
+		}
+	}
+	return false
+}
+
+func (validator *ruiValidator) validateView(object DataObject) {
+	viewTag := object.Tag()
+	creator, ok := viewCreators[viewTag]
+	if !ok {
+		validator.addError(object, `Unknown view type "`+viewTag+`"`)
+		return
+	}
+
+	view := creator(validator.session)
+	if customView, ok := view.(CustomView); ok {
+		if !InitCustomView(customView, viewTag, validator.session, nil) {
+			validator.addError(object, `Unable to create "`+viewTag+`" view`)
+			return
+		}
+		view = customView.SuperView()
+	}
+
+	supported := view.supportedProperties()
+	normalizer, _ := view.(tagNormalizer)
+
+	count := object.PropertyCount()
+	for i := 0; i < count; i++ {
+		node := object.Property(i)
+		if node == nil {
+			continue
+		}
+
+		tag := strings.ToLower(node.Tag())
+		if isPropertyInList(tag, viewKnownProperties[viewTag]) {
+			continue
+		}
+
+		if normalizer != nil {
+			tag = normalizer.normalizeTag(tag)
+		}
+
+		typed := isTypedProperty(tag)
+		if !typed && !isPropertyInList(tag, supported) {
+			validator.addError(node, `Unknown property "`+node.Tag()+`" of "`+viewTag+`" view`)
+			continue
+		}
+
+		switch node.Type() {
+		case TextNode:
+			if text := node.Text(); typed && !isValidPropertyText(tag, text) {
+				validator.addError(node, `Invalid value "`+text+`" of "`+node.Tag()+`" property`)
+			}
+
+		case ObjectNode:
+			if isViewObject(node.Object()) {
+				validator.validateView(node.Object())
+			}
+
+		case ArrayNode:
+			elements := node.ArrayElements()
+			hasView := false
+			for _, element := range elements {
+				if isViewObject(element) {
+					hasView = true
+					break
+				}
+			}
+			if hasView {
+				for _, element := range elements {
+					if element.IsObject() {
+						validator.validateView(element.Object())
+					}
+				}
+			}
+		}
+	}
+}
+
+// ValidateRUIDir checks by ValidateRUI all .rui files of the directory and its subdirectories
+func ValidateRUIDir(dir string) []RUIError {
+	var errors []RUIError
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.ToLower(filepath.Ext(path)) == ".rui" {
+			errors = append(errors, ValidateRUIFile(path)...)
+		}
+		return err
+	})
+	if err != nil {
+		errors = append(errors, RUIError{File: dir, Message: err.Error()})
+	}
+	return errors
+}
@@ -0,0 +1,99 @@
+package rui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRUI(t *testing.T) {
+	createTestLog(t, false)
+
+	text := `ListLayout {
+	id = root,
+	orientation = sideways,
+	content = [
+		TextView { text = "Hello", text-colr = red },
+		Button { content = "OK", semantics = code },
+		UnknownView { },
+		ListView {
+			items = [ "one", TextView { width = abc } ],
+		},
+	],
+}`
+
+	errors := ValidateRUI("main.rui", text)
+	expected := []struct {
+		line, column int
+		message      string
+	}{
+		{3, 2, `"sideways"`},
+		{5, 30, `Unknown property "text-colr" of "TextView" view`},
+		{7, 3, `Unknown view type "UnknownView"`},
+		{9, 32, `"abc"`},
+	}
+
+	if len(errors) != len(expected) {
+		t.Fatalf("ValidateRUI returns %d errors, expected %d: %v", len(errors), len(expected), errors)
+	}
+	for i, err := range errors {
+		if err.File != "main.rui" || err.Line != expected[i].line || err.Column != expected[i].column ||
+			!strings.Contains(err.Message, expected[i].message) {
+			t.Errorf(`error %d: "%s", expected %d:%d "%s"`, i, err.Error(), expected[i].line, expected[i].column, expected[i].message)
+		}
+	}
+
+	if errors := ValidateRUI("", "TextView {\n\ttext = \"Hello\"\n\twidth = 100%\n}"); errors != nil {
+		t.Errorf("errors in the valid document: %v", errors)
+	}
+
+	if errors := ValidateRUI("", "ColumnLayout { text = \"Hello\" }"); len(errors) != 1 ||
+		!strings.Contains(errors[0].Message, `Unknown property "text" of "ColumnLayout" view`) {
+		t.Errorf("property of another view: %v", errors)
+	}
+
+	valid := `StackLayout {
+	current = page2, margin = "4px, 8px", orientation = vertical, opacity = 0.5,
+	content = [
+		EditView { id = page1, type = password, hint = "Password", max-length = 16, title = Tab },
+		NumberPicker { id = page2, min = 0, max = 10, value = 5, text-color = @textColor },
+		ListView { items = [ "one" ], horizontal-align = center, width = "max(50%, 100px)" },
+		GridLayout { cell-width = "1fr, 2fr", row-gap = 4px, disabled = yes, tab-index = 1 },
+	],
+}`
+	lastError = "previous error"
+	if errors := ValidateRUI("", valid); errors != nil {
+		t.Errorf("errors in the valid document: %v", errors)
+	}
+	if lastError != "previous error" {
+		t.Errorf("ValidateRUI changes the last error: %q", lastError)
+	}
+
+	if errors := ValidateRUI("", "TextView {\n\ttext = \"Hello\n}"); len(errors) != 1 || errors[0].Line != 2 {
+		t.Errorf("syntax error: %v", errors)
+	}
+
+	if errors := ValidateRUI("", "theme { colors = _{ myColor = #FF000000 } }"); errors != nil {
+		t.Errorf("errors in the theme: %v", errors)
+	}
+
+	RegisterViewCreator("ValidatorTestView", func(session Session) View {
+		return new(validatorTestView)
+	})
+	defer delete(viewCreators, "ValidatorTestView")
+	if errors := ValidateRUI("", "ValidatorTestView { custom-prop = 1 }"); len(errors) != 1 {
+		t.Errorf("unregistered property of the custom view: %v", errors)
+	}
+	RegisterViewProperties("ValidatorTestView", "custom-prop")
+	defer delete(viewKnownProperties, "ValidatorTestView")
+	if errors := ValidateRUI("", "ValidatorTestView { custom-prop = 1 }"); errors != nil {
+		t.Errorf("registered property of the custom view: %v", errors)
+	}
+}
+
+type validatorTestView struct {
+	CustomViewData
+}
+
+func (view *validatorTestView) CreateSuperView(session Session) View {
+	return NewTextView(session, nil)
+}
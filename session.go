@@ -2,9 +2,14 @@ package rui
 
 import (
 	"fmt"
+	"image"
+	"io"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type webBridge interface {
@@ -24,10 +29,13 @@ type webBridge interface {
 	callCanvasFunc(funcName string, args ...any)
 	callCanvasVarFunc(v any, funcName string, args ...any)
 	callCanvasImageFunc(url string, property string, funcName string, args ...any)
+	callCanvasImageDataFunc(funcName string, img *image.NRGBA, args ...any)
 	createCanvasVar(funcName string, args ...any) any
 	updateCanvasProperty(property string, value any)
 	canvasFinish()
 	canvasTextMetrics(htmlID, font, text string) TextMetrics
+	canvasImageData(htmlID string, x, y, width, height float64) *image.NRGBA
+	canvasDataURL(htmlID, mimeType string, quality float64) []byte
 	htmlPropertyValue(htmlID, name string) string
 	answerReceived(answer DataObject)
 	close()
@@ -48,6 +56,16 @@ type Session interface {
 
 	// DarkTheme returns "true" if the dark theme is used
 	DarkTheme() bool
+	// ColorScheme returns the color scheme set by SetColorScheme:
+	// AutoColorScheme (0), LightColorScheme (1), or DarkColorScheme (2)
+	ColorScheme() int
+	// SetColorScheme forces the light (LightColorScheme) or the dark (DarkColorScheme) theme
+	// or returns to the theme selected by the browser (AutoColorScheme).
+	// The value is saved in the client-side storage and restored in the next sessions
+	SetColorScheme(scheme int)
+	// HighContrast returns "true" if the high-contrast mode is requested by the browser ("prefers-contrast: more").
+	// In this mode the colors of the "colors:contrast" section of the theme are used
+	HighContrast() bool
 	// Mobile returns "true" if current session is displayed on a touch screen device
 	TouchScreen() bool
 	// PixelRatio returns the ratio of the resolution in physical pixels to the resolution
@@ -67,12 +85,34 @@ type Session interface {
 	UserAgent() string
 	// RemoteAddr returns the client address.
 	RemoteAddr() string
-	// Language returns the current session language
+	// Language returns the current session language. If the language is not set by SetLanguage
+	// then the first negotiated language is returned (see NegotiatedLanguages)
 	Language() string
-	// SetLanguage set the current session language
+	// SetLanguage set the current session language. All views, popups, and toasts are re-rendered
+	// with the new language. Invoke SetLanguage("") to return to the negotiated language
 	SetLanguage(lang string)
+	// NegotiatedLanguages returns the languages of loaded string resources which match
+	// the user preferences (the Accept-Language header or the browser languages), sorted by preference
+	NegotiatedLanguages() []string
 	// GetString returns the text for the current language
 	GetString(tag string) (string, bool)
+	// FormatString returns the text for the current language in which "{name}" placeholders
+	// are replaced by the values of params. If params contain the "count" (PluralCount) number
+	// then the plural form of the text corresponding to this number is used
+	FormatString(tag string, params Params) (string, bool)
+	// Locale returns the locale of the session. By default, it is determined by the session language
+	Locale() Locale
+	// SetLocale sets the locale of the session. Invoke SetLocale(nil) to use the locale of the session language
+	SetLocale(locale *Locale)
+	// FormatNumber formats the number using the session locale.
+	// "precision" sets the number of digits after the decimal point, -1 means the minimal number of digits necessary
+	FormatNumber(n float64, precision int) string
+	// FormatCurrency formats the amount of the local currency using the session locale
+	FormatCurrency(amount float64) string
+	// FormatDate formats the date using the session locale
+	FormatDate(date time.Time) string
+	// FormatDateTime formats the date and time using the session locale
+	FormatDateTime(date time.Time) string
 
 	// Content returns the SessionContent of session
 	Content() SessionContent
@@ -97,6 +137,11 @@ type Session interface {
 	DownloadFile(path string)
 	//DownloadFileData downloads (saves) on the client side a file with a specified name and specified content.
 	DownloadFileData(filename string, data []byte)
+	// DownloadStream downloads (saves) on the client side a file with a specified name, MIME type and
+	// the content written by the "content" function when the client requests the file. The function is called
+	// in the goroutine of the HTTP request. The "finished" function (can be nil) is called in the session goroutine
+	// when the download is completed (err == nil), failed or expired (see DownloadExpiration)
+	DownloadStream(filename, mimeType string, content func(w io.Writer) error, finished func(err error))
 	// OpenURL opens the url in the new browser tab
 	OpenURL(url string)
 
@@ -111,12 +156,25 @@ type Session interface {
 	// Invoke SetHotKey(..., ..., nil) for remove hotkey function.
 	SetHotKey(keyCode KeyCode, controlKeys ControlKeyMask, fn func(Session))
 
+	// ShowToast displays the non-blocking notification with the given text.
+	// The following properties can be passed in params: "toast-severity", "toast-timeout",
+	// "toast-actions", "close-button", and "dismiss-event"
+	ShowToast(text string, params Params) Toast
+	// SetToastPosition sets the screen corner in which toasts are stacked.
+	// Valid values: TopLeftToast (0), TopCenterToast (1), TopRightToast (2),
+	// BottomLeftToast (3), BottomCenterToast (4), and BottomRightToast (5, the default value)
+	SetToastPosition(position int)
+	// SetMaxToasts sets the maximum number of simultaneously displayed toasts (5 by default).
+	// When the limit is exceeded, the oldest toast is closed. A value <= 0 removes the limit
+	SetMaxToasts(count int)
+
 	getCurrentTheme() Theme
 	registerAnimation(props []AnimatedProperty) string
 
 	resolveConstants(value string) (string, bool)
 	checkboxOffImage() string
 	checkboxOnImage() string
+	checkboxMixedImage() string
 	radiobuttonOffImage() string
 	radiobuttonOnImage() string
 
@@ -141,14 +199,18 @@ type Session interface {
 	createCanvasVar(funcName string, args ...any) any
 	callCanvasVarFunc(v any, funcName string, args ...any)
 	callCanvasImageFunc(url string, property string, funcName string, args ...any)
+	callCanvasImageDataFunc(funcName string, img *image.NRGBA, args ...any)
 	updateCanvasProperty(property string, value any)
 	canvasFinish()
 	canvasTextMetrics(htmlID, font, text string) TextMetrics
+	canvasImageData(htmlID string, x, y, width, height float64) *image.NRGBA
+	canvasDataURL(htmlID, mimeType string, quality float64) []byte
 	htmlPropertyValue(htmlID, name string) string
 	handleAnswer(data DataObject)
 	handleRootSize(data DataObject)
 	handleResize(data DataObject)
 	handleEvent(command string, data DataObject)
+	postViewCommand(htmlID, command string) bool
 	close()
 
 	onStart()
@@ -163,12 +225,22 @@ type Session interface {
 
 	popupManager() *popupManager
 	imageManager() *imageManager
+	toastManager() *toastManager
+	resourcesChanged(viewsChanged bool)
+	usesTheme(theme Theme) bool
+	themeChanged()
+	updateThemeCSS()
 }
 
 type sessionData struct {
 	customTheme      Theme
 	currentTheme     Theme
+	themeRules       map[string]string
+	themeMediaCSS    string
 	darkTheme        bool
+	systemDarkTheme  bool
+	colorScheme      int
+	highContrast     bool
 	touchScreen      bool
 	screenWidth      int
 	screenHeight     int
@@ -177,8 +249,11 @@ type sessionData struct {
 	userAgent        string
 	language         string
 	languages        []string
+	acceptLanguages  []string
+	negotiatedLangs  []string
 	checkboxOff      string
 	checkboxOn       string
+	checkboxMixed    string
 	radiobuttonOff   string
 	radiobuttonOn    string
 	app              Application
@@ -188,14 +263,23 @@ type sessionData struct {
 	rootView         View
 	ignoreUpdates    bool
 	popups           *popupManager
+	toasts           *toastManager
+	locale           *Locale
 	images           *imageManager
 	bridge           webBridge
 	events           chan DataObject
+	eventsMutex      sync.Mutex
 	animationCounter int
 	animationCSS     string
 	updateScripts    map[string]*strings.Builder
 	clientStorage    map[string]string
 	hotkeys          map[string]func(Session)
+	downloads        map[string]func(error)
+	downloadKey      string
+
+	themeChangedQueued     atomic.Bool
+	resourcesChangedQueued atomic.Bool
+	viewsChanged           atomic.Bool
 }
 
 func newSession(app Application, id int, customTheme string, params DataObject) Session {
@@ -238,13 +322,27 @@ func (session *sessionData) ID() int {
 }
 
 func (session *sessionData) setBridge(events chan DataObject, bridge webBridge) {
+	session.eventsMutex.Lock()
 	session.events = events
+	session.eventsMutex.Unlock()
 	session.bridge = bridge
+
+	// the events queued to the previous connection are lost
+	if session.themeChangedQueued.Load() && !session.postThemeChanged() {
+		session.themeChangedQueued.Store(false)
+	}
+	if session.resourcesChangedQueued.Load() && !session.postResourcesChanged() {
+		session.resourcesChangedQueued.Store(false)
+	}
 }
 
 func (session *sessionData) close() {
-	if session.events != nil {
-		session.events <- ParseDataText(`session-close{session="` + strconv.Itoa(session.sessionID) + `"}`)
+	session.eventsMutex.Lock()
+	events := session.events
+	session.eventsMutex.Unlock()
+
+	if events != nil {
+		events <- ParseDataText(`session-close{session="` + strconv.Itoa(session.sessionID) + `"}`)
 	}
 }
 
@@ -295,7 +393,7 @@ func (session *sessionData) RootView() View {
 }
 
 func (session *sessionData) writeInitScript(writer *strings.Builder) {
-	if css := session.getCurrentTheme().cssText(session); css != "" {
+	if css := session.themeCSSText(); css != "" {
 		css = strings.ReplaceAll(css, "\n", `\n`)
 		css = strings.ReplaceAll(css, "\t", `\t`)
 		writer.WriteString(`document.querySelector('style').textContent += "`)
@@ -333,13 +431,7 @@ func (session *sessionData) reload() {
 	buffer := allocStringBuilder()
 	defer freeStringBuilder(buffer)
 
-	css := appStyles + session.getCurrentTheme().cssText(session) + session.animationCSS
-	css = strings.ReplaceAll(css, "\n", `\n`)
-	css = strings.ReplaceAll(css, "\t", `\t`)
-	buffer.WriteString(`document.querySelector('style').textContent = "`)
-	buffer.WriteString(css)
-	buffer.WriteString("\";\n")
-
+	session.writeStylesScript(buffer)
 	if session.rootView != nil {
 		buffer.WriteString(`document.getElementById('ruiRootView').innerHTML = '`)
 		viewHTML(session.rootView, buffer)
@@ -350,6 +442,70 @@ func (session *sessionData) reload() {
 	session.updateTooltipConstants()
 }
 
+// writeStylesScript writes the script which replaces all CSS styles of the page
+func (session *sessionData) writeStylesScript(buffer *strings.Builder) {
+	css := appStyles + session.themeCSSText() + session.animationCSS
+	css = strings.ReplaceAll(css, "\n", `\n`)
+	css = strings.ReplaceAll(css, "\t", `\t`)
+	buffer.WriteString(`document.querySelector('style').textContent = "`)
+	buffer.WriteString(css)
+	buffer.WriteString("\";\n")
+}
+
+// resourcesChanged is called by the resource watcher. The reloading is performed in the session event
+// handler goroutine. Only one "resources-changed" event is queued at the same time, the changes of views
+// are accumulated until the event is handled
+func (session *sessionData) resourcesChanged(viewsChanged bool) {
+	if viewsChanged {
+		session.viewsChanged.Store(true)
+	}
+	if session.resourcesChangedQueued.CompareAndSwap(false, true) && !session.postResourcesChanged() {
+		session.resourcesChangedQueued.Store(false)
+	}
+}
+
+func (session *sessionData) postResourcesChanged() bool {
+	return session.postEvent(ParseDataText(`resources-changed{session="` + strconv.Itoa(session.sessionID) + `"}`))
+}
+
+// postViewCommand queues the command to the view with htmlID. The command is handled by the view
+// after the current event. It returns false if the command can not be queued
+func (session *sessionData) postViewCommand(htmlID, command string) bool {
+	return session.postEvent(ParseDataText(command + `{session="` + strconv.Itoa(session.sessionID) + `", id="` + htmlID + `"}`))
+}
+
+// postEvent passes the event to the session goroutine. It returns false if the session
+// has no event queue or the queue is full
+func (session *sessionData) postEvent(data DataObject) bool {
+	session.eventsMutex.Lock()
+	defer session.eventsMutex.Unlock()
+
+	if session.events == nil {
+		return false
+	}
+	select {
+	case session.events <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+func (session *sessionData) reloadResources(viewsChanged bool) {
+	session.currentTheme = nil
+	session.resetLanguages()
+	if viewsChanged && session.content != nil {
+		if rootView := session.content.CreateRootView(session); rootView != nil {
+			rootView.setParentID("ruiRootView")
+			session.rootView = rootView
+		}
+	}
+
+	if session.bridge != nil {
+		session.reload()
+	}
+}
+
 func (session *sessionData) ignoreViewUpdates() bool {
 	return session.bridge == nil || session.ignoreUpdates
 }
@@ -496,6 +652,12 @@ func (session *sessionData) callCanvasImageFunc(url string, property string, fun
 	}
 }
 
+func (session *sessionData) callCanvasImageDataFunc(funcName string, img *image.NRGBA, args ...any) {
+	if session.bridge != nil && img != nil {
+		session.bridge.callCanvasImageDataFunc(funcName, img, args...)
+	}
+}
+
 func (session *sessionData) canvasFinish() {
 	if session.bridge != nil {
 		session.bridge.canvasFinish()
@@ -511,6 +673,24 @@ func (session *sessionData) canvasTextMetrics(htmlID, font, text string) TextMet
 	return TextMetrics{Width: 0}
 }
 
+func (session *sessionData) canvasImageData(htmlID string, x, y, width, height float64) *image.NRGBA {
+	if session.bridge != nil {
+		return session.bridge.canvasImageData(htmlID, x, y, width, height)
+	}
+
+	ErrorLog("No connection")
+	return nil
+}
+
+func (session *sessionData) canvasDataURL(htmlID, mimeType string, quality float64) []byte {
+	if session.bridge != nil {
+		return session.bridge.canvasDataURL(htmlID, mimeType, quality)
+	}
+
+	ErrorLog("No connection")
+	return nil
+}
+
 func (session *sessionData) htmlPropertyValue(htmlID, name string) string {
 	if session.bridge != nil {
 		return session.bridge.htmlPropertyValue(htmlID, name)
@@ -589,6 +769,8 @@ func (session *sessionData) handleResize(data DataObject) {
 }
 
 func (session *sessionData) handleSessionInfo(params DataObject) {
+	session.resetLanguages()
+
 	if value, ok := params.PropertyValue("touch"); ok {
 		session.touchScreen = (value == "1" || value == "true")
 	}
@@ -603,16 +785,33 @@ func (session *sessionData) handleSessionInfo(params DataObject) {
 		}
 	}
 
-	if value, ok := params.PropertyValue("language"); ok {
-		session.language = value
-	}
-
 	if value, ok := params.PropertyValue("languages"); ok {
 		session.languages = strings.Split(value, ",")
 	}
 
+	if value, ok := params.PropertyValue("language"); ok && value != "" {
+		found := false
+		for _, lang := range session.languages {
+			if lang == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			session.languages = append([]string{value}, session.languages...)
+		}
+	}
+
+	if value, ok := params.PropertyValue("accept-language"); ok {
+		session.acceptLanguages = ParseAcceptLanguage(value)
+	}
+
 	if value, ok := params.PropertyValue("dark"); ok {
-		session.darkTheme = (value == "1" || value == "true")
+		session.systemDarkTheme = (value == "1" || value == "true")
+	}
+
+	if value, ok := params.PropertyValue("contrast"); ok {
+		session.highContrast = (value == "1" || value == "true")
 	}
 
 	if value, ok := params.PropertyValue("pixel-ratio"); ok {
@@ -632,6 +831,9 @@ func (session *sessionData) handleSessionInfo(params DataObject) {
 			}
 		}
 	}
+
+	session.colorScheme = colorSchemeFromText(session.clientStorage[colorSchemeStorageKey])
+	session.updateDarkTheme()
 }
 
 func (session *sessionData) handleEvent(command string, data DataObject) {
@@ -651,6 +853,26 @@ func (session *sessionData) handleEvent(command string, data DataObject) {
 	case "sessionInfo":
 		session.handleSessionInfo(data)
 
+	case "resources-changed":
+		session.resourcesChangedQueued.Store(false)
+		session.reloadResources(session.viewsChanged.Swap(false))
+
+	case "theme-changed":
+		session.themeChangedQueued.Store(false)
+		session.updateThemeCSS()
+
+	case "colorSchemeChanged":
+		session.handleColorSchemeChanged(data)
+
+	case "download-finished":
+		session.downloadFinished(data)
+
+	case "file-upload-expired":
+		session.fileUploadExpired(data)
+
+	case "toastClose", "toastAction":
+		session.toastManager().handleCommand(command, data)
+
 	case "storageError":
 		if text, ok := data.PropertyValue("error"); ok {
 			ErrorLog(text)
@@ -46,6 +46,7 @@ func (session *sessionData) onFinish() {
 			listener.OnFinish(session)
 		}
 	}
+	session.cancelFileUploads()
 }
 
 func (session *sessionData) onPause() {
@@ -2,13 +2,107 @@ package rui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
+const (
+	// AutoColorScheme - the light or dark theme is selected by the browser ("prefers-color-scheme" media feature).
+	// This is the default value.
+	AutoColorScheme = 0
+	// LightColorScheme - the light theme is always used
+	LightColorScheme = 1
+	// DarkColorScheme - the dark theme is always used
+	DarkColorScheme = 2
+
+	colorSchemeStorageKey = "ruiColorScheme"
+)
+
 func (session *sessionData) DarkTheme() bool {
 	return session.darkTheme
 }
 
+func (session *sessionData) ColorScheme() int {
+	return session.colorScheme
+}
+
+func (session *sessionData) SetColorScheme(scheme int) {
+	var text string
+	switch scheme {
+	case AutoColorScheme:
+		text = "auto"
+
+	case LightColorScheme:
+		text = "light"
+
+	case DarkColorScheme:
+		text = "dark"
+
+	default:
+		ErrorLogF("Invalid color scheme: %d", scheme)
+		return
+	}
+
+	session.colorScheme = scheme
+	session.clientStorage[colorSchemeStorageKey] = text
+	if session.bridge != nil {
+		session.bridge.callFunc("localStorageSet", colorSchemeStorageKey, text)
+		if session.updateDarkTheme() {
+			session.reload()
+		}
+	} else {
+		session.updateDarkTheme()
+	}
+}
+
+func (session *sessionData) HighContrast() bool {
+	return session.highContrast
+}
+
+func colorSchemeFromText(text string) int {
+	switch text {
+	case "light":
+		return LightColorScheme
+
+	case "dark":
+		return DarkColorScheme
+	}
+	return AutoColorScheme
+}
+
+// updateDarkTheme selects the dark or light theme according to the color scheme.
+// It returns true if the theme is changed
+func (session *sessionData) updateDarkTheme() bool {
+	dark := session.systemDarkTheme
+	switch session.colorScheme {
+	case LightColorScheme:
+		dark = false
+
+	case DarkColorScheme:
+		dark = true
+	}
+
+	if dark != session.darkTheme {
+		session.darkTheme = dark
+		return true
+	}
+	return false
+}
+
+// handleColorSchemeChanged handles the change of the "prefers-color-scheme" and "prefers-contrast" media features
+func (session *sessionData) handleColorSchemeChanged(data DataObject) {
+	session.systemDarkTheme = dataBoolProperty(data, "dark")
+	changed := session.updateDarkTheme()
+	if contrast := dataBoolProperty(data, "contrast"); contrast != session.highContrast {
+		session.highContrast = contrast
+		changed = true
+	}
+
+	if changed && session.bridge != nil {
+		session.reload()
+	}
+}
+
 func (session *sessionData) TouchScreen() bool {
 	return session.touchScreen
 }
@@ -112,19 +206,61 @@ func (session *sessionData) Constant(tag string) (string, bool) {
 	return session.constant(tag, []string{})
 }
 
+// getCurrentTheme returns the copy of the themes used by the session. The shared themes are copied
+// under themesMutex, so the session goroutine can read the copy without locking
 func (session *sessionData) getCurrentTheme() Theme {
 	if session.currentTheme != nil {
 		return session.currentTheme
 	}
 
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
+	visited := map[Theme]bool{}
+	current := new(theme)
+	current.init()
+	appendExtendedTheme(current, defaultTheme, visited)
 	if session.customTheme != nil {
-		session.currentTheme = NewTheme("")
-		session.currentTheme.Append(defaultTheme)
-		session.currentTheme.Append(session.customTheme)
-		return session.currentTheme
+		appendExtendedTheme(current, session.customTheme, visited)
+	}
+	session.currentTheme = current
+	return current
+}
+
+// appendExtendedTheme appends to the result the parents of the theme (see Theme.SetParents) and then the theme itself.
+// The caller must hold themesMutex
+func appendExtendedTheme(result *theme, theme Theme, visited map[Theme]bool) {
+	if visited[theme] {
+		return
 	}
+	visited[theme] = true
 
-	return defaultTheme
+	for _, name := range theme.data().parents {
+		if parent, ok := resources.themes[name]; ok {
+			appendExtendedTheme(result, parent, visited)
+		} else {
+			ErrorLogF(`Theme "%s" extended by theme "%s" not found`, name, theme.Name())
+		}
+	}
+	result.append(theme.data())
+}
+
+// themeExtends returns true if the theme extends the ancestor theme directly or through its parents.
+// The caller must hold themesMutex
+func themeExtends(theme, ancestor Theme, visited map[Theme]bool) bool {
+	if visited[theme] {
+		return false
+	}
+	visited[theme] = true
+
+	for _, name := range theme.data().parents {
+		if parent, ok := resources.themes[name]; ok {
+			if parent == ancestor || themeExtends(parent, ancestor, visited) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Color return the color with "tag" name or 0 if it is not exists
@@ -132,7 +268,7 @@ func (session *sessionData) Color(tag string) (Color, bool) {
 	tags := []string{tag}
 	theme := session.getCurrentTheme()
 	for {
-		result := theme.color(tag, session.darkTheme)
+		result := theme.color(tag, session.darkTheme, session.highContrast)
 		if result == "" {
 			ErrorLogF(`"%v" color not found`, tag)
 			return 0, false
@@ -190,17 +326,123 @@ func (session *sessionData) SetCustomTheme(name string) bool {
 		if session.customTheme == nil {
 			return true
 		}
-	} else if theme, ok := resources.themes[name]; ok {
-		session.customTheme = theme
+		session.customTheme = nil
 		session.currentTheme = nil
 	} else {
-		return false
+		themesMutex.RLock()
+		theme, ok := resources.themes[name]
+		themesMutex.RUnlock()
+		if !ok {
+			return false
+		}
+		session.customTheme = theme
+		session.currentTheme = nil
 	}
 
 	session.reload()
 	return true
 }
 
+// usesTheme returns true if the session styles depend on the theme
+func (session *sessionData) usesTheme(theme Theme) bool {
+	if theme == nil {
+		return false
+	}
+	if theme == defaultTheme || theme == session.customTheme {
+		return true
+	}
+
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
+	visited := map[Theme]bool{}
+	if themeExtends(defaultTheme, theme, visited) {
+		return true
+	}
+	return session.customTheme != nil && themeExtends(session.customTheme, theme, visited)
+}
+
+// themeChanged is called when a theme used by the session is changed. The CSS updating is performed
+// in the session event handler goroutine. Only one "theme-changed" event is queued at the same time
+func (session *sessionData) themeChanged() {
+	if session.themeChangedQueued.CompareAndSwap(false, true) && !session.postThemeChanged() {
+		session.themeChangedQueued.Store(false)
+	}
+}
+
+func (session *sessionData) postThemeChanged() bool {
+	return session.postEvent(ParseDataText(`theme-changed{session="` + strconv.Itoa(session.sessionID) + `"}`))
+}
+
+// themeCSSText returns the CSS text of the current theme and remembers its rules for updateThemeCSS
+func (session *sessionData) themeCSSText() string {
+	rules, media := session.getCurrentTheme().data().cssRules(session)
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	session.themeRules = make(map[string]string, len(rules))
+	for _, rule := range rules {
+		if rule.selector != "" {
+			session.themeRules[rule.selector] = rule.text
+		}
+		buffer.WriteString(rule.text)
+	}
+	session.themeMediaCSS = media
+	buffer.WriteString(media)
+	return buffer.String()
+}
+
+// updateThemeCSS sends to the client only the CSS rules changed after the last sending of the theme.
+// All styles are replaced if the media rules are changed
+func (session *sessionData) updateThemeCSS() {
+	session.currentTheme = nil
+	if session.bridge == nil {
+		session.themeRules = nil
+		return
+	}
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	rules, media := session.getCurrentTheme().data().cssRules(session)
+	if session.themeRules == nil || media != session.themeMediaCSS {
+		session.writeStylesScript(buffer)
+		buffer.WriteString("scanElementsSize();")
+	} else {
+		writeRule := func(selector, text string) {
+			text = strings.ReplaceAll(text, "\n", `\n`)
+			text = strings.ReplaceAll(text, "\t", `\t`)
+			buffer.WriteString(`replaceCSSRule("`)
+			buffer.WriteString(selector)
+			buffer.WriteString(`", "`)
+			buffer.WriteString(text)
+			buffer.WriteString("\");\n")
+		}
+
+		newRules := make(map[string]string, len(rules))
+		for _, rule := range rules {
+			if rule.selector != "" {
+				newRules[rule.selector] = rule.text
+				if text, ok := session.themeRules[rule.selector]; !ok || text != rule.text {
+					writeRule(rule.selector, rule.text)
+				}
+			}
+		}
+		for selector := range session.themeRules {
+			if _, ok := newRules[selector]; !ok {
+				writeRule(selector, "")
+			}
+		}
+		session.themeRules = newRules
+	}
+
+	if buffer.Len() > 0 {
+		session.bridge.writeMessage(buffer.String())
+	}
+	session.updateTooltipConstants()
+}
+
 const checkImage = `<svg width="16" height="16" version="1.1" viewBox="0 0 16 16" xmlns="http://www.w3.org/2000/svg"><path d="m4 8 3 4 5-8" fill="none" stroke="#fff" stroke-linecap="round" stroke-linejoin="round" stroke-width="2.5"/></svg>`
 
 func (session *sessionData) checkboxImage(checked bool) string {
@@ -258,6 +500,29 @@ func (session *sessionData) checkboxOnImage() string {
 	return session.checkboxOn
 }
 
+func (session *sessionData) checkboxMixedImage() string {
+	if session.checkboxMixed == "" {
+		var borderColor, backgroundColor Color
+		var ok bool
+
+		if borderColor, ok = session.Color("ruiDisabledTextColor"); !ok {
+			if session.darkTheme {
+				borderColor = 0xFFA0A0A0
+			} else {
+				borderColor = 0xFF202020
+			}
+		}
+
+		if backgroundColor, ok = session.Color("ruiHighlightColor"); !ok {
+			backgroundColor = 0xFF1A74E8
+		}
+
+		session.checkboxMixed = fmt.Sprintf(`<div style="width: 18px; height: 18px; display: grid; justify-items: center; align-items: center; border: 1px solid %s; border-radius: 4px;"><div style="width: 8px; height: 8px; background-color: %s; border-radius: 1px;"></div></div>`,
+			borderColor.cssString(), backgroundColor.cssString())
+	}
+	return session.checkboxMixed
+}
+
 func (session *sessionData) radiobuttonOffImage() string {
 	if session.radiobuttonOff == "" {
 		var borderColor, backgroundColor Color
@@ -313,17 +578,43 @@ func (session *sessionData) Language() string {
 		return session.language
 	}
 
-	if session.languages != nil && len(session.languages) > 0 {
+	if langs := session.negotiatedLanguages(); len(langs) > 0 {
+		return langs[0]
+	}
+
+	if len(session.languages) > 0 {
 		return session.languages[0]
 	}
 
 	return "en"
 }
 
+func (session *sessionData) NegotiatedLanguages() []string {
+	return append([]string{}, session.negotiatedLanguages()...)
+}
+
+// negotiatedLanguages returns the cached result of the language negotiation. The cache is cleared
+// by resetLanguages when the user preferences, the session language or the string resources are changed
+func (session *sessionData) negotiatedLanguages() []string {
+	if session.negotiatedLangs == nil {
+		requested := session.acceptLanguages
+		if len(requested) == 0 {
+			requested = session.languages
+		}
+		session.negotiatedLangs = append([]string{}, NegotiateLanguages(requested, LoadedLanguages())...)
+	}
+	return session.negotiatedLangs
+}
+
+func (session *sessionData) resetLanguages() {
+	session.negotiatedLangs = nil
+}
+
 func (session *sessionData) SetLanguage(lang string) {
 	lang = strings.Trim(lang, " \t\n\r")
 	if lang != session.language {
 		session.language = lang
+		session.resetLanguages()
 
 		if session.rootView != nil && session.bridge != nil {
 			buffer := allocStringBuilder()
@@ -331,6 +622,13 @@ func (session *sessionData) SetLanguage(lang string) {
 
 			viewHTML(session.rootView, buffer)
 			session.bridge.updateInnerHTML("ruiRootView", buffer.String())
+
+			if len(session.popupManager().popups) > 0 {
+				session.popupManager().updatePopupLayerInnerHTML(session)
+			}
+			if session.toasts != nil && len(session.toasts.toasts) > 0 {
+				session.toasts.updateToastLayer(session)
+			}
 		}
 	}
 }
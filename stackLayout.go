@@ -58,6 +58,12 @@ func (layout *stackLayoutData) init(session Session) {
 	layout.properties.Store(TransitionEndEvent, []func(View, string){layout.pushFinished, layout.popFinished})
 }
 
+func (layout *stackLayoutData) supportedProperties() []string {
+	return append([]string{
+		Current, TransitionEndEvent,
+	}, layout.viewsContainerData.supportedProperties()...)
+}
+
 func (layout *stackLayoutData) String() string {
 	return getViewString(layout)
 }
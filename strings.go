@@ -2,12 +2,41 @@ package rui
 
 import (
 	"embed"
+	"fmt"
+	"math"
 	"os"
-	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+)
+
+const (
+	// PluralCount is the name of the FormatString parameter which selects the plural form of a string
+	PluralCount = "count"
+
+	// PluralZero is the CLDR plural category "zero"
+	PluralZero = "zero"
+	// PluralOne is the CLDR plural category "one"
+	PluralOne = "one"
+	// PluralTwo is the CLDR plural category "two"
+	PluralTwo = "two"
+	// PluralFew is the CLDR plural category "few"
+	PluralFew = "few"
+	// PluralMany is the CLDR plural category "many"
+	PluralMany = "many"
+	// PluralOther is the CLDR plural category "other"
+	PluralOther = "other"
 )
 
 var stringResources = map[string]map[string]string{}
+var pluralResources = map[string]map[string]map[string]string{}
+
+// stringResourcesMutex guards stringResources and pluralResources which are changed
+// by the resource watcher while the sessions are running
+var stringResourcesMutex sync.RWMutex
+var pluralRules = map[string]func(n float64) string{}
+var defaultLanguage = ""
 
 func scanEmbedStringsDir(fs *embed.FS, dir string) {
 	if files, err := fs.ReadDir(dir); err == nil {
@@ -16,9 +45,9 @@ func scanEmbedStringsDir(fs *embed.FS, dir string) {
 			path := dir + "/" + name
 			if file.IsDir() {
 				scanEmbedStringsDir(fs, path)
-			} else if strings.ToLower(filepath.Ext(name)) == ".rui" {
+			} else if isDataResourceFile(name) {
 				if data, err := fs.ReadFile(path); err == nil {
-					loadStringResources(string(data))
+					AddStringResources(parseDataResource(name, string(data)))
 				} else {
 					ErrorLog(err.Error())
 				}
@@ -35,9 +64,9 @@ func scanStringsDir(path string) {
 				newPath := path + `/` + filename
 				if file.IsDir() {
 					scanStringsDir(newPath)
-				} else if strings.ToLower(filepath.Ext(newPath)) == ".rui" {
+				} else if isDataResourceFile(newPath) {
 					if data, err := os.ReadFile(newPath); err == nil {
-						loadStringResources(string(data))
+						AddStringResources(parseDataResource(newPath, string(data)))
 					} else {
 						ErrorLog(err.Error())
 					}
@@ -50,24 +79,53 @@ func scanStringsDir(path string) {
 }
 
 func loadStringResources(text string) {
-	data := ParseDataText(text)
+	AddStringResources(ParseDataText(text))
+}
+
+// AddStringResources adds the translations from the data object with the "strings" or "strings:<lang>" tag.
+// The object has the same structure as a file of the "strings" resource folder.
+// It is used to load translations from JSON or YAML (see ParseDataJSON and ParseDataYAML)
+func AddStringResources(data DataObject) {
 	if data == nil {
 		return
 	}
 
+	stringResourcesMutex.Lock()
+	defer stringResourcesMutex.Unlock()
+
 	parseStrings := func(obj DataObject, lang string) {
+		lang = normalizeLanguage(lang)
 		table, ok := stringResources[lang]
 		if !ok {
 			table = map[string]string{}
 		}
+		plurals, ok := pluralResources[lang]
+		if !ok {
+			plurals = map[string]map[string]string{}
+		}
 
 		for i := 0; i < obj.PropertyCount(); i++ {
-			if prop := obj.Property(i); prop != nil && prop.Type() == TextNode {
-				table[prop.Tag()] = prop.Text()
+			if prop := obj.Property(i); prop != nil {
+				switch prop.Type() {
+				case TextNode:
+					table[prop.Tag()] = prop.Text()
+					delete(plurals, prop.Tag())
+
+				case ObjectNode:
+					if forms := parsePluralForms(prop.Object()); len(forms) > 0 {
+						plurals[prop.Tag()] = forms
+						if text, ok := forms[PluralOther]; ok {
+							table[prop.Tag()] = text
+						} else {
+							ErrorLogF(`The "%s" plural string of "%s" language has no "other" form`, prop.Tag(), lang)
+						}
+					}
+				}
 			}
 		}
 
 		stringResources[lang] = table
+		pluralResources[lang] = plurals
 	}
 
 	tag := data.Tag()
@@ -85,44 +143,415 @@ func loadStringResources(text string) {
 	}
 }
 
-// GetString returns the text for the language which is defined by "lang" parameter
-func GetString(tag, lang string) (string, bool) {
-	if table, ok := stringResources[lang]; ok {
-		if text, ok := table[tag]; ok {
-			return text, true
+func parsePluralForms(obj DataObject) map[string]string {
+	forms := map[string]string{}
+	for i := 0; i < obj.PropertyCount(); i++ {
+		if prop := obj.Property(i); prop != nil && prop.Type() == TextNode {
+			switch category := prop.Tag(); category {
+			case PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther:
+				forms[category] = prop.Text()
+
+			default:
+				ErrorLogF(`Invalid plural category "%s". Valid values: zero, one, two, few, many, other`, category)
+			}
 		}
-		DebugLogF(`There is no "%s" string resource`, tag)
 	}
-	DebugLogF(`There are no "%s" language resources`, lang)
-	return tag, false
+	return forms
 }
 
-func (session *sessionData) GetString(tag string) (string, bool) {
-	getString := func(tag, lang string) (string, bool) {
+// normalizeLanguage converts a language tag to lower case and replaces '_' with '-' ("de_AT" -> "de-at")
+func normalizeLanguage(lang string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.Trim(lang, " \t\n\r")), "_", "-")
+}
+
+// languageFallbacks returns the chain of languages in which a string is searched:
+// each language is followed by its parent languages ("de-at" -> "de"), the default language is the last one
+func languageFallbacks(langs ...string) []string {
+	result := []string{}
+	add := func(lang string) {
+		for _, l := range result {
+			if l == lang {
+				return
+			}
+		}
+		result = append(result, lang)
+	}
+
+	for _, lang := range langs {
+		lang = normalizeLanguage(lang)
+		for lang != "" {
+			add(lang)
+			if n := strings.LastIndex(lang, "-"); n > 0 {
+				lang = lang[:n]
+			} else {
+				break
+			}
+		}
+	}
+
+	if defaultLanguage != "" {
+		add(defaultLanguage)
+	}
+	return result
+}
+
+// ParseAcceptLanguage parses the value of the Accept-Language HTTP header and returns
+// the list of languages sorted by preference ("q" weight). Languages with zero weight and "*" are skipped
+func ParseAcceptLanguage(header string) []string {
+	type weightedLanguage struct {
+		lang   string
+		weight float64
+	}
+
+	list := []weightedLanguage{}
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(part, ";")
+		lang = strings.TrimSpace(lang)
+		if lang == "" || lang == "*" {
+			continue
+		}
+
+		weight := 1.0
+		for _, param := range strings.Split(params, ";") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					weight = q
+				}
+			}
+		}
+		if weight > 0 {
+			list = append(list, weightedLanguage{lang: lang, weight: weight})
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].weight > list[j].weight
+	})
+
+	result := make([]string, len(list))
+	for i, item := range list {
+		result[i] = item.lang
+	}
+	return result
+}
+
+// NegotiateLanguages returns the languages from the "available" list that match the "requested" languages,
+// sorted by the order of the requested list. A requested language matches an available language if they are equal,
+// if the available language is its parent ("de-AT" -> "de") or if the available language is its regional
+// variant ("de" -> "de-DE", in the order of the available list). The result contains normalized (lower case) language names
+func NegotiateLanguages(requested, available []string) []string {
+	result := []string{}
+	add := func(lang string) {
+		for _, l := range result {
+			if l == lang {
+				return
+			}
+		}
+		result = append(result, lang)
+	}
+
+	normalized := make([]string, len(available))
+	for i, lang := range available {
+		normalized[i] = normalizeLanguage(lang)
+	}
+
+	has := func(lang string) bool {
+		for _, l := range normalized {
+			if l == lang {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, lang := range requested {
+		lang = normalizeLanguage(lang)
+		for l := lang; l != ""; {
+			if has(l) {
+				add(l)
+				break
+			}
+			if n := strings.LastIndex(l, "-"); n > 0 {
+				l = l[:n]
+			} else {
+				break
+			}
+		}
+
+		for _, l := range normalized {
+			if strings.HasPrefix(l, lang+"-") {
+				add(l)
+			}
+		}
+	}
+	return result
+}
+
+// LoadedLanguages returns the sorted list of languages of loaded string resources
+func LoadedLanguages() []string {
+	stringResourcesMutex.RLock()
+	defer stringResourcesMutex.RUnlock()
+
+	result := make([]string, 0, len(stringResources))
+	for lang := range stringResources {
+		result = append(result, lang)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// SetDefaultLanguage sets the language which is used if there is no translation
+// for the session language and its parent languages
+func SetDefaultLanguage(lang string) {
+	defaultLanguage = normalizeLanguage(lang)
+}
+
+func findString(tag string, langs []string) (string, string, bool) {
+	stringResourcesMutex.RLock()
+	for _, lang := range langs {
 		if table, ok := stringResources[lang]; ok {
 			if text, ok := table[tag]; ok {
-				return text, true
+				stringResourcesMutex.RUnlock()
+				return text, lang, true
 			}
-			DebugLogF(`There is no "%s" string in "%s" resources`, tag, lang)
 		}
-		return tag, false
 	}
+	stringResourcesMutex.RUnlock()
 
+	DebugLogF(`There is no "%s" string in %v resources`, tag, langs)
+	return tag, "", false
+}
+
+// GetString returns the text for the language which is defined by "lang" parameter.
+// If there is no translation for the language then its parent languages ("de-AT" -> "de")
+// and the default language (see SetDefaultLanguage) are used
+func GetString(tag, lang string) (string, bool) {
+	text, _, ok := findString(tag, languageFallbacks(lang))
+	return text, ok
+}
+
+func (session *sessionData) GetString(tag string) (string, bool) {
+	text, _, ok := findString(tag, session.stringLanguages())
+	return text, ok
+}
+
+func (session *sessionData) stringLanguages() []string {
+	langs := make([]string, 0, len(session.languages)+1)
 	if session.language != "" {
-		if text, ok := getString(tag, session.language); ok {
-			return text, true
-		}
+		langs = append(langs, session.language)
 	}
+	langs = append(langs, session.negotiatedLanguages()...)
+	return languageFallbacks(append(langs, session.languages...)...)
+}
+
+// FormatString returns the text for the language which is defined by "lang" parameter
+// with substituted parameters. See Session.FormatString for details
+func FormatString(tag, lang string, params Params) (string, bool) {
+	return formatString(tag, languageFallbacks(lang), params)
+}
+
+func (session *sessionData) FormatString(tag string, params Params) (string, bool) {
+	return formatString(tag, session.stringLanguages(), params)
+}
 
-	if session.languages != nil {
-		for _, lang := range session.languages {
-			if lang != session.language {
-				if text, ok := getString(tag, lang); ok {
-					return text, true
+func formatString(tag string, langs []string, params Params) (string, bool) {
+	text, lang, ok := findString(tag, langs)
+	if ok && params != nil {
+		if count, ok := params[PluralCount]; ok {
+			if n, ok := pluralNumber(count); ok {
+				stringResourcesMutex.RLock()
+				forms, ok := pluralResources[lang][tag]
+				stringResourcesMutex.RUnlock()
+				if ok {
+					if form, ok := forms[PluralCategory(lang, n)]; ok {
+						text = form
+					}
 				}
 			}
 		}
 	}
 
-	return tag, false
+	if len(params) == 0 || !strings.Contains(text, "{") {
+		return text, ok
+	}
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	for {
+		start := strings.IndexRune(text, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexRune(text[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		buffer.WriteString(text[:start])
+		if value, ok := params[text[start+1:end]]; ok {
+			buffer.WriteString(fmt.Sprint(value))
+		} else {
+			buffer.WriteString(text[start : end+1])
+		}
+		text = text[end+1:]
+	}
+	buffer.WriteString(text)
+	return buffer.String(), ok
+}
+
+func pluralNumber(value any) (float64, bool) {
+	switch value := value.(type) {
+	case float32:
+		return float64(value), true
+
+	case float64:
+		return value, true
+
+	case string:
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n, true
+		}
+		return 0, false
+	}
+	if n, ok := isInt(value); ok {
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// SetPluralRule sets the function which returns the plural category ("zero", "one", "two", "few", "many", or "other")
+// of a number for the given language. Use it for languages which are not supported by the library
+func SetPluralRule(lang string, rule func(n float64) string) {
+	lang = normalizeLanguage(lang)
+	if rule == nil {
+		delete(pluralRules, lang)
+	} else {
+		pluralRules[lang] = rule
+	}
+}
+
+// PluralCategory returns the CLDR plural category ("zero", "one", "two", "few", "many", or "other")
+// of the number for the given language
+func PluralCategory(lang string, n float64) string {
+	lang = normalizeLanguage(lang)
+	for l := lang; l != ""; {
+		if rule, ok := pluralRules[l]; ok {
+			return rule(n)
+		}
+		if k := strings.LastIndex(l, "-"); k > 0 {
+			l = l[:k]
+		} else {
+			break
+		}
+	}
+
+	if k := strings.IndexRune(lang, '-'); k > 0 {
+		lang = lang[:k]
+	}
+
+	if n < 0 {
+		n = -n
+	}
+	integer := n == math.Trunc(n)
+	i := int64(n)
+	mod10 := i % 10
+	mod100 := i % 100
+
+	switch lang {
+	case "ja", "zh", "ko", "vi", "th", "id", "ms", "lo", "my":
+		return PluralOther
+
+	case "fr", "hy", "kab":
+		if n < 2 {
+			return PluralOne
+		}
+		return PluralOther
+
+	case "ru", "uk", "be":
+		switch {
+		case !integer:
+			return PluralOther
+		case mod10 == 1 && mod100 != 11:
+			return PluralOne
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return PluralFew
+		}
+		return PluralMany
+
+	case "pl":
+		switch {
+		case !integer:
+			return PluralOther
+		case i == 1:
+			return PluralOne
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return PluralFew
+		}
+		return PluralMany
+
+	case "cs", "sk":
+		switch {
+		case !integer:
+			return PluralMany
+		case i == 1:
+			return PluralOne
+		case i >= 2 && i <= 4:
+			return PluralFew
+		}
+		return PluralOther
+
+	case "ar":
+		switch {
+		case !integer:
+			return PluralOther
+		case i == 0:
+			return PluralZero
+		case i == 1:
+			return PluralOne
+		case i == 2:
+			return PluralTwo
+		case mod100 >= 3 && mod100 <= 10:
+			return PluralFew
+		case mod100 >= 11:
+			return PluralMany
+		}
+		return PluralOther
+	}
+
+	if integer && i == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+// MissingStrings returns the list of string tags which are missing for each language.
+// A tag is considered missing if it is defined for at least one of other languages.
+// The result contains only languages with missing tags
+func MissingStrings() map[string][]string {
+	stringResourcesMutex.RLock()
+	defer stringResourcesMutex.RUnlock()
+
+	allTags := map[string]bool{}
+	for _, table := range stringResources {
+		for tag := range table {
+			allTags[tag] = true
+		}
+	}
+
+	result := map[string][]string{}
+	for lang, table := range stringResources {
+		missing := []string{}
+		for tag := range allTags {
+			if _, ok := table[tag]; !ok {
+				missing = append(missing, tag)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			result[lang] = missing
+		}
+	}
+	return result
 }
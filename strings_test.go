@@ -0,0 +1,185 @@
+package rui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatString(t *testing.T) {
+	createTestLog(t, false)
+	defer func() {
+		delete(stringResources, "ru")
+		delete(stringResources, "en")
+		delete(pluralResources, "ru")
+		delete(pluralResources, "en")
+		defaultLanguage = ""
+	}()
+
+	loadStringResources(`strings {
+		ru = _{
+			"files" = _{
+				one = "{count} файл в {dir}",
+				few = "{count} файла в {dir}",
+				many = "{count} файлов в {dir}",
+				other = "{count} файла в {dir}",
+			},
+			"Hello" = "Привет, {name}!",
+		},
+		en = _{
+			"files" = _{
+				one = "{count} file in {dir}",
+				other = "{count} files in {dir}",
+			},
+			"Yes" = "Yes",
+		},
+	}`)
+
+	tests := []struct {
+		lang  string
+		count any
+		text  string
+	}{
+		{"ru", 1, "1 файл в /tmp"},
+		{"ru-RU", 3, "3 файла в /tmp"},
+		{"RU_ru", 11, "11 файлов в /tmp"},
+		{"ru", 21, "21 файл в /tmp"},
+		{"ru", 1.5, "1.5 файла в /tmp"},
+		{"en", 1, "1 file in /tmp"},
+		{"en-GB", "5", "5 files in /tmp"},
+	}
+
+	for _, test := range tests {
+		text, ok := FormatString("files", test.lang, Params{PluralCount: test.count, "dir": "/tmp"})
+		if !ok || text != test.text {
+			t.Errorf(`FormatString("files", "%s", %v) = "%s", expected "%s"`, test.lang, test.count, text, test.text)
+		}
+	}
+
+	session := newSession(nil, 0, "", nil)
+	session.SetLanguage("ru-BY")
+	if text, _ := session.FormatString("Hello", Params{"name": "Мир", "unused": 1}); text != "Привет, Мир!" {
+		t.Errorf(`Session.FormatString: "%s"`, text)
+	}
+	if text, ok := session.FormatString("Bye, {name}", Params{"name": "World"}); ok || text != "Bye, World" {
+		t.Errorf(`Session.FormatString of the missing string: "%s"`, text)
+	}
+
+	if _, ok := session.GetString("Yes"); ok {
+		t.Error(`"Yes" found without the default language`)
+	}
+	SetDefaultLanguage("en")
+	if text, ok := session.GetString("Yes"); !ok || text != "Yes" {
+		t.Error(`"Yes" not found in the default language`)
+	}
+
+	missing := MissingStrings()
+	if tags := missing["ru"]; len(tags) != 1 || tags[0] != "Yes" {
+		t.Errorf("missing ru strings: %v", tags)
+	}
+	if tags := missing["en"]; len(tags) != 1 || tags[0] != "Hello" {
+		t.Errorf("missing en strings: %v", tags)
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		lang     string
+		n        float64
+		category string
+	}{
+		{"en", 0, PluralOther},
+		{"en", 1, PluralOne},
+		{"fr", 1.5, PluralOne},
+		{"ru", 0, PluralMany},
+		{"ru", 22, PluralFew},
+		{"ru", 112, PluralMany},
+		{"pl", 1, PluralOne},
+		{"pl", 21, PluralMany},
+		{"cs", 3, PluralFew},
+		{"ar", 2, PluralTwo},
+		{"ar", 105, PluralFew},
+		{"ja", 1, PluralOther},
+	}
+
+	for _, test := range tests {
+		if category := PluralCategory(test.lang, test.n); category != test.category {
+			t.Errorf(`PluralCategory("%s", %g) = "%s", expected "%s"`, test.lang, test.n, category, test.category)
+		}
+	}
+
+	SetPluralRule("xx", func(n float64) string {
+		return PluralMany
+	})
+	defer SetPluralRule("xx", nil)
+	if category := PluralCategory("xx-YY", 1); category != PluralMany {
+		t.Errorf(`custom plural rule: "%s"`, category)
+	}
+}
+
+func TestLanguageNegotiation(t *testing.T) {
+	createTestLog(t, false)
+
+	if langs := ParseAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5, it;q=0"); !reflect.DeepEqual(langs, []string{"fr-CH", "fr", "en", "de"}) {
+		t.Errorf("ParseAcceptLanguage: %v", langs)
+	}
+
+	if langs := NegotiateLanguages([]string{"de-AT", "en", "pt"}, []string{"en-US", "de", "en-GB", "ru"}); !reflect.DeepEqual(langs, []string{"de", "en-us", "en-gb"}) {
+		t.Errorf("NegotiateLanguages: %v", langs)
+	}
+
+	loadStringResources(`strings {
+		de = _{ "Yes" = "Ja" },
+		fr = _{ "Yes" = "Oui" },
+	}`)
+	defer func() {
+		delete(stringResources, "de")
+		delete(stringResources, "fr")
+		delete(pluralResources, "de")
+		delete(pluralResources, "fr")
+	}()
+
+	info := NewDataObject("sessionInfo")
+	info.SetPropertyValue("language", "ru-RU")
+	info.SetPropertyValue("languages", "ru-RU,de-DE")
+	info.SetPropertyValue("accept-language", "it;q=0.9, fr-FR;q=0.3, de;q=0.5")
+	session := newSession(nil, 0, "", info)
+
+	if langs := session.NegotiatedLanguages(); !reflect.DeepEqual(langs, []string{"de", "fr"}) {
+		t.Errorf("NegotiatedLanguages: %v", langs)
+	}
+	if lang := session.Language(); lang != "de" {
+		t.Errorf("Language: %s", lang)
+	}
+	if text, _ := session.GetString("Yes"); text != "Ja" {
+		t.Errorf(`GetString("Yes"): %s`, text)
+	}
+
+	session.SetLanguage("fr")
+	if text, _ := session.GetString("Yes"); session.Language() != "fr" || text != "Oui" {
+		t.Errorf(`GetString("Yes") after SetLanguage: %s`, text)
+	}
+	session.SetLanguage("")
+	if lang := session.Language(); lang != "de" {
+		t.Errorf("Language after SetLanguage(\"\"): %s", lang)
+	}
+
+	// the negotiated languages are cached until the string resources are reloaded
+	session.NegotiatedLanguages()[0] = "en"
+	loadStringResources(`strings {
+		it = _{ "Yes" = "Sì" },
+	}`)
+	defer func() {
+		delete(stringResources, "it")
+		delete(pluralResources, "it")
+	}()
+	if text, _ := session.GetString("Yes"); text != "Ja" {
+		t.Errorf(`GetString("Yes") before the reload: %s`, text)
+	}
+	session.(*sessionData).reloadResources(false)
+	if langs := session.NegotiatedLanguages(); !reflect.DeepEqual(langs, []string{"it", "de", "fr"}) {
+		t.Errorf("NegotiatedLanguages after the reload: %v", langs)
+	}
+	if text, _ := session.GetString("Yes"); text != "Sì" {
+		t.Errorf(`GetString("Yes") after the reload: %s`, text)
+	}
+}
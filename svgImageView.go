@@ -35,6 +35,12 @@ func (imageView *svgImageViewData) init(session Session) {
 	imageView.systemClass = "ruiSvgImageView"
 }
 
+func (imageView *svgImageViewData) supportedProperties() []string {
+	return append([]string{
+		Content, CellVerticalAlign, CellHorizontalAlign,
+	}, imageView.viewData.supportedProperties()...)
+}
+
 func (imageView *svgImageViewData) String() string {
 	return getViewString(imageView)
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -269,6 +270,15 @@ func (table *tableViewData) init(session Session) {
 	table.current.Column = -1
 }
 
+func (table *tableViewData) supportedProperties() []string {
+	return append([]string{
+		Content, Current, CellStyle, RowStyle, ColumnStyle, HeadHeight, HeadStyle, FootHeight,
+		FootStyle, CellPadding, CellPaddingTop, CellPaddingRight, CellPaddingBottom, CellPaddingLeft,
+		Gap, AllowSelection, SelectionMode, TableVerticalAlign, TableCellClickedEvent,
+		TableCellSelectedEvent, TableRowClickedEvent, TableRowSelectedEvent,
+	}, table.viewData.supportedProperties()...)
+}
+
 func (table *tableViewData) String() string {
 	return getViewString(table)
 }
@@ -363,6 +373,14 @@ func (table *tableViewData) set(tag string, value any) bool {
 		case [][]string:
 			table.properties.Store(Content, NewTextTableAdapter(val))
 
+		case []DataValue:
+			content, ok := parseTableRUIContent(table.session, val)
+			if !ok {
+				notCompatibleType(tag, value)
+				return false
+			}
+			table.properties.Store(Content, NewSimpleTableAdapter(content))
+
 		default:
 			notCompatibleType(tag, value)
 			return false
@@ -1341,6 +1359,9 @@ func (table *tableViewData) writeCellHtml(adapter TableAdapter, row, column int,
 			}
 		}
 
+	case time.Time:
+		buffer.WriteString(table.Session().Locale().formatTime(value))
+
 	case fmt.Stringer:
 		buffer.WriteString(value.String())
 
@@ -1348,10 +1369,10 @@ func (table *tableViewData) writeCellHtml(adapter TableAdapter, row, column int,
 		buffer.WriteString(string(value))
 
 	case float32:
-		buffer.WriteString(fmt.Sprintf("%g", float64(value)))
+		buffer.WriteString(table.Session().FormatNumber(float64(value), -1))
 
 	case float64:
-		buffer.WriteString(fmt.Sprintf("%g", value))
+		buffer.WriteString(table.Session().FormatNumber(value, -1))
 
 	case bool:
 		if value {
@@ -98,6 +98,13 @@ func (tabsLayout *tabsLayoutData) init(session Session) {
 	tabsLayout.tabCloseListener = []func(TabsLayout, int){}
 }
 
+func (tabsLayout *tabsLayoutData) supportedProperties() []string {
+	return append([]string{
+		Current, Tabs, TabStyle, CurrentTabStyle, TabBarStyle, TabCloseButton, CurrentTabChangedEvent,
+		TabCloseEvent,
+	}, tabsLayout.viewsContainerData.supportedProperties()...)
+}
+
 func (tabsLayout *tabsLayoutData) String() string {
 	return getViewString(tabsLayout)
 }
@@ -32,6 +32,12 @@ func (textView *textViewData) init(session Session) {
 	textView.tag = "TextView"
 }
 
+func (textView *textViewData) supportedProperties() []string {
+	return append([]string{
+		Text, TextOverflow, NotTranslate, TextFormat,
+	}, textView.viewData.supportedProperties()...)
+}
+
 func (textView *textViewData) String() string {
 	return getViewString(textView)
 }
@@ -48,7 +54,7 @@ func (textView *textViewData) remove(tag string) {
 	textView.viewData.remove(tag)
 	if textView.created {
 		switch tag {
-		case Text:
+		case Text, TextFormat:
 			updateInnerHTML(textView.htmlID(), textView.session)
 
 		case TextOverflow:
@@ -107,7 +113,7 @@ func (textView *textViewData) set(tag string, value any) bool {
 			textView.textOverflowUpdated()
 		}
 
-	case NotTranslate:
+	case NotTranslate, TextFormat:
 		if !textView.viewData.set(tag, value) {
 			return false
 		}
@@ -141,7 +147,18 @@ func (textView *textViewData) htmlSubviews(self View, buffer *strings.Builder) {
 			if !GetNotTranslate(textView) {
 				text, _ = textView.session.GetString(text)
 			}
-			buffer.WriteString(text)
+			switch GetTextFormat(textView) {
+			case PlainTextFormat:
+				buffer.WriteString(htmlEscape(text))
+
+			case MarkdownTextFormat:
+				buffer.WriteString(`<div class="ruiMarkdown">`)
+				buffer.WriteString(markdownToHTML(text))
+				buffer.WriteString(`</div>`)
+
+			default:
+				buffer.WriteString(text)
+			}
 		}
 	}
 }
@@ -152,3 +169,10 @@ func (textView *textViewData) htmlSubviews(self View, buffer *strings.Builder) {
 func GetTextOverflow(view View, subviewID ...string) int {
 	return enumStyledProperty(view, subviewID, TextOverflow, SingleLineText, false)
 }
+
+// GetTextFormat returns a value of the "text-format" property:
+// HTMLTextFormat (0), PlainTextFormat (1), or MarkdownTextFormat (2).
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTextFormat(view View, subviewID ...string) int {
+	return enumStyledProperty(view, subviewID, TextFormat, HTMLTextFormat, false)
+}
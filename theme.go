@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 const (
@@ -28,10 +29,12 @@ type mediaStyle struct {
 
 type theme struct {
 	name           string
+	parents        []string
 	constants      map[string]string
 	touchConstants map[string]string
 	colors         map[string]string
 	darkColors     map[string]string
+	contrastColors map[string]string
 	images         map[string]string
 	darkImages     map[string]string
 	styles         map[string]ViewStyle
@@ -41,12 +44,23 @@ type theme struct {
 type Theme interface {
 	fmt.Stringer
 	Name() string
+	// Parents returns the names of the themes extended by this theme (the "extends" property)
+	Parents() []string
+	// SetParents sets the names of the themes extended by this theme. The constants, colors, images
+	// and styles of the theme override the values of its parents. The parents are applied in the given order
+	SetParents(names ...string)
 	Constant(tag string) (string, string)
 	SetConstant(tag string, value, touchUIValue string)
 	// ConstantTags returns the list of all available constants
 	ConstantTags() []string
 	Color(tag string) (string, string)
 	SetColor(tag, color, darkUIColor string)
+	// ContrastColor returns the value of the color constant used in the high-contrast mode
+	// ("colors:contrast" section of the theme) or "" if it is not set
+	ContrastColor(tag string) string
+	// SetContrastColor sets the value of the color constant used in the high-contrast mode.
+	// The empty value removes the constant from the "colors:contrast" section
+	SetContrastColor(tag, color string)
 	// ColorTags returns the list of all available color constants
 	ColorTags() []string
 	Image(tag string) (string, string)
@@ -66,7 +80,7 @@ type Theme interface {
 	Append(anotherTheme Theme)
 
 	constant(tag string, touchUI bool) string
-	color(tag string, darkUI bool) string
+	color(tag string, darkUI, highContrast bool) string
 	image(tag string, darkUI bool) string
 	style(tag string) ViewStyle
 	cssText(session Session) string
@@ -196,6 +210,51 @@ func parseMediaRule(text string) (mediaStyle, bool) {
 
 var defaultTheme = NewTheme("")
 
+// themesMutex guards the data of the themes and the list of the named themes. The themes can be changed
+// by the theme editor and the resource watcher while the sessions are running, so each session renders
+// its own copy of the theme (see sessionData.getCurrentTheme)
+var themesMutex sync.RWMutex
+
+// themeCSSVariables is true if constants and colors of themes are written as CSS custom properties
+var themeCSSVariables = false
+
+// SetThemeCSSVariables turns on/off the output of theme constants and colors as CSS custom properties.
+// In this mode the ":root" rule of the theme CSS defines the "--const-<name>" variables for size constants
+// and the "--color-<name>" variables for color constants, and the style properties set by a constant
+// (for example, "padding = @defaultPadding" or "text-color = @textColor") refer to these variables.
+// So changing a constant or a color at runtime updates only the ":root" rule.
+// The mode must be set before the start of the application
+func SetThemeCSSVariables(enabled bool) {
+	themeCSSVariables = enabled
+}
+
+// isCSSIdent returns true if the text can be used as a part of the CSS custom property name
+func isCSSIdent(text string) bool {
+	if text == "" {
+		return false
+	}
+	for _, ch := range text {
+		if !(ch == '-' || ch == '_' || (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')) {
+			return false
+		}
+	}
+	return true
+}
+
+// cssVariableReference returns the reference to the CSS custom property if the property is set by a constant
+// and the CSS custom properties mode is on (see SetThemeCSSVariables)
+func cssVariableReference(properties Properties, tag, prefix string) (string, bool) {
+	if themeCSSVariables {
+		if text, ok := properties.getRaw(tag).(string); ok {
+			text = strings.Trim(text, " \t")
+			if len(text) > 1 && text[0] == '@' && isCSSIdent(text[1:]) {
+				return "var(--" + prefix + "-" + text[1:] + ")", true
+			}
+		}
+	}
+	return "", false
+}
+
 func NewTheme(name string) Theme {
 	result := new(theme)
 	result.init()
@@ -210,11 +269,21 @@ func CreateThemeFromText(text string) (Theme, bool) {
 	return result, ok
 }
 
+// CreateThemeFromObject creates a new theme from the data object with the "theme" tag.
+// It is used to create a theme from JSON or YAML (see ParseDataJSON and ParseDataYAML)
+func CreateThemeFromObject(data DataObject) (Theme, bool) {
+	result := new(theme)
+	result.init()
+	ok := result.addData(data)
+	return result, ok
+}
+
 func (theme *theme) init() {
 	theme.constants = map[string]string{}
 	theme.touchConstants = map[string]string{}
 	theme.colors = map[string]string{}
 	theme.darkColors = map[string]string{}
+	theme.contrastColors = map[string]string{}
 	theme.images = map[string]string{}
 	theme.darkImages = map[string]string{}
 	theme.styles = map[string]ViewStyle{}
@@ -225,11 +294,44 @@ func (theme *theme) Name() string {
 	return theme.name
 }
 
+func (theme *theme) Parents() []string {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+	return append([]string{}, theme.parents...)
+}
+
+func (theme *theme) SetParents(names ...string) {
+	themesMutex.Lock()
+	theme.parents = []string{}
+	for _, name := range names {
+		theme.addParent(name)
+	}
+	themesMutex.Unlock()
+	notifyThemeChanged(theme)
+}
+
+func (theme *theme) addParent(name string) {
+	name = strings.Trim(name, " \t")
+	if name == "" || name == theme.name {
+		return
+	}
+	for _, parent := range theme.parents {
+		if parent == name {
+			return
+		}
+	}
+	theme.parents = append(theme.parents, name)
+}
+
 func (theme *theme) Constant(tag string) (string, string) {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
 	return theme.constants[tag], theme.touchConstants[tag]
 }
 
 func (theme *theme) SetConstant(tag, value, touchUIValue string) {
+	themesMutex.Lock()
 	value = strings.Trim(value, " \t")
 	if value == "" {
 		delete(theme.constants, tag)
@@ -243,13 +345,19 @@ func (theme *theme) SetConstant(tag, value, touchUIValue string) {
 			theme.touchConstants[tag] = touchUIValue
 		}
 	}
+	themesMutex.Unlock()
+	notifyThemeChanged(theme)
 }
 
 func (theme *theme) Color(tag string) (string, string) {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
 	return theme.colors[tag], theme.darkColors[tag]
 }
 
 func (theme *theme) SetColor(tag, color, darkUIColor string) {
+	themesMutex.Lock()
 	color = strings.Trim(color, " \t")
 	if color == "" {
 		delete(theme.colors, tag)
@@ -263,13 +371,38 @@ func (theme *theme) SetColor(tag, color, darkUIColor string) {
 			theme.darkColors[tag] = darkUIColor
 		}
 	}
+	themesMutex.Unlock()
+	notifyThemeChanged(theme)
+}
+
+func (theme *theme) ContrastColor(tag string) string {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
+	return theme.contrastColors[tag]
+}
+
+func (theme *theme) SetContrastColor(tag, color string) {
+	themesMutex.Lock()
+	color = strings.Trim(color, " \t")
+	if color == "" {
+		delete(theme.contrastColors, tag)
+	} else {
+		theme.contrastColors[tag] = color
+	}
+	themesMutex.Unlock()
+	notifyThemeChanged(theme)
 }
 
 func (theme *theme) Image(tag string) (string, string) {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
 	return theme.images[tag], theme.darkImages[tag]
 }
 
 func (theme *theme) SetImage(tag, image, darkUIImage string) {
+	themesMutex.Lock()
 	image = strings.Trim(image, " \t")
 	if image == "" {
 		delete(theme.images, tag)
@@ -283,9 +416,14 @@ func (theme *theme) SetImage(tag, image, darkUIImage string) {
 			theme.darkImages[tag] = darkUIImage
 		}
 	}
+	themesMutex.Unlock()
+	notifyThemeChanged(theme)
 }
 
 func (theme *theme) Style(tag string) ViewStyle {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
 	if style, ok := theme.styles[tag]; ok {
 		return style
 	}
@@ -293,14 +431,18 @@ func (theme *theme) Style(tag string) ViewStyle {
 }
 
 func (theme *theme) SetStyle(tag string, style ViewStyle) {
+	themesMutex.Lock()
 	if style != nil {
 		theme.styles[tag] = style
 	} else {
 		delete(theme.styles, tag)
 	}
+	themesMutex.Unlock()
+	notifyThemeChanged(theme)
 }
 
 func (theme *theme) RemoveStyle(tag string) {
+	themesMutex.Lock()
 	tag2 := tag + ":"
 	remove := func(styles map[string]ViewStyle) {
 		tags := []string{tag}
@@ -317,9 +459,14 @@ func (theme *theme) RemoveStyle(tag string) {
 	for _, mediaStyle := range theme.mediaStyles {
 		remove(mediaStyle.styles)
 	}
+	themesMutex.Unlock()
+	notifyThemeChanged(theme)
 }
 
 func (theme *theme) MediaStyle(tag string, params MediaStyleParams) ViewStyle {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
 	for _, styles := range theme.mediaStyles {
 		if styles.Orientation == params.Orientation &&
 			styles.MaxWidth == params.MaxWidth &&
@@ -360,6 +507,7 @@ func (theme *theme) SetMediaStyle(tag string, params MediaStyleParams, style Vie
 		return
 	}
 
+	themesMutex.Lock()
 	for i, styles := range theme.mediaStyles {
 		if styles.Orientation == params.Orientation &&
 			styles.MaxWidth == params.MaxWidth &&
@@ -382,9 +530,14 @@ func (theme *theme) SetMediaStyle(tag string, params MediaStyleParams, style Vie
 		})
 		theme.sortMediaStyles()
 	}
+	themesMutex.Unlock()
+	notifyThemeChanged(theme)
 }
 
 func (theme *theme) ConstantTags() []string {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
 	keys := make([]string, 0, len(theme.constants))
 	for k := range theme.constants {
 		keys = append(keys, k)
@@ -401,6 +554,9 @@ func (theme *theme) ConstantTags() []string {
 }
 
 func (theme *theme) ColorTags() []string {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
 	keys := make([]string, 0, len(theme.colors))
 	for k := range theme.colors {
 		keys = append(keys, k)
@@ -412,11 +568,21 @@ func (theme *theme) ColorTags() []string {
 		}
 	}
 
+	for tag := range theme.contrastColors {
+		_, ok := theme.colors[tag]
+		if _, dark := theme.darkColors[tag]; !ok && !dark {
+			keys = append(keys, tag)
+		}
+	}
+
 	sort.Strings(keys)
 	return keys
 }
 
 func (theme *theme) ImageConstantTags() []string {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
 	keys := make([]string, 0, len(theme.colors))
 	for k := range theme.images {
 		keys = append(keys, k)
@@ -433,6 +599,9 @@ func (theme *theme) ImageConstantTags() []string {
 }
 
 func (theme *theme) StyleTags() []string {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
 	keys := make([]string, 0, len(theme.styles)*2)
 
 	appendTag := func(k string) {
@@ -479,6 +648,9 @@ func (theme *theme) MediaStyles(tag string) []struct {
 	Selectors string
 	Params    MediaStyleParams
 } {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
 	result := []struct {
 		Selectors string
 		Params    MediaStyleParams
@@ -528,11 +700,22 @@ func (theme *theme) data() *theme {
 }
 
 func (theme *theme) Append(anotherTheme Theme) {
+	themesMutex.Lock()
+	defer themesMutex.Unlock()
+
+	theme.append(anotherTheme.data())
+}
+
+// append copies the data of another theme to the theme. The caller must hold themesMutex
+func (theme *theme) append(another *theme) {
 	if theme.constants == nil {
 		theme.init()
 	}
 
-	another := anotherTheme.data()
+	for _, parent := range another.parents {
+		theme.addParent(parent)
+	}
+
 	for tag, constant := range another.constants {
 		theme.constants[tag] = constant
 	}
@@ -549,6 +732,10 @@ func (theme *theme) Append(anotherTheme Theme) {
 		theme.darkColors[tag] = color
 	}
 
+	for tag, color := range another.contrastColors {
+		theme.contrastColors[tag] = color
+	}
+
 	for tag, image := range another.images {
 		theme.images[tag] = image
 	}
@@ -577,20 +764,44 @@ func (theme *theme) Append(anotherTheme Theme) {
 			}
 		}
 		if !exists {
-			theme.mediaStyles = append(theme.mediaStyles, anotherMedia)
+			styles := make(map[string]ViewStyle, len(anotherMedia.styles))
+			for tag, style := range anotherMedia.styles {
+				styles[tag] = style
+			}
+			theme.mediaStyles = append(theme.mediaStyles, mediaStyle{
+				MediaStyleParams: anotherMedia.MediaStyleParams,
+				styles:           styles,
+			})
 		}
 	}
 }
 
+// themeCSSRule is the CSS rule of the theme style. The selector is empty for the disabled styles
+type themeCSSRule struct {
+	selector string
+	text     string
+}
+
 func (theme *theme) cssText(session Session) string {
+	rules, media := theme.cssRules(session)
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	for _, rule := range rules {
+		buffer.WriteString(rule.text)
+	}
+	buffer.WriteString(media)
+	return buffer.String()
+}
+
+// cssRules returns the CSS rules of the theme styles and the text of all media rules
+func (theme *theme) cssRules(session Session) ([]themeCSSRule, string) {
 	if theme.styles == nil {
 		theme.init()
-		return ""
+		return []themeCSSRule{}, ""
 	}
 
-	var builder cssStyleBuilder
-	builder.init()
-
 	styleList := func(styles map[string]ViewStyle) []string {
 		ruiStyles := []string{}
 		customStyles := []string{}
@@ -606,13 +817,27 @@ func (theme *theme) cssText(session Session) string {
 		return append(ruiStyles, customStyles...)
 	}
 
-	for _, tag := range styleList(theme.styles) {
+	var builder cssStyleBuilder
+	builder.init()
+
+	tags := styleList(theme.styles)
+	bounds := make([]int, 0, len(tags)+2)
+	selectors := make([]string, 0, len(tags)+1)
+	if themeCSSVariables {
+		bounds = append(bounds, builder.buffer.Len())
+		selectors = append(selectors, ":root")
+		theme.cssVariables(&builder, session)
+	}
+	for _, tag := range tags {
 		if style := theme.styles[tag]; style != nil {
+			bounds = append(bounds, builder.buffer.Len())
+			selectors = append(selectors, cssStyleSelector(tag))
 			builder.startStyle(tag)
 			style.cssViewStyle(&builder, session)
 			builder.endStyle()
 		}
 	}
+	bounds = append(bounds, builder.buffer.Len())
 
 	for _, media := range theme.mediaStyles {
 		builder.startMedia(media.cssText())
@@ -626,43 +851,109 @@ func (theme *theme) cssText(session Session) string {
 		builder.endMedia()
 	}
 
-	return builder.finish()
+	text := builder.finish()
+	rules := make([]themeCSSRule, len(selectors))
+	for i, selector := range selectors {
+		rules[i] = themeCSSRule{selector: selector, text: text[bounds[i]:bounds[i+1]]}
+	}
+	return rules, text[bounds[len(selectors)]:]
+}
+
+// cssVariables writes the ":root" rule with the CSS custom properties of size constants and colors
+func (theme *theme) cssVariables(builder *cssStyleBuilder, session Session) {
+	resolve := func(tag string, value func(tag string) string) (string, bool) {
+		tags := []string{tag}
+		for {
+			result := value(tag)
+			if result == "" {
+				return "", false
+			}
+			if result[0] != '@' {
+				return result, true
+			}
+
+			tag = result[1:]
+			for _, t := range tags {
+				if t == tag {
+					return "", false
+				}
+			}
+			tags = append(tags, tag)
+		}
+	}
+
+	builder.buffer.WriteString(`:root {\n`)
+
+	touchScreen := session.TouchScreen()
+	for _, tag := range theme.ConstantTags() {
+		if isCSSIdent(tag) {
+			value, ok := resolve(tag, func(tag string) string {
+				return theme.constant(tag, touchScreen)
+			})
+			if ok {
+				if size, err := stringToSizeUnit(value); err == nil && size.Type != Auto {
+					builder.add("--const-"+tag, size.cssString("", session))
+				}
+			}
+		}
+	}
+
+	darkTheme := session.DarkTheme()
+	highContrast := session.HighContrast()
+	for _, tag := range theme.ColorTags() {
+		if isCSSIdent(tag) {
+			value, ok := resolve(tag, func(tag string) string {
+				return theme.color(tag, darkTheme, highContrast)
+			})
+			if ok {
+				if color, err := stringToColor(value); err == nil {
+					builder.add("--color-"+tag, color.cssString())
+				}
+			}
+		}
+	}
+
+	builder.endStyle()
 }
 
 func (theme *theme) addText(themeText string) bool {
+	return theme.addData(ParseDataText(themeText))
+}
+
+func (theme *theme) addData(data DataObject) bool {
 	if theme.constants == nil {
 		theme.init()
 	}
 
-	data := ParseDataText(themeText)
 	if data == nil || !data.IsObject() || data.Tag() != "theme" {
 		return false
 	}
 
 	count := data.PropertyCount()
 
-	objToStyle := func(obj DataObject) ViewStyle {
-		params := Params{}
-		for i := 0; i < obj.PropertyCount(); i++ {
-			if node := obj.Property(i); node != nil {
-				switch node.Type() {
-				case ArrayNode:
-					params[node.Tag()] = node.ArrayElements()
+	for i := 0; i < count; i++ {
+		if d := data.Property(i); d != nil {
+			switch tag := d.Tag(); tag {
+			case "name":
+				if d.Type() == TextNode {
+					theme.name = d.Text()
+				}
 
-				case ObjectNode:
-					params[node.Tag()] = node.Object()
+			case "extends":
+				switch d.Type() {
+				case TextNode:
+					for _, name := range strings.Split(d.Text(), ",") {
+						theme.addParent(name)
+					}
 
-				default:
-					params[node.Tag()] = node.Text()
+				case ArrayNode:
+					for _, element := range d.ArrayElements() {
+						if !element.IsObject() {
+							theme.addParent(element.Value())
+						}
+					}
 				}
-			}
-		}
-		return NewViewStyle(params)
-	}
 
-	for i := 0; i < count; i++ {
-		if d := data.Property(i); d != nil {
-			switch tag := d.Tag(); tag {
 			case "constants":
 				if d.Type() == ObjectNode {
 					if obj := d.Object(); obj != nil {
@@ -711,6 +1002,18 @@ func (theme *theme) addText(themeText string) bool {
 					}
 				}
 
+			case "colors:contrast":
+				if d.Type() == ObjectNode {
+					if obj := d.Object(); obj != nil {
+						objCount := obj.PropertyCount()
+						for k := 0; k < objCount; k++ {
+							if prop := obj.Property(k); prop != nil && prop.Type() == TextNode {
+								theme.contrastColors[prop.Tag()] = prop.Text()
+							}
+						}
+					}
+				}
+
 			case "images":
 				if d.Type() == ObjectNode {
 					if obj := d.Object(); obj != nil {
@@ -741,7 +1044,7 @@ func (theme *theme) addText(themeText string) bool {
 					for k := 0; k < arraySize; k++ {
 						if element := d.ArrayElement(k); element != nil && element.IsObject() {
 							if obj := element.Object(); obj != nil {
-								theme.styles[obj.Tag()] = objToStyle(obj)
+								theme.styles[obj.Tag()] = themeStyleFromObject(obj)
 							}
 						}
 					}
@@ -754,7 +1057,7 @@ func (theme *theme) addText(themeText string) bool {
 						for k := 0; k < arraySize; k++ {
 							if element := d.ArrayElement(k); element != nil && element.IsObject() {
 								if obj := element.Object(); obj != nil {
-									rule.styles[obj.Tag()] = objToStyle(obj)
+									rule.styles[obj.Tag()] = themeStyleFromObject(obj)
 								}
 							}
 						}
@@ -769,6 +1072,26 @@ func (theme *theme) addText(themeText string) bool {
 	return true
 }
 
+// themeStyleFromObject creates ViewStyle from the properties of the object of the theme "styles" section
+func themeStyleFromObject(obj DataObject) ViewStyle {
+	params := Params{}
+	for i := 0; i < obj.PropertyCount(); i++ {
+		if node := obj.Property(i); node != nil {
+			switch node.Type() {
+			case ArrayNode:
+				params[node.Tag()] = node.ArrayElements()
+
+			case ObjectNode:
+				params[node.Tag()] = node.Object()
+
+			default:
+				params[node.Tag()] = node.Text()
+			}
+		}
+	}
+	return NewViewStyle(params)
+}
+
 func (theme *theme) sortMediaStyles() {
 	if len(theme.mediaStyles) > 1 {
 		sort.SliceStable(theme.mediaStyles, func(i, j int) bool {
@@ -804,9 +1127,14 @@ func (theme *theme) constant(tag string, touchUI bool) string {
 	return result
 }
 
-func (theme *theme) color(tag string, darkUI bool) string {
+func (theme *theme) color(tag string, darkUI, highContrast bool) string {
 	result := ""
-	if darkUI {
+	if highContrast {
+		if value, ok := theme.contrastColors[tag]; ok {
+			result = value
+		}
+	}
+	if result == "" && darkUI {
 		if value, ok := theme.darkColors[tag]; ok {
 			result = value
 		}
@@ -843,6 +1171,9 @@ func (theme *theme) style(tag string) ViewStyle {
 }
 
 func (theme *theme) String() string {
+	themesMutex.RLock()
+	defer themesMutex.RUnlock()
+
 	buffer := allocStringBuilder()
 	defer freeStringBuilder(buffer)
 
@@ -895,8 +1226,19 @@ func (theme *theme) String() string {
 	}
 
 	buffer.WriteString("theme {\n")
+	if theme.name != "" {
+		buffer.WriteString("\tname = ")
+		writeString(theme.name)
+		buffer.WriteString(",\n")
+	}
+	if len(theme.parents) > 0 {
+		buffer.WriteString("\textends = ")
+		writeString(strings.Join(theme.parents, ", "))
+		buffer.WriteString(",\n")
+	}
 	writeConstants("colors", theme.colors)
 	writeConstants("colors:dark", theme.darkColors)
+	writeConstants("colors:contrast", theme.contrastColors)
 	writeConstants("images", theme.images)
 	writeConstants("images:dark", theme.darkImages)
 	writeConstants("constants", theme.constants)
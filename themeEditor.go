@@ -0,0 +1,170 @@
+package rui
+
+import "strings"
+
+// NewThemeEditor creates the view for the live editing of the theme. If the theme is nil then the default theme is edited.
+// The result is TabsLayout with the "Constants", "Colors" and "Styles" pages which list
+// ConstantTags, ColorTags and StyleTags of the theme. Each change is applied to the theme immediately
+// by SetConstant, SetColor and SetStyle, so the CSS of all sessions using the theme is updated.
+// Incomplete values (an empty constant, an invalid color, a style with syntax errors) are not applied.
+// The params are applied to the TabsLayout.
+func NewThemeEditor(session Session, theme Theme, params Params) TabsLayout {
+	if theme == nil {
+		theme = defaultTheme
+	}
+
+	editor := NewTabsLayout(session, params)
+	editor.Append(themeEditorConstantsPage(session, theme))
+	editor.Append(themeEditorColorsPage(session, theme))
+	editor.Append(themeEditorStylesPage(session, theme))
+	return editor
+}
+
+func themeEditorPage(session Session, title string, headers ...string) GridLayout {
+	cellWidth := []SizeUnit{AutoSize()}
+	for i := 1; i < len(headers); i++ {
+		cellWidth = append(cellWidth, Fr(1))
+	}
+
+	page := NewGridLayout(session, Params{
+		Title:     title,
+		CellWidth: cellWidth,
+		Gap:       Px(4),
+		Padding:   Px(8),
+	})
+
+	for i, header := range headers {
+		page.Append(NewTextView(session, Params{
+			Row:        0,
+			Column:     i,
+			Text:       header,
+			TextWeight: 7,
+		}))
+	}
+	return page
+}
+
+func themeEditorLabel(session Session, row int, tag string) View {
+	return NewTextView(session, Params{
+		Row:    row,
+		Column: 0,
+		Text:   tag,
+	})
+}
+
+func themeEditorConstantsPage(session Session, theme Theme) View {
+	page := themeEditorPage(session, "Constants", "Constant", "Value", "Touch value")
+
+	for i, tag := range theme.ConstantTags() {
+		row := i + 1
+		value, touchValue := theme.Constant(tag)
+		valueEdit := NewEditView(session, Params{Row: row, Column: 1, Text: value})
+		touchEdit := NewEditView(session, Params{Row: row, Column: 2, Text: touchValue})
+
+		apply := func(EditView, string, string) {
+			if value := GetText(valueEdit); strings.Trim(value, " \t") != "" {
+				theme.SetConstant(tag, value, GetText(touchEdit))
+			}
+		}
+		valueEdit.Set(EditTextChangedEvent, apply)
+		touchEdit.Set(EditTextChangedEvent, apply)
+
+		page.Append(themeEditorLabel(session, row, tag))
+		page.Append(valueEdit)
+		page.Append(touchEdit)
+	}
+	return page
+}
+
+func themeEditorColorsPage(session Session, theme Theme) View {
+	page := themeEditorPage(session, "Colors", "Color", "Value", "Dark value", "Contrast value")
+
+	isColor := func(text string) bool {
+		text = strings.Trim(text, " \t")
+		if text == "" {
+			return false
+		}
+		if text[0] == '@' {
+			return len(text) > 1
+		}
+		_, err := stringToColor(text)
+		return err == nil
+	}
+
+	for i, tag := range theme.ColorTags() {
+		row := i + 1
+		color, darkColor := theme.Color(tag)
+		colorEdit := NewEditView(session, Params{Row: row, Column: 1, Text: color})
+		darkEdit := NewEditView(session, Params{Row: row, Column: 2, Text: darkColor})
+		contrastEdit := NewEditView(session, Params{Row: row, Column: 3, Text: theme.ContrastColor(tag)})
+
+		apply := func(EditView, string, string) {
+			color := GetText(colorEdit)
+			darkColor := GetText(darkEdit)
+			if isColor(color) && (strings.Trim(darkColor, " \t") == "" || isColor(darkColor)) {
+				theme.SetColor(tag, color, darkColor)
+			}
+		}
+		colorEdit.Set(EditTextChangedEvent, apply)
+		darkEdit.Set(EditTextChangedEvent, apply)
+		contrastEdit.Set(EditTextChangedEvent, func(_ EditView, color, _ string) {
+			if strings.Trim(color, " \t") == "" || isColor(color) {
+				theme.SetContrastColor(tag, color)
+			}
+		})
+
+		page.Append(themeEditorLabel(session, row, tag))
+		page.Append(colorEdit)
+		page.Append(darkEdit)
+		page.Append(contrastEdit)
+	}
+	return page
+}
+
+func themeEditorStylesPage(session Session, theme Theme) View {
+	page := themeEditorPage(session, "Styles", "Style", "Properties")
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	row := 0
+	for _, tag := range theme.StyleTags() {
+		style := theme.Style(tag)
+		if style == nil {
+			continue
+		}
+
+		row++
+		buffer.Reset()
+		writeViewStyle(tag, style, buffer, "")
+
+		edit := NewEditView(session, Params{
+			Row:          row,
+			Column:       1,
+			Text:         buffer.String(),
+			EditViewType: MultiLineText,
+			FontName:     "monospace",
+		})
+		edit.Set(EditTextChangedEvent, func(_ EditView, text, _ string) {
+			if style, ok := parseThemeEditorStyle(text); ok {
+				theme.SetStyle(tag, style)
+			}
+		})
+
+		page.Append(themeEditorLabel(session, row, tag))
+		page.Append(edit)
+	}
+	return page
+}
+
+// parseThemeEditorStyle parses the style text written by the theme editor ("tag { property = value, ... }")
+func parseThemeEditorStyle(text string) (ViewStyle, bool) {
+	failed := false
+	obj := parseDataText(text, func(int, int, string) {
+		failed = true
+	})
+	if failed || obj == nil {
+		return nil, false
+	}
+	return themeStyleFromObject(obj), true
+}
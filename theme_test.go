@@ -0,0 +1,302 @@
+package rui
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type themeTestBridge struct {
+	webBridge
+	messages []string
+}
+
+func (bridge *themeTestBridge) writeMessage(text string) bool {
+	bridge.messages = append(bridge.messages, text)
+	return true
+}
+
+func (bridge *themeTestBridge) callFunc(funcName string, args ...any) bool {
+	return true
+}
+
+func (bridge *themeTestBridge) lastMessage() string {
+	if count := len(bridge.messages); count > 0 {
+		return bridge.messages[count-1]
+	}
+	return ""
+}
+
+func TestThemeLiveUpdate(t *testing.T) {
+	createTestLog(t, false)
+
+	theme := NewTheme("liveUpdateTest")
+	theme.SetColor("liveColor", "#FF112233", "")
+	theme.SetStyle("liveStyle", NewViewStyle(Params{BackgroundColor: "@liveColor"}))
+	theme.SetStyle("liveOther", NewViewStyle(Params{TextColor: "#FF000000"}))
+	AddTheme(theme)
+
+	session := newSession(nil, 0, "", nil)
+	events := make(chan DataObject, 8)
+	bridge := new(themeTestBridge)
+	session.setBridge(events, bridge)
+
+	if !session.SetCustomTheme("liveUpdateTest") || !session.usesTheme(theme) {
+		t.Fatal("the custom theme is not set")
+	}
+	if text := bridge.lastMessage(); !strings.Contains(text, ".liveStyle {") {
+		t.Errorf("the theme CSS is not sent:\n%s", text)
+	}
+
+	update := func() string {
+		session.themeChanged()
+		data := <-events
+		session.handleEvent(data.Tag(), data)
+		return bridge.lastMessage()
+	}
+
+	theme.SetColor("liveColor", "#FF445566", "")
+	text := update()
+	if !strings.HasPrefix(text, `replaceCSSRule(".liveStyle", ".liveStyle {`) || !strings.Contains(text, "rgb(68,85,102)") {
+		t.Errorf("the changed rule is not sent:\n%s", text)
+	}
+	if strings.Contains(text, "liveOther") || strings.Contains(text, "document.querySelector") {
+		t.Errorf("unchanged rules are sent:\n%s", text)
+	}
+
+	theme.SetStyle("liveOther", nil)
+	if text := update(); text != `replaceCSSRule(".liveOther", "");`+"\n" {
+		t.Errorf("the removed rule is not sent:\n%s", text)
+	}
+
+	theme.SetMediaStyle("liveStyle", MediaStyleParams{MaxWidth: 600}, NewViewStyle(Params{Width: Px(100)}))
+	if text := update(); !strings.HasPrefix(text, "document.querySelector('style').textContent") {
+		t.Errorf("the styles are not replaced after the media rule change:\n%s", text)
+	}
+
+	editorSession := newSession(nil, 0, "", nil)
+	editor := NewThemeEditor(editorSession, theme, nil)
+	if views := editor.Views(); len(views) != 3 {
+		t.Fatalf("theme editor pages: %d", len(views))
+	} else {
+		var colorEdit View
+		for _, view := range views[1].(ViewsContainer).Views() {
+			if _, ok := view.(EditView); ok && GetRow(view).First == 1 && GetColumn(view).First == 1 {
+				colorEdit = view
+			}
+		}
+		if colorEdit == nil {
+			t.Fatal("the color editor is not found")
+		}
+
+		colorEdit.handleCommand(colorEdit, "textChanged", ParseDataText(`_{ text = "#FF7Q" }`))
+		if color, _ := theme.Color("liveColor"); color != "#FF445566" {
+			t.Errorf("invalid color is applied: %s", color)
+		}
+
+		colorEdit.handleCommand(colorEdit, "textChanged", ParseDataText(`_{ text = "#FF778899" }`))
+		if color, _ := theme.Color("liveColor"); color != "#FF778899" {
+			t.Errorf("the color is not applied: %s", color)
+		}
+	}
+
+	if style, ok := parseThemeEditorStyle("liveStyle { padding = 4px }"); !ok || style.Get(Padding) == nil {
+		t.Error("the style text is not parsed")
+	}
+	if _, ok := parseThemeEditorStyle("liveStyle { padding = "); ok {
+		t.Error("the invalid style text is parsed")
+	}
+}
+
+func TestThemeConcurrentUpdate(t *testing.T) {
+	createTestLog(t, false)
+
+	theme := NewTheme("concurrentUpdateTest")
+	theme.SetColor("concurrentColor", "#FF000000", "")
+	theme.SetStyle("concurrentStyle", NewViewStyle(Params{BackgroundColor: "@concurrentColor"}))
+	AddTheme(theme)
+	defer func() {
+		themesMutex.Lock()
+		delete(resources.themes, "concurrentUpdateTest")
+		themesMutex.Unlock()
+	}()
+
+	session := newSession(nil, 0, "", nil)
+	events := make(chan DataObject, 2)
+	session.setBridge(events, new(themeTestBridge))
+	if !session.SetCustomTheme("concurrentUpdateTest") {
+		t.Fatal("the custom theme is not set")
+	}
+
+	// the editor changes the theme in its own goroutine while the session reads it
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			theme.SetColor("concurrentColor", "#FF0000"+strconv.FormatInt(int64(i%10), 10)+"0", "")
+			session.themeChanged()
+		}
+		close(done)
+	}()
+
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+
+		case data := <-events:
+			session.handleEvent(data.Tag(), data)
+		}
+		session.Color("concurrentColor")
+	}
+
+	// the session does not handle the events: the changes are coalesced and the queue is not overflowed
+	for len(events) > 0 {
+		data := <-events
+		session.handleEvent(data.Tag(), data)
+	}
+	for i := 0; i < 10; i++ {
+		theme.SetColor("concurrentColor", "#FF00FF00", "")
+		session.themeChanged()
+	}
+	if len(events) != 1 {
+		t.Errorf("%d theme-changed events are queued", len(events))
+	}
+
+	data := <-events
+	session.handleEvent(data.Tag(), data)
+	if color, _ := session.Color("concurrentColor"); color != 0xFF00FF00 {
+		t.Errorf("the theme change is lost: %X", color)
+	}
+}
+
+func TestColorScheme(t *testing.T) {
+	createTestLog(t, false)
+
+	theme, ok := CreateThemeFromText(`theme {
+		colors = _{ schemeColor = #FF000001 },
+		colors:dark = _{ schemeColor = #FF000002 },
+		colors:contrast = _{ schemeColor = #FF000003 },
+	}`)
+	if !ok || theme.ContrastColor("schemeColor") != "#FF000003" {
+		t.Fatal("colors:contrast section is not parsed")
+	}
+	if !strings.Contains(theme.String(), "colors:contrast") {
+		t.Errorf("colors:contrast section is not written:\n%s", theme.String())
+	}
+	defaultTheme.Append(theme)
+
+	session := newSession(nil, 0, "", ParseDataText(`_{ dark = 1, storage = _{ ruiColorScheme = light } }`))
+	if session.DarkTheme() || session.ColorScheme() != LightColorScheme {
+		t.Error("the light color scheme is not restored from the client storage")
+	}
+	if color, _ := session.Color("schemeColor"); color != 0xFF000001 {
+		t.Errorf("light color: %X", color)
+	}
+
+	events := make(chan DataObject, 8)
+	bridge := new(themeTestBridge)
+	session.setBridge(events, bridge)
+
+	session.SetColorScheme(AutoColorScheme)
+	if !session.DarkTheme() || len(bridge.messages) != 1 {
+		t.Error("the auto color scheme is not applied")
+	}
+	if value, _ := session.ClientItem("ruiColorScheme"); value != "auto" {
+		t.Errorf("stored color scheme: %s", value)
+	}
+	if color, _ := session.Color("schemeColor"); color != 0xFF000002 {
+		t.Errorf("dark color: %X", color)
+	}
+
+	session.handleEvent("colorSchemeChanged", ParseDataText(`_{ dark = 1, contrast = 1 }`))
+	if !session.HighContrast() || len(bridge.messages) != 2 {
+		t.Error("the high-contrast mode is not applied")
+	}
+	if color, _ := session.Color("schemeColor"); color != 0xFF000003 {
+		t.Errorf("contrast color: %X", color)
+	}
+
+	session.SetColorScheme(DarkColorScheme)
+	if len(bridge.messages) != 2 {
+		t.Error("the session is reloaded without the theme change")
+	}
+
+	ignoreTestLog = true
+	session.SetColorScheme(10)
+	ignoreTestLog = false
+	if session.ColorScheme() != DarkColorScheme {
+		t.Error("invalid color scheme is applied")
+	}
+}
+
+func TestThemeExtends(t *testing.T) {
+	createTestLog(t, false)
+
+	base, _ := CreateThemeFromText(`theme {
+		name = extBase,
+		constants = _{ extWidth = 4px, extText = 2em },
+		colors = _{ extColor = #FF000010 },
+	}`)
+	child, _ := CreateThemeFromText(`theme {
+		name = extChild,
+		extends = "extBase, extMissing",
+		constants = _{ extText = 3em },
+		colors = _{ extColor2 = @extColor },
+		styles = [
+			extStyle { width = @extWidth, text-size = @extText, text-color = @extColor2 },
+		],
+	}`)
+	if base.Name() != "extBase" || child.Name() != "extChild" {
+		t.Fatalf("theme names: %s, %s", base.Name(), child.Name())
+	}
+	if parents := child.Parents(); len(parents) != 2 || parents[0] != "extBase" {
+		t.Errorf("theme parents: %v", parents)
+	}
+	if copy, _ := CreateThemeFromText(child.String()); copy == nil || copy.Name() != "extChild" || len(copy.Parents()) != 2 {
+		t.Errorf("name and extends are not written:\n%s", child.String())
+	}
+	child.SetParents("extBase")
+	AddTheme(base)
+	AddTheme(child)
+
+	SetThemeCSSVariables(true)
+	defer SetThemeCSSVariables(false)
+
+	session := newSession(nil, 0, "", nil)
+	events := make(chan DataObject, 8)
+	bridge := new(themeTestBridge)
+	session.setBridge(events, bridge)
+	session.SetCustomTheme("extChild")
+
+	if value, _ := session.Constant("extWidth"); value != "4px" {
+		t.Errorf("inherited constant: %s", value)
+	}
+	if value, _ := session.Constant("extText"); value != "3em" {
+		t.Errorf("overridden constant: %s", value)
+	}
+	if color, _ := session.Color("extColor2"); color != 0xFF000010 {
+		t.Errorf("inherited color: %X", color)
+	}
+	if !session.usesTheme(base) {
+		t.Error("the session does not use the parent theme")
+	}
+
+	css := bridge.lastMessage()
+	for _, text := range []string{
+		":root {", "--const-extWidth: 4px;", "--const-extText: 3rem;", "--color-extColor2: rgb(0,0,16);",
+		"width: var(--const-extWidth);", "font-size: var(--const-extText);", "color: var(--color-extColor2);",
+	} {
+		if !strings.Contains(css, text) {
+			t.Errorf(`"%s" is not found in the CSS:\n%s`, text, css)
+		}
+	}
+
+	base.SetConstant("extWidth", "8px", "")
+	session.themeChanged()
+	data := <-events
+	session.handleEvent(data.Tag(), data)
+	if text := bridge.lastMessage(); !strings.HasPrefix(text, `replaceCSSRule(":root", `) ||
+		!strings.Contains(text, "--const-extWidth: 8px;") || strings.Contains(text, "extStyle") {
+		t.Errorf("only the variables must be updated:\n%s", text)
+	}
+}
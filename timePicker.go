@@ -43,6 +43,12 @@ func (picker *timePickerData) init(session Session) {
 	picker.timeChangedListeners = []func(TimePicker, time.Time, time.Time){}
 }
 
+func (picker *timePickerData) supportedProperties() []string {
+	return append([]string{
+		TimePickerMin, TimePickerMax, TimePickerStep, TimePickerValue, TimeChangedEvent,
+	}, picker.viewData.supportedProperties()...)
+}
+
 func (picker *timePickerData) String() string {
 	return getViewString(picker)
 }
@@ -0,0 +1,345 @@
+package rui
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// ToastSeverity is the constant for the "toast-severity" property tag.
+	// The "toast-severity" int property sets the severity level of the Toast.
+	// Valid values: InfoToast (0), SuccessToast (1), WarningToast (2), and ErrorToast (3).
+	// The default value is InfoToast
+	ToastSeverity = "toast-severity"
+
+	// ToastTimeout is the constant for the "toast-timeout" property tag.
+	// The "toast-timeout" float property sets the time in seconds after which the Toast is closed automatically.
+	// The timer is paused while the mouse pointer is over the Toast.
+	// If the value is less than or equal to 0 then the Toast is not closed automatically.
+	// The default value is 4
+	ToastTimeout = "toast-timeout"
+
+	// ToastActions is the constant for the "toast-actions" property tag.
+	// Using the "toast-actions" property you can add action buttons to the Toast.
+	// The "toast-actions" property can be assigned the following data types: ToastAction and []ToastAction
+	ToastActions = "toast-actions"
+
+	// InfoToast is value of the "toast-severity" property: an informational message
+	InfoToast = 0
+	// SuccessToast is value of the "toast-severity" property: a message about the successful completion of an operation
+	SuccessToast = 1
+	// WarningToast is value of the "toast-severity" property: a warning message
+	WarningToast = 2
+	// ErrorToast is value of the "toast-severity" property: an error message
+	ErrorToast = 3
+
+	// TopLeftToast is value of the toast position: toasts are stacked in the top left corner of the screen
+	TopLeftToast = 0
+	// TopCenterToast is value of the toast position: toasts are stacked at the top center of the screen
+	TopCenterToast = 1
+	// TopRightToast is value of the toast position: toasts are stacked in the top right corner of the screen
+	TopRightToast = 2
+	// BottomLeftToast is value of the toast position: toasts are stacked in the bottom left corner of the screen
+	BottomLeftToast = 3
+	// BottomCenterToast is value of the toast position: toasts are stacked at the bottom center of the screen
+	BottomCenterToast = 4
+	// BottomRightToast is value of the toast position: toasts are stacked in the bottom right corner of the screen
+	BottomRightToast = 5
+
+	defaultToastTimeout = 4
+	defaultMaxToasts    = 5
+)
+
+// ToastAction describes an action button of the Toast
+type ToastAction struct {
+	// Title - the button title
+	Title string
+	// OnClick - the function that is called when the button is clicked.
+	// The Toast is closed after the function call
+	OnClick func(Toast)
+}
+
+// Toast interface of a non-blocking notification
+type Toast interface {
+	// Session returns current Session interface
+	Session() Session
+	// Text returns the text of the Toast
+	Text() string
+	// Severity returns the severity level of the Toast: InfoToast, SuccessToast, WarningToast, or ErrorToast
+	Severity() int
+	// Dismiss closes the Toast
+	Dismiss()
+}
+
+type toastData struct {
+	id               string
+	session          Session
+	text             string
+	severity         int
+	timeout          float64
+	closeButton      bool
+	actions          []ToastAction
+	dismissListeners []func(Toast)
+}
+
+type toastManager struct {
+	toasts    []*toastData
+	position  int
+	maxCount  int
+	idCounter int
+}
+
+var toastSeverityStyles = []string{"ruiToastInfo", "ruiToastSuccess", "ruiToastWarning", "ruiToastError"}
+var toastPositionStyles = []string{"ruiToastTopLeft", "ruiToastTopCenter", "ruiToastTopRight",
+	"ruiToastBottomLeft", "ruiToastBottomCenter", "ruiToastBottomRight"}
+
+func (toast *toastData) Session() Session {
+	return toast.session
+}
+
+func (toast *toastData) Text() string {
+	return toast.text
+}
+
+func (toast *toastData) Severity() int {
+	return toast.severity
+}
+
+func (toast *toastData) Dismiss() {
+	toast.session.toastManager().dismissToast(toast)
+}
+
+func (toast *toastData) init(session Session, text string, params Params) {
+	toast.session = session
+	toast.text = text
+	toast.severity = InfoToast
+	toast.timeout = defaultToastTimeout
+	toast.closeButton = false
+	toast.actions = []ToastAction{}
+	toast.dismissListeners = []func(Toast){}
+
+	for tag, value := range params {
+		switch tag {
+		case ToastSeverity:
+			if severity, ok := enumProperty(params, tag, session, InfoToast); ok {
+				toast.severity = severity
+			} else {
+				invalidPropertyValue(tag, value)
+			}
+
+		case ToastTimeout:
+			if n, ok := isInt(value); ok {
+				toast.timeout = float64(n)
+			} else if timeout, ok := floatProperty(params, tag, session, defaultToastTimeout); ok {
+				toast.timeout = timeout
+			} else {
+				invalidPropertyValue(tag, value)
+			}
+
+		case CloseButton:
+			toast.closeButton, _ = boolProperty(params, tag, session)
+
+		case ToastActions:
+			switch value := value.(type) {
+			case ToastAction:
+				toast.actions = []ToastAction{value}
+
+			case []ToastAction:
+				toast.actions = value
+
+			default:
+				notCompatibleType(tag, value)
+			}
+
+		case DismissEvent:
+			if listeners, ok := valueToNoParamListeners[Toast](value); ok {
+				if listeners != nil {
+					toast.dismissListeners = listeners
+				}
+			} else {
+				notCompatibleType(tag, value)
+			}
+
+		default:
+			ErrorLogF(`"%s" property is not supported by Toast`, tag)
+		}
+	}
+}
+
+func (toast *toastData) html(buffer *strings.Builder) {
+	buffer.WriteString(`<div id="`)
+	buffer.WriteString(toast.id)
+	buffer.WriteString(`" class="ruiToast `)
+	buffer.WriteString(toastSeverityStyles[toast.severity])
+	buffer.WriteString(`" role="status"`)
+	if toast.timeout > 0 {
+		buffer.WriteString(` data-timeout="`)
+		buffer.WriteString(strconv.Itoa(int(toast.timeout * 1000)))
+		buffer.WriteString(`" onmouseenter="toastMouseEnter(this)" onmouseleave="toastMouseLeave(this)"`)
+	}
+	buffer.WriteString(`><div class="ruiToastText">`)
+
+	text := toast.text
+	if str, ok := toast.session.GetString(text); ok {
+		text = str
+	}
+	buffer.WriteString(htmlEscape(text))
+	buffer.WriteString(`</div>`)
+
+	for n, action := range toast.actions {
+		title := action.Title
+		if str, ok := toast.session.GetString(title); ok {
+			title = str
+		}
+		buffer.WriteString(`<button class="ruiToastAction" onclick="toastActionClick('`)
+		buffer.WriteString(toast.id)
+		buffer.WriteString(`', `)
+		buffer.WriteString(strconv.Itoa(n))
+		buffer.WriteString(`)">`)
+		buffer.WriteString(htmlEscape(title))
+		buffer.WriteString(`</button>`)
+	}
+
+	if toast.closeButton {
+		buffer.WriteString(`<div class="ruiToastClose" onclick="toastCloseClick('`)
+		buffer.WriteString(toast.id)
+		buffer.WriteString(`')">✕</div>`)
+	}
+	buffer.WriteString(`</div>`)
+}
+
+func (manager *toastManager) showToast(toast *toastData) {
+	manager.idCounter++
+	toast.id = "ruiToast" + strconv.Itoa(manager.idCounter)
+	manager.toasts = append(manager.toasts, toast)
+
+	if maxCount := manager.maxCount; maxCount > 0 && len(manager.toasts) > maxCount {
+		removed := manager.toasts[:len(manager.toasts)-maxCount]
+		manager.toasts = manager.toasts[len(manager.toasts)-maxCount:]
+		manager.updateToastLayer(toast.session)
+		for _, old := range removed {
+			old.onDismiss()
+		}
+	} else {
+		manager.updateToastLayer(toast.session)
+	}
+
+	if toast.timeout > 0 {
+		toast.session.callFunc("startToastTimer", toast.id)
+	}
+}
+
+func (manager *toastManager) dismissToast(toast *toastData) {
+	for n, t := range manager.toasts {
+		if t == toast {
+			manager.toasts = append(manager.toasts[:n], manager.toasts[n+1:]...)
+			manager.updateToastLayer(toast.session)
+			toast.onDismiss()
+			return
+		}
+	}
+}
+
+func (manager *toastManager) toastByID(id string) *toastData {
+	for _, toast := range manager.toasts {
+		if toast.id == id {
+			return toast
+		}
+	}
+	return nil
+}
+
+func (manager *toastManager) updateToastLayer(session Session) {
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	// the newest toast is always placed closest to the corner
+	count := len(manager.toasts)
+	for i := range manager.toasts {
+		if manager.position < BottomLeftToast {
+			manager.toasts[count-1-i].html(buffer)
+		} else {
+			manager.toasts[i].html(buffer)
+		}
+	}
+	session.updateInnerHTML("ruiToastLayer", buffer.String())
+}
+
+func (manager *toastManager) setPosition(session Session, position int) {
+	if position < TopLeftToast || position > BottomRightToast {
+		ErrorLogF(`Invalid toast position: %d`, position)
+		return
+	}
+	if manager.position != position {
+		manager.position = position
+		session.updateProperty("ruiToastLayer", "class", "ruiToastLayer "+toastPositionStyles[position])
+		manager.updateToastLayer(session)
+	}
+}
+
+func (manager *toastManager) setMaxCount(count int) {
+	manager.maxCount = count
+	if count > 0 && len(manager.toasts) > count {
+		removed := append([]*toastData{}, manager.toasts[:len(manager.toasts)-count]...)
+		for _, toast := range removed {
+			toast.Dismiss()
+		}
+	}
+}
+
+func (manager *toastManager) handleCommand(command string, data DataObject) {
+	id, ok := data.PropertyValue("id")
+	if !ok {
+		ErrorLog(`"id" property not found. Event: ` + command)
+		return
+	}
+
+	toast := manager.toastByID(id)
+	if toast == nil {
+		return
+	}
+
+	switch command {
+	case "toastClose":
+		toast.Dismiss()
+
+	case "toastAction":
+		if n, ok := dataIntProperty(data, "action"); ok && n >= 0 && n < len(toast.actions) {
+			if action := toast.actions[n].OnClick; action != nil {
+				action(toast)
+			}
+			toast.Dismiss()
+		}
+	}
+}
+
+func (toast *toastData) onDismiss() {
+	for _, listener := range toast.dismissListeners {
+		listener(toast)
+	}
+}
+
+func (session *sessionData) ShowToast(text string, params Params) Toast {
+	toast := new(toastData)
+	toast.init(session, text, params)
+	session.toastManager().showToast(toast)
+	return toast
+}
+
+func (session *sessionData) SetToastPosition(position int) {
+	session.toastManager().setPosition(session, position)
+}
+
+func (session *sessionData) SetMaxToasts(count int) {
+	session.toastManager().setMaxCount(count)
+}
+
+func (session *sessionData) toastManager() *toastManager {
+	if session.toasts == nil {
+		session.toasts = new(toastManager)
+		session.toasts.toasts = []*toastData{}
+		session.toasts.position = BottomRightToast
+		session.toasts.maxCount = defaultMaxToasts
+	}
+	return session.toasts
+}
@@ -0,0 +1,86 @@
+package rui
+
+import (
+	"testing"
+)
+
+func TestSessionToasts(t *testing.T) {
+	createTestLog(t, false)
+	session := newSession(nil, 0, "", nil)
+	session.SetMaxToasts(2)
+
+	// there is no connection, so "No connection" errors of starting timers are expected
+	ignoreTestLog = true
+	defer func() {
+		ignoreTestLog = false
+	}()
+
+	dismissed := []string{}
+	dismissListener := func(toast Toast) {
+		dismissed = append(dismissed, toast.Text())
+	}
+
+	actionCount := 0
+	first := session.ShowToast("first", Params{
+		ToastSeverity: "warning",
+		ToastTimeout:  0,
+		DismissEvent:  dismissListener,
+		ToastActions: ToastAction{
+			Title: "Undo",
+			OnClick: func(toast Toast) {
+				actionCount++
+			},
+		},
+	})
+	if first.Severity() != WarningToast {
+		t.Errorf("severity: %d", first.Severity())
+	}
+
+	second := session.ShowToast("second", Params{DismissEvent: dismissListener})
+	session.ShowToast("third", Params{ToastSeverity: ErrorToast, DismissEvent: dismissListener})
+
+	manager := session.toastManager()
+	if len(manager.toasts) != 2 || len(dismissed) != 1 || dismissed[0] != "first" {
+		t.Errorf("max toasts: %d toasts, dismissed %v", len(manager.toasts), dismissed)
+	}
+
+	// the command of the removed toast is ignored
+	session.handleEvent("toastAction", testToastCommand(first, "action", "0"))
+	if actionCount != 0 {
+		t.Error("the action of the dismissed toast is called")
+	}
+
+	session.handleEvent("toastClose", testToastCommand(second))
+	if len(manager.toasts) != 1 || manager.toasts[0].Text() != "third" || len(dismissed) != 2 {
+		t.Errorf("toastClose: %d toasts, dismissed %v", len(manager.toasts), dismissed)
+	}
+
+	undo := 0
+	toast := session.ShowToast("saved", Params{
+		ToastSeverity: SuccessToast,
+		ToastActions: []ToastAction{
+			{Title: "Open"},
+			{Title: "Undo", OnClick: func(toast Toast) { undo++ }},
+		},
+	})
+	session.handleEvent("toastAction", testToastCommand(toast, "action", "1"))
+	if undo != 1 || manager.toastByID(toast.(*toastData).id) != nil {
+		t.Errorf("toastAction: undo = %d", undo)
+	}
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+	session.ShowToast("<b>", Params{CloseButton: true}).(*toastData).html(buffer)
+	if html := buffer.String(); html != `<div id="ruiToast5" class="ruiToast ruiToastInfo" role="status" data-timeout="4000" onmouseenter="toastMouseEnter(this)" onmouseleave="toastMouseLeave(this)"><div class="ruiToastText">&lt;b&gt;</div><div class="ruiToastClose" onclick="toastCloseClick('ruiToast5')">✕</div></div>` {
+		t.Errorf("html: %s", html)
+	}
+}
+
+func testToastCommand(toast Toast, params ...string) DataObject {
+	data := NewDataObject("")
+	data.SetPropertyValue("id", toast.(*toastData).id)
+	for i := 0; i+1 < len(params); i += 2 {
+		data.SetPropertyValue(params[i], params[i+1])
+	}
+	return data
+}
@@ -0,0 +1,171 @@
+package rui
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TreeAdapter - the tree data source.
+// A tree item is addressed by the path of the item indexes starting from the root:
+// nil (or the empty slice) is the invisible root, []int{2} is the third top-level item,
+// []int{2, 0} is the first child of the third top-level item, etc.
+// Children are requested only when an item is expanded, so the adapter may load them lazily.
+type TreeAdapter interface {
+	// TreeChildCount returns the number of children of the item
+	TreeChildCount(path []int) int
+	// TreeItem returns the view of the item
+	TreeItem(path []int, session Session) View
+	// IsTreeItemLeaf returns true if the item has no children and can not be expanded
+	IsTreeItemLeaf(path []int) bool
+	// IsTreeItemEnabled returns true if the item can be selected and checked
+	IsTreeItemEnabled(path []int) bool
+}
+
+// TreeNode describes an item of a static tree used by NewTextTreeAdapter
+type TreeNode struct {
+	// Text - the item text
+	Text string
+	// Children - the child items
+	Children []TreeNode
+}
+
+type textTreeAdapter struct {
+	nodes  []TreeNode
+	views  map[string]View
+	params Params
+}
+
+// NewTextTreeAdapter create the new TreeAdapter for a static text tree displaying.
+// The second argument is parameters of a TextView item
+func NewTextTreeAdapter(nodes []TreeNode, params Params) TreeAdapter {
+	if nodes == nil {
+		return nil
+	}
+	adapter := new(textTreeAdapter)
+	adapter.nodes = nodes
+	if params != nil {
+		adapter.params = params
+	} else {
+		adapter.params = Params{}
+	}
+	adapter.views = map[string]View{}
+	return adapter
+}
+
+func (adapter *textTreeAdapter) node(path []int) (*TreeNode, bool) {
+	nodes := adapter.nodes
+	var node *TreeNode
+	for _, index := range path {
+		if index < 0 || index >= len(nodes) {
+			return nil, false
+		}
+		node = &nodes[index]
+		nodes = node.Children
+	}
+	return node, true
+}
+
+func (adapter *textTreeAdapter) TreeChildCount(path []int) int {
+	if len(path) == 0 {
+		return len(adapter.nodes)
+	}
+	if node, ok := adapter.node(path); ok {
+		return len(node.Children)
+	}
+	return 0
+}
+
+func (adapter *textTreeAdapter) TreeItem(path []int, session Session) View {
+	node, ok := adapter.node(path)
+	if !ok || node == nil {
+		return nil
+	}
+
+	key := treePathKey(path)
+	if view, ok := adapter.views[key]; ok {
+		return view
+	}
+
+	adapter.params[Text] = node.Text
+	view := NewTextView(session, adapter.params)
+	adapter.views[key] = view
+	return view
+}
+
+func (adapter *textTreeAdapter) IsTreeItemLeaf(path []int) bool {
+	return adapter.TreeChildCount(path) == 0
+}
+
+func (adapter *textTreeAdapter) IsTreeItemEnabled(path []int) bool {
+	return true
+}
+
+func treePathKey(path []int) string {
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	for i, index := range path {
+		if i > 0 {
+			buffer.WriteRune('.')
+		}
+		buffer.WriteString(strconv.Itoa(index))
+	}
+	return buffer.String()
+}
+
+func treeKeyPath(key string) ([]int, bool) {
+	if key == "" {
+		return []int{}, true
+	}
+
+	parts := strings.Split(key, ".")
+	path := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		path[i] = n
+	}
+	return path, true
+}
+
+func treeParentKey(key string) string {
+	if n := strings.LastIndex(key, "."); n >= 0 {
+		return key[:n]
+	}
+	return ""
+}
+
+func treeChildKey(key string, index int) string {
+	if key == "" {
+		return strconv.Itoa(index)
+	}
+	return key + "." + strconv.Itoa(index)
+}
+
+// isTreeKeyAncestor returns true if the item "key" is an ancestor of the item "descendant"
+func isTreeKeyAncestor(key, descendant string) bool {
+	if key == "" {
+		return descendant != ""
+	}
+	return strings.HasPrefix(descendant, key+".")
+}
+
+func dataValuesToTreeNodes(values []DataValue) []TreeNode {
+	nodes := make([]TreeNode, 0, len(values))
+	for _, value := range values {
+		if value.IsObject() {
+			obj := value.Object()
+			node := TreeNode{}
+			node.Text, _ = obj.PropertyValue(Text)
+			if items := obj.PropertyByTag(Items); items != nil && items.Type() == ArrayNode {
+				node.Children = dataValuesToTreeNodes(items.ArrayElements())
+			}
+			nodes = append(nodes, node)
+		} else {
+			nodes = append(nodes, TreeNode{Text: value.Value()})
+		}
+	}
+	return nodes
+}
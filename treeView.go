@@ -0,0 +1,1306 @@
+package rui
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// TreeItemClickedEvent is the constant for "tree-item-clicked" property tag.
+	// The "tree-item-clicked" event occurs when the user clicks on an item of the tree.
+	// The main listener format: func(TreeView, []int), where the second argument is the item path.
+	TreeItemClickedEvent = "tree-item-clicked"
+	// TreeItemSelectedEvent is the constant for "tree-item-selected" property tag.
+	// The "tree-item-selected" event occurs when a tree item becomes current.
+	// The main listener format: func(TreeView, []int), where the second argument is the item path (nil if there is no current item).
+	TreeItemSelectedEvent = "tree-item-selected"
+	// TreeSelectionChangedEvent is the constant for "tree-selection-changed" property tag.
+	// The "tree-selection-changed" event occurs when the set of selected tree items is changed.
+	// The main listener format: func(TreeView, [][]int), where the second argument is the array of selected item paths.
+	TreeSelectionChangedEvent = "tree-selection-changed"
+	// TreeItemExpandedEvent is the constant for "tree-item-expanded" property tag.
+	// The "tree-item-expanded" event occurs when a tree item is expanded.
+	// The main listener format: func(TreeView, []int), where the second argument is the item path.
+	TreeItemExpandedEvent = "tree-item-expanded"
+	// TreeItemCollapsedEvent is the constant for "tree-item-collapsed" property tag.
+	// The "tree-item-collapsed" event occurs when a tree item is collapsed.
+	// The main listener format: func(TreeView, []int), where the second argument is the item path.
+	TreeItemCollapsedEvent = "tree-item-collapsed"
+	// TreeItemCheckedEvent is the constant for "tree-item-checked" property tag.
+	// The "tree-item-checked" event occurs when a tree item checkbox becomes checked/unchecked.
+	// The main listener format: func(TreeView, [][]int), where the second argument is the array of checked item paths.
+	TreeItemCheckedEvent = "tree-item-checked"
+	// TreeSelectionMode is the constant for "tree-selection-mode" property tag.
+	// The "tree-selection-mode" int property defines how many items can be selected:
+	// SingleSelection (0) or MultipleSelection (1)
+	TreeSelectionMode = "tree-selection-mode"
+	// TreeIndent is the constant for "tree-indent" property tag.
+	// The "tree-indent" SizeUnit property defines the indent of the child items relative to the parent item.
+	// The default value is defined by the "ruiTreeIndent" constant
+	TreeIndent = "tree-indent"
+)
+
+const (
+	// SingleSelection is value of "tree-selection-mode" property: only one item can be selected
+	SingleSelection = 0
+	// MultipleSelection is value of "tree-selection-mode" property: several items can be selected
+	// by clicking with Ctrl (Command) or Shift keys pressed
+	MultipleSelection = 1
+
+	// TreeCheckOff is the state of a tree item checkbox: the item is not checked
+	TreeCheckOff = 0
+	// TreeCheckOn is the state of a tree item checkbox: the item and all its children are checked
+	TreeCheckOn = 1
+	// TreeCheckMixed is the state of a tree item checkbox: some of the item children are checked
+	TreeCheckMixed = 2
+)
+
+// TreeView - the tree view interface
+type TreeView interface {
+	View
+	ParentView
+	// ReloadTreeViewData updates TreeView content
+	ReloadTreeViewData()
+	// ExpandTreeItem expands the item with the given path
+	ExpandTreeItem(path []int)
+	// CollapseTreeItem collapses the item with the given path
+	CollapseTreeItem(path []int)
+	// IsTreeItemExpanded returns true if the item with the given path is expanded
+	IsTreeItemExpanded(path []int) bool
+	// CurrentTreeItem returns the path of the current item or nil if there is no current item
+	CurrentTreeItem() []int
+	// SetCurrentTreeItem makes current the item with the given path. Parents of the item are expanded
+	SetCurrentTreeItem(path []int)
+	// SelectedTreeItems returns the paths of selected items
+	SelectedTreeItems() [][]int
+	// SetTreeItemChecked sets the checkbox state of the item with the given path
+	SetTreeItemChecked(path []int, checked bool)
+	// TreeItemCheckState returns the checkbox state of the item with the given path:
+	// TreeCheckOff (0), TreeCheckOn (1), or TreeCheckMixed (2)
+	TreeItemCheckState(path []int) int
+	// CheckedTreeItems returns the paths of checked items. If all children of an item are checked
+	// then only the path of the item is returned
+	CheckedTreeItems() [][]int
+}
+
+type treeViewData struct {
+	viewData
+	adapter            TreeAdapter
+	clickedListeners   []func(TreeView, []int)
+	selectedListeners  []func(TreeView, []int)
+	selectionListeners []func(TreeView, [][]int)
+	expandedListeners  []func(TreeView, []int)
+	collapsedListeners []func(TreeView, []int)
+	checkedListeners   []func(TreeView, [][]int)
+	items              map[string]View
+	expanded           map[string]bool
+	checked            map[string]bool
+	pendingChecked     []string
+	selected           map[string]bool
+	current            string
+	hasCurrent         bool
+	anchor             string
+}
+
+// NewTreeView creates the new tree view
+func NewTreeView(session Session, params Params) TreeView {
+	view := new(treeViewData)
+	view.init(session)
+	setInitParams(view, params)
+	return view
+}
+
+func newTreeView(session Session) View {
+	return NewTreeView(session, nil)
+}
+
+// Init initialize fields of TreeView by default values
+func (treeView *treeViewData) init(session Session) {
+	treeView.viewData.init(session)
+	treeView.tag = "TreeView"
+	treeView.systemClass = "ruiTreeView"
+	treeView.items = map[string]View{}
+	treeView.expanded = map[string]bool{}
+	treeView.checked = map[string]bool{}
+	treeView.selected = map[string]bool{}
+	treeView.clickedListeners = []func(TreeView, []int){}
+	treeView.selectedListeners = []func(TreeView, []int){}
+	treeView.selectionListeners = []func(TreeView, [][]int){}
+	treeView.expandedListeners = []func(TreeView, []int){}
+	treeView.collapsedListeners = []func(TreeView, []int){}
+	treeView.checkedListeners = []func(TreeView, [][]int){}
+}
+
+func (treeView *treeViewData) supportedProperties() []string {
+	return append([]string{
+		Items, Checked, ItemCheckbox, TreeSelectionMode, TreeIndent, ListItemStyle, CurrentStyle,
+		CurrentInactiveStyle, TreeItemClickedEvent, TreeItemSelectedEvent, TreeItemExpandedEvent,
+		TreeItemCollapsedEvent, TreeSelectionChangedEvent, TreeItemCheckedEvent,
+	}, treeView.viewData.supportedProperties()...)
+}
+
+func (treeView *treeViewData) String() string {
+	return getViewString(treeView)
+}
+
+func (treeView *treeViewData) Views() []View {
+	views := make([]View, 0, len(treeView.items))
+	for _, view := range treeView.items {
+		views = append(views, view)
+	}
+	return views
+}
+
+func (treeView *treeViewData) Focusable() bool {
+	return true
+}
+
+func (treeView *treeViewData) Remove(tag string) {
+	treeView.remove(strings.ToLower(tag))
+}
+
+func (treeView *treeViewData) remove(tag string) {
+	switch tag {
+	case Items:
+		if treeView.adapter == nil {
+			return
+		}
+		treeView.adapter = nil
+		treeView.resetState()
+		if treeView.created {
+			updateInnerHTML(treeView.htmlID(), treeView.session)
+		}
+
+	case Checked:
+		treeView.pendingChecked = nil
+		if len(treeView.checked) == 0 {
+			return
+		}
+		treeView.checked = map[string]bool{}
+		if treeView.created {
+			updateInnerHTML(treeView.htmlID(), treeView.session)
+		}
+
+	case ItemCheckbox, TreeSelectionMode, TreeIndent:
+		if _, ok := treeView.properties.Load(tag); !ok {
+			return
+		}
+		treeView.properties.Delete(tag)
+		if treeView.created {
+			updateInnerHTML(treeView.htmlID(), treeView.session)
+		}
+
+	case ListItemStyle, CurrentStyle, CurrentInactiveStyle:
+		if _, ok := treeView.properties.Load(tag); !ok {
+			return
+		}
+		treeView.properties.Delete(tag)
+		treeView.itemStyleChanged(tag)
+
+	case TreeItemClickedEvent:
+		if len(treeView.clickedListeners) == 0 {
+			return
+		}
+		treeView.clickedListeners = []func(TreeView, []int){}
+
+	case TreeItemSelectedEvent:
+		if len(treeView.selectedListeners) == 0 {
+			return
+		}
+		treeView.selectedListeners = []func(TreeView, []int){}
+
+	case TreeSelectionChangedEvent:
+		if len(treeView.selectionListeners) == 0 {
+			return
+		}
+		treeView.selectionListeners = []func(TreeView, [][]int){}
+
+	case TreeItemExpandedEvent:
+		if len(treeView.expandedListeners) == 0 {
+			return
+		}
+		treeView.expandedListeners = []func(TreeView, []int){}
+
+	case TreeItemCollapsedEvent:
+		if len(treeView.collapsedListeners) == 0 {
+			return
+		}
+		treeView.collapsedListeners = []func(TreeView, []int){}
+
+	case TreeItemCheckedEvent:
+		if len(treeView.checkedListeners) == 0 {
+			return
+		}
+		treeView.checkedListeners = []func(TreeView, [][]int){}
+
+	default:
+		treeView.viewData.remove(tag)
+		return
+	}
+
+	treeView.propertyChangedEvent(tag)
+}
+
+func (treeView *treeViewData) Set(tag string, value any) bool {
+	return treeView.set(strings.ToLower(tag), value)
+}
+
+func (treeView *treeViewData) set(tag string, value any) bool {
+	if value == nil {
+		treeView.remove(tag)
+		return true
+	}
+
+	setPathListeners := func(listeners *[]func(TreeView, []int)) bool {
+		result, ok := valueToEventListeners[TreeView, []int](value)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		} else if result == nil {
+			result = []func(TreeView, []int){}
+		}
+		*listeners = result
+		treeView.propertyChangedEvent(tag)
+		return true
+	}
+
+	setPathsListeners := func(listeners *[]func(TreeView, [][]int)) bool {
+		result, ok := valueToEventListeners[TreeView, [][]int](value)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		} else if result == nil {
+			result = []func(TreeView, [][]int){}
+		}
+		*listeners = result
+		treeView.propertyChangedEvent(tag)
+		return true
+	}
+
+	switch tag {
+	case TreeItemClickedEvent:
+		return setPathListeners(&treeView.clickedListeners)
+
+	case TreeItemSelectedEvent:
+		return setPathListeners(&treeView.selectedListeners)
+
+	case TreeItemExpandedEvent:
+		return setPathListeners(&treeView.expandedListeners)
+
+	case TreeItemCollapsedEvent:
+		return setPathListeners(&treeView.collapsedListeners)
+
+	case TreeSelectionChangedEvent:
+		return setPathsListeners(&treeView.selectionListeners)
+
+	case TreeItemCheckedEvent:
+		return setPathsListeners(&treeView.checkedListeners)
+
+	case Items:
+		if !treeView.setItems(value) {
+			return false
+		}
+
+	case Checked:
+		if !treeView.setChecked(value) {
+			return false
+		}
+
+	case ItemCheckbox, TreeSelectionMode:
+		if !treeView.setEnumProperty(tag, value, enumProperties[tag].values) {
+			return false
+		}
+		if tag == ItemCheckbox {
+			treeView.applyPendingChecked()
+		}
+
+	case TreeIndent:
+		if !treeView.setSizeProperty(tag, value) {
+			return false
+		}
+
+	case ListItemStyle, CurrentStyle, CurrentInactiveStyle:
+		text, ok := value.(string)
+		if !ok {
+			notCompatibleType(tag, value)
+			return false
+		}
+		if text == "" {
+			treeView.properties.Delete(tag)
+		} else {
+			treeView.properties.Store(tag, text)
+		}
+		treeView.itemStyleChanged(tag)
+		treeView.propertyChangedEvent(tag)
+		return true
+
+	default:
+		return treeView.viewData.set(tag, value)
+	}
+
+	if treeView.created {
+		updateInnerHTML(treeView.htmlID(), treeView.session)
+	}
+	treeView.propertyChangedEvent(tag)
+	return true
+}
+
+func (treeView *treeViewData) itemStyleChanged(tag string) {
+	if treeView.created {
+		switch tag {
+		case CurrentStyle:
+			treeView.session.updateProperty(treeView.htmlID(), "data-focusitemstyle", treeView.currentStyle())
+
+		case CurrentInactiveStyle:
+			treeView.session.updateProperty(treeView.htmlID(), "data-bluritemstyle", treeView.currentInactiveStyle())
+		}
+		updateInnerHTML(treeView.htmlID(), treeView.session)
+	}
+}
+
+func (treeView *treeViewData) Get(tag string) any {
+	return treeView.get(strings.ToLower(tag))
+}
+
+func (treeView *treeViewData) get(tag string) any {
+	switch tag {
+	case TreeItemClickedEvent:
+		return treeView.clickedListeners
+
+	case TreeItemSelectedEvent:
+		return treeView.selectedListeners
+
+	case TreeSelectionChangedEvent:
+		return treeView.selectionListeners
+
+	case TreeItemExpandedEvent:
+		return treeView.expandedListeners
+
+	case TreeItemCollapsedEvent:
+		return treeView.collapsedListeners
+
+	case TreeItemCheckedEvent:
+		return treeView.checkedListeners
+
+	case Items:
+		return treeView.adapter
+
+	case Checked:
+		return treeView.CheckedTreeItems()
+
+	case ListItemStyle:
+		return treeView.listItemStyle()
+
+	case CurrentStyle:
+		return treeView.currentStyle()
+
+	case CurrentInactiveStyle:
+		return treeView.currentInactiveStyle()
+	}
+	return treeView.viewData.get(tag)
+}
+
+func (treeView *treeViewData) resetState() {
+	treeView.items = map[string]View{}
+	treeView.expanded = map[string]bool{}
+	treeView.checked = map[string]bool{}
+	treeView.selected = map[string]bool{}
+	treeView.current = ""
+	treeView.hasCurrent = false
+	treeView.anchor = ""
+}
+
+func (treeView *treeViewData) setItems(value any) bool {
+	var adapter TreeAdapter
+	switch value := value.(type) {
+	case []string:
+		nodes := make([]TreeNode, len(value))
+		for i, text := range value {
+			nodes[i].Text = text
+		}
+		adapter = NewTextTreeAdapter(nodes, nil)
+
+	case []TreeNode:
+		adapter = NewTextTreeAdapter(value, nil)
+
+	case []DataValue:
+		adapter = NewTextTreeAdapter(dataValuesToTreeNodes(value), nil)
+
+	case TreeAdapter:
+		adapter = value
+
+	default:
+		notCompatibleType(Items, value)
+		return false
+	}
+
+	treeView.adapter = adapter
+	treeView.resetState()
+	treeView.applyPendingChecked()
+	return true
+}
+
+func (treeView *treeViewData) setChecked(value any) bool {
+	var paths [][]int
+	switch value := value.(type) {
+	case string:
+		for _, key := range strings.Split(value, ",") {
+			if key = strings.Trim(key, " \t"); key != "" {
+				path, ok := treeKeyPath(key)
+				if !ok {
+					invalidPropertyValue(Checked, value)
+					return false
+				}
+				paths = append(paths, path)
+			}
+		}
+
+	case []int:
+		paths = [][]int{value}
+
+	case [][]int:
+		paths = value
+
+	default:
+		notCompatibleType(Checked, value)
+		return false
+	}
+
+	if GetTreeViewCheckbox(treeView) == SingleCheckbox && len(paths) > 1 {
+		invalidPropertyValue(Checked, value)
+		return false
+	}
+
+	treeView.pendingChecked = make([]string, len(paths))
+	for i, path := range paths {
+		treeView.pendingChecked[i] = treePathKey(path)
+	}
+
+	// the listeners are not called while the view is being created from Params or a .rui description
+	if treeView.applyPendingChecked() && !treeView.session.ignoreViewUpdates() {
+		for _, listener := range treeView.checkedListeners {
+			listener(treeView, treeView.CheckedTreeItems())
+		}
+	}
+	return true
+}
+
+// applyPendingChecked checks the items set by the "checked" property. The items can not be checked
+// until the adapter and the checkbox mode are set, so the keys are kept until then
+func (treeView *treeViewData) applyPendingChecked() bool {
+	if treeView.pendingChecked == nil || treeView.adapter == nil || GetTreeViewCheckbox(treeView) == NoneCheckbox {
+		return false
+	}
+
+	treeView.checked = map[string]bool{}
+	for _, key := range treeView.pendingChecked {
+		treeView.checkItem(key, true)
+	}
+	treeView.pendingChecked = nil
+	return true
+}
+
+func (treeView *treeViewData) ReloadTreeViewData() {
+	treeView.items = map[string]View{}
+	if treeView.created {
+		updateInnerHTML(treeView.htmlID(), treeView.session)
+	}
+}
+
+func (treeView *treeViewData) childCount(key string) int {
+	if treeView.adapter == nil {
+		return 0
+	}
+	if path, ok := treeKeyPath(key); ok {
+		return treeView.adapter.TreeChildCount(path)
+	}
+	return 0
+}
+
+func (treeView *treeViewData) isLeaf(key string) bool {
+	if treeView.adapter == nil {
+		return true
+	}
+	if path, ok := treeKeyPath(key); ok {
+		return treeView.adapter.IsTreeItemLeaf(path)
+	}
+	return true
+}
+
+func (treeView *treeViewData) isEnabled(key string) bool {
+	if treeView.adapter == nil {
+		return false
+	}
+	if path, ok := treeKeyPath(key); ok {
+		return treeView.adapter.IsTreeItemEnabled(path)
+	}
+	return false
+}
+
+func (treeView *treeViewData) itemView(key string) View {
+	if view, ok := treeView.items[key]; ok {
+		return view
+	}
+	if treeView.adapter == nil {
+		return nil
+	}
+
+	path, ok := treeKeyPath(key)
+	if !ok {
+		return nil
+	}
+
+	view := treeView.adapter.TreeItem(path, treeView.session)
+	if view != nil {
+		view.setParentID(treeView.htmlID())
+		treeView.items[key] = view
+	}
+	return view
+}
+
+func (treeView *treeViewData) ExpandTreeItem(path []int) {
+	treeView.setExpanded(treePathKey(path), true)
+}
+
+func (treeView *treeViewData) CollapseTreeItem(path []int) {
+	treeView.setExpanded(treePathKey(path), false)
+}
+
+func (treeView *treeViewData) IsTreeItemExpanded(path []int) bool {
+	return treeView.expanded[treePathKey(path)]
+}
+
+func (treeView *treeViewData) setExpanded(key string, expanded bool) {
+	if key == "" || treeView.expanded[key] == expanded {
+		return
+	}
+
+	if expanded {
+		if treeView.isLeaf(key) {
+			return
+		}
+		treeView.expanded[key] = true
+	} else {
+		delete(treeView.expanded, key)
+		for itemKey := range treeView.items {
+			if isTreeKeyAncestor(key, itemKey) {
+				delete(treeView.items, itemKey)
+			}
+		}
+		currentHidden := treeView.hasCurrent && isTreeKeyAncestor(key, treeView.current)
+		if currentHidden {
+			treeView.setCurrent(key)
+		}
+
+		// the hidden items can not be seen or deselected, so they are removed from the selection
+		selection := map[string]bool{}
+		for itemKey := range treeView.selected {
+			if !isTreeKeyAncestor(key, itemKey) {
+				selection[itemKey] = true
+			}
+		}
+		if currentHidden && GetTreeViewSelectionMode(treeView) != MultipleSelection {
+			selection[key] = true
+		}
+		treeView.setSelection(selection)
+	}
+
+	if treeView.created {
+		buffer := allocStringBuilder()
+		defer freeStringBuilder(buffer)
+
+		treeView.itemHTML(key, strings.Count(key, "."), buffer)
+		treeView.session.updateInnerHTML(treeView.htmlID()+"-"+key+"-node", buffer.String())
+	}
+
+	if path, ok := treeKeyPath(key); ok {
+		listeners := treeView.collapsedListeners
+		if expanded {
+			listeners = treeView.expandedListeners
+		}
+		for _, listener := range listeners {
+			listener(treeView, path)
+		}
+	}
+}
+
+func (treeView *treeViewData) CurrentTreeItem() []int {
+	if !treeView.hasCurrent {
+		return nil
+	}
+	path, _ := treeKeyPath(treeView.current)
+	return path
+}
+
+func (treeView *treeViewData) SetCurrentTreeItem(path []int) {
+	if path == nil {
+		treeView.clearCurrent()
+		return
+	}
+
+	key := treePathKey(path)
+	for parent := treeParentKey(key); parent != ""; parent = treeParentKey(parent) {
+		treeView.setExpanded(parent, true)
+	}
+	treeView.selectItem(key, false, false)
+	if treeView.created {
+		treeView.session.callFunc("selectTreeItemByID", treeView.htmlID(), treeView.itemID(key))
+	}
+}
+
+func (treeView *treeViewData) clearCurrent() {
+	if !treeView.hasCurrent {
+		return
+	}
+
+	treeView.hasCurrent = false
+	treeView.current = ""
+	if treeView.created {
+		treeView.session.removeProperty(treeView.htmlID(), "data-current")
+		updateInnerHTML(treeView.htmlID(), treeView.session)
+	}
+	for _, listener := range treeView.selectedListeners {
+		listener(treeView, nil)
+	}
+	treeView.setSelection(map[string]bool{})
+}
+
+func (treeView *treeViewData) setCurrent(key string) bool {
+	if treeView.hasCurrent && treeView.current == key {
+		return false
+	}
+
+	treeView.current = key
+	treeView.hasCurrent = true
+	if treeView.created {
+		treeView.session.updateProperty(treeView.htmlID(), "data-current", treeView.itemID(key))
+	}
+
+	if path, ok := treeKeyPath(key); ok {
+		for _, listener := range treeView.selectedListeners {
+			listener(treeView, path)
+		}
+	}
+	treeView.propertyChangedEvent(Current)
+	return true
+}
+
+// selectItem makes the item current and updates the selection according to the pressed keys
+func (treeView *treeViewData) selectItem(key string, ctrl, shift bool) {
+	if !treeView.isEnabled(key) {
+		return
+	}
+
+	if prev := treeView.current; treeView.hasCurrent && prev != key {
+		defer func() {
+			if treeView.created && treeView.selected[prev] {
+				treeView.session.updateProperty(treeView.itemID(prev), "class", treeView.itemClass(prev))
+			}
+		}()
+	}
+
+	treeView.setCurrent(key)
+
+	if GetTreeViewSelectionMode(treeView) != MultipleSelection {
+		treeView.anchor = key
+		treeView.setSelection(map[string]bool{key: true})
+		return
+	}
+
+	switch {
+	case shift:
+		selection := map[string]bool{}
+		visible := treeView.visibleKeys()
+		from, to := -1, -1
+		for i, k := range visible {
+			if k == treeView.anchor {
+				from = i
+			}
+			if k == key {
+				to = i
+			}
+		}
+		if from < 0 {
+			from = to
+		}
+		if from > to {
+			from, to = to, from
+		}
+		if to >= 0 {
+			for _, k := range visible[from : to+1] {
+				if treeView.isEnabled(k) {
+					selection[k] = true
+				}
+			}
+		}
+		if ctrl {
+			for k := range treeView.selected {
+				selection[k] = true
+			}
+		}
+		treeView.setSelection(selection)
+
+	case ctrl:
+		selection := map[string]bool{}
+		for k := range treeView.selected {
+			selection[k] = true
+		}
+		if selection[key] {
+			delete(selection, key)
+		} else {
+			selection[key] = true
+		}
+		treeView.anchor = key
+		treeView.setSelection(selection)
+
+	default:
+		treeView.anchor = key
+		treeView.setSelection(map[string]bool{key: true})
+	}
+}
+
+func (treeView *treeViewData) setSelection(selection map[string]bool) {
+	changed := len(selection) != len(treeView.selected)
+	if !changed {
+		for key := range selection {
+			if !treeView.selected[key] {
+				changed = true
+				break
+			}
+		}
+	}
+	if !changed {
+		return
+	}
+
+	old := treeView.selected
+	treeView.selected = selection
+
+	if treeView.created {
+		session := treeView.session
+		for key := range old {
+			if !selection[key] && key != treeView.current {
+				session.updateProperty(treeView.itemID(key), "class", treeView.itemClass(key))
+			}
+		}
+		for key := range selection {
+			if !old[key] && key != treeView.current {
+				session.updateProperty(treeView.itemID(key), "class", treeView.itemClass(key))
+			}
+		}
+	}
+
+	for _, listener := range treeView.selectionListeners {
+		listener(treeView, treeView.SelectedTreeItems())
+	}
+}
+
+func (treeView *treeViewData) SelectedTreeItems() [][]int {
+	return treeKeysToPaths(treeView.selected)
+}
+
+func treeKeysToPaths(keys map[string]bool) [][]int {
+	result := make([][]int, 0, len(keys))
+	for key := range keys {
+		if path, ok := treeKeyPath(key); ok {
+			result = append(result, path)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return result
+}
+
+// visibleKeys returns the keys of all displayed items in the display order
+func (treeView *treeViewData) visibleKeys() []string {
+	result := []string{}
+	var scan func(key string)
+	scan = func(key string) {
+		count := treeView.childCount(key)
+		for i := 0; i < count; i++ {
+			child := treeChildKey(key, i)
+			result = append(result, child)
+			if treeView.expanded[child] {
+				scan(child)
+			}
+		}
+	}
+	scan("")
+	return result
+}
+
+func (treeView *treeViewData) TreeItemCheckState(path []int) int {
+	return treeView.checkState(treePathKey(path))
+}
+
+func (treeView *treeViewData) checkState(key string) int {
+	if treeView.checked[key] {
+		return TreeCheckOn
+	}
+
+	if GetTreeViewCheckbox(treeView) != MultipleCheckbox {
+		return TreeCheckOff
+	}
+
+	for parent := treeParentKey(key); parent != ""; parent = treeParentKey(parent) {
+		if treeView.checked[parent] {
+			return TreeCheckOn
+		}
+	}
+
+	for checked := range treeView.checked {
+		if isTreeKeyAncestor(key, checked) {
+			return TreeCheckMixed
+		}
+	}
+	return TreeCheckOff
+}
+
+func (treeView *treeViewData) SetTreeItemChecked(path []int, checked bool) {
+	key := treePathKey(path)
+	if key == "" || !treeView.checkItem(key, checked) {
+		return
+	}
+
+	treeView.updateCheckboxes()
+	for _, listener := range treeView.checkedListeners {
+		listener(treeView, treeView.CheckedTreeItems())
+	}
+	treeView.propertyChangedEvent(Checked)
+}
+
+// checkItem changes the checked state of the item. The "checked" map keeps only the topmost checked items:
+// a checked item implies that all its descendants are checked too.
+func (treeView *treeViewData) checkItem(key string, checked bool) bool {
+	checkbox := GetTreeViewCheckbox(treeView)
+	if checkbox == NoneCheckbox || !treeView.isEnabled(key) {
+		return false
+	}
+
+	if checkbox == SingleCheckbox {
+		if treeView.checked[key] == checked {
+			return false
+		}
+		treeView.checked = map[string]bool{}
+		if checked {
+			treeView.checked[key] = true
+		}
+		return true
+	}
+
+	state := treeView.checkState(key)
+	if (checked && state == TreeCheckOn) || (!checked && state == TreeCheckOff) {
+		return false
+	}
+
+	for k := range treeView.checked {
+		if isTreeKeyAncestor(key, k) {
+			delete(treeView.checked, k)
+		}
+	}
+
+	if checked {
+		treeView.checked[key] = true
+		// replace the fully checked parents by the parent item
+		for parent := treeParentKey(key); parent != ""; parent = treeParentKey(parent) {
+			count := treeView.childCount(parent)
+			for i := 0; i < count; i++ {
+				if !treeView.checked[treeChildKey(parent, i)] {
+					return true
+				}
+			}
+			for i := 0; i < count; i++ {
+				delete(treeView.checked, treeChildKey(parent, i))
+			}
+			treeView.checked[parent] = true
+		}
+		return true
+	}
+
+	if treeView.checked[key] {
+		delete(treeView.checked, key)
+		return true
+	}
+
+	// one of parents is checked: uncheck it and check its other descendants
+	ancestor := treeParentKey(key)
+	for ancestor != "" && !treeView.checked[ancestor] {
+		ancestor = treeParentKey(ancestor)
+	}
+	if ancestor == "" {
+		return true
+	}
+
+	delete(treeView.checked, ancestor)
+	for node := key; node != ancestor; node = treeParentKey(node) {
+		parent := treeParentKey(node)
+		count := treeView.childCount(parent)
+		for i := 0; i < count; i++ {
+			if sibling := treeChildKey(parent, i); sibling != node {
+				treeView.checked[sibling] = true
+			}
+		}
+	}
+	return true
+}
+
+func (treeView *treeViewData) CheckedTreeItems() [][]int {
+	return treeKeysToPaths(treeView.checked)
+}
+
+func (treeView *treeViewData) updateCheckboxes() {
+	if !treeView.created {
+		return
+	}
+
+	htmlID := treeView.htmlID()
+	session := treeView.session
+	if session.startUpdateScript(htmlID) {
+		defer session.finishUpdateScript(htmlID)
+	}
+
+	buffer := allocStringBuilder()
+	defer freeStringBuilder(buffer)
+
+	for _, key := range treeView.visibleKeys() {
+		buffer.Reset()
+		treeView.checkboxHTML(key, buffer)
+		session.updateInnerHTML(treeView.itemID(key)+"-check", buffer.String())
+	}
+}
+
+func (treeView *treeViewData) itemID(key string) string {
+	return treeView.htmlID() + "-" + key
+}
+
+func (treeView *treeViewData) itemStyle(tag, defaultStyle string) string {
+	if value := treeView.getRaw(tag); value != nil {
+		if style, ok := value.(string); ok {
+			if style, ok = treeView.session.resolveConstants(style); ok {
+				return style
+			}
+		}
+	}
+	if value := valueFromStyle(treeView, tag); value != nil {
+		if style, ok := value.(string); ok {
+			if style, ok = treeView.session.resolveConstants(style); ok {
+				return style
+			}
+		}
+	}
+	return defaultStyle
+}
+
+func (treeView *treeViewData) listItemStyle() string {
+	return treeView.itemStyle(ListItemStyle, "ruiTreeItem")
+}
+
+func (treeView *treeViewData) currentStyle() string {
+	return treeView.itemStyle(CurrentStyle, "ruiListItemFocused")
+}
+
+func (treeView *treeViewData) currentInactiveStyle() string {
+	return treeView.itemStyle(CurrentInactiveStyle, "ruiListItemSelected")
+}
+
+func (treeView *treeViewData) itemClass(key string) string {
+	cls := "ruiTreeItemRow " + treeView.listItemStyle()
+	if (treeView.hasCurrent && key == treeView.current) || treeView.selected[key] {
+		cls += " " + treeView.currentInactiveStyle()
+	}
+	return cls
+}
+
+func (treeView *treeViewData) indent() string {
+	indent := GetTreeViewIndent(treeView)
+	if indent.Type == Auto {
+		if size, ok := sizeConstant(treeView.session, "ruiTreeIndent"); ok && size.Type != Auto {
+			indent = size
+		} else {
+			indent = Px(16)
+		}
+	}
+	return indent.cssString("16px", treeView.session)
+}
+
+func (treeView *treeViewData) checkboxHTML(key string, buffer *strings.Builder) {
+	session := treeView.session
+	switch GetTreeViewCheckbox(treeView) {
+	case SingleCheckbox:
+		if treeView.checked[key] {
+			buffer.WriteString(session.radiobuttonOnImage())
+		} else {
+			buffer.WriteString(session.radiobuttonOffImage())
+		}
+
+	case MultipleCheckbox:
+		switch treeView.checkState(key) {
+		case TreeCheckOn:
+			buffer.WriteString(session.checkboxOnImage())
+
+		case TreeCheckMixed:
+			buffer.WriteString(session.checkboxMixedImage())
+
+		default:
+			buffer.WriteString(session.checkboxOffImage())
+		}
+	}
+}
+
+func (treeView *treeViewData) itemHTML(key string, level int, buffer *strings.Builder) {
+	itemID := treeView.itemID(key)
+	leaf := treeView.isLeaf(key)
+	expanded := !leaf && treeView.expanded[key]
+	checkbox := GetTreeViewCheckbox(treeView)
+
+	buffer.WriteString(`<div id="`)
+	buffer.WriteString(itemID)
+	buffer.WriteString(`" class="`)
+	buffer.WriteString(treeView.itemClass(key))
+	buffer.WriteString(`" data-tree="`)
+	buffer.WriteString(treeView.htmlID())
+	buffer.WriteString(`" data-path="`)
+	buffer.WriteString(key)
+	if parent := treeParentKey(key); parent != "" {
+		buffer.WriteString(`" data-parent="`)
+		buffer.WriteString(treeView.itemID(parent))
+	}
+	buffer.WriteString(`" data-expanded="`)
+	switch {
+	case leaf:
+		buffer.WriteString(`-1`)
+	case expanded:
+		buffer.WriteString(`1`)
+	default:
+		buffer.WriteString(`0`)
+	}
+	if !treeView.isEnabled(key) {
+		buffer.WriteString(`" data-disabled="1`)
+	}
+	buffer.WriteString(`" onclick="treeItemClickEvent(this, event)" style="display: grid; grid-template-columns: auto auto 1fr; align-items: center;">`)
+
+	buffer.WriteString(`<div class="ruiTreeToggle" onclick="treeToggleClickEvent(this, event)"`)
+	if level > 0 {
+		buffer.WriteString(` style="margin-left: calc(`)
+		buffer.WriteString(treeView.indent())
+		buffer.WriteString(` * `)
+		buffer.WriteString(strconv.Itoa(level))
+		buffer.WriteString(`);"`)
+	}
+	buffer.WriteString(`>`)
+	switch {
+	case leaf:
+	case expanded:
+		buffer.WriteString(`&#9662;`)
+	default:
+		buffer.WriteString(`&#9656;`)
+	}
+	buffer.WriteString(`</div>`)
+
+	buffer.WriteString(`<div id="`)
+	buffer.WriteString(itemID)
+	buffer.WriteString(`-check"`)
+	if checkbox != NoneCheckbox {
+		buffer.WriteString(` class="ruiTreeCheckbox" onclick="treeCheckboxClickEvent(this, event)">`)
+		treeView.checkboxHTML(key, buffer)
+	} else {
+		buffer.WriteString(`>`)
+	}
+	buffer.WriteString(`</div><div>`)
+
+	if view := treeView.itemView(key); view != nil {
+		viewHTML(view, buffer)
+	} else {
+		buffer.WriteString("ERROR: invalid item view")
+	}
+	buffer.WriteString(`</div></div>`)
+
+	if expanded {
+		buffer.WriteString(`<div role="group">`)
+		treeView.childrenHTML(key, level+1, buffer)
+		buffer.WriteString(`</div>`)
+	}
+}
+
+func (treeView *treeViewData) childrenHTML(key string, level int, buffer *strings.Builder) {
+	count := treeView.childCount(key)
+	for i := 0; i < count; i++ {
+		child := treeChildKey(key, i)
+		buffer.WriteString(`<div id="`)
+		buffer.WriteString(treeView.itemID(child))
+		buffer.WriteString(`-node" role="treeitem">`)
+		treeView.itemHTML(child, level, buffer)
+		buffer.WriteString(`</div>`)
+	}
+}
+
+func (treeView *treeViewData) htmlProperties(self View, buffer *strings.Builder) {
+	treeView.viewData.htmlProperties(self, buffer)
+	buffer.WriteString(` role="tree" onfocus="treeViewFocusEvent(this, event)" onblur="treeViewBlurEvent(this, event)"`)
+	buffer.WriteString(` onkeydown="treeViewKeyDownEvent(this, event)" data-focusitemstyle="`)
+	buffer.WriteString(treeView.currentStyle())
+	buffer.WriteString(`" data-bluritemstyle="`)
+	buffer.WriteString(treeView.currentInactiveStyle())
+	buffer.WriteString(`"`)
+	if treeView.hasCurrent {
+		buffer.WriteString(` data-current="`)
+		buffer.WriteString(treeView.itemID(treeView.current))
+		buffer.WriteRune('"')
+	}
+}
+
+func (treeView *treeViewData) htmlSubviews(self View, buffer *strings.Builder) {
+	if treeView.adapter == nil {
+		return
+	}
+
+	if !treeView.session.ignoreViewUpdates() {
+		treeView.session.setIgnoreViewUpdates(true)
+		defer treeView.session.setIgnoreViewUpdates(false)
+	}
+
+	buffer.WriteString(`<div style="display: flex; flex-direction: column; min-width: 100%;">`)
+	treeView.childrenHTML("", 0, buffer)
+	buffer.WriteString(`</div>`)
+}
+
+func (treeView *treeViewData) handleCommand(self View, command string, data DataObject) bool {
+	pathKey := func() (string, bool) {
+		if key, ok := data.PropertyValue("path"); ok {
+			if _, ok := treeKeyPath(key); ok && key != "" {
+				return key, true
+			}
+		}
+		return "", false
+	}
+
+	switch command {
+	case "treeItemSelected", "treeItemClick":
+		if key, ok := pathKey(); ok && !IsDisabled(treeView) {
+			treeView.selectItem(key, dataBoolProperty(data, "ctrl"), dataBoolProperty(data, "shift"))
+			if command == "treeItemClick" {
+				if path, ok := treeKeyPath(key); ok {
+					for _, listener := range treeView.clickedListeners {
+						listener(treeView, path)
+					}
+				}
+			}
+		}
+
+	case "treeItemToggle":
+		if key, ok := pathKey(); ok && !IsDisabled(treeView) {
+			treeView.setExpanded(key, !treeView.expanded[key])
+		}
+
+	case "treeItemCheck":
+		if key, ok := pathKey(); ok && !IsDisabled(treeView) {
+			if path, ok := treeKeyPath(key); ok {
+				treeView.SetTreeItemChecked(path, treeView.checkState(key) != TreeCheckOn)
+			}
+		}
+
+	default:
+		return treeView.viewData.handleCommand(self, command, data)
+	}
+
+	return true
+}
+
+// GetTreeItemClickedListeners returns a TreeItemClickedListener of the TreeView.
+// If there are no listeners then the empty list is returned
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTreeItemClickedListeners(view View, subviewID ...string) []func(TreeView, []int) {
+	return getEventListeners[TreeView, []int](view, subviewID, TreeItemClickedEvent)
+}
+
+// GetTreeItemSelectedListeners returns a TreeItemSelectedListener of the TreeView.
+// If there are no listeners then the empty list is returned
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTreeItemSelectedListeners(view View, subviewID ...string) []func(TreeView, []int) {
+	return getEventListeners[TreeView, []int](view, subviewID, TreeItemSelectedEvent)
+}
+
+// GetTreeSelectionChangedListeners returns a TreeSelectionChangedListener of the TreeView.
+// If there are no listeners then the empty list is returned
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTreeSelectionChangedListeners(view View, subviewID ...string) []func(TreeView, [][]int) {
+	return getEventListeners[TreeView, [][]int](view, subviewID, TreeSelectionChangedEvent)
+}
+
+// GetTreeItemExpandedListeners returns a TreeItemExpandedListener of the TreeView.
+// If there are no listeners then the empty list is returned
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTreeItemExpandedListeners(view View, subviewID ...string) []func(TreeView, []int) {
+	return getEventListeners[TreeView, []int](view, subviewID, TreeItemExpandedEvent)
+}
+
+// GetTreeItemCollapsedListeners returns a TreeItemCollapsedListener of the TreeView.
+// If there are no listeners then the empty list is returned
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTreeItemCollapsedListeners(view View, subviewID ...string) []func(TreeView, []int) {
+	return getEventListeners[TreeView, []int](view, subviewID, TreeItemCollapsedEvent)
+}
+
+// GetTreeItemCheckedListeners returns a TreeItemCheckedListener of the TreeView.
+// If there are no listeners then the empty list is returned
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTreeItemCheckedListeners(view View, subviewID ...string) []func(TreeView, [][]int) {
+	return getEventListeners[TreeView, [][]int](view, subviewID, TreeItemCheckedEvent)
+}
+
+// GetTreeViewCheckbox returns the TreeView checkbox type: NoneCheckbox (0), SingleCheckbox (1), or MultipleCheckbox (2).
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTreeViewCheckbox(view View, subviewID ...string) int {
+	return enumStyledProperty(view, subviewID, ItemCheckbox, NoneCheckbox, false)
+}
+
+// GetTreeViewSelectionMode returns the TreeView selection mode: SingleSelection (0) or MultipleSelection (1).
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTreeViewSelectionMode(view View, subviewID ...string) int {
+	return enumStyledProperty(view, subviewID, TreeSelectionMode, SingleSelection, false)
+}
+
+// GetTreeViewIndent returns the indent of the TreeView child items.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTreeViewIndent(view View, subviewID ...string) SizeUnit {
+	return sizeStyledProperty(view, subviewID, TreeIndent, false)
+}
+
+// GetTreeViewAdapter - returns the TreeView adapter.
+// If the second argument (subviewID) is not specified or it is "" then a value from the first argument (view) is returned.
+func GetTreeViewAdapter(view View, subviewID ...string) TreeAdapter {
+	if len(subviewID) > 0 && subviewID[0] != "" {
+		view = ViewByID(view, subviewID[0])
+	}
+	if view != nil {
+		if value := view.Get(Items); value != nil {
+			if adapter, ok := value.(TreeAdapter); ok {
+				return adapter
+			}
+		}
+	}
+	return nil
+}
+
+// ReloadTreeViewData updates TreeView content
+// If the second argument (subviewID) is not specified or it is "" then content the first argument (view) is updated.
+func ReloadTreeViewData(view View, subviewID ...string) {
+	if len(subviewID) > 0 && subviewID[0] != "" {
+		view = ViewByID(view, subviewID[0])
+	}
+
+	if view != nil {
+		if treeView, ok := view.(TreeView); ok {
+			treeView.ReloadTreeViewData()
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package rui
+
+import (
+	"testing"
+)
+
+func TestTreeViewCheck(t *testing.T) {
+	createTestLog(t, false)
+	session := newSession(nil, 0, "", nil)
+
+	nodes := []TreeNode{
+		{Text: "A", Children: []TreeNode{{Text: "A1"}, {Text: "A2"}, {Text: "A3"}}},
+		{Text: "B"},
+	}
+
+	treeView := NewTreeView(session, Params{
+		Items:        nodes,
+		ItemCheckbox: MultipleCheckbox,
+	})
+
+	checkState := func(path []int, expected int) {
+		if state := treeView.TreeItemCheckState(path); state != expected {
+			t.Errorf("TreeItemCheckState(%v) = %d, expected: %d", path, state, expected)
+		}
+	}
+
+	treeView.SetTreeItemChecked([]int{0, 1}, true)
+	checkState([]int{0}, TreeCheckMixed)
+	checkState([]int{0, 1}, TreeCheckOn)
+	checkState([]int{0, 0}, TreeCheckOff)
+
+	treeView.SetTreeItemChecked([]int{0, 0}, true)
+	treeView.SetTreeItemChecked([]int{0, 2}, true)
+	checkState([]int{0}, TreeCheckOn)
+	if checked := treeView.CheckedTreeItems(); len(checked) != 1 || treePathKey(checked[0]) != "0" {
+		t.Errorf("CheckedTreeItems() = %v, expected: [[0]]", checked)
+	}
+
+	treeView.SetTreeItemChecked([]int{0, 1}, false)
+	checkState([]int{0}, TreeCheckMixed)
+	checkState([]int{0, 0}, TreeCheckOn)
+	checkState([]int{0, 1}, TreeCheckOff)
+	checkState([]int{1}, TreeCheckOff)
+
+	treeView.SetTreeItemChecked([]int{0}, false)
+	if checked := treeView.CheckedTreeItems(); len(checked) != 0 {
+		t.Errorf("CheckedTreeItems() = %v, expected: []", checked)
+	}
+}
+
+func TestTreeViewInitChecked(t *testing.T) {
+	createTestLog(t, false)
+	session := newSession(nil, 0, "", nil)
+
+	listenerCalled := false
+	treeView := NewTreeView(session, Params{
+		Items:                []string{"a", "b"},
+		ItemCheckbox:         MultipleCheckbox,
+		Checked:              "1",
+		TreeItemCheckedEvent: func(TreeView, [][]int) { listenerCalled = true },
+	})
+	if checked := treeView.CheckedTreeItems(); len(checked) != 1 || treePathKey(checked[0]) != "1" {
+		t.Errorf("CheckedTreeItems() = %v, expected: [[1]]", checked)
+	}
+	if listenerCalled {
+		t.Error("the checked listener is called on the view creation")
+	}
+
+	view := CreateViewFromText(session, `TreeView {
+		checked = "0.1, 1",
+		items = [_{ text = A, items = [A1, A2] }, B],
+		checkbox = multiple,
+	}`)
+	treeView, ok := view.(TreeView)
+	if !ok {
+		t.Fatal("TreeView is not created")
+	}
+	if checked := treeView.CheckedTreeItems(); len(checked) != 2 ||
+		treePathKey(checked[0]) != "0.1" || treePathKey(checked[1]) != "1" {
+		t.Errorf("CheckedTreeItems() = %v, expected: [[0 1] [1]]", checked)
+	}
+
+	copy, ok := CreateViewFromText(session, ViewToRUI(treeView)).(TreeView)
+	if !ok {
+		t.Fatalf("ViewToRUI result is not parsed:\n%s", ViewToRUI(treeView))
+	}
+	if checked := copy.CheckedTreeItems(); len(checked) != 2 {
+		t.Errorf("CheckedTreeItems() after ViewToRUI = %v", checked)
+	}
+}
+
+func TestTreeViewSelection(t *testing.T) {
+	createTestLog(t, false)
+	session := newSession(nil, 0, "", nil)
+
+	treeView := NewTreeView(session, Params{
+		Items: []TreeNode{
+			{Text: "A", Children: []TreeNode{{Text: "A1"}, {Text: "A2"}}},
+			{Text: "B"},
+		},
+		TreeSelectionMode: MultipleSelection,
+	}).(*treeViewData)
+
+	treeView.ExpandTreeItem([]int{0})
+	if !treeView.IsTreeItemExpanded([]int{0}) {
+		t.Error("Item [0] is not expanded")
+	}
+
+	treeView.selectItem("0", false, false)
+	treeView.selectItem("1", false, true)
+	if selected := treeView.SelectedTreeItems(); len(selected) != 4 {
+		t.Errorf("SelectedTreeItems() = %v, expected 4 items", selected)
+	}
+
+	treeView.selectItem("0.1", true, false)
+	if selected := treeView.SelectedTreeItems(); len(selected) != 3 {
+		t.Errorf("SelectedTreeItems() = %v, expected 3 items", selected)
+	}
+
+	treeView.CollapseTreeItem([]int{0})
+	if current := treeView.CurrentTreeItem(); len(current) != 1 || current[0] != 0 {
+		t.Errorf("CurrentTreeItem() = %v, expected: [0]", current)
+	}
+	// the hidden children are removed from the selection
+	if selected := treeView.SelectedTreeItems(); len(selected) != 2 || treeView.selected["0.0"] {
+		t.Errorf("SelectedTreeItems() = %v, expected: [[0] [1]]", selected)
+	}
+
+	// a disabled TreeView can not be expanded from the client
+	treeView.Set(Disabled, true)
+	toggle := ParseDataText(`treeItemToggle{session=0, id=` + treeView.htmlID() + `, path="0"}`)
+	treeView.handleCommand(treeView, toggle.Tag(), toggle)
+	if treeView.IsTreeItemExpanded([]int{0}) {
+		t.Error("The disabled TreeView is expanded")
+	}
+}
@@ -38,6 +38,19 @@ func freeStringBuilder(builder *strings.Builder) {
 	}
 }
 
+var htmlEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&#39;",
+)
+
+// htmlEscape replaces the special characters of HTML (and XML) text and attribute values with the character references
+func htmlEscape(text string) string {
+	return htmlEscaper.Replace(text)
+}
+
 func GetLocalIP() string {
 	addrs, err := net.InterfaceAddrs()
 	if err != nil {
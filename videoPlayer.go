@@ -44,6 +44,12 @@ func (player *videoPlayerData) init(session Session) {
 	player.tag = "VideoPlayer"
 }
 
+func (player *videoPlayerData) supportedProperties() []string {
+	return append([]string{
+		VideoWidth, VideoHeight, Poster,
+	}, player.mediaPlayerData.supportedProperties()...)
+}
+
 func (player *videoPlayerData) String() string {
 	return getViewString(player)
 }
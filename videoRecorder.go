@@ -0,0 +1,59 @@
+package rui
+
+const (
+	// RecordAudio is the constant for the "record-audio" property tag of VideoRecorder.
+	// The "record-audio" bool property defines whether the sound from the microphone is recorded with the video.
+	// The default value is true.
+	RecordAudio = "record-audio"
+	// FacingMode is the constant for the "facing-mode" property tag of VideoRecorder.
+	// The "facing-mode" int property defines the preferred camera of the mobile device:
+	// UserFacing (0) - the front camera (by default), EnvironmentFacing (1) - the back camera.
+	FacingMode = "facing-mode"
+
+	// UserFacing - value of the "facing-mode" property: the camera facing the user (the front camera).
+	UserFacing = 0
+	// EnvironmentFacing - value of the "facing-mode" property: the camera facing away from the user (the back camera).
+	EnvironmentFacing = 1
+)
+
+// VideoRecorder - the view showing the live preview of the camera and recording the video.
+// After the camera is closed the view shows the last recording
+type VideoRecorder interface {
+	MediaRecorder
+}
+
+type videoRecorderData struct {
+	mediaRecorderData
+}
+
+// NewVideoRecorder create new VideoRecorder object and return it
+func NewVideoRecorder(session Session, params Params) VideoRecorder {
+	view := new(videoRecorderData)
+	view.init(session)
+	setInitParams(view, params)
+	return view
+}
+
+func newVideoRecorder(session Session) View {
+	return NewVideoRecorder(session, nil)
+}
+
+func (recorder *videoRecorderData) init(session Session) {
+	recorder.mediaRecorderData.init(session)
+	recorder.tag = "VideoRecorder"
+	recorder.video = true
+}
+
+func (recorder *videoRecorderData) supportedProperties() []string {
+	return append([]string{
+		RecordAudio, FacingMode,
+	}, recorder.mediaRecorderData.supportedProperties()...)
+}
+
+func (recorder *videoRecorderData) String() string {
+	return getViewString(recorder)
+}
+
+func (recorder *videoRecorderData) htmlTag() string {
+	return "video"
+}
@@ -60,6 +60,7 @@ type View interface {
 	htmlClass(disabled bool) string
 	htmlTag() string
 	closeHTMLTag() bool
+	supportedProperties() []string
 	htmlID() string
 	parentHTMLID() string
 	setParentID(parentID string)
@@ -137,6 +138,31 @@ func (view *viewData) init(session Session) {
 	view.created = false
 }
 
+// supportedProperties returns the properties handled by all views which are not included
+// in the lists of typed properties (sizeProperties, enumProperties, etc.)
+func (view *viewData) supportedProperties() []string {
+	return []string{
+		ID, Style, StyleDisabled, UserData, "tab-index", Tooltip, Title, Icon, Row, Column,
+		FocusEvent, LostFocusEvent, KeyDownEvent, KeyUpEvent, ClickEvent, DoubleClickEvent,
+		MouseDown, MouseUp, MouseMove, MouseOut, MouseOver, ContextMenuEvent,
+		PointerDown, PointerUp, PointerMove, PointerOut, PointerOver, PointerCancel,
+		TouchStart, TouchEnd, TouchMove, TouchCancel, ResizeEvent, ScrollEvent,
+		TransitionRunEvent, TransitionStartEvent, TransitionEndEvent, TransitionCancelEvent,
+		AnimationStartEvent, AnimationEndEvent, AnimationIterationEvent, AnimationCancelEvent,
+		Background, Border, BorderTop, BorderRight, BorderBottom, BorderLeft,
+		BorderTopStyle, BorderRightStyle, BorderBottomStyle, BorderLeftStyle, Outline,
+		CellBorder, CellBorderTop, CellBorderRight, CellBorderBottom, CellBorderLeft,
+		CellBorderStyle, CellBorderTopStyle, CellBorderRightStyle, CellBorderBottomStyle, CellBorderLeftStyle,
+		CellBorderColor, CellBorderTopColor, CellBorderRightColor, CellBorderBottomColor, CellBorderLeftColor,
+		CellBorderWidth, CellBorderTopWidth, CellBorderRightWidth, CellBorderBottomWidth, CellBorderLeftWidth,
+		CellPadding, CellPaddingTop, CellPaddingRight, CellPaddingBottom, CellPaddingLeft,
+		CellWidth, CellHeight, CellStyle, RowStyle, ColumnStyle, HeadStyle, FootStyle,
+		ColumnSeparator, ColumnSeparatorStyle, ColumnSeparatorWidth, ColumnSeparatorColor,
+		Shadow, TextShadow, Clip, ShapeOutside, Filter, BackdropFilter, FontName, AccentColor,
+		Transition, AnimationTag,
+	}
+}
+
 func (view *viewData) Session() Session {
 	return view.session
 }
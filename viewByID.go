@@ -289,6 +289,18 @@ func CanvasViewByID(rootView View, id string) CanvasView {
 	return nil
 }
 
+// ChartViewByID return a ChartView (LineChart, BarChart, PieChart or ScatterChart) with id equal to the argument of the function or
+// nil if there is no such View or View is not ChartView
+func ChartViewByID(rootView View, id string) ChartView {
+	if view := ViewByID(rootView, id); view != nil {
+		if chart, ok := view.(ChartView); ok {
+			return chart
+		}
+		ErrorLog(`ChartViewByID(_, "` + id + `"): The found View is not ChartView`)
+	}
+	return nil
+}
+
 /*
 // TableViewByID return a TableView with id equal to the argument of the function or
 // nil if there is no such View or View is not TableView
@@ -327,6 +339,30 @@ func VideoPlayerByID(rootView View, id string) VideoPlayer {
 	return nil
 }
 
+// AudioRecorderByID return an AudioRecorder with id equal to the argument of the function or
+// nil if there is no such View or View is not AudioRecorder
+func AudioRecorderByID(rootView View, id string) AudioRecorder {
+	if view := ViewByID(rootView, id); view != nil {
+		if recorder, ok := view.(AudioRecorder); ok {
+			return recorder
+		}
+		ErrorLog(`AudioRecorderByID(_, "` + id + `"): The found View is not AudioRecorder`)
+	}
+	return nil
+}
+
+// VideoRecorderByID return a VideoRecorder with id equal to the argument of the function or
+// nil if there is no such View or View is not VideoRecorder
+func VideoRecorderByID(rootView View, id string) VideoRecorder {
+	if view := ViewByID(rootView, id); view != nil {
+		if recorder, ok := view.(VideoRecorder); ok {
+			return recorder
+		}
+		ErrorLog(`VideoRecorderByID(_, "` + id + `"): The found View is not VideoRecorder`)
+	}
+	return nil
+}
+
 // ImageViewByID return a ImageView with id equal to the argument of the function or
 // nil if there is no such View or View is not ImageView
 func ImageViewByID(rootView View, id string) ImageView {
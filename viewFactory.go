@@ -2,38 +2,47 @@ package rui
 
 import (
 	"os"
-	"path/filepath"
-	"strings"
 )
 
 var viewCreators = map[string]func(Session) View{
-	"View":           newView,
-	"ColumnLayout":   newColumnLayout,
-	"ListLayout":     newListLayout,
-	"GridLayout":     newGridLayout,
-	"StackLayout":    newStackLayout,
-	"TabsLayout":     newTabsLayout,
-	"AbsoluteLayout": newAbsoluteLayout,
-	"Resizable":      newResizable,
-	"DetailsView":    newDetailsView,
-	"TextView":       newTextView,
-	"Button":         newButton,
-	"Checkbox":       newCheckbox,
-	"DropDownList":   newDropDownList,
-	"ProgressBar":    newProgressBar,
-	"NumberPicker":   newNumberPicker,
-	"ColorPicker":    newColorPicker,
-	"DatePicker":     newDatePicker,
-	"TimePicker":     newTimePicker,
-	"FilePicker":     newFilePicker,
-	"EditView":       newEditView,
-	"ListView":       newListView,
-	"CanvasView":     newCanvasView,
-	"ImageView":      newImageView,
-	"SvgImageView":   newSvgImageView,
-	"TableView":      newTableView,
-	"AudioPlayer":    newAudioPlayer,
-	"VideoPlayer":    newVideoPlayer,
+	"View":            newView,
+	"ColumnLayout":    newColumnLayout,
+	"ListLayout":      newListLayout,
+	"GridLayout":      newGridLayout,
+	"StackLayout":     newStackLayout,
+	"TabsLayout":      newTabsLayout,
+	"AbsoluteLayout":  newAbsoluteLayout,
+	"Resizable":       newResizable,
+	"DetailsView":     newDetailsView,
+	"TextView":        newTextView,
+	"Button":          newButton,
+	"Checkbox":        newCheckbox,
+	"DropDownList":    newDropDownList,
+	"ProgressBar":     newProgressBar,
+	"NumberPicker":    newNumberPicker,
+	"ColorPicker":     newColorPicker,
+	"DatePicker":      newDatePicker,
+	"TimePicker":      newTimePicker,
+	"DateTimePicker":  newDateTimePicker,
+	"DateRangePicker": newDateRangePicker,
+	"CalendarView":    newCalendarView,
+	"FilePicker":      newFilePicker,
+	"EditView":        newEditView,
+	"CodeEditor":      newCodeEditor,
+	"ListView":        newListView,
+	"TreeView":        newTreeView,
+	"CanvasView":      newCanvasView,
+	"LineChart":       newLineChart,
+	"BarChart":        newBarChart,
+	"PieChart":        newPieChart,
+	"ScatterChart":    newScatterChart,
+	"ImageView":       newImageView,
+	"SvgImageView":    newSvgImageView,
+	"TableView":       newTableView,
+	"AudioPlayer":     newAudioPlayer,
+	"VideoPlayer":     newVideoPlayer,
+	"AudioRecorder":   newAudioRecorder,
+	"VideoRecorder":   newVideoRecorder,
 }
 
 // RegisterViewCreator register function of creating view
@@ -58,9 +67,18 @@ func RegisterViewCreator(tag string, creator func(Session) View) bool {
 		"ColorPicker",
 		"DatePicker",
 		"TimePicker",
+		"DateTimePicker",
+		"DateRangePicker",
+		"CalendarView",
 		"EditView",
+		"CodeEditor",
 		"ListView",
+		"TreeView",
 		"CanvasView",
+		"LineChart",
+		"BarChart",
+		"PieChart",
+		"ScatterChart",
 		"ImageView",
 		"TableView",
 	}
@@ -77,6 +95,9 @@ func RegisterViewCreator(tag string, creator func(Session) View) bool {
 
 // CreateViewFromObject create new View and initialize it by Node data
 func CreateViewFromObject(session Session, object DataObject) View {
+	if object == nil {
+		return nil
+	}
 	tag := object.Tag()
 
 	if creator, ok := viewCreators[tag]; ok {
@@ -107,12 +128,23 @@ func CreateViewFromText(session Session, text string) View {
 }
 
 // CreateViewFromResources create new View and initialize it by the content of
-// the resource file from "views" directory
+// the resource file from "views" directory. The file can be in the .rui, JSON (.json) or YAML (.yaml, .yml) format.
+// If the name has no extension then the "name.rui", "name.json", "name.yaml", and "name.yml" files are searched
 func CreateViewFromResources(session Session, name string) View {
-	if strings.ToLower(filepath.Ext(name)) != ".rui" {
-		name += ".rui"
+	names := []string{name}
+	if !isDataResourceFile(name) {
+		names = []string{name + ".rui", name + ".json", name + ".yaml", name + ".yml"}
+	}
+
+	for _, name := range names {
+		if view := createViewFromResourceFile(session, name); view != nil {
+			return view
+		}
 	}
+	return nil
+}
 
+func createViewFromResourceFile(session Session, name string) View {
 	for _, fs := range resources.embedFS {
 		rootDirs := embedRootDirs(fs)
 		for _, dir := range rootDirs {
@@ -122,14 +154,14 @@ func CreateViewFromResources(session Session, name string) View {
 
 			case viewDir:
 				if data, err := fs.ReadFile(dir + "/" + name); err == nil {
-					if data := ParseDataText(string(data)); data != nil {
+					if data := parseDataResource(name, string(data)); data != nil {
 						return CreateViewFromObject(session, data)
 					}
 				}
 
 			default:
 				if data, err := fs.ReadFile(dir + "/" + viewDir + "/" + name); err == nil {
-					if data := ParseDataText(string(data)); data != nil {
+					if data := parseDataResource(name, string(data)); data != nil {
 						return CreateViewFromObject(session, data)
 					}
 				}
@@ -139,7 +171,7 @@ func CreateViewFromResources(session Session, name string) View {
 
 	if resources.path != "" {
 		if data, err := os.ReadFile(resources.path + viewDir + "/" + name); err == nil {
-			if data := ParseDataText(string(data)); data != nil {
+			if data := parseDataResource(name, string(data)); data != nil {
 				return CreateViewFromObject(session, data)
 			}
 		}
@@ -219,7 +219,11 @@ func (style *viewStyle) cssViewStyle(builder cssBuilder, session Session) {
 			if !ok {
 				cssTag = tag
 			}
-			builder.add(cssTag, size.cssString("", session))
+			if variable, ok := cssVariableReference(style, tag, "const"); ok {
+				builder.add(cssTag, variable)
+			} else {
+				builder.add(cssTag, size.cssString("", session))
+			}
 		}
 	}
 
@@ -232,7 +236,11 @@ func (style *viewStyle) cssViewStyle(builder cssBuilder, session Session) {
 	}
 	for _, p := range colorProperties {
 		if color, ok := colorProperty(style, p.property, session); ok && color != 0 {
-			builder.add(p.cssTag, color.cssString())
+			if variable, ok := cssVariableReference(style, p.property, "color"); ok {
+				builder.add(p.cssTag, variable)
+			} else {
+				builder.add(p.cssTag, color.cssString())
+			}
 		}
 	}
 
@@ -590,7 +598,7 @@ func writePropertyValue(buffer *strings.Builder, tag string, value any, indent s
 			} else {
 				for _, ch := range text {
 					if (ch >= '0' && ch <= '9') || (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') ||
-						ch == '+' || ch == '-' || ch == '@' || ch == '/' || ch == '_' || ch == ':' ||
+						ch == '+' || ch == '-' || ch == '@' || ch == '_' || ch == ':' ||
 						ch == '#' || ch == '%' || ch == 'π' || ch == '°' {
 					} else {
 						simple = false
@@ -647,8 +655,8 @@ func writePropertyValue(buffer *strings.Builder, tag string, value any, indent s
 					writeString(text)
 					buffer.WriteString(",\n")
 				}
+				buffer.WriteString(indent)
 			}
-			buffer.WriteString(indent)
 			buffer.WriteRune(']')
 		}
 
@@ -777,7 +785,11 @@ func writeViewStyle(name string, view ViewStyle, buffer *strings.Builder, indent
 	buffer.WriteString(" {\n")
 	indent += "\t"
 
+	rootView, isView := view.(View)
 	writeProperty := func(tag string, value any) {
+		if isView {
+			value = viewRUIValue(rootView, tag, value)
+		}
 		if supportedPropertyValue(value) {
 			buffer.WriteString(indent)
 			buffer.WriteString(tag)
@@ -788,6 +800,9 @@ func writeViewStyle(name string, view ViewStyle, buffer *strings.Builder, indent
 	}
 
 	tags := view.AllTags()
+	if isView {
+		tags = viewRUITags(rootView, tags)
+	}
 	removeTag := func(tag string) {
 		for i, t := range tags {
 			if t == tag {
@@ -844,6 +859,10 @@ func writeViewStyle(name string, view ViewStyle, buffer *strings.Builder, indent
 		}
 	}
 
+	if isView {
+		writeViewListeners(rootView, buffer, indent)
+	}
+
 	indent = indent[:len(indent)-1]
 	buffer.WriteString(indent)
 	buffer.WriteString("}")
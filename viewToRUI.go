@@ -0,0 +1,334 @@
+package rui
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ViewToRUI returns the description of the view and all its subviews in the .rui format.
+// The result can be passed to CreateViewFromText or saved to the "views" resource directory.
+//
+// Besides the regular properties the following is written:
+//   - the items of ListView as an array of strings (if the items are set by []string) or an array of views;
+//   - the items and the disabled items of DropDownList;
+//   - the selected days of CalendarView as a list of dates separated by commas;
+//   - the items of TreeView created from texts as an array of texts and objects "_{ text = ..., items = [...] }"
+//     and the paths of the checked items ("0.1, 2");
+//   - the content of TableView as an array of rows, where each row is an object "_{ cells = [...] }".
+//     All cells except views are written as text, table joins are written as empty cells;
+//   - the event listeners as comments, for example "// click-event: 2 listeners".
+//     Listeners are functions, so they must be set again after the view is re-created.
+//
+// ViewToRUI returns the same text as the View.String() method
+func ViewToRUI(view View) string {
+	if view == nil {
+		return ""
+	}
+	return getViewString(view)
+}
+
+// viewExtraRUITags is the list of properties which are not stored in the property list of views
+var viewExtraRUITags = []string{Items, DisabledItems, Checked}
+
+// viewEventRUITags is the list of event properties which can be stored outside the property list of views
+var viewEventRUITags = []string{
+	CheckboxChangedEvent, DropDownEvent, EditTextChangedEvent, NumberChangedEvent, ColorChangedEvent,
+	DateChangedEvent, TimeChangedEvent, DateTimeChangedEvent, DateRangeChangedEvent, CodeChangedEvent,
+	CalendarSelectionChangedEvent, FileSelectedEvent, CurrentTabChangedEvent, TabCloseEvent, DismissEvent,
+	ListItemClickedEvent, ListItemSelectedEvent, ListItemCheckedEvent,
+	TableCellClickedEvent, TableCellSelectedEvent, TableRowClickedEvent, TableRowSelectedEvent,
+	TreeItemClickedEvent, TreeItemSelectedEvent, TreeItemCheckedEvent, TreeItemExpandedEvent,
+	TreeItemCollapsedEvent, TreeSelectionChangedEvent, ResizeEvent, ScrollEvent, LoadedEvent, ErrorEvent,
+	AbortEvent, CanPlayEvent, CanPlayThroughEvent, CompleteEvent, DurationChangedEvent, EmptiedEvent,
+	EndedEvent, LoadedDataEvent, LoadedMetadataEvent, LoadStartEvent, PauseEvent, PlayEvent, PlayingEvent,
+	ProgressEvent, RateChangedEvent, SeekedEvent, SeekingEvent, StalledEvent, SuspendEvent,
+	TimeUpdateEvent, VolumeChangedEvent, WaitingEvent, PlayerErrorEvent,
+}
+
+// viewRUITags appends to "tags" the tags of the view properties which are not returned by AllTags
+func viewRUITags(view View, tags []string) []string {
+	for _, tag := range viewExtraRUITags {
+		if containsTag(tags, tag) {
+			continue
+		}
+		switch value := view.Get(tag).(type) {
+		case nil:
+		case ListAdapter:
+			if value != nil && value.ListSize() > 0 {
+				tags = append(tags, tag)
+			}
+		case TreeAdapter:
+			if adapter, ok := value.(*textTreeAdapter); ok && len(adapter.nodes) > 0 {
+				tags = append(tags, tag)
+			}
+		case []string:
+			if len(value) > 0 {
+				tags = append(tags, tag)
+			}
+		case []any:
+			if len(value) > 0 {
+				tags = append(tags, tag)
+			}
+		case []time.Time:
+			if len(value) > 0 {
+				tags = append(tags, tag)
+			}
+		case [][]int:
+			if len(value) > 0 {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// viewRUIValue converts the values which can not be written directly (adapters, dates, time zones)
+// to the .rui compatible values
+func viewRUIValue(view View, tag string, value any) any {
+	switch value := value.(type) {
+	case time.Time:
+		switch view := view.(type) {
+		case *dateTimePickerData:
+			return view.formatValue(value)
+
+		case *timePickerData:
+			return value.Format(timeFormat)
+		}
+		return value.Format(dateFormat)
+
+	case []time.Time:
+		dates := make([]string, len(value))
+		for i, date := range value {
+			dates[i] = date.Format(dateFormat)
+		}
+		return strings.Join(dates, ", ")
+
+	case [][]int:
+		keys := make([]string, len(value))
+		for i, path := range value {
+			keys[i] = treePathKey(path)
+		}
+		return strings.Join(keys, ", ")
+
+	case *time.Location:
+		if value != nil {
+			return value.String()
+		}
+
+	case TreeAdapter:
+		if adapter, ok := value.(*textTreeAdapter); ok {
+			return treeRUIContent(adapter.nodes)
+		}
+		return nil
+
+	case ListAdapter:
+		if adapter, ok := value.(*textListAdapter); ok {
+			return adapter.items
+		}
+		session := view.Session()
+		count := value.ListSize()
+		items := make([]View, 0, count)
+		for i := 0; i < count; i++ {
+			if item := value.ListItem(i, session); item != nil {
+				items = append(items, item)
+			}
+		}
+		return items
+
+	case TableAdapter:
+		return &tableRUIContent{adapter: value, session: view.Session()}
+	}
+	return value
+}
+
+// writeViewListeners writes the references to the event listeners of the view as comments
+func writeViewListeners(view View, buffer *strings.Builder, indent string) {
+	tags := view.AllTags()
+	for _, tag := range viewEventRUITags {
+		if !containsTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+
+	for _, tag := range tags {
+		value := reflect.ValueOf(view.Get(tag))
+		if value.Kind() != reflect.Slice || value.Type().Elem().Kind() != reflect.Func {
+			continue
+		}
+
+		if count := value.Len(); count > 0 {
+			buffer.WriteString(indent)
+			buffer.WriteString("// ")
+			buffer.WriteString(tag)
+			buffer.WriteString(": ")
+			buffer.WriteString(strconv.Itoa(count))
+			if count == 1 {
+				buffer.WriteString(" listener\n")
+			} else {
+				buffer.WriteString(" listeners\n")
+			}
+		}
+	}
+}
+
+type treeRUIContent []TreeNode
+
+func (nodes treeRUIContent) writeString(buffer *strings.Builder, indent string) {
+	if len(nodes) == 0 {
+		buffer.WriteString("[]")
+		return
+	}
+
+	itemIndent := indent
+	indent = indent[:len(indent)-1]
+	buffer.WriteString("[\n")
+
+	for _, node := range nodes {
+		buffer.WriteString(itemIndent)
+		if len(node.Children) == 0 {
+			writePropertyValue(buffer, Text, node.Text, itemIndent)
+		} else {
+			buffer.WriteString("_{ text = ")
+			writePropertyValue(buffer, Text, node.Text, itemIndent)
+			buffer.WriteString(", items = ")
+			treeRUIContent(node.Children).writeString(buffer, itemIndent+"\t")
+			buffer.WriteString(" }")
+		}
+		buffer.WriteString(",\n")
+	}
+
+	buffer.WriteString(indent)
+	buffer.WriteRune(']')
+}
+
+type tableRUIContent struct {
+	adapter TableAdapter
+	session Session
+}
+
+func (content *tableRUIContent) writeString(buffer *strings.Builder, indent string) {
+	rowCount := content.adapter.RowCount()
+	columnCount := content.adapter.ColumnCount()
+	if rowCount == 0 {
+		buffer.WriteString("[]")
+		return
+	}
+
+	rowIndent := indent
+	indent = indent[:len(indent)-1]
+	buffer.WriteString("[\n")
+
+	for row := 0; row < rowCount; row++ {
+		buffer.WriteString(rowIndent)
+		buffer.WriteString("_{ cells = [")
+		for column := 0; column < columnCount; column++ {
+			if column > 0 {
+				buffer.WriteString(", ")
+			}
+			if view, ok := content.adapter.Cell(row, column).(View); ok && view != nil {
+				writeViewStyle(view.Tag(), view, buffer, rowIndent)
+			} else {
+				writePropertyValue(buffer, Text, content.cellText(row, column), rowIndent)
+			}
+		}
+		buffer.WriteString("] },\n")
+	}
+
+	buffer.WriteString(indent)
+	buffer.WriteRune(']')
+}
+
+func (content *tableRUIContent) cellText(row, column int) string {
+	switch value := content.adapter.Cell(row, column).(type) {
+	case string:
+		return value
+
+	case rune:
+		return string(value)
+
+	case bool:
+		if value {
+			return "true"
+		}
+		return "false"
+
+	case float32:
+		return fmt.Sprintf("%g", float64(value))
+
+	case float64:
+		return fmt.Sprintf("%g", value)
+
+	case time.Time:
+		return content.session.Locale().formatTime(value)
+
+	case VerticalTableJoin, HorizontalTableJoin:
+		return ""
+
+	case fmt.Stringer:
+		return value.String()
+
+	default:
+		if n, ok := isInt(value); ok {
+			return strconv.Itoa(n)
+		}
+	}
+	return ""
+}
+
+// parseTableRUIContent converts the rows written by ViewToRUI ("_{ cells = [...] }") to the table content
+func parseTableRUIContent(session Session, rows []DataValue) ([][]any, bool) {
+	content := make([][]any, len(rows))
+	for i, row := range rows {
+		if !row.IsObject() {
+			return nil, false
+		}
+
+		node := row.Object().PropertyByTag("cells")
+		if node == nil {
+			content[i] = []any{}
+			continue
+		}
+
+		switch node.Type() {
+		case TextNode:
+			content[i] = []any{node.Text()}
+
+		case ObjectNode:
+			view := CreateViewFromObject(session, node.Object())
+			if view == nil {
+				return nil, false
+			}
+			content[i] = []any{view}
+
+		case ArrayNode:
+			cells := node.ArrayElements()
+			content[i] = make([]any, len(cells))
+			for j, cell := range cells {
+				if cell.IsObject() {
+					view := CreateViewFromObject(session, cell.Object())
+					if view == nil {
+						return nil, false
+					}
+					content[i][j] = view
+				} else {
+					content[i][j] = cell.Value()
+				}
+			}
+		}
+	}
+	return content, true
+}
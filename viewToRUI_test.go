@@ -0,0 +1,182 @@
+package rui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestViewToRUI(t *testing.T) {
+	createTestLog(t, false)
+	session := newSession(nil, 0, "", nil)
+
+	view := NewColumnLayout(session, Params{
+		ID:      "root",
+		Padding: Px(8),
+		Content: []View{
+			NewTextView(session, Params{ID: "title", Text: "Hello \"world\""}),
+			NewListView(session, Params{ID: "texts", Items: []string{"one", "two"}}),
+			NewListView(session, Params{ID: "views", Items: []View{
+				NewButton(session, Params{Content: "OK"}),
+			}}),
+			NewDropDownList(session, Params{ID: "dropDown", Items: []string{"A", "B", "C"}, DisabledItems: []int{1, 2}, Current: 2}),
+			NewTableView(session, Params{ID: "table", Content: [][]any{
+				{"Name", 'x', 1.5},
+				{NewTextView(session, Params{Text: "cell"}), true, 10},
+			}}),
+		},
+		ClickEvent: func(View) {},
+	})
+	view.Set(ClickEvent, []func(View){func(View) {}, func(View) {}})
+	ViewByID(view, "dropDown").Set(DropDownEvent, func(DropDownList, int) {})
+
+	text := ViewToRUI(view)
+	for _, comment := range []string{"// click-event: 2 listeners", "// drop-down-event: 1 listener"} {
+		if !strings.Contains(text, comment) {
+			t.Errorf(`"%s" is not found in:\n%s`, comment, text)
+		}
+	}
+
+	copy := CreateViewFromText(session, text)
+	if copy == nil {
+		t.Fatalf("ViewToRUI result is not parsed:\n%s", text)
+	}
+	if copyText := ViewToRUI(copy); copyText != removeRUIComments(text) {
+		t.Errorf("round trip error.\nResult:\n%s\nExpected:\n%s", copyText, text)
+	}
+
+	if GetText(copy, "title") != `Hello "world"` {
+		t.Errorf(`title: "%s"`, GetText(copy, "title"))
+	}
+
+	if adapter := GetListViewAdapter(copy, "texts"); adapter == nil || adapter.ListSize() != 2 {
+		t.Error("text items of ListView are lost")
+	}
+
+	if adapter := GetListViewAdapter(copy, "views"); adapter == nil || adapter.ListSize() != 1 {
+		t.Error("view items of ListView are lost")
+	} else if button, ok := adapter.ListItem(0, session).(Button); !ok || GetText(button.(ViewsContainer).Views()[0]) != "OK" {
+		t.Error("button item of ListView is lost")
+	}
+
+	if items := GetDropDownItems(copy, "dropDown"); len(items) != 3 || items[2] != "C" {
+		t.Errorf("DropDownList items: %v", items)
+	}
+	if items := GetDropDownDisabledItems(copy, "dropDown"); len(items) != 2 || items[1] != 2 {
+		t.Errorf("DropDownList disabled items: %v", items)
+	}
+	if current := GetCurrent(copy, "dropDown"); current != 2 {
+		t.Errorf("DropDownList current: %d", current)
+	}
+
+	content := GetTableContent(copy, "table")
+	if content == nil || content.RowCount() != 2 || content.ColumnCount() != 3 {
+		t.Fatal("TableView content is lost")
+	}
+	if cell := content.Cell(0, 2); cell != "1.5" {
+		t.Errorf("cell (0, 2): %v", cell)
+	}
+	if cell, ok := content.Cell(1, 0).(TextView); !ok || GetText(cell) != "cell" {
+		t.Errorf("cell (1, 0): %v", content.Cell(1, 0))
+	}
+}
+
+func TestViewToRUIRoundTrip(t *testing.T) {
+	createTestLog(t, false)
+	session := newSession(nil, 0, "", nil)
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skip(err)
+	}
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	view := NewColumnLayout(session, Params{
+		ID: "root",
+		Content: []View{
+			NewTreeView(session, Params{
+				ID:                "tree",
+				TreeSelectionMode: MultipleSelection,
+				Items: []TreeNode{
+					{Text: "src/"},
+					{Text: "docs", Children: []TreeNode{{Text: "README.md"}, {Text: "api", Children: []TreeNode{{Text: "index"}}}}},
+				},
+			}),
+			NewCodeEditor(session, Params{ID: "code", Text: "a := 1\n\tb", ShowLineNumbers: false, CodeInsertSpaces: true}),
+			NewCalendarView(session, Params{
+				ID:                    "calendar",
+				CalendarMin:           day(2024, 1, 1),
+				CalendarMax:           "2024-12-31",
+				CalendarDate:          day(2024, 5, 1),
+				CalendarSelectionMode: MultipleSelection,
+				Checked:               []time.Time{day(2024, 5, 3), day(2024, 5, 7)},
+			}),
+			NewDateRangePicker(session, Params{
+				ID:             "range",
+				CalendarMin:    day(2024, 1, 1),
+				DateRangeStart: day(2024, 2, 1),
+				DateRangeEnd:   "2024-02-10",
+			}),
+			NewDateTimePicker(session, Params{
+				ID:                  "dateTime",
+				TimeZone:            moscow,
+				DateTimePickerMin:   time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+				DateTimePickerValue: time.Date(2024, 3, 1, 10, 30, 15, 0, moscow),
+			}),
+			NewLineChart(session, Params{ID: "line", ChartTitle: "Sales", ChartZoom: true, ChartXAxisType: TimeChartAxis}),
+			NewBarChart(session, Params{ID: "bar", ChartLegend: false}),
+			NewPieChart(session, Params{ID: "pie", ChartTooltip: false}),
+			NewScatterChart(session, Params{ID: "scatter"}),
+			NewAudioRecorder(session, Params{ID: "audio", RecorderMimeType: "audio/webm"}),
+			NewVideoRecorder(session, Params{ID: "video", RecordAudio: false, FacingMode: "user"}),
+		},
+	})
+
+	text := ViewToRUI(view)
+	copy := CreateViewFromText(session, text)
+	if copy == nil {
+		t.Fatalf("ViewToRUI result is not parsed:\n%s", text)
+	}
+	if copyText := ViewToRUI(copy); copyText != text {
+		t.Errorf("round trip error.\nResult:\n%s\nExpected:\n%s", copyText, text)
+	}
+
+	if adapter := GetTreeViewAdapter(copy, "tree"); adapter == nil || adapter.TreeChildCount(nil) != 2 ||
+		adapter.TreeChildCount([]int{1}) != 2 || adapter.TreeChildCount([]int{1, 1}) != 1 {
+		t.Error("TreeView items are lost")
+	} else if item := adapter.TreeItem([]int{1, 1, 0}, session); GetText(item) != "index" {
+		t.Errorf("TreeView item 1.1.0: %v", item)
+	}
+
+	if calendar, ok := ViewByID(copy, "calendar").(CalendarView); !ok {
+		t.Error("CalendarView is lost")
+	} else if dates := calendar.SelectedDates(); len(dates) != 2 || !dates[1].Equal(day(2024, 5, 7)) {
+		t.Errorf("CalendarView selected dates: %v", dates)
+	} else if calendar.IsDateEnabled(day(2023, 12, 31)) {
+		t.Error("CalendarView min date is lost")
+	}
+
+	if start, end, ok := GetDateRange(copy, "range"); !ok || !start.Equal(day(2024, 2, 1)) || !end.Equal(day(2024, 2, 10)) {
+		t.Errorf("DateRangePicker range: %v - %v", start, end)
+	}
+
+	if location := GetTimeZone(copy, "dateTime"); location.String() != "Europe/Moscow" {
+		t.Errorf("DateTimePicker time zone: %v", location)
+	}
+	if value := GetDateTimePickerValue(copy, "dateTime"); !value.Equal(time.Date(2024, 3, 1, 10, 30, 15, 0, moscow)) {
+		t.Errorf("DateTimePicker value: %v", value)
+	}
+}
+
+func removeRUIComments(text string) string {
+	lines := strings.Split(text, "\n")
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "//") {
+			result = append(result, line)
+		}
+	}
+	return strings.Join(result, "\n")
+}
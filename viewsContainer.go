@@ -33,6 +33,12 @@ func (container *viewsContainerData) init(session Session) {
 	container.views = []View{}
 }
 
+func (container *viewsContainerData) supportedProperties() []string {
+	return append([]string{
+		Content, Disabled,
+	}, container.viewData.supportedProperties()...)
+}
+
 func (container *viewsContainerData) String() string {
 	return getViewString(container)
 }
@@ -4,6 +4,7 @@ package rui
 
 import (
 	"fmt"
+	"image"
 	"strconv"
 	"strings"
 	"syscall/js"
@@ -184,6 +185,16 @@ func (bridge *wasmBridge) callCanvasImageFunc(url string, property string, funcN
 	}
 }
 
+func (bridge *wasmBridge) callCanvasImageDataFunc(funcName string, img *image.NRGBA, args ...any) {
+	if !bridge.canvas.IsNull() {
+		bounds := img.Bounds()
+		data := js.Global().Get("Uint8Array").New(len(img.Pix))
+		js.CopyBytesToJS(data, img.Pix)
+		bridge.printFuncToLog(funcName, append([]any{"ctx", "data", bounds.Dx(), bounds.Dy()}, args...)...)
+		js.Global().Call(funcName, append([]any{bridge.canvas, data, bounds.Dx(), bounds.Dy()}, args...)...)
+	}
+}
+
 func (bridge *wasmBridge) createCanvasVar(funcName string, args ...any) any {
 	if bridge.canvas.IsNull() {
 		return bridge.canvas
@@ -247,6 +258,58 @@ func (bridge *wasmBridge) canvasTextMetrics(htmlID, font, text string) TextMetri
 	return result
 }
 
+func (bridge *wasmBridge) canvasImageData(htmlID string, x, y, width, height float64) (result *image.NRGBA) {
+	// getImageData throws SecurityError if the canvas is tainted by a cross-origin image
+	defer func() {
+		if err := recover(); err != nil {
+			ErrorLog(fmt.Sprint("Unable to read the canvas image data: ", err))
+			result = nil
+		}
+	}()
+
+	imageData := js.Global().Call("readCanvasImageData", htmlID, x, y, width, height)
+	if imageData.IsUndefined() || imageData.IsNull() {
+		return nil
+	}
+
+	w := imageData.Get("width").Int()
+	h := imageData.Get("height").Int()
+	data := imageData.Get("data")
+	if w <= 0 || h <= 0 || data.Get("length").Int() != 4*w*h {
+		return nil
+	}
+
+	result = image.NewNRGBA(image.Rect(0, 0, w, h))
+	js.CopyBytesToGo(result.Pix, js.Global().Get("Uint8Array").New(data.Get("buffer")))
+	return result
+}
+
+func (bridge *wasmBridge) canvasDataURL(htmlID, mimeType string, quality float64) (result []byte) {
+	// toDataURL throws SecurityError if the canvas is tainted by a cross-origin image
+	defer func() {
+		if err := recover(); err != nil {
+			ErrorLog(fmt.Sprint("Unable to encode the canvas image: ", err))
+			result = nil
+		}
+	}()
+
+	canvas := js.Global().Get("document").Call("getElementById", htmlID)
+	if canvas.IsUndefined() || canvas.IsNull() {
+		return nil
+	}
+
+	var url js.Value
+	if quality >= 0 {
+		url = canvas.Call("toDataURL", mimeType, quality)
+	} else {
+		url = canvas.Call("toDataURL", mimeType)
+	}
+	if url.Type() != js.TypeString {
+		return nil
+	}
+	return canvasDataURLBytes(url.String())
+}
+
 func (bridge *wasmBridge) htmlPropertyValue(htmlID, name string) string {
 	element := js.Global().Get("document").Call("getElementById", htmlID)
 	if !element.IsUndefined() && !element.IsNull() {
@@ -3,7 +3,9 @@
 package rui
 
 import (
+	"encoding/base64"
 	"fmt"
+	"image"
 	"net/http"
 	"strconv"
 	"strings"
@@ -21,6 +23,7 @@ type wsBridge struct {
 	closed          bool
 	buffer          strings.Builder
 	canvasBuffer    strings.Builder
+	canvasID        string
 	canvasVarNumber int
 	updateScripts   map[string]*strings.Builder
 }
@@ -243,10 +246,12 @@ if (styles) {
 }
 
 func (bridge *wsBridge) canvasStart(htmlID string) {
+	bridge.canvasID = htmlID
 	bridge.canvasBuffer.Reset()
 	bridge.canvasBuffer.WriteString(`const ctx = getCanvasContext('`)
 	bridge.canvasBuffer.WriteString(htmlID)
 	bridge.canvasBuffer.WriteString(`');`)
+	bridge.canvasBuffer.WriteString("\nctx.canvasVars = {};")
 }
 
 func (bridge *wsBridge) callCanvasFunc(funcName string, args ...any) {
@@ -272,10 +277,12 @@ func (bridge *wsBridge) updateCanvasProperty(property string, value any) {
 	bridge.canvasBuffer.WriteString(";")
 }
 
+// createCanvasVar stores the variable in the canvasVars object of the context (not in a script variable),
+// so the variable remains available in the script started by flushCanvas
 func (bridge *wsBridge) createCanvasVar(funcName string, args ...any) any {
 	bridge.canvasVarNumber++
-	result := canvasVar{name: fmt.Sprintf("v%d", bridge.canvasVarNumber)}
-	bridge.canvasBuffer.WriteString("\nvar ")
+	result := canvasVar{name: fmt.Sprintf("ctx.canvasVars.v%d", bridge.canvasVarNumber)}
+	bridge.canvasBuffer.WriteString("\n")
 	bridge.canvasBuffer.WriteString(result.name)
 	bridge.canvasBuffer.WriteString(" = ctx.")
 	bridge.canvasBuffer.WriteString(funcName)
@@ -332,10 +339,30 @@ func (bridge *wsBridge) callCanvasImageFunc(url string, property string, funcNam
 	bridge.canvasBuffer.WriteString(");\n}")
 }
 
+func (bridge *wsBridge) callCanvasImageDataFunc(funcName string, img *image.NRGBA, args ...any) {
+	bounds := img.Bounds()
+	bridge.canvasBuffer.WriteString("\n")
+	bridge.canvasBuffer.WriteString(funcName)
+	bridge.canvasBuffer.WriteString("(ctx, '")
+	bridge.canvasBuffer.WriteString(base64.StdEncoding.EncodeToString(img.Pix))
+	bridge.canvasBuffer.WriteString("', ")
+	bridge.canvasBuffer.WriteString(strconv.Itoa(bounds.Dx()))
+	bridge.canvasBuffer.WriteString(", ")
+	bridge.canvasBuffer.WriteString(strconv.Itoa(bounds.Dy()))
+	for _, arg := range args {
+		bridge.canvasBuffer.WriteString(", ")
+		argText, _ := bridge.argToString(arg)
+		bridge.canvasBuffer.WriteString(argText)
+	}
+	bridge.canvasBuffer.WriteString(");")
+}
+
 func (bridge *wsBridge) canvasFinish() {
 	bridge.senderMutex.Lock()
 	defer bridge.senderMutex.Unlock()
 
+	bridge.canvasID = ""
+
 	bridge.canvasBuffer.WriteString("\n")
 	script := bridge.canvasBuffer.String()
 	if ProtocolInDebugLog {
@@ -383,31 +410,106 @@ func (bridge *wsBridge) writeMessage(script string) bool {
 func (bridge *wsBridge) canvasTextMetrics(htmlID, font, text string) TextMetrics {
 	result := TextMetrics{}
 
+	answer := make(chan DataObject)
 	bridge.answerMutex.Lock()
 	answerID := bridge.answerID
 	bridge.answerID++
+	bridge.answer[answerID] = answer
+	bridge.answerMutex.Unlock()
+
+	if bridge.callFunc("canvasTextMetrics", answerID, htmlID, font, text) {
+		data := <-answer
+		result.Width = dataFloatProperty(data, "width")
+	}
+
+	bridge.answerMutex.Lock()
+	delete(bridge.answer, answerID)
 	bridge.answerMutex.Unlock()
+	return result
+}
+
+// flushCanvas sends the commands of the canvas drawn before reading of the canvas content.
+// The drawing continues with the same context and the same canvas variables
+func (bridge *wsBridge) flushCanvas(htmlID string) {
+	if bridge.canvasID == htmlID {
+		bridge.canvasBuffer.WriteString("\n")
+		bridge.writeMessage(bridge.canvasBuffer.String())
+		bridge.canvasBuffer.Reset()
+		bridge.canvasBuffer.WriteString(`const ctx = document.getElementById('`)
+		bridge.canvasBuffer.WriteString(htmlID)
+		bridge.canvasBuffer.WriteString(`').getContext('2d');`)
+	}
+}
+
+func (bridge *wsBridge) canvasImageData(htmlID string, x, y, width, height float64) *image.NRGBA {
+	bridge.flushCanvas(htmlID)
 
 	answer := make(chan DataObject)
+	bridge.answerMutex.Lock()
+	answerID := bridge.answerID
+	bridge.answerID++
 	bridge.answer[answerID] = answer
+	bridge.answerMutex.Unlock()
 
-	if bridge.callFunc("canvasTextMetrics", answerID, htmlID, font, text) {
+	var result *image.NRGBA
+	if bridge.callFunc("getCanvasImageData", answerID, htmlID, x, y, width, height) {
 		data := <-answer
-		result.Width = dataFloatProperty(data, "width")
+		if text, ok := data.PropertyValue("error"); ok {
+			ErrorLog("Unable to read the canvas image data: " + text)
+		}
+		w, _ := dataIntProperty(data, "width")
+		h, _ := dataIntProperty(data, "height")
+		if text, ok := data.PropertyValue("data"); ok && w > 0 && h > 0 {
+			if pix, err := base64.StdEncoding.DecodeString(text); err != nil {
+				ErrorLog(err.Error())
+			} else if len(pix) != 4*w*h {
+				ErrorLogF(`Invalid size of the image data: %d, expected %d`, len(pix), 4*w*h)
+			} else {
+				result = &image.NRGBA{Pix: pix, Stride: 4 * w, Rect: image.Rect(0, 0, w, h)}
+			}
+		}
 	}
 
+	bridge.answerMutex.Lock()
 	delete(bridge.answer, answerID)
+	bridge.answerMutex.Unlock()
 	return result
 }
 
-func (bridge *wsBridge) htmlPropertyValue(htmlID, name string) string {
+func (bridge *wsBridge) canvasDataURL(htmlID, mimeType string, quality float64) []byte {
+	bridge.flushCanvas(htmlID)
+
+	answer := make(chan DataObject)
 	bridge.answerMutex.Lock()
 	answerID := bridge.answerID
 	bridge.answerID++
+	bridge.answer[answerID] = answer
+	bridge.answerMutex.Unlock()
+
+	var result []byte
+	if bridge.callFunc("getCanvasDataURL", answerID, htmlID, mimeType, quality) {
+		data := <-answer
+		if text, ok := data.PropertyValue("error"); ok {
+			ErrorLog("Unable to encode the canvas image: " + text)
+		}
+		if url, ok := data.PropertyValue("url"); ok {
+			result = canvasDataURLBytes(url)
+		}
+	}
+
+	bridge.answerMutex.Lock()
+	delete(bridge.answer, answerID)
 	bridge.answerMutex.Unlock()
+	return result
+}
 
+func (bridge *wsBridge) htmlPropertyValue(htmlID, name string) string {
 	answer := make(chan DataObject)
+	bridge.answerMutex.Lock()
+	answerID := bridge.answerID
+	bridge.answerID++
 	bridge.answer[answerID] = answer
+	bridge.answerMutex.Unlock()
 
 	if bridge.callFunc("getPropertyValue", answerID, htmlID, name) {
 		data := <-answer
@@ -416,16 +518,22 @@ func (bridge *wsBridge) htmlPropertyValue(htmlID, name string) string {
 		}
 	}
 
+	bridge.answerMutex.Lock()
 	delete(bridge.answer, answerID)
+	bridge.answerMutex.Unlock()
 	return ""
 }
 
 func (bridge *wsBridge) answerReceived(answer DataObject) {
 	if text, ok := answer.PropertyValue("answerID"); ok {
 		if id, err := strconv.Atoi(text); err == nil {
-			if chanel, ok := bridge.answer[id]; ok {
+			bridge.answerMutex.Lock()
+			chanel, ok := bridge.answer[id]
+			delete(bridge.answer, id)
+			bridge.answerMutex.Unlock()
+
+			if ok {
 				chanel <- answer
-				delete(bridge.answer, id)
 			} else {
 				ErrorLog("Bad answerID = " + text + " (chan not found)")
 			}
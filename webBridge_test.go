@@ -0,0 +1,142 @@
+//go:build !wasm
+
+package rui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// createTestSocketBridge returns the socket bridge connected to the returned client connection
+func createTestSocketBridge(t *testing.T) (*wsBridge, *websocket.Conn) {
+	bridges := make(chan webBridge, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		bridges <- CreateSocketBridge(w, req)
+	}))
+
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	bridge, ok := (<-bridges).(*wsBridge)
+	if !ok || bridge == nil {
+		t.Fatal("the socket bridge is not created")
+	}
+	t.Cleanup(bridge.close)
+	return bridge, conn
+}
+
+func TestCanvasVarAfterFlush(t *testing.T) {
+	createTestLog(t, false)
+	bridge, conn := createTestSocketBridge(t)
+
+	// GetImageData and ToDataURL flush the drawn commands, the gradient must be used after that
+	bridge.canvasStart("canvas1")
+	gradient := bridge.createCanvasVar("createLinearGradient", 0, 0, 100, 0)
+	bridge.callCanvasVarFunc(gradient, "addColorStop", 0, "red")
+	bridge.flushCanvas("canvas1")
+	bridge.updateCanvasProperty("fillStyle", gradient)
+	bridge.callCanvasFunc("fillRect", 0, 0, 100, 100)
+	bridge.canvasFinish()
+
+	read := func() string {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	first := read()
+	if !strings.Contains(first, "ctx.canvasVars = {};\nctx.canvasVars.v1 = ctx.createLinearGradient(0, 0, 100, 0);") ||
+		!strings.Contains(first, "ctx.canvasVars.v1.addColorStop(0, 'red');") {
+		t.Errorf("invalid script before the flush:\n%s", first)
+	}
+
+	second := read()
+	if strings.Contains(second, "canvasVars = {}") || strings.Contains(second, "var ") ||
+		!strings.Contains(second, "ctx.fillStyle = ctx.canvasVars.v1;") {
+		t.Errorf("invalid script after the flush:\n%s", second)
+	}
+}
+
+func TestCanvasImageDataError(t *testing.T) {
+	errorText := ""
+	SetErrorLog(func(text string) {
+		errorText += text + "\n"
+	})
+	defer createTestLog(t, false)
+
+	bridge, conn := createTestSocketBridge(t)
+
+	// the client answers as the browser does when the canvas is tainted by a cross-origin image
+	go func() {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if args, ok := strings.CutPrefix(string(data), "getCanvasImageData("); ok {
+				answerID, _, _ := strings.Cut(args, ",")
+				conn.WriteMessage(websocket.TextMessage,
+					[]byte("answer{answerID="+answerID+", width=0, height=0, error=`SecurityError`}"))
+			}
+		}
+	}()
+	go func() {
+		if message, ok := bridge.readMessage(); ok {
+			bridge.answerReceived(ParseDataText(message))
+		}
+	}()
+
+	if result := bridge.canvasImageData("canvas1", 0, 0, 10, 10); result != nil {
+		t.Error("the image data of the tainted canvas is not nil")
+	}
+	if !strings.Contains(errorText, "SecurityError") {
+		t.Errorf("the error is not logged: %q", errorText)
+	}
+}
+
+func TestCanvasDataURLError(t *testing.T) {
+	errorText := ""
+	SetErrorLog(func(text string) {
+		errorText += text + "\n"
+	})
+	defer createTestLog(t, false)
+
+	bridge, conn := createTestSocketBridge(t)
+
+	go func() {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if args, ok := strings.CutPrefix(string(data), "getCanvasDataURL("); ok {
+				answerID, _, _ := strings.Cut(args, ",")
+				conn.WriteMessage(websocket.TextMessage,
+					[]byte("answer{answerID="+answerID+", url=\"\", error=`SecurityError`}"))
+			}
+		}
+	}()
+	go func() {
+		if message, ok := bridge.readMessage(); ok {
+			bridge.answerReceived(ParseDataText(message))
+		}
+	}()
+
+	if result := bridge.canvasDataURL("canvas1", "image/png", -1); result != nil {
+		t.Error("the image of the tainted canvas is not nil")
+	}
+	if !strings.Contains(errorText, "SecurityError") {
+		t.Errorf("the error is not logged: %q", errorText)
+	}
+}